@@ -34,6 +34,7 @@ TEST_OPTS:=--atomic \
   --set spire.enabled='${SPIRE_ENABLED}',spire.org='${CONTAINER_REPO}',spire.tag='${CONTAINER_TAG}' \
   --set admission-webhook.org='${CONTAINER_REPO}',admission-webhook.tag='${CONTAINER_TAG}',admission-webhook.enforceLimits='${ENFORCE_LIMITS}' \
   --set prefix-service.org='${CONTAINER_REPO}',prefix-service.tag='${CONTAINER_TAG}' \
+  --set ipam-service.org='${CONTAINER_REPO}',ipam-service.tag='${CONTAINER_TAG}' \
   --namespace '${NSM_NAMESPACE}'
 
 .PHONY: helm-init
@@ -32,7 +32,7 @@ func (m *Mechanism) Clone() *Mechanism {
 	return proto.Clone(m).(*Mechanism)
 }
 
-var mechanismValidators map[string]func(*Mechanism) error
+var mechanismValidators = map[string]func(*Mechanism) error{}
 var mechanismValidatorsMutex sync.Mutex
 
 // AddMechanism adds a Mechanism
@@ -0,0 +1,102 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geneve
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/common"
+)
+
+func init() {
+	connection.AddMechanism(MECHANISM, validate)
+}
+
+// Mechanism - a geneve mechanism utility wrapper
+type Mechanism interface {
+	// SrcIP -  src ip
+	SrcIP() (string, error)
+	// DstIP - dst ip
+	DstIP() (string, error)
+	// VNI - vni
+	VNI() (uint32, error)
+}
+
+type mechanism struct {
+	*connection.Mechanism
+}
+
+// ToMechanism - convert unified mechanism to useful wrapper
+func ToMechanism(m *connection.Mechanism) Mechanism {
+	if m.Type == MECHANISM {
+		return &mechanism{
+			m,
+		}
+	}
+	return nil
+}
+
+func (m *mechanism) SrcIP() (string, error) {
+	return common.GetSrcIP(m.Mechanism)
+}
+
+func (m *mechanism) DstIP() (string, error) {
+	return common.GetDstIP(m.Mechanism)
+}
+
+// VNI returns the VNI parameter of the Mechanism
+func (m *mechanism) VNI() (uint32, error) {
+	if m == nil {
+		return 0, errors.New("mechanism cannot be nil")
+	}
+
+	if m.GetParameters() == nil {
+		return 0, errors.Errorf("mechanism.Parameters cannot be nil: %v", m)
+	}
+
+	genevevni, ok := m.Parameters[VNI]
+	if !ok {
+		return 0, errors.Errorf("mechanism.Type %s requires mechanism.Parameters[%s]", m.GetType(), VNI)
+	}
+
+	vni, err := strconv.ParseUint(genevevni, 10, 24)
+	if err != nil {
+		return 0, errors.Wrapf(err, "mechanism.Parameters[%s] must be a valid 24-bit unsigned integer, instead was: %s: %v", VNI, genevevni, m)
+	}
+
+	return uint32(vni), nil
+}
+
+// validate - source IP is required, destination IP and VNI are set by NSMD on mechanism selection
+func validate(m *connection.Mechanism) error {
+	gm := ToMechanism(m)
+	if _, err := gm.SrcIP(); err != nil {
+		return err
+	}
+	if _, ok := m.GetParameters()[DstIP]; ok {
+		if _, err := gm.DstIP(); err != nil {
+			return err
+		}
+	}
+	if _, ok := m.GetParameters()[VNI]; ok {
+		if _, err := gm.VNI(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gre - GRE remote mechanism constants and helpers
+package gre
+
+import (
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/common"
+)
+
+const (
+	// Mechanism string
+	MECHANISM = "GRE"
+
+	// Mechanism parameters
+	// SrcIP - source IP
+	SrcIP = common.SrcIP
+	// DstIP - destination IP
+	DstIP = common.DstIP
+	// SrcOriginalIP - original src IP
+	SrcOriginalIP = common.SrcOriginalIP
+	// DstExternalIP - external destination ip
+	DstExternalIP = common.DstExternalIP
+	// Key - GRE key identifying the tunnel between the peers
+	Key = "key"
+)
@@ -0,0 +1,59 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipsec - IPsec (ESP) remote mechanism constants and helpers
+package ipsec
+
+import (
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/common"
+)
+
+const (
+	// MECHANISM string
+	MECHANISM = "IPSEC"
+
+	// Mechanism parameters
+	// SrcIP - source IP
+	SrcIP = common.SrcIP
+	// DstIP - destination IP
+	DstIP = common.DstIP
+	// SrcOriginalIP - original src IP
+	SrcOriginalIP = common.SrcOriginalIP
+	// DstExternalIP - external destination ip
+	DstExternalIP = common.DstExternalIP
+	// SrcSPI - SPI of the security association to the source host, set by the source NSMD
+	SrcSPI = "src_spi"
+	// DstSPI - SPI of the security association to the destination host, set by the destination NSMD
+	DstSPI = "dst_spi"
+	// SrcPublicKey - base64 encoded X25519 public key of the source forwarder, advertised by the forwarder
+	SrcPublicKey = "src_public_key"
+	// DstPublicKey - base64 encoded X25519 public key of the destination forwarder, set by the destination NSMD
+	DstPublicKey = "dst_public_key"
+	// Encapsulation - encapsulation of the connection inside ESP tunnel, advertised by the forwarder
+	Encapsulation = "encapsulation"
+
+	// EncapsulationNone - the connection is an L3 XFRM interface
+	EncapsulationNone = ""
+	// EncapsulationVXLAN - the connection is an L2 VXLAN interface over XFRM interface
+	EncapsulationVXLAN = "VXLAN"
+
+	// Algorithm - AEAD algorithm of the security associations
+	Algorithm = "rfc4106(gcm(aes))"
+	// KeyLength - length of AES-128 key and 4 bytes salt, keys are derived by the forwarders from their key pairs
+	KeyLength = 20
+	// PublicKeyLength - length of X25519 public key
+	PublicKeyLength = 32
+	// ICVLength - length of the integrity check value in bits
+	ICVLength = 128
+)
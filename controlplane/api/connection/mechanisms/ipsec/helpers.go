@@ -0,0 +1,198 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipsec
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/common"
+)
+
+// minSPI - SPIs below 256 are reserved by RFC 4303
+const minSPI = 256
+
+func init() {
+	connection.AddMechanism(MECHANISM, validate)
+}
+
+// Mechanism - an ipsec mechanism utility wrapper
+type Mechanism interface {
+	// SrcIP -  src ip
+	SrcIP() (string, error)
+	// DstIP - dst ip
+	DstIP() (string, error)
+	// SrcSPI - SPI of the security association to the source host
+	SrcSPI() (uint32, error)
+	// DstSPI - SPI of the security association to the destination host
+	DstSPI() (uint32, error)
+	// SrcPublicKey - public key of the source forwarder
+	SrcPublicKey() ([]byte, error)
+	// DstPublicKey - public key of the destination forwarder
+	DstPublicKey() ([]byte, error)
+	// Encapsulation - encapsulation of the connection inside ESP tunnel
+	Encapsulation() string
+}
+
+type mechanism struct {
+	*connection.Mechanism
+}
+
+// ToMechanism - convert unified mechanism to useful wrapper
+func ToMechanism(m *connection.Mechanism) Mechanism {
+	if m.Type == MECHANISM {
+		return &mechanism{
+			m,
+		}
+	}
+	return nil
+}
+
+func (m *mechanism) SrcIP() (string, error) {
+	return common.GetSrcIP(m.Mechanism)
+}
+
+func (m *mechanism) DstIP() (string, error) {
+	return common.GetDstIP(m.Mechanism)
+}
+
+func (m *mechanism) SrcSPI() (uint32, error) {
+	return m.spi(SrcSPI)
+}
+
+func (m *mechanism) DstSPI() (uint32, error) {
+	return m.spi(DstSPI)
+}
+
+func (m *mechanism) SrcPublicKey() ([]byte, error) {
+	return m.publicKey(SrcPublicKey)
+}
+
+func (m *mechanism) DstPublicKey() ([]byte, error) {
+	return m.publicKey(DstPublicKey)
+}
+
+func (m *mechanism) Encapsulation() string {
+	return m.GetParameters()[Encapsulation]
+}
+
+func (m *mechanism) stringValue(parameter string) (string, error) {
+	if m == nil {
+		return "", errors.New("mechanism cannot be nil")
+	}
+
+	if m.GetParameters() == nil {
+		return "", errors.Errorf("mechanism.Parameters cannot be nil: %v", m)
+	}
+
+	value, ok := m.Parameters[parameter]
+	if !ok {
+		return "", errors.Errorf("mechanism.Type %s requires mechanism.Parameters[%s]", m.GetType(), parameter)
+	}
+
+	return value, nil
+}
+
+func (m *mechanism) spi(parameter string) (uint32, error) {
+	value, err := m.stringValue(parameter)
+	if err != nil {
+		return 0, err
+	}
+
+	spi, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || spi < minSPI {
+		return 0, errors.Errorf("mechanism.Parameters[%s] must be a valid SPI, instead was: %s", parameter, value)
+	}
+
+	return uint32(spi), nil
+}
+
+func (m *mechanism) publicKey(parameter string) ([]byte, error) {
+	value, err := m.stringValue(parameter)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(key) != PublicKeyLength {
+		return nil, errors.Errorf("mechanism.Parameters[%s] must be a base64 encoded %d bytes public key", parameter, PublicKeyLength)
+	}
+
+	return key, nil
+}
+
+// SetSrcSPI - generates SPI of the security association to the source host. Keys of the security associations are
+// not a part of the mechanism, forwarders derive them from their key pairs and SPIs.
+func SetSrcSPI(parameters map[string]string) error {
+	return setSPI(parameters, SrcSPI)
+}
+
+// SetDstSPI - generates SPI of the security association to the destination host
+func SetDstSPI(parameters map[string]string) error {
+	return setSPI(parameters, DstSPI)
+}
+
+func setSPI(parameters map[string]string, spiParameter string) error {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return errors.Wrap(err, "failed to generate IPsec security association SPI")
+	}
+
+	spi := binary.BigEndian.Uint32(buf)
+	if spi < minSPI {
+		spi += minSPI
+	}
+
+	parameters[spiParameter] = strconv.FormatUint(uint64(spi), 10)
+	return nil
+}
+
+// validate - source IP is required, destination IP and security associations are set by NSMD on mechanism
+// selection
+func validate(m *connection.Mechanism) error {
+	im := ToMechanism(m)
+	if _, err := im.SrcIP(); err != nil {
+		return err
+	}
+	if _, ok := m.GetParameters()[DstIP]; ok {
+		if _, err := im.DstIP(); err != nil {
+			return err
+		}
+	}
+	for _, parameter := range []string{SrcSPI, DstSPI} {
+		if _, ok := m.GetParameters()[parameter]; ok {
+			if _, err := im.(*mechanism).spi(parameter); err != nil {
+				return err
+			}
+		}
+	}
+	for _, parameter := range []string{SrcPublicKey, DstPublicKey} {
+		if _, ok := m.GetParameters()[parameter]; ok {
+			if _, err := im.(*mechanism).publicKey(parameter); err != nil {
+				return err
+			}
+		}
+	}
+	switch im.Encapsulation() {
+	case EncapsulationNone, EncapsulationVXLAN:
+		return nil
+	}
+	return errors.Errorf("mechanism.Parameters[%s] has unknown encapsulation: %s", Encapsulation, im.Encapsulation())
+}
@@ -244,6 +244,8 @@ type IPContext struct {
 	IpNeighbors          []*IpNeighbor         `protobuf:"bytes,8,rep,name=ip_neighbors,json=ipNeighbors,proto3" json:"ip_neighbors,omitempty"`
 	ExtraPrefixRequest   []*ExtraPrefixRequest `protobuf:"bytes,9,rep,name=extra_prefix_request,json=extraPrefixRequest,proto3" json:"extra_prefix_request,omitempty"`
 	ExtraPrefixes        []string              `protobuf:"bytes,10,rep,name=extra_prefixes,json=extraPrefixes,proto3" json:"extra_prefixes,omitempty"`
+	ExtraSrcIpAddrs      []string              `protobuf:"bytes,11,rep,name=extra_src_ip_addrs,json=extraSrcIpAddrs,proto3" json:"extra_src_ip_addrs,omitempty"`
+	ExtraDstIpAddrs      []string              `protobuf:"bytes,12,rep,name=extra_dst_ip_addrs,json=extraDstIpAddrs,proto3" json:"extra_dst_ip_addrs,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
 	XXX_unrecognized     []byte                `json:"-"`
 	XXX_sizecache        int32                 `json:"-"`
@@ -344,6 +346,20 @@ func (m *IPContext) GetExtraPrefixes() []string {
 	return nil
 }
 
+func (m *IPContext) GetExtraSrcIpAddrs() []string {
+	if m != nil {
+		return m.ExtraSrcIpAddrs
+	}
+	return nil
+}
+
+func (m *IPContext) GetExtraDstIpAddrs() []string {
+	if m != nil {
+		return m.ExtraDstIpAddrs
+	}
+	return nil
+}
+
 type DNSConfig struct {
 	// ips of DNS Servers for this DNSConfig.  Any given IP may be IPv4 or IPv6
 	DnsServerIps []string `protobuf:"bytes,1,rep,name=dns_server_ips,json=dnsServerIps,proto3" json:"dns_server_ips,omitempty"`
@@ -559,51 +575,52 @@ func init() {
 func init() { proto.RegisterFile("connectioncontext.proto", fileDescriptor_c30b3f1555e8b686) }
 
 var fileDescriptor_c30b3f1555e8b686 = []byte{
-	// 725 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0xed, 0x4f, 0x13, 0x4f,
-	0x10, 0xfe, 0xf5, 0x85, 0xd2, 0x9b, 0x42, 0x5f, 0xf6, 0x47, 0xe4, 0xa2, 0xa0, 0xe4, 0x22, 0x8a,
-	0x31, 0xe1, 0x43, 0x35, 0x68, 0xd0, 0xf8, 0x56, 0xd0, 0x34, 0x11, 0xd2, 0x2c, 0x89, 0x1a, 0xfd,
-	0x70, 0x39, 0x6e, 0x07, 0xba, 0xb1, 0xdd, 0x3b, 0x76, 0xaf, 0x58, 0xfe, 0x40, 0xff, 0x2b, 0x4d,
-	0xcc, 0xbe, 0xdc, 0xd9, 0xd0, 0xaa, 0x9f, 0xd8, 0x9b, 0x79, 0x9e, 0x67, 0x66, 0x9f, 0x99, 0xa5,
-	0xb0, 0x1e, 0x27, 0x42, 0x60, 0x9c, 0xf1, 0x44, 0xc4, 0x89, 0xc8, 0x70, 0x9a, 0xed, 0xa6, 0x32,
-	0xc9, 0x12, 0xd2, 0x99, 0x4b, 0x04, 0xef, 0x00, 0xfa, 0xe9, 0x31, 0xf2, 0xf3, 0xe1, 0x69, 0x22,
-	0x49, 0x13, 0xca, 0x3c, 0xf5, 0x4b, 0x5b, 0xa5, 0x1d, 0x8f, 0x96, 0x79, 0x4a, 0x1e, 0x40, 0x7b,
-	0x18, 0x49, 0xf6, 0x2d, 0x92, 0x18, 0x46, 0x8c, 0x49, 0x54, 0xca, 0x2f, 0x9b, 0x6c, 0x2b, 0x8f,
-	0xbf, 0xb6, 0xe1, 0xe0, 0x0e, 0x2c, 0xd1, 0x64, 0x92, 0x21, 0xb9, 0x01, 0xb5, 0x54, 0xe2, 0x19,
-	0x9f, 0x3a, 0x1d, 0xf7, 0x15, 0x30, 0xa8, 0xf7, 0xd3, 0xb7, 0xd1, 0x98, 0x8f, 0xae, 0xc8, 0x3e,
-	0xd4, 0xce, 0xcc, 0xc9, 0x60, 0x9a, 0xdd, 0x60, 0x77, 0xbe, 0xe5, 0x1c, 0xbc, 0x6b, 0xff, 0x50,
-	0xc7, 0x08, 0x36, 0xa0, 0xe6, 0x54, 0xea, 0x50, 0xed, 0x0f, 0x3e, 0x3c, 0x6e, 0xff, 0xe7, 0x4e,
-	0x7b, 0xed, 0x52, 0xf0, 0xbd, 0x04, 0xe4, 0x70, 0x9a, 0xc9, 0x68, 0x60, 0xaa, 0x52, 0xbc, 0x98,
-	0xa0, 0xca, 0xc8, 0x73, 0x68, 0xe8, 0xfe, 0xc3, 0x99, 0xaa, 0x8d, 0xee, 0xad, 0xbf, 0x54, 0xa5,
-	0xa0, 0xf1, 0xae, 0xd0, 0x26, 0x80, 0xbd, 0x44, 0x38, 0x42, 0x61, 0x0c, 0x58, 0xa5, 0x9e, 0x8d,
-	0xbc, 0x47, 0x41, 0xee, 0x43, 0x4b, 0xe2, 0xc5, 0x84, 0x4b, 0x64, 0xa1, 0x98, 0x8c, 0x4f, 0x51,
-	0xfa, 0x15, 0x83, 0x69, 0xe6, 0xe1, 0x63, 0x13, 0xd5, 0x76, 0x4a, 0xdb, 0xd0, 0x6f, 0x64, 0xd5,
-	0x20, 0x5b, 0x45, 0xdc, 0x42, 0x83, 0x1f, 0x15, 0xf0, 0xfa, 0x83, 0x9e, 0xed, 0x8a, 0xdc, 0x86,
-	0x86, 0x92, 0x71, 0xc8, 0x53, 0x33, 0x05, 0x67, 0xac, 0xa7, 0x64, 0xdc, 0x4f, 0xb5, 0xff, 0x3a,
-	0xcf, 0x54, 0x56, 0xe4, 0xed, 0x88, 0x3c, 0xa6, 0x32, 0x97, 0xbf, 0x07, 0x2d, 0xc7, 0xcf, 0x3b,
-	0x32, 0x1d, 0xd6, 0xe9, 0xaa, 0xd1, 0xa0, 0x2e, 0xa8, 0x71, 0x4e, 0xa7, 0xc0, 0x55, 0x2d, 0xce,
-	0x68, 0x15, 0xb8, 0x27, 0x00, 0x5a, 0x4f, 0xea, 0x81, 0x2b, 0x7f, 0x69, 0xab, 0xb2, 0xd3, 0xe8,
-	0xfa, 0x0b, 0xdc, 0x34, 0x1b, 0x61, 0x1a, 0x35, 0x27, 0xa5, 0x89, 0xba, 0x80, 0x23, 0xd6, 0xfe,
-	0x45, 0x64, 0x2a, 0x73, 0xc4, 0x87, 0xd0, 0xc1, 0x69, 0x3c, 0x9a, 0x30, 0x64, 0xa1, 0x75, 0x1e,
-	0x95, 0xbf, 0xbc, 0x55, 0xd9, 0xf1, 0x68, 0x3b, 0x4f, 0x0c, 0x5c, 0x9c, 0xbc, 0x82, 0x15, 0x9e,
-	0x86, 0xc2, 0x6d, 0xb5, 0xf2, 0xeb, 0xa6, 0xce, 0xe6, 0xc2, 0x71, 0xe7, 0xbb, 0x4f, 0x1b, 0xbc,
-	0x38, 0x2b, 0xf2, 0x11, 0xd6, 0x50, 0x6f, 0x91, 0xab, 0x15, 0xba, 0xf1, 0xf8, 0x9e, 0x51, 0xda,
-	0x5e, 0xa0, 0x34, 0xbf, 0x74, 0x94, 0xe0, 0xfc, 0x22, 0x6e, 0x43, 0x73, 0x56, 0x18, 0x95, 0x0f,
-	0xe6, 0x12, 0xab, 0x33, 0x58, 0x54, 0xc1, 0x27, 0xf0, 0x0e, 0x8e, 0x4f, 0x7a, 0x89, 0x38, 0xe3,
-	0xe7, 0xe4, 0x2e, 0x34, 0x99, 0x50, 0xa1, 0x42, 0x79, 0x89, 0x32, 0xe4, 0xa9, 0xf2, 0x4b, 0x86,
-	0xb3, 0xc2, 0x84, 0x3a, 0x31, 0xc1, 0x7e, 0xaa, 0xb4, 0xb2, 0xc2, 0x48, 0xc6, 0xc3, 0x90, 0x25,
-	0xe3, 0x88, 0x0b, 0xfd, 0x52, 0x8d, 0xb2, 0x8d, 0x1e, 0xd8, 0x60, 0x70, 0x00, 0x60, 0x95, 0xcd,
-	0x62, 0xed, 0xc1, 0x72, 0x6c, 0x8a, 0x58, 0xcd, 0x46, 0x77, 0x63, 0xc1, 0xd5, 0x8a, 0x4e, 0x68,
-	0x0e, 0x0e, 0x7a, 0xd0, 0x3a, 0xcc, 0x86, 0x28, 0x05, 0x66, 0xb9, 0xd4, 0x3a, 0x2c, 0xeb, 0x9d,
-	0x18, 0x47, 0x71, 0xfe, 0xf0, 0x95, 0x8c, 0x8f, 0xa2, 0x58, 0x27, 0xf4, 0xcc, 0x75, 0xc2, 0x2e,
-	0x66, 0x8d, 0xa9, 0xec, 0x28, 0x8a, 0x83, 0x9f, 0x65, 0xe8, 0xf4, 0x8a, 0x6a, 0xb9, 0xce, 0x33,
-	0x00, 0x9e, 0x86, 0xae, 0xb6, 0x7b, 0xa9, 0x8b, 0xba, 0x2a, 0x5e, 0x07, 0xf5, 0x78, 0x9a, 0x93,
-	0x5f, 0x40, 0x43, 0x5b, 0x95, 0xb3, 0xcb, 0x86, 0xbd, 0xf9, 0xc7, 0x3b, 0x19, 0x3a, 0x30, 0xa1,
-	0x72, 0xfe, 0x11, 0xb4, 0xd1, 0xdd, 0xab, 0x10, 0xa9, 0x18, 0x91, 0x45, 0xff, 0xa2, 0xae, 0x59,
-	0x40, 0x5b, 0x78, 0xcd, 0x93, 0x2f, 0x60, 0xe7, 0x5a, 0x68, 0x55, 0x8d, 0xc9, 0x7b, 0x0b, 0xb4,
-	0xe6, 0x8c, 0xb0, 0x1b, 0xe5, 0x3e, 0x0e, 0x45, 0x26, 0xaf, 0xe8, 0x0a, 0xce, 0x84, 0x6e, 0xbe,
-	0x84, 0xce, 0x1c, 0x84, 0xb4, 0xa1, 0xf2, 0x15, 0xaf, 0xdc, 0x04, 0xf4, 0x91, 0xac, 0xc1, 0xd2,
-	0x65, 0x34, 0x9a, 0xa0, 0x33, 0xdf, 0x7e, 0xec, 0x97, 0x9f, 0x96, 0xde, 0xfc, 0xff, 0x79, 0xfe,
-	0x07, 0xe1, 0xb4, 0x66, 0x7e, 0x2a, 0x1e, 0xfd, 0x0a, 0x00, 0x00, 0xff, 0xff, 0xdb, 0xb5, 0x64,
-	0x85, 0x45, 0x06, 0x00, 0x00,
+	// 750 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0xdb, 0x6e, 0xd3, 0x40,
+	0x10, 0x25, 0x97, 0xa6, 0xf1, 0x38, 0xcd, 0x65, 0xa9, 0xa8, 0x05, 0x2d, 0x44, 0x16, 0x85, 0x22,
+	0xa4, 0x3e, 0x04, 0x54, 0x50, 0x41, 0xdc, 0x92, 0x82, 0x22, 0xd1, 0x2a, 0xda, 0x4a, 0x80, 0xe0,
+	0xc1, 0x72, 0xed, 0x69, 0x63, 0x91, 0xd8, 0xee, 0xae, 0x53, 0xd2, 0x0f, 0xe4, 0x17, 0xf8, 0x1b,
+	0x24, 0xb4, 0x37, 0xd7, 0x6a, 0x02, 0x3c, 0x75, 0x7d, 0xe6, 0xcc, 0x99, 0xd9, 0x33, 0xd3, 0x0d,
+	0x6c, 0x04, 0x49, 0x1c, 0x63, 0x90, 0x45, 0x49, 0x1c, 0x24, 0x71, 0x86, 0xf3, 0x6c, 0x37, 0x65,
+	0x49, 0x96, 0x90, 0xce, 0x42, 0xc0, 0xfd, 0x00, 0x30, 0x4c, 0x8f, 0x30, 0x3a, 0x1b, 0x9f, 0x24,
+	0x8c, 0x34, 0xa1, 0x1c, 0xa5, 0x4e, 0xa9, 0x5b, 0xda, 0xb1, 0x68, 0x39, 0x4a, 0xc9, 0x23, 0x68,
+	0x8f, 0x7d, 0x16, 0xfe, 0xf0, 0x19, 0x7a, 0x7e, 0x18, 0x32, 0xe4, 0xdc, 0x29, 0xcb, 0x68, 0xcb,
+	0xe0, 0x6f, 0x15, 0xec, 0xde, 0x83, 0x15, 0x9a, 0xcc, 0x32, 0x24, 0xb7, 0xa0, 0x96, 0x32, 0x3c,
+	0x8d, 0xe6, 0x5a, 0x47, 0x7f, 0xb9, 0x21, 0xd4, 0x87, 0xe9, 0x7b, 0x7f, 0x1a, 0x4d, 0x2e, 0xc9,
+	0x3e, 0xd4, 0x4e, 0xe5, 0x49, 0x72, 0x9a, 0x3d, 0x77, 0x77, 0xb1, 0x65, 0x43, 0xde, 0x55, 0x7f,
+	0xa8, 0xce, 0x70, 0x37, 0xa1, 0xa6, 0x55, 0xea, 0x50, 0x1d, 0x8e, 0x3e, 0x3d, 0x6d, 0xdf, 0xd0,
+	0xa7, 0xbd, 0x76, 0xc9, 0xfd, 0x59, 0x02, 0x72, 0x30, 0xcf, 0x98, 0x3f, 0x92, 0x55, 0x29, 0x9e,
+	0xcf, 0x90, 0x67, 0xe4, 0x25, 0xd8, 0xa2, 0x7f, 0xaf, 0x50, 0xd5, 0xee, 0xdd, 0xf9, 0x47, 0x55,
+	0x0a, 0x82, 0xaf, 0x0b, 0x6d, 0x01, 0xa8, 0x4b, 0x78, 0x13, 0x8c, 0xa5, 0x01, 0x6b, 0xd4, 0x52,
+	0xc8, 0x47, 0x8c, 0xc9, 0x43, 0x68, 0x31, 0x3c, 0x9f, 0x45, 0x0c, 0x43, 0x2f, 0x9e, 0x4d, 0x4f,
+	0x90, 0x39, 0x15, 0xc9, 0x69, 0x1a, 0xf8, 0x48, 0xa2, 0xc2, 0x4e, 0xa6, 0x1a, 0xba, 0x62, 0x56,
+	0x25, 0xb3, 0x95, 0xe3, 0x8a, 0xea, 0xfe, 0xaa, 0x82, 0x35, 0x1c, 0xf5, 0x55, 0x57, 0xe4, 0x2e,
+	0xd8, 0x9c, 0x05, 0x5e, 0x94, 0xca, 0x29, 0x68, 0x63, 0x2d, 0xce, 0x82, 0x61, 0x2a, 0xfc, 0x17,
+	0xf1, 0x90, 0x67, 0x79, 0x5c, 0x8d, 0xc8, 0x0a, 0x79, 0xa6, 0xe3, 0x0f, 0xa0, 0xa5, 0xf3, 0x4d,
+	0x47, 0xb2, 0xc3, 0x3a, 0x5d, 0x93, 0x1a, 0x54, 0x83, 0x82, 0xa7, 0x75, 0x72, 0x5e, 0x55, 0xf1,
+	0xa4, 0x56, 0xce, 0x7b, 0x06, 0x20, 0xf4, 0x98, 0x18, 0x38, 0x77, 0x56, 0xba, 0x95, 0x1d, 0xbb,
+	0xe7, 0x2c, 0x71, 0x53, 0x6e, 0x84, 0x6c, 0x54, 0x9e, 0xb8, 0x48, 0x14, 0x05, 0x74, 0x62, 0xed,
+	0x7f, 0x89, 0x21, 0xcf, 0x74, 0xe2, 0x63, 0xe8, 0xe0, 0x3c, 0x98, 0xcc, 0x42, 0x0c, 0x3d, 0xe5,
+	0x3c, 0x72, 0x67, 0xb5, 0x5b, 0xd9, 0xb1, 0x68, 0xdb, 0x04, 0x46, 0x1a, 0x27, 0x6f, 0xa0, 0x11,
+	0xa5, 0x5e, 0xac, 0xb7, 0x9a, 0x3b, 0x75, 0x59, 0x67, 0x6b, 0xe9, 0xb8, 0xcd, 0xee, 0x53, 0x3b,
+	0xca, 0xcf, 0x9c, 0x7c, 0x86, 0x75, 0x14, 0x5b, 0xa4, 0x6b, 0x79, 0x7a, 0x3c, 0x8e, 0x25, 0x95,
+	0xb6, 0x97, 0x28, 0x2d, 0x2e, 0x1d, 0x25, 0xb8, 0x80, 0x91, 0x6d, 0x68, 0x16, 0x85, 0x91, 0x3b,
+	0x20, 0x2f, 0xb1, 0x56, 0xe0, 0xca, 0xeb, 0xaa, 0x64, 0xaf, 0x30, 0x76, 0xee, 0xd8, 0x92, 0xda,
+	0x92, 0x91, 0x63, 0x33, 0xfc, 0x02, 0xb9, 0xb0, 0x03, 0xdc, 0x69, 0x14, 0xc8, 0x03, 0xb3, 0x09,
+	0xdc, 0xfd, 0x02, 0xd6, 0xe0, 0xe8, 0xb8, 0x9f, 0xc4, 0xa7, 0xd1, 0x19, 0xb9, 0x0f, 0xcd, 0x30,
+	0xe6, 0x1e, 0x47, 0x76, 0x81, 0xcc, 0x8b, 0x52, 0xee, 0x94, 0x64, 0x56, 0x23, 0x8c, 0xf9, 0xb1,
+	0x04, 0x87, 0x29, 0x17, 0x3d, 0x73, 0xf4, 0x59, 0x30, 0xf6, 0xc2, 0x64, 0xea, 0x47, 0xb1, 0x78,
+	0x03, 0x64, 0xcf, 0x0a, 0x1d, 0x28, 0xd0, 0x1d, 0x00, 0x28, 0x65, 0xb9, 0xb2, 0x7b, 0xb0, 0x1a,
+	0xc8, 0x22, 0x4a, 0xd3, 0xee, 0x6d, 0x2e, 0x31, 0x2d, 0xef, 0x84, 0x1a, 0xb2, 0xdb, 0x87, 0xd6,
+	0x41, 0x36, 0x46, 0x16, 0x63, 0x66, 0xa4, 0x36, 0x60, 0x55, 0xd8, 0x30, 0xf5, 0x03, 0xf3, 0xa4,
+	0x70, 0x16, 0x1c, 0xfa, 0x81, 0x08, 0x88, 0x2b, 0x8b, 0x80, 0x5a, 0xf9, 0x5a, 0xc8, 0xb3, 0x43,
+	0x3f, 0x70, 0x7f, 0x97, 0xa1, 0xd3, 0xcf, 0xab, 0x19, 0x9d, 0x17, 0x00, 0x51, 0xea, 0xe9, 0xda,
+	0xfa, 0x0d, 0x58, 0xd6, 0x55, 0xfe, 0x7f, 0x47, 0xad, 0x28, 0x35, 0xc9, 0xaf, 0xc0, 0x16, 0x56,
+	0x99, 0xec, 0x72, 0xb7, 0xf4, 0x97, 0x95, 0xba, 0xf2, 0x80, 0x42, 0x18, 0x73, 0x93, 0x7f, 0x08,
+	0x6d, 0xd4, 0xf7, 0xca, 0x45, 0x2a, 0x52, 0x64, 0xd9, 0xe3, 0x77, 0xcd, 0x02, 0xda, 0xc2, 0x6b,
+	0x9e, 0x7c, 0x03, 0xb5, 0x31, 0xb9, 0x56, 0x55, 0x9a, 0xbc, 0xb7, 0x44, 0x6b, 0xc1, 0x08, 0xb5,
+	0xab, 0xfa, 0xe3, 0x20, 0xce, 0xd8, 0x25, 0x6d, 0x60, 0x01, 0xba, 0xfd, 0x1a, 0x3a, 0x0b, 0x14,
+	0xd2, 0x86, 0xca, 0x77, 0xbc, 0xd4, 0x13, 0x10, 0x47, 0xb2, 0x0e, 0x2b, 0x17, 0xfe, 0x64, 0x86,
+	0xda, 0x7c, 0xf5, 0xb1, 0x5f, 0x7e, 0x5e, 0x7a, 0x77, 0xf3, 0xeb, 0xe2, 0x4f, 0xcd, 0x49, 0x4d,
+	0xfe, 0x08, 0x3d, 0xf9, 0x33, 0x00, 0x43, 0xb0, 0xd7, 0xcb, 0x9f, 0x06, 0x00, 0x00,
 }
@@ -27,6 +27,12 @@ func (c *ConnectionContext) IsValid() error {
 		}
 	}
 
+	for _, addr := range append(ip.GetExtraSrcIpAddrs(), ip.GetExtraDstIpAddrs()...) {
+		if _, _, err := net.ParseCIDR(addr); err != nil {
+			return errors.Errorf("ConnectionContext extra ip address should be a valid CIDR address: %v", ip)
+		}
+	}
+
 	for _, neighbor := range ip.GetIpNeighbors() {
 		if neighbor.GetIp() == "" {
 			return errors.Errorf("ConnectionContext.IpNeighbors.Ip is required and cannot be empty/nil: %v", ip)
@@ -35,6 +41,10 @@ func (c *ConnectionContext) IsValid() error {
 			return errors.Errorf("ConnectionContext.IpNeighbors.HardwareAddress is required and cannot be empty/nil: %v", ip)
 		}
 	}
+
+	if _, err := c.GetQoS(); err != nil {
+		return errors.Wrapf(err, "ConnectionContext.ExtraContext has invalid QoS: %v", c.GetExtraContext())
+	}
 	return nil
 }
 
@@ -58,6 +68,24 @@ func (c *ConnectionContext) MeetsRequirements(original *ConnectionContext) error
 	return nil
 }
 
+// SrcIPAddrs returns source ip addresses of all IP families, SrcIpAddr goes first
+func (c *IPContext) SrcIPAddrs() []string {
+	return ipAddrs(c.GetSrcIpAddr(), c.GetExtraSrcIpAddrs())
+}
+
+// DstIPAddrs returns destination ip addresses of all IP families, DstIpAddr goes first
+func (c *IPContext) DstIPAddrs() []string {
+	return ipAddrs(c.GetDstIpAddr(), c.GetExtraDstIpAddrs())
+}
+
+func ipAddrs(addr string, extraAddrs []string) []string {
+	var addrs []string
+	if addr != "" {
+		addrs = append(addrs, addr)
+	}
+	return append(addrs, extraAddrs...)
+}
+
 //Validate - checks DNSConfig and returns error if DNSConfig is not valid
 func (c *DNSConfig) Validate() error {
 	if c == nil {
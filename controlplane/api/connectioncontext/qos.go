@@ -0,0 +1,161 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectioncontext
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Keys of the QoS parameters in ExtraContext, the same keys are used in the connection labels to request QoS
+const (
+	// QoSRateKey - maximum rate in bits per second, tc units are accepted: "bit", "kbit", "mbit", "gbit"
+	QoSRateKey = "qos.rate"
+	// QoSBurstKey - maximum burst in bytes, tc units are accepted: "b", "kb", "mb"
+	QoSBurstKey = "qos.burst"
+	// QoSDSCPKey - DSCP marking of the packets sent to the connection: 0-63 or a name, f.e. "EF", "AF41", "CS1"
+	QoSDSCPKey = "qos.dscp"
+)
+
+// QoS - traffic shaping and marking of the connection, applied by forwarder to the packets sent by each side
+type QoS struct {
+	// Rate - maximum rate in bits per second, zero means unlimited
+	Rate uint64
+	// Burst - maximum burst in bytes, zero means forwarder default
+	Burst uint64
+	// DSCP - DSCP marking, nil means packets are not marked
+	DSCP *uint8
+}
+
+var dscpNames = map[string]uint8{
+	"CS0": 0, "CS1": 8, "CS2": 16, "CS3": 24, "CS4": 32, "CS5": 40, "CS6": 48, "CS7": 56,
+	"AF11": 10, "AF12": 12, "AF13": 14,
+	"AF21": 18, "AF22": 20, "AF23": 22,
+	"AF31": 26, "AF32": 28, "AF33": 30,
+	"AF41": 34, "AF42": 36, "AF43": 38,
+	"EF": 46,
+}
+
+type unit struct {
+	suffix     string
+	multiplier uint64
+}
+
+var rateUnits = []unit{
+	{"gbit", 1000 * 1000 * 1000}, {"mbit", 1000 * 1000}, {"kbit", 1000}, {"bit", 1},
+}
+
+var burstUnits = []unit{
+	{"mb", 1024 * 1024}, {"kb", 1024}, {"b", 1},
+}
+
+// ParseQoS parses QoS parameters from ExtraContext or labels, nil is returned if there are no QoS parameters
+func ParseQoS(values map[string]string) (*QoS, error) {
+	rate, hasRate := values[QoSRateKey]
+	burst, hasBurst := values[QoSBurstKey]
+	dscp, hasDSCP := values[QoSDSCPKey]
+	if !hasRate && !hasBurst && !hasDSCP {
+		return nil, nil
+	}
+
+	qos := &QoS{}
+	var err error
+	if hasRate {
+		if qos.Rate, err = parseQuantity(rate, rateUnits); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", QoSRateKey)
+		}
+	}
+	if hasBurst {
+		if qos.Burst, err = parseQuantity(burst, burstUnits); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", QoSBurstKey)
+		}
+	}
+	if hasDSCP {
+		if qos.DSCP, err = ParseDSCP(dscp); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", QoSDSCPKey)
+		}
+	}
+	return qos, nil
+}
+
+// ParseDSCP parses DSCP value or name
+func ParseDSCP(value string) (*uint8, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if dscp, ok := dscpNames[value]; ok {
+		return &dscp, nil
+	}
+	dscp, err := strconv.ParseUint(value, 10, 8)
+	if err != nil || dscp > 63 {
+		return nil, errors.Errorf("DSCP should be 0-63 or a name, got: %s", value)
+	}
+	result := uint8(dscp)
+	return &result, nil
+}
+
+func parseQuantity(value string, units []unit) (uint64, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	multiplier := uint64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSuffix(value, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	quantity, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("expected a number with an optional unit, got: %s", value)
+	}
+	return quantity * multiplier, nil
+}
+
+// ToMap writes QoS parameters to ExtraContext or labels
+func (q *QoS) ToMap(values map[string]string) {
+	delete(values, QoSRateKey)
+	delete(values, QoSBurstKey)
+	delete(values, QoSDSCPKey)
+	if q == nil {
+		return
+	}
+	if q.Rate != 0 {
+		values[QoSRateKey] = strconv.FormatUint(q.Rate, 10) + "bit"
+	}
+	if q.Burst != 0 {
+		values[QoSBurstKey] = strconv.FormatUint(q.Burst, 10) + "b"
+	}
+	if q.DSCP != nil {
+		values[QoSDSCPKey] = strconv.Itoa(int(*q.DSCP))
+	}
+}
+
+// IsEmpty returns true if QoS neither shapes nor marks the traffic
+func (q *QoS) IsEmpty() bool {
+	return q == nil || q.Rate == 0 && q.DSCP == nil
+}
+
+// GetQoS returns QoS of the connection, nil is returned if it is not set
+func (c *ConnectionContext) GetQoS() (*QoS, error) {
+	return ParseQoS(c.GetExtraContext())
+}
+
+// SetQoS sets QoS of the connection, nil removes it
+func (c *ConnectionContext) SetQoS(qos *QoS) {
+	if c.ExtraContext == nil {
+		c.ExtraContext = map[string]string{}
+	}
+	qos.ToMap(c.ExtraContext)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crossconnect
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Keys of the crossconnect leg statistics in Metrics
+const (
+	RxBytesKey          = "rx_bytes"
+	TxBytesKey          = "tx_bytes"
+	RxPacketsKey        = "rx_packets"
+	TxPacketsKey        = "tx_packets"
+	RxDroppedPacketsKey = "rx_dropped_packets"
+	TxDroppedPacketsKey = "tx_dropped_packets"
+	RxErrorPacketsKey   = "rx_error_packets"
+	TxErrorPacketsKey   = "tx_error_packets"
+
+	// WireguardLastHandshakeKey - time of the last WireGuard peer handshake in unix seconds
+	WireguardLastHandshakeKey = "wg_last_handshake"
+	// WireguardRxBytesKey - bytes received from the WireGuard peer
+	WireguardRxBytesKey = "wg_rx_bytes"
+	// WireguardTxBytesKey - bytes transmitted to the WireGuard peer
+	WireguardTxBytesKey = "wg_tx_bytes"
+)
+
+// Statistics - traffic statistics of the device serving one leg of the crossconnect
+type Statistics struct {
+	RxBytes   uint64
+	TxBytes   uint64
+	RxPackets uint64
+	TxPackets uint64
+	RxDropped uint64
+	TxDropped uint64
+	RxErrors  uint64
+	TxErrors  uint64
+
+	// WireguardPeer - peer statistics, set only for the WireGuard devices
+	WireguardPeer *WireguardPeerStatistics
+}
+
+// WireguardPeerStatistics - handshake and transfer counters of the WireGuard peer
+type WireguardPeerStatistics struct {
+	LastHandshake time.Time
+	RxBytes       uint64
+	TxBytes       uint64
+}
+
+// Metrics converts statistics to the Metrics sent in the CrossConnectEvent
+func (s *Statistics) Metrics() *Metrics {
+	metrics := map[string]string{
+		RxBytesKey:          strconv.FormatUint(s.RxBytes, 10),
+		TxBytesKey:          strconv.FormatUint(s.TxBytes, 10),
+		RxPacketsKey:        strconv.FormatUint(s.RxPackets, 10),
+		TxPacketsKey:        strconv.FormatUint(s.TxPackets, 10),
+		RxDroppedPacketsKey: strconv.FormatUint(s.RxDropped, 10),
+		TxDroppedPacketsKey: strconv.FormatUint(s.TxDropped, 10),
+		RxErrorPacketsKey:   strconv.FormatUint(s.RxErrors, 10),
+		TxErrorPacketsKey:   strconv.FormatUint(s.TxErrors, 10),
+	}
+	if peer := s.WireguardPeer; peer != nil {
+		var lastHandshake int64
+		if !peer.LastHandshake.IsZero() {
+			lastHandshake = peer.LastHandshake.Unix()
+		}
+		metrics[WireguardLastHandshakeKey] = strconv.FormatInt(lastHandshake, 10)
+		metrics[WireguardRxBytesKey] = strconv.FormatUint(peer.RxBytes, 10)
+		metrics[WireguardTxBytesKey] = strconv.FormatUint(peer.TxBytes, 10)
+	}
+	return &Metrics{Metrics: metrics}
+}
+
+// StatisticsFromMetrics parses statistics from the Metrics received in the CrossConnectEvent, missing counters are
+// left zero
+func StatisticsFromMetrics(m *Metrics) (*Statistics, error) {
+	s := &Statistics{}
+	metrics := m.GetMetrics()
+	for key, value := range map[string]*uint64{
+		RxBytesKey:          &s.RxBytes,
+		TxBytesKey:          &s.TxBytes,
+		RxPacketsKey:        &s.RxPackets,
+		TxPacketsKey:        &s.TxPackets,
+		RxDroppedPacketsKey: &s.RxDropped,
+		TxDroppedPacketsKey: &s.TxDropped,
+		RxErrorPacketsKey:   &s.RxErrors,
+		TxErrorPacketsKey:   &s.TxErrors,
+	} {
+		if err := parseCounter(metrics, key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	lastHandshake, ok := metrics[WireguardLastHandshakeKey]
+	if !ok {
+		return s, nil
+	}
+	peer := &WireguardPeerStatistics{}
+	seconds, err := strconv.ParseInt(lastHandshake, 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s metric", WireguardLastHandshakeKey)
+	}
+	if seconds != 0 {
+		peer.LastHandshake = time.Unix(seconds, 0)
+	}
+	if err := parseCounter(metrics, WireguardRxBytesKey, &peer.RxBytes); err != nil {
+		return nil, err
+	}
+	if err := parseCounter(metrics, WireguardTxBytesKey, &peer.TxBytes); err != nil {
+		return nil, err
+	}
+	s.WireguardPeer = peer
+	return s, nil
+}
+
+func parseCounter(metrics map[string]string, key string, value *uint64) error {
+	str, ok := metrics[key]
+	if !ok {
+		return nil
+	}
+	counter, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid %s metric", key)
+	}
+	*value = counter
+	return nil
+}
@@ -0,0 +1,3 @@
+package ipam
+
+//go:generate bash -c "protoc -I . ipam.proto --go_out=plugins=grpc:. --proto_path=$GOPATH/src/ --proto_path=$GOPATH/pkg/mod/  --proto_path=$( go list -f '{{ .Dir }}' -m github.com/golang/protobuf )"
@@ -245,6 +245,7 @@ type NetworkServiceEndpoint struct {
 	NetworkServiceManagerName string            `protobuf:"bytes,4,opt,name=network_service_manager_name,json=networkServiceManagerName,proto3" json:"network_service_manager_name,omitempty"`
 	Labels                    map[string]string `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	State                     string            `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
+	ConnectionCount           uint32            `protobuf:"varint,7,opt,name=connection_count,json=connectionCount,proto3" json:"connection_count,omitempty"`
 	XXX_NoUnkeyedLiteral      struct{}          `json:"-"`
 	XXX_unrecognized          []byte            `json:"-"`
 	XXX_sizecache             int32             `json:"-"`
@@ -317,6 +318,13 @@ func (m *NetworkServiceEndpoint) GetState() string {
 	return ""
 }
 
+func (m *NetworkServiceEndpoint) GetConnectionCount() uint32 {
+	if m != nil {
+		return m.ConnectionCount
+	}
+	return 0
+}
+
 type FindNetworkServiceRequest struct {
 	NetworkServiceName   string   `protobuf:"bytes,1,opt,name=network_service_name,json=networkServiceName,proto3" json:"network_service_name,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -572,58 +580,60 @@ func init() {
 func init() { proto.RegisterFile("registry.proto", fileDescriptor_41af05d40a615591) }
 
 var fileDescriptor_41af05d40a615591 = []byte{
-	// 811 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0x4d, 0x6e, 0xd3, 0x40,
-	0x14, 0xd6, 0x24, 0x6d, 0x4a, 0x9f, 0x21, 0xa9, 0xa6, 0x6d, 0xea, 0x18, 0x10, 0x51, 0xca, 0x22,
-	0x48, 0x60, 0xaa, 0x20, 0x24, 0x60, 0x53, 0x4a, 0x9b, 0xb2, 0x20, 0x09, 0x92, 0x03, 0x42, 0x42,
-	0x48, 0x91, 0x9b, 0x0c, 0xa9, 0xa9, 0xff, 0xf0, 0x4c, 0x52, 0xdc, 0x1b, 0x70, 0x03, 0x0e, 0xc1,
-	0x1d, 0x58, 0xb2, 0xed, 0x3d, 0xb8, 0x04, 0xf2, 0x8c, 0x13, 0xdb, 0x89, 0xdd, 0x34, 0xea, 0xa6,
-	0x9a, 0x9f, 0xf7, 0xbe, 0xf7, 0xe6, 0xfb, 0x3e, 0xbf, 0x06, 0x8a, 0x1e, 0x19, 0x1a, 0x94, 0x79,
-	0xbe, 0xea, 0x7a, 0x0e, 0x73, 0xf0, 0xad, 0xc9, 0x5e, 0x91, 0x5d, 0xe6, 0xbb, 0x84, 0x3e, 0x25,
-	0x96, 0xcb, 0x7c, 0xf1, 0x57, 0xc4, 0x28, 0xd5, 0xf0, 0x86, 0x19, 0x16, 0xa1, 0x4c, 0xb7, 0xdc,
-	0x68, 0x25, 0x22, 0x6a, 0x06, 0x14, 0x3b, 0x84, 0x9d, 0x3b, 0xde, 0x59, 0x97, 0x78, 0x63, 0xa3,
-	0x4f, 0x30, 0x86, 0x15, 0x5b, 0xb7, 0x88, 0x8c, 0xaa, 0xa8, 0xbe, 0xae, 0xf1, 0x35, 0x96, 0x61,
-	0xcd, 0xd5, 0x7d, 0xd3, 0xd1, 0x07, 0x72, 0x8e, 0x1f, 0x4f, 0xb6, 0xf8, 0x11, 0xac, 0x59, 0x3a,
-	0xeb, 0x9f, 0x12, 0x2a, 0xe7, 0xab, 0xf9, 0xba, 0xd4, 0x28, 0xa9, 0xd3, 0x3e, 0xdb, 0xc1, 0x85,
-	0x36, 0xb9, 0xaf, 0xfd, 0x45, 0xb0, 0xca, 0x8f, 0x70, 0x0b, 0x4a, 0xd4, 0x19, 0x79, 0x7d, 0xd2,
-	0xa3, 0xc4, 0x24, 0x7d, 0xe6, 0x78, 0x32, 0xe2, 0xc9, 0xbb, 0x33, 0xc9, 0x6a, 0x97, 0x87, 0x75,
-	0xc3, 0xa8, 0xa6, 0xcd, 0x3c, 0x5f, 0x2b, 0xd2, 0xc4, 0x21, 0x7e, 0x02, 0x05, 0xcf, 0x19, 0x31,
-	0x42, 0xe5, 0x1c, 0x07, 0xd9, 0x8e, 0x40, 0x8e, 0x08, 0x65, 0x86, 0xad, 0x33, 0xc3, 0xb1, 0xb5,
-	0x30, 0x48, 0x39, 0x80, 0xcd, 0x14, 0x54, 0xbc, 0x01, 0xf9, 0x33, 0xe2, 0x87, 0xaf, 0x0e, 0x96,
-	0x78, 0x0b, 0x56, 0xc7, 0xba, 0x39, 0x22, 0xe1, 0x93, 0xc5, 0xe6, 0x55, 0xee, 0x05, 0xaa, 0x5d,
-	0x22, 0x90, 0x62, 0xd0, 0x58, 0x87, 0xad, 0x41, 0xb4, 0x9d, 0x7d, 0x94, 0x9a, 0xda, 0x4f, 0x7c,
-	0x9d, 0x7c, 0xdf, 0xe6, 0x60, 0xfe, 0x06, 0x97, 0xa1, 0x70, 0x4e, 0x8c, 0xe1, 0x29, 0xe3, 0xdd,
-	0xdc, 0xd1, 0xc2, 0x9d, 0x72, 0x0c, 0x72, 0x16, 0xd0, 0x52, 0x4f, 0xfa, 0x85, 0x60, 0x3b, 0x69,
-	0x84, 0xb6, 0x6e, 0xeb, 0x43, 0xe2, 0xa5, 0xfa, 0x61, 0x03, 0xf2, 0x23, 0xcf, 0x0c, 0x51, 0x82,
-	0x25, 0x3e, 0x84, 0x12, 0xf9, 0xe1, 0x1a, 0x9e, 0x60, 0x20, 0x70, 0x99, 0x9c, 0xaf, 0xa2, 0xba,
-	0xd4, 0x50, 0xd4, 0xa1, 0xe3, 0x0c, 0x4d, 0x22, 0xfc, 0x76, 0x32, 0xfa, 0xaa, 0x7e, 0x98, 0x58,
-	0x50, 0x2b, 0x46, 0x29, 0xc1, 0x61, 0xd0, 0x1e, 0x65, 0x3a, 0x23, 0xf2, 0x8a, 0x68, 0x8f, 0x6f,
-	0x6a, 0x97, 0x39, 0x28, 0x27, 0x5b, 0x6b, 0xda, 0x03, 0xd7, 0x31, 0x6c, 0xb6, 0xa4, 0x57, 0xf7,
-	0x60, 0xcb, 0x16, 0x38, 0x3d, 0x2a, 0x80, 0x7a, 0x3c, 0x3b, 0xcf, 0xc3, 0xb0, 0x9d, 0xa8, 0xd1,
-	0x09, 0xb0, 0xf6, 0xe1, 0xde, 0x6c, 0x86, 0x25, 0x68, 0x11, 0x99, 0xa2, 0xcf, 0x8a, 0x9d, 0x46,
-	0x1c, 0x07, 0x38, 0x82, 0x82, 0xa9, 0x9f, 0x10, 0x93, 0xca, 0xab, 0xdc, 0x0b, 0x8f, 0x23, 0x2f,
-	0xa4, 0x3f, 0x49, 0x6d, 0xf1, 0x70, 0xe1, 0x84, 0x30, 0x37, 0xe2, 0xa5, 0x10, 0xe3, 0x45, 0x79,
-	0x09, 0x52, 0x2c, 0x78, 0x29, 0xb5, 0xdb, 0x50, 0x39, 0x36, 0xec, 0x41, 0xb2, 0x05, 0x8d, 0x7c,
-	0x1f, 0x11, 0xca, 0x32, 0x69, 0x42, 0x59, 0x34, 0xd5, 0xfe, 0xe4, 0x41, 0x49, 0xc3, 0xa3, 0xae,
-	0x63, 0xd3, 0x84, 0x22, 0x28, 0xa9, 0xc8, 0x01, 0x94, 0x66, 0x4a, 0xf1, 0x5e, 0xa5, 0x86, 0x9c,
-	0xc5, 0x93, 0x56, 0x4c, 0xd6, 0xc7, 0x17, 0x20, 0x67, 0x48, 0x34, 0x99, 0x48, 0xaf, 0x23, 0xac,
-	0xec, 0x26, 0xd5, 0x54, 0xf3, 0x87, 0x3a, 0x94, 0x53, 0x05, 0xa6, 0xf8, 0x0b, 0x54, 0x66, 0x6b,
-	0x93, 0x50, 0x47, 0x2a, 0xaf, 0xf0, 0xe2, 0xd5, 0x45, 0x82, 0x6b, 0x3b, 0x76, 0xea, 0x39, 0x55,
-	0xbe, 0xc1, 0xdd, 0x2b, 0x9a, 0x4a, 0xd1, 0xfb, 0x79, 0x5c, 0x6f, 0xa9, 0xf1, 0x20, 0xab, 0x74,
-	0x88, 0x13, 0x37, 0xc4, 0xcf, 0x1c, 0x94, 0x3a, 0xdd, 0xa6, 0x26, 0x12, 0xc4, 0x54, 0x4b, 0x11,
-	0x07, 0x2d, 0x29, 0xce, 0x27, 0xd8, 0xc9, 0x10, 0xe7, 0xba, 0x3d, 0x6e, 0xa7, 0x52, 0x8f, 0x3f,
-	0xcf, 0xab, 0x3e, 0x61, 0x3e, 0x9c, 0x3b, 0x8b, 0x89, 0x2f, 0xa7, 0x13, 0x5f, 0xfb, 0x08, 0x1b,
-	0x1a, 0xb1, 0x9c, 0x31, 0xe1, 0x84, 0x88, 0x6f, 0xe2, 0x00, 0xee, 0x67, 0xd5, 0x8b, 0x7f, 0x1c,
-	0x4a, 0x3a, 0x24, 0xff, 0x48, 0x2e, 0x40, 0x49, 0x6f, 0xa4, 0x65, 0x50, 0x76, 0xb5, 0x95, 0xd0,
-	0x0d, 0xad, 0xd4, 0xf8, 0x87, 0x66, 0x47, 0x68, 0xa8, 0xb4, 0x8f, 0x0f, 0x41, 0x12, 0x6b, 0xe2,
-	0x75, 0xba, 0x4d, 0x5c, 0x89, 0x15, 0x49, 0xfa, 0x41, 0xc9, 0xbe, 0xc2, 0xef, 0xa0, 0xf4, 0x66,
-	0x64, 0x9e, 0xdd, 0x18, 0xa8, 0x8e, 0xf6, 0x10, 0xde, 0x87, 0xf5, 0x29, 0xff, 0x58, 0x89, 0x62,
-	0x67, 0x45, 0x51, 0xca, 0x73, 0xff, 0x5a, 0x9a, 0xc1, 0x6f, 0x9f, 0xc6, 0x05, 0xec, 0x24, 0x1f,
-	0x7b, 0x64, 0xd0, 0xbe, 0x33, 0x26, 0x9e, 0x8f, 0x7b, 0x80, 0xe7, 0x67, 0x00, 0xde, 0xbd, 0x7a,
-	0x42, 0x88, 0x6a, 0x0f, 0xaf, 0x33, 0x46, 0x1a, 0xbf, 0x11, 0x48, 0x1d, 0x6a, 0x4d, 0xe9, 0x7d,
-	0x1f, 0xa7, 0xb7, 0x8d, 0x17, 0xf9, 0x5d, 0x59, 0x14, 0x80, 0x5b, 0x70, 0xfb, 0x2d, 0x61, 0x53,
-	0x69, 0x71, 0x06, 0x09, 0xf1, 0x76, 0xb3, 0x6d, 0x77, 0x52, 0xe0, 0x59, 0xcf, 0xfe, 0x07, 0x00,
-	0x00, 0xff, 0xff, 0x10, 0xd8, 0x69, 0xfe, 0x5d, 0x0a, 0x00, 0x00,
+	// 838 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0xdd, 0x6e, 0xe3, 0x44,
+	0x14, 0xd6, 0x24, 0x6d, 0x4a, 0x8f, 0x21, 0xa9, 0x66, 0xdb, 0x74, 0x6a, 0x40, 0x44, 0xd9, 0xbd,
+	0xc8, 0x4a, 0x60, 0x56, 0x41, 0x48, 0xc0, 0xcd, 0x52, 0xda, 0x2c, 0x17, 0x24, 0x41, 0x72, 0x40,
+	0x48, 0x08, 0x29, 0x72, 0x9d, 0x21, 0x6b, 0x6a, 0xcf, 0x18, 0xcf, 0x38, 0x8b, 0xfb, 0x06, 0xbc,
+	0x01, 0x0f, 0xc1, 0x3b, 0x70, 0xc9, 0x2d, 0xef, 0xc1, 0x23, 0x70, 0x83, 0x3c, 0xe3, 0xc4, 0x76,
+	0x6a, 0x37, 0x1b, 0xed, 0x4d, 0x35, 0x3f, 0xe7, 0x7c, 0xe7, 0x9b, 0xef, 0x7c, 0x3e, 0x0d, 0xb4,
+	0x23, 0xba, 0xf4, 0x84, 0x8c, 0x12, 0x2b, 0x8c, 0xb8, 0xe4, 0xf8, 0xad, 0xf5, 0xde, 0x24, 0xa1,
+	0x4c, 0x42, 0x2a, 0x3e, 0xa6, 0x41, 0x28, 0x13, 0xfd, 0x57, 0xc7, 0x98, 0xbd, 0xec, 0x46, 0x7a,
+	0x01, 0x15, 0xd2, 0x09, 0xc2, 0x7c, 0xa5, 0x23, 0xfa, 0x1e, 0xb4, 0xa7, 0x54, 0xbe, 0xe2, 0xd1,
+	0xed, 0x8c, 0x46, 0x2b, 0xcf, 0xa5, 0x18, 0xc3, 0x01, 0x73, 0x02, 0x4a, 0x50, 0x0f, 0x0d, 0x8e,
+	0x6d, 0xb5, 0xc6, 0x04, 0x8e, 0x42, 0x27, 0xf1, 0xb9, 0xb3, 0x20, 0x0d, 0x75, 0xbc, 0xde, 0xe2,
+	0xa7, 0x70, 0x14, 0x38, 0xd2, 0x7d, 0x49, 0x05, 0x69, 0xf6, 0x9a, 0x03, 0x63, 0xd8, 0xb1, 0x36,
+	0x3c, 0x27, 0xe9, 0x85, 0xbd, 0xbe, 0xef, 0xff, 0x8d, 0xe0, 0x50, 0x1d, 0xe1, 0x31, 0x74, 0x04,
+	0x8f, 0x23, 0x97, 0xce, 0x05, 0xf5, 0xa9, 0x2b, 0x79, 0x44, 0x90, 0x4a, 0x7e, 0xbc, 0x95, 0x6c,
+	0xcd, 0x54, 0xd8, 0x2c, 0x8b, 0x1a, 0x31, 0x19, 0x25, 0x76, 0x5b, 0x94, 0x0e, 0xf1, 0x47, 0xd0,
+	0x8a, 0x78, 0x2c, 0xa9, 0x20, 0x0d, 0x05, 0x72, 0x96, 0x83, 0x5c, 0x53, 0x21, 0x3d, 0xe6, 0x48,
+	0x8f, 0x33, 0x3b, 0x0b, 0x32, 0x2f, 0xe1, 0x51, 0x05, 0x2a, 0x3e, 0x81, 0xe6, 0x2d, 0x4d, 0xb2,
+	0x57, 0xa7, 0x4b, 0x7c, 0x0a, 0x87, 0x2b, 0xc7, 0x8f, 0x69, 0xf6, 0x64, 0xbd, 0xf9, 0xa2, 0xf1,
+	0x19, 0xea, 0xff, 0x83, 0xc0, 0x28, 0x40, 0x63, 0x07, 0x4e, 0x17, 0xf9, 0x76, 0xfb, 0x51, 0x56,
+	0x25, 0x9f, 0xe2, 0xba, 0xfc, 0xbe, 0x47, 0x8b, 0xfb, 0x37, 0xb8, 0x0b, 0xad, 0x57, 0xd4, 0x5b,
+	0xbe, 0x94, 0x8a, 0xcd, 0x3b, 0x76, 0xb6, 0x33, 0x5f, 0x00, 0xa9, 0x03, 0xda, 0xeb, 0x49, 0x7f,
+	0x20, 0x38, 0x2b, 0x1b, 0x61, 0xe2, 0x30, 0x67, 0x49, 0xa3, 0x4a, 0x3f, 0x9c, 0x40, 0x33, 0x8e,
+	0xfc, 0x0c, 0x25, 0x5d, 0xe2, 0x2b, 0xe8, 0xd0, 0xdf, 0x42, 0x2f, 0xd2, 0x0a, 0xa4, 0x2e, 0x23,
+	0xcd, 0x1e, 0x1a, 0x18, 0x43, 0xd3, 0x5a, 0x72, 0xbe, 0xf4, 0xa9, 0xf6, 0xdb, 0x4d, 0xfc, 0xb3,
+	0xf5, 0xdd, 0xda, 0x82, 0x76, 0x3b, 0x4f, 0x49, 0x0f, 0x53, 0x7a, 0x42, 0x3a, 0x92, 0x92, 0x03,
+	0x4d, 0x4f, 0x6d, 0xfa, 0xff, 0x35, 0xa0, 0x5b, 0xa6, 0x36, 0x62, 0x8b, 0x90, 0x7b, 0x4c, 0xee,
+	0xe9, 0xd5, 0x67, 0x70, 0xca, 0x34, 0xce, 0x5c, 0x68, 0xa0, 0x39, 0x73, 0x32, 0xa2, 0xc7, 0x36,
+	0x66, 0xa5, 0x1a, 0xd3, 0x14, 0xeb, 0x39, 0xbc, 0xb7, 0x9d, 0x11, 0x68, 0x59, 0x74, 0xa6, 0xe6,
+	0x79, 0xc1, 0xaa, 0x84, 0x53, 0x00, 0xd7, 0xd0, 0xf2, 0x9d, 0x1b, 0xea, 0x0b, 0x72, 0xa8, 0xbc,
+	0xf0, 0x61, 0xee, 0x85, 0xea, 0x27, 0x59, 0x63, 0x15, 0xae, 0x9d, 0x90, 0xe5, 0xe6, 0xba, 0xb4,
+	0x0a, 0xba, 0xe0, 0xa7, 0x70, 0xe2, 0x72, 0xc6, 0xa8, 0xab, 0x24, 0x77, 0x79, 0xcc, 0x24, 0x39,
+	0x52, 0xe6, 0xe8, 0xe4, 0xe7, 0x57, 0xe9, 0xb1, 0xf9, 0x39, 0x18, 0x05, 0xdc, 0xbd, 0x8c, 0x31,
+	0x81, 0x8b, 0x17, 0x1e, 0x5b, 0x94, 0xd9, 0xda, 0xf4, 0xd7, 0x98, 0x0a, 0x59, 0xab, 0x28, 0xaa,
+	0x53, 0xb4, 0xff, 0x57, 0x13, 0xcc, 0x2a, 0x3c, 0x11, 0x72, 0x26, 0x4a, 0xcd, 0x43, 0xe5, 0xe6,
+	0x5d, 0x42, 0x67, 0xab, 0x94, 0xe2, 0x6a, 0x0c, 0x49, 0x9d, 0xa4, 0x76, 0xbb, 0x5c, 0x1f, 0xdf,
+	0x01, 0xa9, 0xe9, 0xe6, 0x7a, 0x78, 0x7d, 0x99, 0x63, 0xd5, 0x93, 0xb4, 0x2a, 0xbf, 0x93, 0xac,
+	0x65, 0xdd, 0x4a, 0x2f, 0x08, 0xfc, 0x13, 0x5c, 0x6c, 0xd7, 0xa6, 0x59, 0xcb, 0x05, 0x39, 0x50,
+	0xc5, 0x7b, 0xbb, 0xbc, 0x61, 0x9f, 0xb3, 0xca, 0x73, 0x61, 0xfe, 0x02, 0xef, 0x3e, 0x40, 0xaa,
+	0xa2, 0xdf, 0x9f, 0x16, 0xfb, 0x6d, 0x0c, 0x3f, 0xa8, 0x2b, 0x9d, 0xe1, 0x14, 0x0d, 0xf1, 0x7b,
+	0x03, 0x3a, 0xd3, 0xd9, 0xc8, 0xd6, 0x09, 0x7a, 0x00, 0x56, 0x34, 0x07, 0xed, 0xd9, 0x9c, 0x1f,
+	0xe0, 0xbc, 0xa6, 0x39, 0xaf, 0xcb, 0xf1, 0xac, 0x52, 0x7a, 0xfc, 0x23, 0x90, 0x3a, 0xe5, 0xb3,
+	0x11, 0xb5, 0x5b, 0xf8, 0x6e, 0xb5, 0xf0, 0xfd, 0xef, 0xe1, 0xc4, 0xa6, 0x01, 0x5f, 0x51, 0x25,
+	0x88, 0xfe, 0x26, 0x2e, 0xe1, 0xfd, 0xba, 0x7a, 0xc5, 0x8f, 0xc3, 0xac, 0x86, 0x54, 0x1f, 0xc9,
+	0x1d, 0x98, 0xd5, 0x44, 0xc6, 0x9e, 0x90, 0x0f, 0x5b, 0x09, 0xbd, 0xa1, 0x95, 0x86, 0xff, 0xa2,
+	0xed, 0x69, 0x9b, 0x75, 0x3a, 0xc1, 0x57, 0x60, 0xe8, 0x35, 0x8d, 0xa6, 0xb3, 0x11, 0xbe, 0x28,
+	0x14, 0x29, 0xfb, 0xc1, 0xac, 0xbf, 0xc2, 0xdf, 0x40, 0xe7, 0xab, 0xd8, 0xbf, 0x7d, 0x63, 0xa0,
+	0x01, 0x7a, 0x86, 0xf0, 0x73, 0x38, 0xde, 0xe8, 0x8f, 0xcd, 0x3c, 0x76, 0xbb, 0x29, 0x66, 0xf7,
+	0xde, 0x7f, 0xa1, 0x51, 0xfa, 0x33, 0x69, 0x78, 0x07, 0xe7, 0xe5, 0xc7, 0x5e, 0x7b, 0xc2, 0xe5,
+	0x2b, 0x1a, 0x25, 0x78, 0x0e, 0xf8, 0xfe, 0x0c, 0xc0, 0x8f, 0x1f, 0x9e, 0x10, 0xba, 0xda, 0x93,
+	0xd7, 0x19, 0x23, 0xc3, 0x3f, 0x11, 0x18, 0x53, 0x11, 0x6c, 0xe4, 0xfd, 0xb6, 0x28, 0xef, 0x04,
+	0xef, 0xf2, 0xbb, 0xb9, 0x2b, 0x00, 0x8f, 0xe1, 0xed, 0xaf, 0xa9, 0xdc, 0xb4, 0x16, 0xd7, 0x88,
+	0x60, 0x3e, 0xa9, 0x03, 0x2a, 0xda, 0xee, 0xa6, 0xa5, 0xb2, 0x3e, 0xf9, 0x7f, 0x00, 0xa7, 0x26,
+	0x5f, 0x8f, 0x88, 0x0a, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
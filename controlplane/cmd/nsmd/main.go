@@ -18,9 +18,11 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/metrics"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/model"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/nsm"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/nsmd"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/selector"
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
 )
 
@@ -30,6 +32,16 @@ var version string
 const (
 	NsmdAPIAddressEnv      = "NSMD_API_ADDRESS"
 	NsmdAPIAddressDefaults = ":5001"
+	// NsmdEndpointSelectorEnv - environment variable name - policy of selecting endpoints for the connections
+	NsmdEndpointSelectorEnv = utils.EnvVar("NSMD_ENDPOINT_SELECTOR")
+	// LeastConnectionsSelector - NsmdEndpointSelectorEnv value to select endpoints with the least number of connections
+	LeastConnectionsSelector = "least-connections"
+	// NsmdForwarderSelectorEnv - environment variable name - policies of selecting forwarders for the connections
+	NsmdForwarderSelectorEnv = utils.EnvVar("NSMD_FORWARDER_SELECTOR")
+	// NsmdHealPolicyEnv - environment variable name - policies of healing the connections
+	NsmdHealPolicyEnv = utils.EnvVar("NSMD_HEAL_POLICY")
+	// NsmdModelStoreEnv - environment variable name - path of the file to persist NSMD state in, state is not persisted if empty
+	NsmdModelStoreEnv = utils.EnvVar("NSMD_MODEL_STORE")
 )
 
 func main() {
@@ -49,11 +61,52 @@ func main() {
 	span.LogValue("tracing.init-complete", fmt.Sprintf("%v", time.Since(start)))
 	defer span.Finish() // Mark it as finished, since it will be used as root.
 
+	// Admission controller and NSMD metrics are registered in the default Prometheus registry
+	if prometheus, err := tools.ReadEnvBool(metrics.PrometheusEnv, metrics.PrometheusDefault); err == nil && prometheus {
+		go metrics.RunPrometheusMetricsServer()
+	}
+
 	apiRegistry := nsmd.NewApiRegistry()
 	serviceRegistry := nsmd.NewServiceRegistry()
 
+	forwarderSelector, err := model.ParseForwarderSelector(NsmdForwarderSelectorEnv.StringValue())
+	if err != nil {
+		span.LogError(errors.Wrap(err, "failed to parse forwarder selection policies"))
+		return
+	}
+
+	healPolicies, err := model.ParseHealPolicies(NsmdHealPolicyEnv.StringValue())
+	if err != nil {
+		span.LogError(errors.Wrap(err, "failed to parse heal policies"))
+		return
+	}
+
+	var store model.Store
+	if storePath := NsmdModelStoreEnv.StringValue(); storePath != "" {
+		fileStore, err := model.NewFileStore(storePath)
+		if err != nil {
+			span.LogError(errors.Wrap(err, "failed to open model store"))
+			return
+		}
+		defer func() { _ = fileStore.Close() }()
+		store = fileStore
+	}
+
 	model := model.NewModel() // This is TCP gRPC server uri to access this NSMD via network.
 	defer serviceRegistry.Stop()
+	if store != nil {
+		if err := model.Restore(store); err != nil {
+			span.LogError(errors.Wrap(err, "failed to restore model"))
+			return
+		}
+	}
+	model.SetForwarderSelector(forwarderSelector)
+	model.SetHealPolicies(healPolicies)
+	leastConnections := NsmdEndpointSelectorEnv.StringValue() == LeastConnectionsSelector
+	if leastConnections {
+		span.Logger().Infof("Using %s endpoint selector", LeastConnectionsSelector)
+		model.SetSelector(selector.NewLeastConnectionsMatchSelector(model))
+	}
 	manager := nsm.NewNetworkServiceManager(span.Context(), model, serviceRegistry)
 
 	var server nsmd.NSMServer
@@ -77,6 +130,11 @@ func main() {
 	monitorCrossConnectClient := nsmd.NewMonitorCrossConnectClient(model, server, server.XconManager(), server)
 	model.AddListener(monitorCrossConnectClient)
 
+	if leastConnections {
+		// Advertise connections served by local endpoints to the other NSMs
+		model.AddListener(nsmd.NewConnectionCountAdvertiser(span.Context(), model, serviceRegistry))
+	}
+
 	// Starting forwarder
 	logrus.Info("Starting Forwarder registration server...")
 	if err := server.StartForwarderRegistratorServer(span.Context()); err != nil {
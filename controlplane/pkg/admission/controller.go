@@ -0,0 +1,194 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admission - limits number of connections NSMD serves for the workspaces, NetworkServices and remote NSMs
+package admission
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+)
+
+// Scopes connections are counted in
+const (
+	WorkspaceScope      = "workspace"
+	NetworkServiceScope = "network_service"
+	RemoteNsmScope      = "remote_nsm"
+)
+
+const (
+	// ConnectionsMetric is a name of gauge vector with numbers of admitted connections
+	ConnectionsMetric = "nsm_admitted_connections"
+	// RejectedConnectionsMetric is a name of counter vector with numbers of rejected connection requests
+	RejectedConnectionsMetric = "nsm_rejected_connections_total"
+
+	// ScopeKey is a metric label for the scope connection is counted in
+	ScopeKey = "scope"
+	// NameKey is a metric label for the workspace, NetworkService or remote NSM name
+	NameKey = "name"
+)
+
+// Quotas are maximum numbers of connections, zero means unlimited
+type Quotas struct {
+	// Workspace - maximum number of connections requested by the clients of a single workspace
+	Workspace int
+	// NetworkService - maximum number of connections to a single NetworkService
+	NetworkService int
+	// RemoteNsm - maximum number of connections requested by a single remote NSM
+	RemoteNsm int
+}
+
+type key struct {
+	scope string
+	name  string
+}
+
+// Controller admits new connections while quotas of all their scopes are not exhausted. Connections are counted
+// from admission until they are released.
+type Controller struct {
+	quotas      Quotas
+	mtx         sync.Mutex
+	connections map[string][]key
+	counts      map[key]int
+
+	admitted *prometheus.GaugeVec
+	rejected *prometheus.CounterVec
+}
+
+// NewController creates admission controller enforcing the quotas
+func NewController(quotas Quotas) *Controller {
+	admitted := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: ConnectionsMetric,
+		Help: "Number of connections admitted by NSMD",
+	}, []string{ScopeKey, NameKey})
+	rejected := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: RejectedConnectionsMetric,
+		Help: "Number of connection requests rejected by NSMD since quota is exhausted",
+	}, []string{ScopeKey, NameKey})
+
+	return &Controller{
+		quotas:      quotas,
+		connections: map[string][]key{},
+		counts:      map[key]int{},
+		admitted:    register(admitted).(*prometheus.GaugeVec),
+		rejected:    register(rejected).(*prometheus.CounterVec),
+	}
+}
+
+func register(collector prometheus.Collector) prometheus.Collector {
+	if err := prometheus.Register(collector); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		logrus.Infof("failed to register vector %v, err: %v", collector, err)
+	}
+	return collector
+}
+
+// Admit counts a new connection requested by the client of the workspace, workspace is empty for the remote
+// connections. ResourceExhausted error is returned if one of the quotas is exhausted.
+func (c *Controller) Admit(id string, conn *connection.Connection, workspace string) error {
+	keys := c.keys(conn, workspace)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if _, ok := c.connections[id]; ok {
+		return nil
+	}
+	for _, k := range keys {
+		if limit := c.limit(k.scope); limit > 0 && c.counts[k] >= limit {
+			c.rejected.WithLabelValues(k.scope, k.name).Inc()
+			return status.Errorf(codes.ResourceExhausted, "connection quota of %s %s is exhausted: %d connections", k.scope, k.name, limit)
+		}
+	}
+	c.add(id, keys)
+	return nil
+}
+
+// Restore counts the connection restored after NSMD restart, quotas are not checked for the restored connections
+func (c *Controller) Restore(id string, conn *connection.Connection, workspace string) {
+	keys := c.keys(conn, workspace)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if _, ok := c.connections[id]; !ok {
+		c.add(id, keys)
+	}
+}
+
+func (c *Controller) add(id string, keys []key) {
+	for _, k := range keys {
+		c.counts[k]++
+		c.admitted.WithLabelValues(k.scope, k.name).Set(float64(c.counts[k]))
+	}
+	c.connections[id] = keys
+}
+
+// Release stops counting the connection
+func (c *Controller) Release(id string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	keys, ok := c.connections[id]
+	if !ok {
+		return
+	}
+	delete(c.connections, id)
+	for _, k := range keys {
+		c.counts[k]--
+		c.admitted.WithLabelValues(k.scope, k.name).Set(float64(c.counts[k]))
+		if c.counts[k] == 0 {
+			delete(c.counts, k)
+		}
+	}
+}
+
+// Count returns number of connections admitted in the scope with the given name
+func (c *Controller) Count(scope, name string) int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.counts[key{scope: scope, name: name}]
+}
+
+func (c *Controller) keys(conn *connection.Connection, workspace string) []key {
+	keys := []key{{scope: NetworkServiceScope, name: conn.GetNetworkService()}}
+	if workspace != "" {
+		keys = append(keys, key{scope: WorkspaceScope, name: workspace})
+	}
+	if conn.IsRemote() {
+		keys = append(keys, key{scope: RemoteNsmScope, name: conn.GetSourceNetworkServiceManagerName()})
+	}
+	return keys
+}
+
+func (c *Controller) limit(scope string) int {
+	switch scope {
+	case WorkspaceScope:
+		return c.quotas.Workspace
+	case NetworkServiceScope:
+		return c.quotas.NetworkService
+	case RemoteNsmScope:
+		return c.quotas.RemoteNsm
+	}
+	return 0
+}
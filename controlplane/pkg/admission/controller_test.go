@@ -0,0 +1,110 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/metrics"
+)
+
+func newTestConnection(networkService string, nsms ...string) *connection.Connection {
+	conn := &connection.Connection{
+		NetworkService: networkService,
+		Path:           &connection.Path{},
+	}
+	for _, nsm := range nsms {
+		conn.Path.PathSegments = append(conn.Path.PathSegments, &connection.PathSegment{Name: nsm})
+	}
+	return conn
+}
+
+func TestAdmitWorkspaceQuota(t *testing.T) {
+	g := NewWithT(t)
+
+	c := NewController(Quotas{Workspace: 2})
+	g.Expect(c.Admit("1", newTestConnection("ns"), "ws1")).To(BeNil())
+	g.Expect(c.Admit("2", newTestConnection("ns"), "ws1")).To(BeNil())
+	g.Expect(c.Admit("3", newTestConnection("ns"), "ws2")).To(BeNil())
+
+	err := c.Admit("4", newTestConnection("ns"), "ws1")
+	g.Expect(status.Code(err)).To(Equal(codes.ResourceExhausted))
+	g.Expect(testutil.ToFloat64(c.rejected.WithLabelValues(WorkspaceScope, "ws1"))).To(Equal(1.0))
+
+	// Admitted connection is not counted twice
+	g.Expect(c.Admit("1", newTestConnection("ns"), "ws1")).To(BeNil())
+
+	c.Release("1")
+	g.Expect(c.Count(WorkspaceScope, "ws1")).To(Equal(1))
+	g.Expect(c.Admit("4", newTestConnection("ns"), "ws1")).To(BeNil())
+	g.Expect(testutil.ToFloat64(c.admitted.WithLabelValues(WorkspaceScope, "ws1"))).To(Equal(2.0))
+}
+
+func TestAdmitNetworkServiceAndRemoteNsmQuotas(t *testing.T) {
+	g := NewWithT(t)
+
+	c := NewController(Quotas{NetworkService: 2, RemoteNsm: 1})
+	g.Expect(c.Admit("1", newTestConnection("ns1", "nsm1", "nsm0"), "")).To(BeNil())
+	g.Expect(c.Count(RemoteNsmScope, "nsm1")).To(Equal(1))
+
+	err := c.Admit("2", newTestConnection("ns2", "nsm1", "nsm0"), "")
+	g.Expect(status.Code(err)).To(Equal(codes.ResourceExhausted))
+
+	g.Expect(c.Admit("3", newTestConnection("ns1", "nsm2", "nsm0"), "")).To(BeNil())
+	err = c.Admit("4", newTestConnection("ns1"), "ws")
+	g.Expect(status.Code(err)).To(Equal(codes.ResourceExhausted))
+
+	// Rejected connection is not counted
+	g.Expect(c.Count(WorkspaceScope, "ws")).To(Equal(0))
+	g.Expect(c.Count(NetworkServiceScope, "ns2")).To(Equal(0))
+
+	// Restored connections are counted over the quota
+	c.Restore("5", newTestConnection("ns1"), "ws")
+	g.Expect(c.Count(NetworkServiceScope, "ns1")).To(Equal(3))
+	c.Release("5")
+	c.Release("3")
+	g.Expect(c.Count(NetworkServiceScope, "ns1")).To(Equal(1))
+}
+
+func TestAdmissionMetricsAreServed(t *testing.T) {
+	g := NewWithT(t)
+
+	c := NewController(Quotas{Workspace: 1})
+	g.Expect(c.Admit("1", newTestConnection("ns"), "scraped")).To(BeNil())
+	err := c.Admit("2", newTestConnection("ns"), "scraped")
+	g.Expect(status.Code(err)).To(Equal(codes.ResourceExhausted))
+
+	// NSMD metrics server serves the default registry
+	server := httptest.NewServer(metrics.GetPrometheusMetricsServer().Handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	g.Expect(err).To(BeNil())
+	defer func() { _ = resp.Body.Close() }()
+	body, err := ioutil.ReadAll(resp.Body)
+	g.Expect(err).To(BeNil())
+
+	g.Expect(string(body)).To(ContainSubstring(ConnectionsMetric + `{name="scraped",scope="workspace"} 1`))
+	g.Expect(string(body)).To(ContainSubstring(RejectedConnectionsMetric + `{name="scraped",scope="workspace"} 1`))
+}
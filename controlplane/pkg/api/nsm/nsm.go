@@ -23,6 +23,7 @@ import (
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/admission"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/model"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/serviceregistry"
 	"github.com/networkservicemesh/networkservicemesh/sdk/monitor/connectionmonitor"
@@ -86,6 +87,7 @@ type MonitorManager interface {
 //NetworkServiceManager - hold useful nsm structures
 type NetworkServiceManager interface {
 	GetHealProperties() *properties.Properties
+	AdmissionController() *admission.Controller
 	WaitForForwarder(ctx context.Context, duration time.Duration) error
 	RemoteConnectionLost(ctx context.Context, clientConnection ClientConnection)
 	NotifyRenamedEndpoint(nseOldName, nseNewName string)
@@ -0,0 +1,62 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/admission"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/model"
+)
+
+// admissionService - admits new connections by the connection quotas
+type admissionService struct {
+	controller *admission.Controller
+}
+
+func (srv *admissionService) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*connection.Connection, error) {
+	clientConnection := ModelConnection(ctx)
+	if clientConnection == nil || clientConnection.ConnectionState != model.ClientConnectionRequesting {
+		// Connection is updated or healed, it is admitted already.
+		return ProcessNext(ctx, request)
+	}
+
+	if err := srv.controller.Admit(clientConnection.GetID(), request.GetConnection(), WorkspaceName(ctx)); err != nil {
+		Log(ctx).Error(err)
+		return nil, err
+	}
+	conn, err := ProcessNext(ctx, request)
+	if err != nil {
+		srv.controller.Release(clientConnection.GetID())
+	}
+	return conn, err
+}
+
+func (srv *admissionService) Close(ctx context.Context, connection *connection.Connection) (*empty.Empty, error) {
+	srv.controller.Release(connection.GetId())
+	return ProcessClose(ctx, connection)
+}
+
+// NewAdmissionService -  creates a service to reject new connections once connection quotas are exhausted,
+// it should follow the connection service in the chain
+func NewAdmissionService(controller *admission.Controller) networkservice.NetworkServiceServer {
+	return &admissionService{
+		controller: controller,
+	}
+}
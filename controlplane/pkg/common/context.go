@@ -43,6 +43,7 @@ const (
 	ignoredEndpoints      ContextKeyType = "IgnoredEndpoints"
 	workspaceName         ContextKeyType = "WorkspaceName"
 	remoteMechanisms      ContextKeyType = "RemoteMechanisms"
+	connectionUpdate      ContextKeyType = "ConnectionUpdate"
 )
 
 // WithClientConnection -
@@ -142,6 +143,25 @@ func ModelConnection(ctx context.Context) *model.ClientConnection {
 	return conn.(*model.ClientConnection)
 }
 
+// WithConnectionUpdate -
+//   Wraps 'parent' in a new Context that marks the request as in place update of the established model
+//   connection, e.g. rotation of its keys. The endpoint is requested again, and if the update fails the
+//   connection is kept as it was instead of being closed.
+//
+func WithConnectionUpdate(parent context.Context) context.Context {
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithValue(parent, connectionUpdate, true)
+}
+
+// IsConnectionUpdate -
+//    Returns true if the request is in place update of the established connection
+func IsConnectionUpdate(ctx context.Context) bool {
+	value, ok := ctx.Value(connectionUpdate).(bool)
+	return ok && value
+}
+
 // WithRemoteMechanisms -
 //   Wraps 'parent' in a new Context that has the remote mechanisms
 //   using Context.Value(...) and returns the result.
@@ -84,11 +84,17 @@ func (eps *excludedPrefixesService) validateConnection(conn *connection.Connecti
 	}
 
 	ipCtx := conn.GetContext().GetIpContext()
-	if err := eps.validateIPAddress(ipCtx.GetSrcIpAddr(), "srcIP"); err != nil {
-		return err
+	for _, srcIP := range ipCtx.SrcIPAddrs() {
+		if err := eps.validateIPAddress(srcIP, "srcIP"); err != nil {
+			return err
+		}
 	}
-
-	return eps.validateIPAddress(ipCtx.GetDstIpAddr(), "dstIP")
+	for _, dstIP := range ipCtx.DstIPAddrs() {
+		if err := eps.validateIPAddress(dstIP, "dstIP"); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (eps *excludedPrefixesService) validateIPAddress(ip, ipName string) error {
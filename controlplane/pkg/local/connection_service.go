@@ -52,6 +52,12 @@ func (cce *connectionService) Request(ctx context.Context, request *networkservi
 	workspaceName := common.WorkspaceName(ctx)
 	span.LogValue("workspace", workspaceName)
 
+	healPolicy, err := cce.model.GetHealPolicies().RequestPolicy(request)
+	if err != nil {
+		span.LogError(err)
+		return nil, err
+	}
+
 	// We need to take updated connection in case of updates
 	clientConnection := common.ModelConnection(ctx) // Case only for Healing.
 
@@ -82,8 +88,9 @@ func (cce *connectionService) Request(ctx context.Context, request *networkservi
 		cce.model.AddClientConnection(ctx, clientConnection)
 	}
 
-	// 8. Remember original Request for Heal cases.
+	// 8. Remember original Request and heal policy for Heal cases.
 	clientConnection.Request = request
+	clientConnection.HealPolicy = healPolicy
 	ctx = common.WithModelConnection(ctx, clientConnection)
 
 	conn, err := common.ProcessNext(ctx, request)
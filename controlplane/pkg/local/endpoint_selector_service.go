@@ -52,6 +52,8 @@ func (cce *endpointSelectorService) Request(ctx context.Context, request *networ
 	// true if we detect we need to request NSE to upgrade/update connection.
 	// 4.1 New Network service is requested, we need to close current connection and do re-request of NSE.
 	requestNSEOnUpdate := cce.checkNSEUpdateIsRequired(ctx, clientConnection, request, logger, dp)
+	// 4.3 In place update of the connection, e.g. keys rotation, renegotiates remote mechanism with the same NSE.
+	requestNSEOnUpdate = requestNSEOnUpdate || common.IsConnectionUpdate(ctx)
 	span.LogObject("requestNSEOnUpdate", requestNSEOnUpdate)
 
 	// 7. do a Request() on NSE and select it.
@@ -90,6 +92,11 @@ func (cce *endpointSelectorService) Request(ctx context.Context, request *networ
 		// 7.1.8 in case of error we put NSE into ignored list to check another one.
 		if err != nil {
 			logger.Errorf("NSM:(7.1.8) NSE respond with error: %v ", err)
+			if common.IsConnectionUpdate(parentCtx) {
+				// Updated connection is kept with the same NSE.
+				span.Finish()
+				return nil, err
+			}
 			lastError = err
 			ignoreEndpoints[endpoint.GetEndpointNSMName()] = endpoint
 			span.Finish()
@@ -121,6 +128,9 @@ func (cce *endpointSelectorService) selectEndpoint(ctx context.Context, clientCo
 		endpointName := clientConnection.Endpoint.GetEndpointNSMName()
 		if clientConnection.Endpoint != nil && ignoreEndpoints[endpointName] == nil {
 			endpoint = clientConnection.Endpoint
+		} else if clientConnection.Endpoint != nil && clientConnection.HealPolicy.Mode == model.HealModeSameEndpoint {
+			// Connection could not be moved to another endpoint by its heal policy.
+			return nil, errors.Errorf("endpoint %s is not available and connection %s could be healed with the same endpoint only", endpointName, clientConnection.GetID())
 		} else {
 			// Ignored, we need to update DSTid.
 			clientConnection.Xcon.Destination.Id = "-"
@@ -25,6 +25,7 @@ import (
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/ipsec"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/srv6"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
@@ -53,15 +54,9 @@ type forwarderService struct {
 }
 
 func (cce *forwarderService) selectForwarder(request *networkservice.NetworkServiceRequest) (*model.Forwarder, error) {
-	dp, err := cce.model.SelectForwarder(func(dp *model.Forwarder) bool {
-		for _, m := range request.GetRequestMechanismPreferences() {
-			if cce.findMechanism(dp.LocalMechanisms, m.GetType()) != nil {
-				return true
-			}
-		}
-		return false
+	return cce.model.SelectRequestForwarder(request, func(dp *model.Forwarder) []*connection.Mechanism {
+		return dp.LocalMechanisms
 	})
-	return dp, err
 }
 
 func (cce *forwarderService) findMechanism(mechanismPreferences []*connection.Mechanism, mechanismType string) *connection.Mechanism {
@@ -141,6 +136,8 @@ func (cce *forwarderService) prepareRemoteMechanisms(request *networkservice.Net
 			cce.prepareSRv6Mechanism(m, request)
 		case wireguard.MECHANISM:
 			cce.prepareWireguardMechanism(m, request)
+		case ipsec.MECHANISM:
+			cce.prepareIPsecMechanism(m)
 		}
 		mechanisms = append(mechanisms, m)
 	}
@@ -178,8 +175,25 @@ func (cce *forwarderService) prepareWireguardMechanism(m *connection.Mechanism,
 	return m
 }
 
+// prepareIPsecMechanism - generates SPI of the security association to the source host, new one is generated on
+// every request, so the keys derived by the forwarders are rotated by the connection updates
+func (cce *forwarderService) prepareIPsecMechanism(m *connection.Mechanism) *connection.Mechanism {
+	if m.GetParameters() == nil {
+		m.Parameters = map[string]string{}
+	}
+	if err := ipsec.SetSrcSPI(m.GetParameters()); err != nil {
+		logrus.Errorf("NSM: failed to prepare IPsec mechanism: %v", err)
+	}
+	return m
+}
+
 func (cce *forwarderService) doFailureClose(ctx context.Context) {
 	clientConnection := common.ModelConnection(ctx)
+	if common.IsConnectionUpdate(ctx) {
+		// Forwarder still serves the previous cross connection of the updated connection.
+		common.Log(ctx).Warnf("NSM: keeping previous cross connection of %s after failed update", clientConnection.GetID())
+		return
+	}
 
 	newCtx, cancel := context.WithTimeout(context.Background(), ErrorCloseTimeout)
 	defer cancel()
@@ -0,0 +1,242 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+)
+
+// Names of the crossconnect statistics metrics
+const (
+	CrossConnectRxBytesMetric   = "nsm_crossconnect_rx_bytes_total"
+	CrossConnectTxBytesMetric   = "nsm_crossconnect_tx_bytes_total"
+	CrossConnectRxPacketsMetric = "nsm_crossconnect_rx_packets_total"
+	CrossConnectTxPacketsMetric = "nsm_crossconnect_tx_packets_total"
+	CrossConnectRxDroppedMetric = "nsm_crossconnect_rx_dropped_total"
+	CrossConnectTxDroppedMetric = "nsm_crossconnect_tx_dropped_total"
+	CrossConnectRxErrorsMetric  = "nsm_crossconnect_rx_errors_total"
+	CrossConnectTxErrorsMetric  = "nsm_crossconnect_tx_errors_total"
+
+	WireguardLastHandshakeMetric = "nsm_crossconnect_wireguard_last_handshake_seconds"
+	WireguardRxBytesMetric       = "nsm_crossconnect_wireguard_rx_bytes_total"
+	WireguardTxBytesMetric       = "nsm_crossconnect_wireguard_tx_bytes_total"
+)
+
+// Labels of the crossconnect statistics metrics
+const (
+	// NsmKey is a label for the NSMD the crossconnect is monitored from
+	NsmKey = "nsm"
+	// CrossConnectKey is a label for the crossconnect id
+	CrossConnectKey = "crossconnect"
+	// LegKey is a label for the crossconnect leg: "SRC" or "DST"
+	LegKey = "leg"
+	// NetworkServiceKey is a label for the NetworkService
+	NetworkServiceKey = "network_service"
+	// EndpointKey is a label for the NetworkServiceEndpoint name
+	EndpointKey = "endpoint"
+	// ClientPodKey is a label for the client pod
+	ClientPodKey = "client_pod"
+	// ClientNamespaceKey is a label for the client pod namespace
+	ClientNamespaceKey = "client_namespace"
+	// MechanismKey is a label for the mechanism type of the crossconnect leg
+	MechanismKey = "mechanism"
+)
+
+const (
+	srcLeg = "SRC"
+	dstLeg = "DST"
+)
+
+var crossConnectLabels = []string{NsmKey, CrossConnectKey, LegKey, NetworkServiceKey, EndpointKey, ClientPodKey, ClientNamespaceKey, MechanismKey}
+
+type legKey struct {
+	nsm          string
+	crossConnect string
+	leg          string
+}
+
+type legStatistics struct {
+	labels     []string
+	statistics *crossconnect.Statistics
+}
+
+// CrossConnectCollector is a Prometheus collector exposing traffic statistics of the crossconnect legs reported by
+// the forwarders in the crossconnect events
+type CrossConnectCollector struct {
+	mtx  sync.Mutex
+	legs map[legKey]*legStatistics
+
+	counters      map[string]*prometheus.Desc
+	lastHandshake *prometheus.Desc
+	wgRxBytes     *prometheus.Desc
+	wgTxBytes     *prometheus.Desc
+}
+
+// NewCrossConnectCollector creates crossconnect statistics collector
+func NewCrossConnectCollector() *CrossConnectCollector {
+	newDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(name, help, crossConnectLabels, nil)
+	}
+	return &CrossConnectCollector{
+		legs: map[legKey]*legStatistics{},
+		counters: map[string]*prometheus.Desc{
+			CrossConnectRxBytesMetric:   newDesc(CrossConnectRxBytesMetric, "Bytes received by the crossconnect leg device"),
+			CrossConnectTxBytesMetric:   newDesc(CrossConnectTxBytesMetric, "Bytes transmitted by the crossconnect leg device"),
+			CrossConnectRxPacketsMetric: newDesc(CrossConnectRxPacketsMetric, "Packets received by the crossconnect leg device"),
+			CrossConnectTxPacketsMetric: newDesc(CrossConnectTxPacketsMetric, "Packets transmitted by the crossconnect leg device"),
+			CrossConnectRxDroppedMetric: newDesc(CrossConnectRxDroppedMetric, "Received packets dropped by the crossconnect leg device"),
+			CrossConnectTxDroppedMetric: newDesc(CrossConnectTxDroppedMetric, "Transmitted packets dropped by the crossconnect leg device"),
+			CrossConnectRxErrorsMetric:  newDesc(CrossConnectRxErrorsMetric, "Receive errors of the crossconnect leg device"),
+			CrossConnectTxErrorsMetric:  newDesc(CrossConnectTxErrorsMetric, "Transmit errors of the crossconnect leg device"),
+		},
+		lastHandshake: newDesc(WireguardLastHandshakeMetric, "Time of the last WireGuard peer handshake in unix seconds"),
+		wgRxBytes:     newDesc(WireguardRxBytesMetric, "Bytes received from the WireGuard peer"),
+		wgTxBytes:     newDesc(WireguardTxBytesMetric, "Bytes transmitted to the WireGuard peer"),
+	}
+}
+
+// RegisterCrossConnectCollector creates crossconnect statistics collector and registers it in the default Prometheus
+// registry, already registered collector is returned if there is one
+func RegisterCrossConnectCollector() *CrossConnectCollector {
+	collector := NewCrossConnectCollector()
+	if err := prometheus.Register(collector); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(*CrossConnectCollector)
+		}
+		logrus.Infof("failed to register crossconnect collector, err: %v", err)
+	}
+	return collector
+}
+
+// HandleEvent updates statistics of the crossconnects monitored from the nsm with the event metrics, statistics of
+// the deleted crossconnects are removed
+func (c *CrossConnectCollector) HandleEvent(nsm string, event *crossconnect.CrossConnectEvent) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if event.GetType() == crossconnect.CrossConnectEventType_DELETE {
+		for _, xcon := range event.GetCrossConnects() {
+			delete(c.legs, legKey{nsm: nsm, crossConnect: xcon.GetId(), leg: srcLeg})
+			delete(c.legs, legKey{nsm: nsm, crossConnect: xcon.GetId(), leg: dstLeg})
+		}
+		return
+	}
+
+	for metricName, metrics := range event.GetMetrics() {
+		// Metrics are named as SRC-id or DST-id
+		parts := strings.SplitN(metricName, "-", 2)
+		if len(parts) != 2 || (parts[0] != srcLeg && parts[0] != dstLeg) {
+			logrus.Warnf("metrics: unexpected crossconnect metrics name: %s", metricName)
+			continue
+		}
+		leg, id := parts[0], parts[1]
+
+		xcon, ok := event.GetCrossConnects()[id]
+		if !ok {
+			logrus.Warnf("metrics: crossconnect %s is missing in the event", id)
+			continue
+		}
+		statistics, err := crossconnect.StatisticsFromMetrics(metrics)
+		if err != nil {
+			logrus.Warnf("metrics: failed to parse %s statistics: %v", metricName, err)
+			continue
+		}
+		c.legs[legKey{nsm: nsm, crossConnect: id, leg: leg}] = &legStatistics{
+			labels:     legLabels(nsm, xcon, leg),
+			statistics: statistics,
+		}
+	}
+}
+
+// Forget removes statistics of all crossconnects monitored from the nsm
+func (c *CrossConnectCollector) Forget(nsm string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for key := range c.legs {
+		if key.nsm == nsm {
+			delete(c.legs, key)
+		}
+	}
+}
+
+func legLabels(nsm string, xcon *crossconnect.CrossConnect, leg string) []string {
+	mechanism := xcon.GetSource().GetMechanism().GetType()
+	if leg == dstLeg {
+		mechanism = xcon.GetDestination().GetMechanism().GetType()
+	}
+	clientLabels := xcon.GetSource().GetLabels()
+	return []string{
+		nsm,
+		xcon.GetId(),
+		leg,
+		xcon.GetSource().GetNetworkService(),
+		xcon.GetDestination().GetNetworkServiceEndpointName(),
+		clientLabels[connection.PodNameKey],
+		clientLabels[connection.NamespaceKey],
+		mechanism,
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *CrossConnectCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range c.counters {
+		ch <- desc
+	}
+	ch <- c.lastHandshake
+	ch <- c.wgRxBytes
+	ch <- c.wgTxBytes
+}
+
+// Collect implements prometheus.Collector
+func (c *CrossConnectCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, leg := range c.legs {
+		s := leg.statistics
+		for name, value := range map[string]uint64{
+			CrossConnectRxBytesMetric:   s.RxBytes,
+			CrossConnectTxBytesMetric:   s.TxBytes,
+			CrossConnectRxPacketsMetric: s.RxPackets,
+			CrossConnectTxPacketsMetric: s.TxPackets,
+			CrossConnectRxDroppedMetric: s.RxDropped,
+			CrossConnectTxDroppedMetric: s.TxDropped,
+			CrossConnectRxErrorsMetric:  s.RxErrors,
+			CrossConnectTxErrorsMetric:  s.TxErrors,
+		} {
+			ch <- prometheus.MustNewConstMetric(c.counters[name], prometheus.CounterValue, float64(value), leg.labels...)
+		}
+
+		peer := s.WireguardPeer
+		if peer == nil {
+			continue
+		}
+		var lastHandshake float64
+		if !peer.LastHandshake.IsZero() {
+			lastHandshake = float64(peer.LastHandshake.Unix())
+		}
+		ch <- prometheus.MustNewConstMetric(c.lastHandshake, prometheus.GaugeValue, lastHandshake, leg.labels...)
+		ch <- prometheus.MustNewConstMetric(c.wgRxBytes, prometheus.CounterValue, float64(peer.RxBytes), leg.labels...)
+		ch <- prometheus.MustNewConstMetric(c.wgTxBytes, prometheus.CounterValue, float64(peer.TxBytes), leg.labels...)
+	}
+}
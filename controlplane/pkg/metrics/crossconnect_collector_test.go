@@ -0,0 +1,139 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+)
+
+func newTestCrossConnect() *crossconnect.CrossConnect {
+	return &crossconnect.CrossConnect{
+		Id: "1",
+		Source: &connection.Connection{
+			NetworkService: "icmp-responder",
+			Mechanism:      &connection.Mechanism{Type: kernel.MECHANISM},
+			Labels: map[string]string{
+				connection.PodNameKey:   "client",
+				connection.NamespaceKey: "default",
+			},
+		},
+		Destination: &connection.Connection{
+			NetworkService:             "icmp-responder",
+			NetworkServiceEndpointName: "nse",
+			Mechanism:                  &connection.Mechanism{Type: wireguard.MECHANISM},
+		},
+	}
+}
+
+func TestCrossConnectCollector(t *testing.T) {
+	g := NewWithT(t)
+
+	src := &crossconnect.Statistics{RxBytes: 100, TxBytes: 200, RxPackets: 1, TxPackets: 2, RxDropped: 3}
+	dst := &crossconnect.Statistics{
+		TxErrors: 4,
+		WireguardPeer: &crossconnect.WireguardPeerStatistics{
+			LastHandshake: time.Unix(1580000000, 0),
+			RxBytes:       300,
+			TxBytes:       400,
+		},
+	}
+	xcon := newTestCrossConnect()
+
+	c := NewCrossConnectCollector()
+	c.HandleEvent("nsmd", &crossconnect.CrossConnectEvent{
+		Type:          crossconnect.CrossConnectEventType_UPDATE,
+		CrossConnects: map[string]*crossconnect.CrossConnect{xcon.GetId(): xcon},
+		Metrics: map[string]*crossconnect.Metrics{
+			"SRC-1": src.Metrics(),
+			"DST-1": dst.Metrics(),
+		},
+	})
+
+	labels := `network_service="icmp-responder",nsm="nsmd"`
+	srcLabels := `{client_namespace="default",client_pod="client",crossconnect="1",endpoint="nse",leg="SRC",mechanism="KERNEL_INTERFACE",` + labels + `}`
+	dstLabels := `{client_namespace="default",client_pod="client",crossconnect="1",endpoint="nse",leg="DST",mechanism="WIREGUARD",` + labels + `}`
+	expected := `
+# HELP nsm_crossconnect_rx_bytes_total Bytes received by the crossconnect leg device
+# TYPE nsm_crossconnect_rx_bytes_total counter
+nsm_crossconnect_rx_bytes_total` + srcLabels + ` 100
+nsm_crossconnect_rx_bytes_total` + dstLabels + ` 0
+# HELP nsm_crossconnect_rx_dropped_total Received packets dropped by the crossconnect leg device
+# TYPE nsm_crossconnect_rx_dropped_total counter
+nsm_crossconnect_rx_dropped_total` + srcLabels + ` 3
+nsm_crossconnect_rx_dropped_total` + dstLabels + ` 0
+# HELP nsm_crossconnect_tx_errors_total Transmit errors of the crossconnect leg device
+# TYPE nsm_crossconnect_tx_errors_total counter
+nsm_crossconnect_tx_errors_total` + srcLabels + ` 0
+nsm_crossconnect_tx_errors_total` + dstLabels + ` 4
+# HELP nsm_crossconnect_wireguard_last_handshake_seconds Time of the last WireGuard peer handshake in unix seconds
+# TYPE nsm_crossconnect_wireguard_last_handshake_seconds gauge
+nsm_crossconnect_wireguard_last_handshake_seconds` + dstLabels + ` 1.58e+09
+# HELP nsm_crossconnect_wireguard_tx_bytes_total Bytes transmitted to the WireGuard peer
+# TYPE nsm_crossconnect_wireguard_tx_bytes_total counter
+nsm_crossconnect_wireguard_tx_bytes_total` + dstLabels + ` 400
+`
+	g.Expect(testutil.CollectAndCompare(c, strings.NewReader(expected),
+		CrossConnectRxBytesMetric,
+		CrossConnectRxDroppedMetric,
+		CrossConnectTxErrorsMetric,
+		WireguardLastHandshakeMetric,
+		WireguardTxBytesMetric,
+	)).To(Succeed())
+
+	// Deleted crossconnect statistics are not exposed anymore
+	c.HandleEvent("nsmd", &crossconnect.CrossConnectEvent{
+		Type:          crossconnect.CrossConnectEventType_DELETE,
+		CrossConnects: map[string]*crossconnect.CrossConnect{xcon.GetId(): xcon},
+	})
+	g.Expect(testutil.CollectAndCompare(c, strings.NewReader(""), CrossConnectRxBytesMetric)).To(Succeed())
+}
+
+func TestStatisticsMetricsConversion(t *testing.T) {
+	g := NewWithT(t)
+
+	statistics := &crossconnect.Statistics{
+		RxBytes:  1,
+		TxErrors: 2,
+		WireguardPeer: &crossconnect.WireguardPeerStatistics{
+			LastHandshake: time.Unix(1580000000, 0),
+			TxBytes:       3,
+		},
+	}
+	parsed, err := crossconnect.StatisticsFromMetrics(statistics.Metrics())
+	g.Expect(err).To(BeNil())
+	g.Expect(parsed).To(Equal(statistics))
+
+	// Metrics sent by the older forwarders have no WireGuard peer and drops statistics
+	parsed, err = crossconnect.StatisticsFromMetrics(&crossconnect.Metrics{Metrics: map[string]string{
+		crossconnect.RxBytesKey: "10",
+	}})
+	g.Expect(err).To(BeNil())
+	g.Expect(parsed).To(Equal(&crossconnect.Statistics{RxBytes: 10}))
+
+	_, err = crossconnect.StatisticsFromMetrics(&crossconnect.Metrics{Metrics: map[string]string{
+		crossconnect.TxBytesKey: "-1",
+	}})
+	g.Expect(err).NotTo(BeNil())
+}
@@ -22,6 +22,7 @@ type baseDomain struct {
 	mtx      sync.RWMutex
 	handlers []*ModificationHandler
 	innerMap map[string]cloneable
+	journal  *journal
 }
 
 func newBase() baseDomain {
@@ -42,9 +43,14 @@ func (b *baseDomain) load(key string) (interface{}, bool) {
 }
 
 func (b *baseDomain) store(ctx context.Context, key string, value cloneable) {
+	var change *journalChange
+	// Deferred before unlock, so the change is written after the domain lock is released
+	defer func() { change.commit() }()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
+	change = b.journal.put(key, value)
+
 	old, exist := b.innerMap[key]
 	if !exist {
 		b.innerMap[key] = value.clone()
@@ -57,17 +63,22 @@ func (b *baseDomain) store(ctx context.Context, key string, value cloneable) {
 }
 
 func (b *baseDomain) delete(ctx context.Context, key string) {
+	var change *journalChange
+	defer func() { change.commit() }()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 	v, exist := b.innerMap[key]
 	if !exist {
 		return
 	}
+	change = b.journal.delete(key)
 	delete(b.innerMap, key)
 	b.resourceDeleted(ctx, v)
 }
 
 func (b *baseDomain) applyChanges(ctx context.Context, key string, changeFunc func(interface{})) interface{} {
+	var change *journalChange
+	defer func() { change.commit() }()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
@@ -79,11 +90,21 @@ func (b *baseDomain) applyChanges(ctx context.Context, key string, changeFunc fu
 	upd := old.clone()
 	changeFunc(upd)
 
+	change = b.journal.put(key, upd)
 	b.innerMap[key] = upd.clone()
 	b.resourceUpdated(ctx, old, upd)
 	return upd
 }
 
+// restore replaces domain objects with the restored ones and starts journaling all further changes
+func (b *baseDomain) restore(values map[string]cloneable, j *journal) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.innerMap = values
+	b.journal = j
+}
+
 func (b *baseDomain) kvRange(f func(key string, v interface{}) bool) {
 	b.mtx.RLock()
 	defer b.mtx.RUnlock()
@@ -46,6 +46,7 @@ type ClientConnection struct {
 	ForwarderRegisteredName string
 	ConnectionState         ClientConnectionState
 	ForwarderState          ForwarderState
+	HealPolicy              HealPolicy
 	Span                    spanhelper.SpanHelper
 	Monitor                 connectionmonitor.MonitorServer
 }
@@ -114,6 +115,7 @@ func (cc *ClientConnection) clone() cloneable {
 		Request:                 request,
 		ConnectionState:         cc.ConnectionState,
 		ForwarderState:          cc.ForwarderState,
+		HealPolicy:              cc.HealPolicy,
 		Span:                    cc.Span,
 		Monitor:                 cc.Monitor,
 	}
@@ -0,0 +1,360 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bufio"
+	"encoding/json"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	walSuffix      = ".wal"
+	snapshotSuffix = ".tmp"
+	// compactThreshold - number of write-ahead log records to compact log into a new snapshot
+	compactThreshold = 1024
+	storeFileMode    = 0600
+	storeDirMode     = 0700
+
+	opPut    = "put"
+	opDelete = "delete"
+)
+
+// walRecord is a single change of the store written into the write-ahead log
+type walRecord struct {
+	Op       string `json:"op"`
+	Kind     string `json:"kind"`
+	Key      string `json:"key"`
+	Value    []byte `json:"value,omitempty"`
+	Checksum uint32 `json:"crc"`
+}
+
+func (r *walRecord) checksum() uint32 {
+	crc := crc32.NewIEEE()
+	for _, s := range []string{r.Op, r.Kind, r.Key} {
+		_, _ = crc.Write([]byte(s))
+		_, _ = crc.Write([]byte{0})
+	}
+	_, _ = crc.Write(r.Value)
+	return crc.Sum32()
+}
+
+// pendingRecord is a record written into the write-ahead log and waiting to be synced
+type pendingRecord struct {
+	record *walRecord
+	// end - offset of the end of the record in the log
+	end  int64
+	done bool
+	err  error
+}
+
+// FileStore is a Store keeping objects in the snapshot file and journaling every change into
+// the write-ahead log next to it. Every change is synced to disk before it is applied, so the
+// state survives NSMD crash. Changes written concurrently are synced together by a single fsync.
+// Log is replayed on open and periodically compacted into a new snapshot.
+type FileStore struct {
+	mtx        sync.Mutex
+	cond       *sync.Cond
+	path       string
+	objects    map[string]map[string][]byte
+	wal        *os.File
+	walRecords int
+	// written - offset of the end of the last record written into the log, synced - of the last synced one
+	written int64
+	synced  int64
+	syncing bool
+	// pending - records written into the log in the log order, they are applied once synced
+	pending []*pendingRecord
+}
+
+// NewFileStore opens file store at the given path, restoring objects from the snapshot and the write-ahead log
+func NewFileStore(path string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), storeDirMode); err != nil {
+		return nil, errors.Wrapf(err, "failed to create directory for model store %s", path)
+	}
+
+	s := &FileStore{
+		path:    path,
+		objects: map[string]map[string][]byte{},
+	}
+	s.cond = sync.NewCond(&s.mtx)
+	if err := s.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := s.replayWal(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Put stores value of the object of the given kind with the given key
+func (s *FileStore) Put(kind, key string, value []byte) error {
+	return s.write(&walRecord{Op: opPut, Kind: kind, Key: key, Value: value})
+}
+
+// Delete removes the object of the given kind with the given key
+func (s *FileStore) Delete(kind, key string) error {
+	return s.write(&walRecord{Op: opDelete, Kind: kind, Key: key})
+}
+
+// Load returns values of all stored objects of the given kind by their keys
+func (s *FileStore) Load(kind string) (map[string][]byte, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	rv := map[string][]byte{}
+	for key, value := range s.objects[kind] {
+		rv[key] = value
+	}
+	return rv, nil
+}
+
+// Close waits for the pending changes to be synced and closes the write-ahead log
+func (s *FileStore) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for s.syncing || len(s.pending) > 0 {
+		s.cond.Wait()
+	}
+	if s.wal == nil {
+		return nil
+	}
+	err := s.wal.Close()
+	s.wal = nil
+	return err
+}
+
+func (s *FileStore) write(record *walRecord) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.wal == nil {
+		return errors.Errorf("model store %s is closed", s.path)
+	}
+
+	record.Checksum = record.checksum()
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if _, err := s.wal.Write(data); err != nil {
+		// Partially written record is dropped, so the following records are appended after the last good one
+		s.truncate(s.written)
+		return errors.Wrapf(err, "failed to write model store log %s", s.path+walSuffix)
+	}
+	s.written += int64(len(data))
+	pending := &pendingRecord{record: record, end: s.written}
+	s.pending = append(s.pending, pending)
+
+	for !pending.done {
+		if s.syncing {
+			s.cond.Wait()
+			continue
+		}
+		s.syncPending()
+	}
+	if pending.err != nil {
+		return pending.err
+	}
+
+	if s.walRecords >= compactThreshold && !s.syncing && len(s.pending) == 0 {
+		return s.compact()
+	}
+	return nil
+}
+
+// syncPending syncs all records written into the log with a single fsync and applies them in the log
+// order. Records written during the sync are synced by the next call. Lock is released during the sync.
+func (s *FileStore) syncPending() {
+	if s.wal == nil {
+		err := errors.Errorf("model store %s is closed", s.path)
+		for _, pending := range s.pending {
+			pending.done, pending.err = true, err
+		}
+		s.pending = nil
+		s.cond.Broadcast()
+		return
+	}
+	wal, batch := s.wal, s.pending
+	s.pending = nil
+	s.syncing = true
+	s.mtx.Unlock()
+
+	err := wal.Sync()
+
+	s.mtx.Lock()
+	s.syncing = false
+	defer s.cond.Broadcast()
+
+	if err != nil {
+		// Unsynced records are dropped, the ones written during the sync follow them in the log
+		err = errors.Wrapf(err, "failed to sync model store log %s", s.path+walSuffix)
+		batch = append(batch, s.pending...)
+		s.pending = nil
+		s.truncate(s.synced)
+		for _, pending := range batch {
+			pending.done, pending.err = true, err
+		}
+		return
+	}
+	for _, pending := range batch {
+		s.apply(pending.record)
+		s.walRecords++
+		pending.done = true
+	}
+	s.synced = batch[len(batch)-1].end
+}
+
+// truncate drops records after the offset from the end of the log. Store is closed if the log could
+// not be truncated, since the following records could not be appended after the last good one.
+func (s *FileStore) truncate(offset int64) {
+	if err := s.wal.Truncate(offset); err != nil {
+		logrus.Errorf("Failed to truncate model store log %s, closing the store: %v", s.path+walSuffix, err)
+		_ = s.wal.Close()
+		s.wal = nil
+		return
+	}
+	s.written = offset
+}
+
+func (s *FileStore) apply(record *walRecord) {
+	switch record.Op {
+	case opPut:
+		objects, ok := s.objects[record.Kind]
+		if !ok {
+			objects = map[string][]byte{}
+			s.objects[record.Kind] = objects
+		}
+		objects[record.Key] = record.Value
+	case opDelete:
+		delete(s.objects[record.Kind], record.Key)
+	}
+}
+
+func (s *FileStore) loadSnapshot() error {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to read model store snapshot %s", s.path)
+	}
+	if err := json.Unmarshal(data, &s.objects); err != nil {
+		return errors.Wrapf(err, "failed to decode model store snapshot %s", s.path)
+	}
+	return nil
+}
+
+// replayWal applies log records written after the last snapshot. Damaged records are skipped, the last
+// one could be only partially written record of the last change. Log is rewritten by the following compaction.
+func (s *FileStore) replayWal() error {
+	file, err := os.Open(s.path + walSuffix)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to open model store log %s", s.path+walSuffix)
+	}
+	defer func() { _ = file.Close() }()
+
+	reader := bufio.NewReader(file)
+	for count := 0; ; count++ {
+		line, err := reader.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return nil
+		}
+		record := &walRecord{}
+		if decodeErr := json.Unmarshal(line, record); decodeErr != nil || record.Checksum != record.checksum() {
+			if _, peekErr := reader.Peek(1); err != nil || peekErr != nil {
+				logrus.Warnf("Model store log %s ends with partially written record %d, dropping it", file.Name(), count)
+				return nil
+			}
+			logrus.Warnf("Model store log %s has damaged record %d, skipping it", file.Name(), count)
+			continue
+		}
+		s.apply(record)
+	}
+}
+
+// compact writes a new snapshot and starts a new write-ahead log
+func (s *FileStore) compact() error {
+	data, err := json.Marshal(s.objects)
+	if err != nil {
+		return err
+	}
+	if err := writeFileSync(s.path+snapshotSuffix, data); err != nil {
+		return errors.Wrapf(err, "failed to write model store snapshot %s", s.path)
+	}
+	if err := os.Rename(s.path+snapshotSuffix, s.path); err != nil {
+		return errors.Wrapf(err, "failed to replace model store snapshot %s", s.path)
+	}
+	if err := syncDir(filepath.Dir(s.path)); err != nil {
+		return err
+	}
+
+	if s.wal != nil {
+		_ = s.wal.Close()
+		s.wal = nil
+	}
+	wal, err := os.OpenFile(s.path+walSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, storeFileMode)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open model store log %s", s.path+walSuffix)
+	}
+	if err := wal.Sync(); err != nil {
+		_ = wal.Close()
+		return errors.Wrapf(err, "failed to sync model store log %s", s.path+walSuffix)
+	}
+	s.wal = wal
+	s.walRecords = 0
+	s.written, s.synced = 0, 0
+	return nil
+}
+
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, storeFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open model store directory %s", path)
+	}
+	defer func() { _ = dir.Close() }()
+	return dir.Sync()
+}
@@ -26,6 +26,12 @@ type Forwarder struct {
 	LocalMechanisms      []*connection.Mechanism
 	RemoteMechanisms     []*connection.Mechanism
 	MechanismsConfigured bool
+	// Capacity is the maximum number of cross connects the forwarder could serve, 0 means unlimited
+	Capacity uint32
+	// Labels are used to select the forwarder by affinity
+	Labels map[string]string
+	// restored is set for the forwarder restored from the model store until it is registered again
+	restored bool
 }
 
 // Clone returns pointer to copy of Forwarder
@@ -44,12 +50,23 @@ func (d *Forwarder) clone() cloneable {
 		rm = append(rm, m.Clone())
 	}
 
+	var labels map[string]string
+	if d.Labels != nil {
+		labels = make(map[string]string, len(d.Labels))
+		for k, v := range d.Labels {
+			labels[k] = v
+		}
+	}
+
 	return &Forwarder{
 		RegisteredName:       d.RegisteredName,
 		SocketLocation:       d.SocketLocation,
 		LocalMechanisms:      lm,
 		RemoteMechanisms:     rm,
 		MechanismsConfigured: d.MechanismsConfigured,
+		Capacity:             d.Capacity,
+		Labels:               labels,
+		restored:             d.restored,
 	}
 }
 
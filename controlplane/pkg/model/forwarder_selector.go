@@ -0,0 +1,226 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/selector"
+)
+
+// Forwarder selection policies
+const (
+	// LeastCrossConnectsPolicy - select forwarder serving the least number of cross connects
+	LeastCrossConnectsPolicy = "least-crossconnects"
+	// MechanismPreferencePolicy - select forwarder supporting the most preferred mechanism of the request
+	MechanismPreferencePolicy = "mechanism-preference"
+	// AffinityPolicy - select forwarder with labels matching the selector, e.g. `affinity:type=kernel&zone=In(a,b)`
+	AffinityPolicy = "affinity"
+)
+
+// ForwarderCandidate is a forwarder able to serve the connection
+type ForwarderCandidate struct {
+	Forwarder *Forwarder
+	// Mechanisms are the forwarder mechanisms for the requested side of connection: local or remote ones
+	Mechanisms []*connection.Mechanism
+	// CrossConnects is the number of cross connects served by the forwarder
+	CrossConnects int
+}
+
+// ForwarderSelector selects forwarder for the connection among the candidates supporting requested mechanisms
+// and having capacity left. Candidates are ordered by forwarder name, nil is returned if none of them is suitable.
+type ForwarderSelector interface {
+	SelectForwarder(request *networkservice.NetworkServiceRequest, candidates []*ForwarderCandidate) *Forwarder
+}
+
+type leastCrossConnectsSelector struct{}
+
+// NewLeastCrossConnectsSelector creates selector choosing forwarder serving the least number of cross connects
+func NewLeastCrossConnectsSelector() ForwarderSelector {
+	return &leastCrossConnectsSelector{}
+}
+
+func (s *leastCrossConnectsSelector) SelectForwarder(_ *networkservice.NetworkServiceRequest, candidates []*ForwarderCandidate) *Forwarder {
+	return leastCrossConnects(candidates)
+}
+
+func leastCrossConnects(candidates []*ForwarderCandidate) *Forwarder {
+	var rv *ForwarderCandidate
+	for _, candidate := range candidates {
+		if rv == nil || candidate.CrossConnects < rv.CrossConnects {
+			rv = candidate
+		}
+	}
+	if rv == nil {
+		return nil
+	}
+	return rv.Forwarder
+}
+
+type mechanismPreferenceSelector struct{}
+
+// NewMechanismPreferenceSelector creates selector choosing forwarder supporting the most preferred mechanism of
+// the request, forwarder serving the least number of cross connects is chosen among the equal ones
+func NewMechanismPreferenceSelector() ForwarderSelector {
+	return &mechanismPreferenceSelector{}
+}
+
+func (s *mechanismPreferenceSelector) SelectForwarder(request *networkservice.NetworkServiceRequest, candidates []*ForwarderCandidate) *Forwarder {
+	for _, m := range request.GetRequestMechanismPreferences() {
+		var supporting []*ForwarderCandidate
+		for _, candidate := range candidates {
+			if supportsMechanism(candidate.Mechanisms, m.GetType()) {
+				supporting = append(supporting, candidate)
+			}
+		}
+		if len(supporting) > 0 {
+			return leastCrossConnects(supporting)
+		}
+	}
+	return nil
+}
+
+type affinitySelector struct {
+	labels *selector.LabelSelector
+}
+
+// NewAffinitySelector creates selector choosing forwarder with labels matching the selector, forwarder serving
+// the least number of cross connects is chosen among the matching ones
+func NewAffinitySelector(labels map[string]string) (ForwarderSelector, error) {
+	if err := selector.ValidateSelector(labels); err != nil {
+		return nil, err
+	}
+	parsed, err := selector.ParseLabelSelector(labels)
+	if err != nil {
+		return nil, err
+	}
+	return &affinitySelector{
+		labels: parsed,
+	}, nil
+}
+
+func (s *affinitySelector) SelectForwarder(_ *networkservice.NetworkServiceRequest, candidates []*ForwarderCandidate) *Forwarder {
+	var matching []*ForwarderCandidate
+	for _, candidate := range candidates {
+		if s.labels.Matches(candidate.Forwarder.Labels) {
+			matching = append(matching, candidate)
+		}
+	}
+	return leastCrossConnects(matching)
+}
+
+type networkServiceForwarderSelector struct {
+	defaultSelector ForwarderSelector
+	selectors       map[string]ForwarderSelector
+}
+
+// NewNetworkServiceForwarderSelector creates selector applying policy configured for the NetworkService of
+// the request, default selector is used for the other NetworkServices
+func NewNetworkServiceForwarderSelector(defaultSelector ForwarderSelector, selectors map[string]ForwarderSelector) ForwarderSelector {
+	return &networkServiceForwarderSelector{
+		defaultSelector: defaultSelector,
+		selectors:       selectors,
+	}
+}
+
+func (s *networkServiceForwarderSelector) SelectForwarder(request *networkservice.NetworkServiceRequest, candidates []*ForwarderCandidate) *Forwarder {
+	if nsSelector, ok := s.selectors[request.GetConnection().GetNetworkService()]; ok {
+		return nsSelector.SelectForwarder(request, candidates)
+	}
+	return s.defaultSelector.SelectForwarder(request, candidates)
+}
+
+// ParseForwarderSelector parses forwarder selection policies configuration. Configuration is a list of policies
+// separated by ';', each of the form `[<network-service>:]<policy>[:<arguments>]`. Policy without NetworkService
+// is the default one, least-crossconnects is used if it is not set:
+//
+//	least-crossconnects;secure-intranet:affinity:type=kernel;vpn:mechanism-preference
+func ParseForwarderSelector(config string) (ForwarderSelector, error) {
+	defaultSelector := NewLeastCrossConnectsSelector()
+	selectors := map[string]ForwarderSelector{}
+	for _, entry := range strings.Split(config, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		networkService := ""
+		parts := strings.SplitN(entry, ":", 2)
+		if !isForwarderPolicy(parts[0]) {
+			if len(parts) != 2 {
+				return nil, errors.Errorf("unknown forwarder selection policy %s", entry)
+			}
+			networkService, entry = parts[0], parts[1]
+		}
+
+		policySelector, err := parseForwarderPolicy(entry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid forwarder selection policy %s", entry)
+		}
+		if networkService == "" {
+			defaultSelector = policySelector
+		} else {
+			selectors[networkService] = policySelector
+		}
+	}
+	if len(selectors) == 0 {
+		return defaultSelector, nil
+	}
+	return NewNetworkServiceForwarderSelector(defaultSelector, selectors), nil
+}
+
+func isForwarderPolicy(name string) bool {
+	switch name {
+	case LeastCrossConnectsPolicy, MechanismPreferencePolicy, AffinityPolicy:
+		return true
+	}
+	return false
+}
+
+func parseForwarderPolicy(policy string) (ForwarderSelector, error) {
+	parts := strings.SplitN(policy, ":", 2)
+	switch parts[0] {
+	case LeastCrossConnectsPolicy:
+		return NewLeastCrossConnectsSelector(), nil
+	case MechanismPreferencePolicy:
+		return NewMechanismPreferenceSelector(), nil
+	case AffinityPolicy:
+		if len(parts) != 2 {
+			return nil, errors.New("affinity policy requires label selector")
+		}
+		labels := map[string]string{}
+		for _, requirement := range strings.Split(parts[1], "&") {
+			kv := strings.SplitN(requirement, "=", 2)
+			if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+				return nil, errors.Errorf("invalid label requirement %s", requirement)
+			}
+			labels[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+		return NewAffinitySelector(labels)
+	}
+	return nil, errors.Errorf("unknown policy %s", parts[0])
+}
+
+func supportsMechanism(mechanisms []*connection.Mechanism, mechanismType string) bool {
+	for _, m := range mechanisms {
+		if m.GetType() == mechanismType {
+			return true
+		}
+	}
+	return false
+}
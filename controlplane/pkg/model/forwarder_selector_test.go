@@ -0,0 +1,152 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/memif"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+)
+
+func newSelectorTestModel(selector ForwarderSelector) Model {
+	ctx := context.Background()
+	m := NewModel()
+	m.SetForwarderSelector(selector)
+	m.AddForwarder(ctx, &Forwarder{
+		RegisteredName:  "kernel-forwarder",
+		LocalMechanisms: []*connection.Mechanism{{Type: kernel.MECHANISM}},
+		Labels:          map[string]string{"type": "kernel"},
+	})
+	m.AddForwarder(ctx, &Forwarder{
+		RegisteredName:  "vppagent",
+		LocalMechanisms: []*connection.Mechanism{{Type: kernel.MECHANISM}, {Type: memif.MECHANISM}},
+		Labels:          map[string]string{"type": "vpp"},
+		Capacity:        2,
+	})
+	return m
+}
+
+func newSelectorTestRequest(id, networkService string, mechanisms ...string) *networkservice.NetworkServiceRequest {
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &connection.Connection{
+			Id:             id,
+			NetworkService: networkService,
+		},
+	}
+	for _, m := range mechanisms {
+		request.MechanismPreferences = append(request.MechanismPreferences, &connection.Mechanism{Type: m})
+	}
+	return request
+}
+
+func addSelectorTestConnection(m Model, id, forwarder string) {
+	m.AddClientConnection(context.Background(), &ClientConnection{
+		ConnectionID:            id,
+		ForwarderRegisteredName: forwarder,
+	})
+}
+
+func localMechanisms(dp *Forwarder) []*connection.Mechanism {
+	return dp.LocalMechanisms
+}
+
+func TestSelectForwarderLeastCrossConnects(t *testing.T) {
+	g := NewWithT(t)
+
+	m := newSelectorTestModel(NewLeastCrossConnectsSelector())
+	addSelectorTestConnection(m, "1", "kernel-forwarder")
+
+	dp, err := m.SelectRequestForwarder(newSelectorTestRequest("2", "ns", kernel.MECHANISM), localMechanisms)
+	g.Expect(err).To(BeNil())
+	g.Expect(dp.RegisteredName).To(Equal("vppagent"))
+
+	// Only vppagent supports memif
+	dp, err = m.SelectRequestForwarder(newSelectorTestRequest("2", "ns", memif.MECHANISM), localMechanisms)
+	g.Expect(err).To(BeNil())
+	g.Expect(dp.RegisteredName).To(Equal("vppagent"))
+
+	_, err = m.SelectRequestForwarder(newSelectorTestRequest("2", "ns", "UNKNOWN"), localMechanisms)
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestSelectForwarderCapacity(t *testing.T) {
+	g := NewWithT(t)
+
+	m := newSelectorTestModel(NewLeastCrossConnectsSelector())
+	addSelectorTestConnection(m, "1", "vppagent")
+	addSelectorTestConnection(m, "2", "vppagent")
+
+	_, err := m.SelectRequestForwarder(newSelectorTestRequest("3", "ns", memif.MECHANISM), localMechanisms)
+	g.Expect(err).NotTo(BeNil())
+
+	// Connection being healed is not counted against the capacity
+	dp, err := m.SelectRequestForwarder(newSelectorTestRequest("2", "ns", memif.MECHANISM), localMechanisms)
+	g.Expect(err).To(BeNil())
+	g.Expect(dp.RegisteredName).To(Equal("vppagent"))
+
+	// Closing connections are not counted too
+	m.ApplyClientConnectionChanges(context.Background(), "1", func(cc *ClientConnection) {
+		cc.ConnectionState = ClientConnectionClosing
+	})
+	dp, err = m.SelectRequestForwarder(newSelectorTestRequest("3", "ns", memif.MECHANISM), localMechanisms)
+	g.Expect(err).To(BeNil())
+	g.Expect(dp.RegisteredName).To(Equal("vppagent"))
+}
+
+func TestSelectForwarderPolicies(t *testing.T) {
+	g := NewWithT(t)
+
+	selector, err := ParseForwarderSelector("mechanism-preference; secure:affinity:type=kernel; fast:affinity:type=In(vpp,dpdk)")
+	g.Expect(err).To(BeNil())
+
+	m := newSelectorTestModel(selector)
+	addSelectorTestConnection(m, "1", "vppagent")
+
+	// Most preferred mechanism wins over the number of cross connects
+	dp, err := m.SelectRequestForwarder(newSelectorTestRequest("2", "ns", memif.MECHANISM, kernel.MECHANISM), localMechanisms)
+	g.Expect(err).To(BeNil())
+	g.Expect(dp.RegisteredName).To(Equal("vppagent"))
+
+	dp, err = m.SelectRequestForwarder(newSelectorTestRequest("2", "fast", kernel.MECHANISM), localMechanisms)
+	g.Expect(err).To(BeNil())
+	g.Expect(dp.RegisteredName).To(Equal("vppagent"))
+
+	dp, err = m.SelectRequestForwarder(newSelectorTestRequest("2", "secure", kernel.MECHANISM, memif.MECHANISM), localMechanisms)
+	g.Expect(err).To(BeNil())
+	g.Expect(dp.RegisteredName).To(Equal("kernel-forwarder"))
+
+	// Affinity is strict
+	_, err = m.SelectRequestForwarder(newSelectorTestRequest("2", "secure", memif.MECHANISM), localMechanisms)
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestParseForwarderSelector(t *testing.T) {
+	g := NewWithT(t)
+
+	for _, config := range []string{"", "least-crossconnects", "mechanism-preference", "ns:affinity:a=b&c=Exists()"} {
+		_, err := ParseForwarderSelector(config)
+		g.Expect(err).To(BeNil(), config)
+	}
+	for _, config := range []string{"random", "ns:random", "affinity", "ns:affinity:a", "affinity:a=Regex(()"} {
+		_, err := ParseForwarderSelector(config)
+		g.Expect(err).NotTo(BeNil(), config)
+	}
+}
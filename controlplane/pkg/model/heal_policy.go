@@ -0,0 +1,228 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+)
+
+// HealMode describes how the connection is healed once its destination, forwarder or remote NSM is lost
+type HealMode int8
+
+const (
+	// HealModeAnyEndpoint means connection is healed with the same endpoint if it is still available,
+	// otherwise with any other endpoint of the NetworkService
+	HealModeAnyEndpoint HealMode = 0
+
+	// HealModeSameEndpoint means connection is healed with the same endpoint only, it is closed if
+	// the endpoint does not come back
+	HealModeSameEndpoint HealMode = 1
+
+	// HealModeNone means connection is not healed and is closed instead
+	HealModeNone HealMode = 2
+)
+
+// Heal policy names and connection labels overriding the policy of the NetworkService
+const (
+	AnyEndpointHealPolicy  = "any-endpoint"
+	SameEndpointHealPolicy = "same-endpoint"
+	NoHealPolicy           = "no-heal"
+
+	// HealPolicyLabel - connection label selecting heal mode, e.g. `heal-policy=same-endpoint`
+	HealPolicyLabel = "heal-policy"
+	// HealRetryCountLabel - connection label setting number of heal request attempts
+	HealRetryCountLabel = "heal-retry-count"
+	// HealRetryDelayLabel - connection label setting delay between heal request attempts, e.g. `heal-retry-delay=5s`
+	HealRetryDelayLabel = "heal-retry-delay"
+
+	healRetryCountArg = "retry-count"
+	healRetryDelayArg = "retry-delay"
+)
+
+func (m HealMode) String() string {
+	switch m {
+	case HealModeAnyEndpoint:
+		return AnyEndpointHealPolicy
+	case HealModeSameEndpoint:
+		return SameEndpointHealPolicy
+	case HealModeNone:
+		return NoHealPolicy
+	}
+	return "unknown"
+}
+
+// ParseHealMode returns heal mode by its policy name
+func ParseHealMode(name string) (HealMode, error) {
+	switch name {
+	case AnyEndpointHealPolicy:
+		return HealModeAnyEndpoint, nil
+	case SameEndpointHealPolicy:
+		return HealModeSameEndpoint, nil
+	case NoHealPolicy:
+		return HealModeNone, nil
+	}
+	return HealModeAnyEndpoint, errors.Errorf("unknown heal policy %s", name)
+}
+
+// HealPolicy describes how the connection is healed. Zero retry count and delay mean NSMD defaults are used.
+type HealPolicy struct {
+	Mode       HealMode      `json:"mode,omitempty"`
+	RetryCount int           `json:"retry_count,omitempty"`
+	RetryDelay time.Duration `json:"retry_delay,omitempty"`
+}
+
+// HealPolicies holds heal policies configured for the NetworkServices
+type HealPolicies struct {
+	defaultPolicy HealPolicy
+	policies      map[string]HealPolicy
+}
+
+// NewHealPolicies creates heal policies applying policy configured for the NetworkService of the request,
+// default policy is used for the other NetworkServices
+func NewHealPolicies(defaultPolicy HealPolicy, policies map[string]HealPolicy) *HealPolicies {
+	return &HealPolicies{
+		defaultPolicy: defaultPolicy,
+		policies:      policies,
+	}
+}
+
+// RequestPolicy returns heal policy for the request. Policy of the NetworkService is overridden by the
+// connection labels, policy of the NetworkService is returned with error if labels are not valid.
+func (p *HealPolicies) RequestPolicy(request *networkservice.NetworkServiceRequest) (HealPolicy, error) {
+	var nsPolicy HealPolicy
+	if p != nil {
+		nsPolicy = p.defaultPolicy
+		if policy, ok := p.policies[request.GetConnection().GetNetworkService()]; ok {
+			nsPolicy = policy
+		}
+	}
+
+	policy := nsPolicy
+	labels := request.GetConnection().GetLabels()
+	if name, ok := labels[HealPolicyLabel]; ok {
+		mode, err := ParseHealMode(name)
+		if err != nil {
+			return nsPolicy, errors.Wrapf(err, "invalid %s label", HealPolicyLabel)
+		}
+		policy.Mode = mode
+	}
+	if value, ok := labels[HealRetryCountLabel]; ok {
+		if err := policy.setRetryCount(value); err != nil {
+			return nsPolicy, errors.Wrapf(err, "invalid %s label", HealRetryCountLabel)
+		}
+	}
+	if value, ok := labels[HealRetryDelayLabel]; ok {
+		if err := policy.setRetryDelay(value); err != nil {
+			return nsPolicy, errors.Wrapf(err, "invalid %s label", HealRetryDelayLabel)
+		}
+	}
+	return policy, nil
+}
+
+func (p *HealPolicy) setRetryCount(value string) error {
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	if count <= 0 {
+		return errors.Errorf("retry count should be positive: %d", count)
+	}
+	p.RetryCount = count
+	return nil
+}
+
+func (p *HealPolicy) setRetryDelay(value string) error {
+	delay, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	if delay <= 0 {
+		return errors.Errorf("retry delay should be positive: %v", delay)
+	}
+	p.RetryDelay = delay
+	return nil
+}
+
+// ParseHealPolicies parses heal policies configuration. Configuration is a list of policies separated by ';',
+// each of the form `[<network-service>:]<policy>[:retry-count=<count>&retry-delay=<duration>]`. Policy without
+// NetworkService is the default one, any-endpoint is used if it is not set:
+//
+//	any-endpoint;firewall:same-endpoint:retry-count=10&retry-delay=2s;sniffer:no-heal
+func ParseHealPolicies(config string) (*HealPolicies, error) {
+	var defaultPolicy HealPolicy
+	policies := map[string]HealPolicy{}
+	for _, entry := range strings.Split(config, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		networkService := ""
+		parts := strings.SplitN(entry, ":", 2)
+		if _, err := ParseHealMode(parts[0]); err != nil {
+			if len(parts) != 2 {
+				return nil, err
+			}
+			networkService, entry = parts[0], parts[1]
+		}
+
+		policy, err := parseHealPolicy(entry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid heal policy %s", entry)
+		}
+		if networkService == "" {
+			defaultPolicy = policy
+		} else {
+			policies[networkService] = policy
+		}
+	}
+	return NewHealPolicies(defaultPolicy, policies), nil
+}
+
+func parseHealPolicy(entry string) (HealPolicy, error) {
+	var policy HealPolicy
+	parts := strings.SplitN(entry, ":", 2)
+	mode, err := ParseHealMode(parts[0])
+	if err != nil {
+		return policy, err
+	}
+	policy.Mode = mode
+	if len(parts) == 1 {
+		return policy, nil
+	}
+	for _, arg := range strings.Split(parts[1], "&") {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			return policy, errors.Errorf("invalid argument %s", arg)
+		}
+		switch strings.TrimSpace(kv[0]) {
+		case healRetryCountArg:
+			err = policy.setRetryCount(strings.TrimSpace(kv[1]))
+		case healRetryDelayArg:
+			err = policy.setRetryDelay(strings.TrimSpace(kv[1]))
+		default:
+			err = errors.Errorf("unknown argument %s", kv[0])
+		}
+		if err != nil {
+			return policy, err
+		}
+	}
+	return policy, nil
+}
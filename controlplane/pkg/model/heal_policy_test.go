@@ -0,0 +1,93 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+)
+
+func newHealPolicyTestRequest(networkService string, labels map[string]string) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &connection.Connection{
+			NetworkService: networkService,
+			Labels:         labels,
+		},
+	}
+}
+
+func TestHealPolicyNetworkService(t *testing.T) {
+	g := NewWithT(t)
+
+	policies, err := ParseHealPolicies("no-heal; firewall:same-endpoint:retry-count=3&retry-delay=2s; vpn:any-endpoint")
+	g.Expect(err).To(BeNil())
+
+	policy, err := policies.RequestPolicy(newHealPolicyTestRequest("ns", nil))
+	g.Expect(err).To(BeNil())
+	g.Expect(policy).To(Equal(HealPolicy{Mode: HealModeNone}))
+
+	policy, err = policies.RequestPolicy(newHealPolicyTestRequest("firewall", nil))
+	g.Expect(err).To(BeNil())
+	g.Expect(policy).To(Equal(HealPolicy{Mode: HealModeSameEndpoint, RetryCount: 3, RetryDelay: 2 * time.Second}))
+
+	policy, err = policies.RequestPolicy(newHealPolicyTestRequest("vpn", nil))
+	g.Expect(err).To(BeNil())
+	g.Expect(policy).To(Equal(HealPolicy{Mode: HealModeAnyEndpoint}))
+}
+
+func TestHealPolicyLabels(t *testing.T) {
+	g := NewWithT(t)
+
+	policies, err := ParseHealPolicies("firewall:same-endpoint:retry-count=3")
+	g.Expect(err).To(BeNil())
+
+	policy, err := policies.RequestPolicy(newHealPolicyTestRequest("firewall", map[string]string{
+		HealPolicyLabel:     NoHealPolicy,
+		HealRetryDelayLabel: "100ms",
+	}))
+	g.Expect(err).To(BeNil())
+	g.Expect(policy).To(Equal(HealPolicy{Mode: HealModeNone, RetryCount: 3, RetryDelay: 100 * time.Millisecond}))
+
+	// Policy of the NetworkService is returned along with error for invalid labels
+	policy, err = policies.RequestPolicy(newHealPolicyTestRequest("firewall", map[string]string{
+		HealPolicyLabel:     NoHealPolicy,
+		HealRetryCountLabel: "-1",
+	}))
+	g.Expect(err).NotTo(BeNil())
+	g.Expect(policy).To(Equal(HealPolicy{Mode: HealModeSameEndpoint, RetryCount: 3}))
+
+	// Default model policies heal with any endpoint
+	policy, err = NewModel().GetHealPolicies().RequestPolicy(newHealPolicyTestRequest("ns", nil))
+	g.Expect(err).To(BeNil())
+	g.Expect(policy).To(Equal(HealPolicy{}))
+}
+
+func TestParseHealPolicies(t *testing.T) {
+	g := NewWithT(t)
+
+	for _, config := range []string{"", "any-endpoint", "same-endpoint:retry-delay=1m", "ns:no-heal;ns2:any-endpoint:retry-count=1"} {
+		_, err := ParseHealPolicies(config)
+		g.Expect(err).To(BeNil(), config)
+	}
+	for _, config := range []string{"never", "ns:never", "ns:no-heal:retries=1", "same-endpoint:retry-count=0", "same-endpoint:retry-delay=1"} {
+		_, err := ParseHealPolicies(config)
+		g.Expect(err).NotTo(BeNil(), config)
+	}
+}
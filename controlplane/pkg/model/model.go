@@ -2,11 +2,15 @@ package model
 
 import (
 	"context"
+	"sort"
 	"strconv"
 	"sync"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/registry"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/selector"
 )
@@ -24,6 +28,12 @@ type Model interface {
 	UpdateForwarder(ctx context.Context, forwarder *Forwarder)
 	DeleteForwarder(ctx context.Context, name string)
 	SelectForwarder(forwarderSelector func(dp *Forwarder) bool) (*Forwarder, error)
+	SelectRequestForwarder(request *networkservice.NetworkServiceRequest, mechanisms func(dp *Forwarder) []*connection.Mechanism) (*Forwarder, error)
+	GetForwarderSelector() ForwarderSelector
+	SetForwarderSelector(selector ForwarderSelector)
+
+	GetHealPolicies() *HealPolicies
+	SetHealPolicies(policies *HealPolicies)
 
 	AddClientConnection(ctx context.Context, clientConnection *ClientConnection)
 	GetClientConnection(connectionID string) *ClientConnection
@@ -35,6 +45,8 @@ type Model interface {
 	ConnectionID() string
 	CorrectIDGenerator(id string)
 
+	Restore(store Store) error
+
 	AddListener(listener Listener)
 	RemoveListener(listener Listener)
 	ListenerCount() int
@@ -43,6 +55,9 @@ type Model interface {
 	GetNsm() *registry.NetworkServiceManager
 
 	GetSelector() selector.Selector
+	SetSelector(selector selector.Selector)
+
+	selector.ConnectionCounter
 }
 
 type model struct {
@@ -53,6 +68,8 @@ type model struct {
 	lastConnectionID uint64
 	mtx              sync.RWMutex
 	selector         selector.Selector
+	forwarders       ForwarderSelector
+	healPolicies     *HealPolicies
 	nsm              *registry.NetworkServiceManager
 	listeners        map[Listener]func()
 }
@@ -72,7 +89,15 @@ func (m *model) AddListener(listener Listener) {
 
 	dpListenerDelete := m.SetForwarderModificationHandler(&ModificationHandler{
 		AddFunc: func(ctx context.Context, new interface{}) {
-			listener.ForwarderAdded(ctx, new.(*Forwarder))
+			// Restored forwarder is announced when it is registered again
+			if dp := new.(*Forwarder); !dp.restored {
+				listener.ForwarderAdded(ctx, dp)
+			}
+		},
+		UpdateFunc: func(ctx context.Context, old interface{}, new interface{}) {
+			if old.(*Forwarder).restored && !new.(*Forwarder).restored {
+				listener.ForwarderAdded(ctx, new.(*Forwarder))
+			}
 		},
 		DeleteFunc: func(ctx context.Context, del interface{}) {
 			listener.ForwarderDeleted(ctx, del.(*Forwarder))
@@ -124,6 +149,8 @@ func NewModel() Model {
 		endpointDomain:         newEndpointDomain(),
 		forwarderDomain:        newForwarderDomain(),
 		selector:               selector.NewMatchSelector(),
+		forwarders:             NewLeastCrossConnectsSelector(),
+		healPolicies:           NewHealPolicies(HealPolicy{}, nil),
 		listeners:              make(map[Listener]func()),
 	}
 }
@@ -149,6 +176,39 @@ func (m *model) CorrectIDGenerator(id string) {
 	}
 }
 
+// Restore replaces model state with the one persisted in the store and journals all further changes
+// of connections, endpoints and forwarders into it. Should be called before any listener is added.
+func (m *model) Restore(store Store) error {
+	connections, err := loadKind(store, ClientConnectionKind, func(data []byte) (cloneable, error) {
+		return decodeClientConnection(data)
+	})
+	if err != nil {
+		return err
+	}
+	endpoints, err := loadKind(store, EndpointKind, func(data []byte) (cloneable, error) {
+		return decodeEndpoint(data)
+	})
+	if err != nil {
+		return err
+	}
+	forwarders, err := loadKind(store, ForwarderKind, func(data []byte) (cloneable, error) {
+		return decodeForwarder(data)
+	})
+	if err != nil {
+		return err
+	}
+
+	m.clientConnectionDomain.restore(connections, &journal{store: store, kind: ClientConnectionKind, encode: encodeClientConnection})
+	m.endpointDomain.restore(endpoints, &journal{store: store, kind: EndpointKind, encode: encodeEndpoint})
+	m.forwarderDomain.restore(forwarders, &journal{store: store, kind: ForwarderKind, encode: encodeForwarder})
+
+	for id := range connections {
+		m.CorrectIDGenerator(id)
+	}
+	logrus.Infof("Model restored: %d connections, %d endpoints, %d forwarders", len(connections), len(endpoints), len(forwarders))
+	return nil
+}
+
 func (m *model) GetNsm() *registry.NetworkServiceManager {
 	m.mtx.RLock()
 	defer m.mtx.RUnlock()
@@ -164,5 +224,121 @@ func (m *model) SetNsm(nsm *registry.NetworkServiceManager) {
 }
 
 func (m *model) GetSelector() selector.Selector {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
 	return m.selector
 }
+
+func (m *model) SetSelector(selector selector.Selector) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.selector = selector
+}
+
+func (m *model) GetForwarderSelector() ForwarderSelector {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	return m.forwarders
+}
+
+func (m *model) SetForwarderSelector(selector ForwarderSelector) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.forwarders = selector
+}
+
+func (m *model) GetHealPolicies() *HealPolicies {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	return m.healPolicies
+}
+
+func (m *model) SetHealPolicies(policies *HealPolicies) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.healPolicies = policies
+}
+
+// SelectRequestForwarder selects forwarder for the request with the forwarder selector. Only forwarders supporting
+// one of the requested mechanisms and having capacity left are considered, the connection being requested is not
+// counted against the capacity, so it could be healed with the same forwarder.
+func (m *model) SelectRequestForwarder(request *networkservice.NetworkServiceRequest, mechanisms func(dp *Forwarder) []*connection.Mechanism) (*Forwarder, error) {
+	crossConnects := map[string]int{}
+	for _, cc := range m.GetAllClientConnections() {
+		if cc.ConnectionState == ClientConnectionClosing || cc.GetID() == request.GetConnection().GetId() {
+			continue
+		}
+		crossConnects[cc.ForwarderRegisteredName]++
+	}
+
+	var candidates []*ForwarderCandidate
+	saturated := false
+	m.forwarderDomain.kvRange(func(_ string, value interface{}) bool {
+		dp := value.(*Forwarder)
+		dpMechanisms := mechanisms(dp)
+		supported := false
+		for _, mechanism := range request.GetRequestMechanismPreferences() {
+			if supportsMechanism(dpMechanisms, mechanism.GetType()) {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return true
+		}
+		if dp.Capacity > 0 && crossConnects[dp.RegisteredName] >= int(dp.Capacity) {
+			saturated = true
+			return true
+		}
+		candidates = append(candidates, &ForwarderCandidate{
+			Forwarder:     dp,
+			Mechanisms:    dpMechanisms,
+			CrossConnects: crossConnects[dp.RegisteredName],
+		})
+		return true
+	})
+
+	if len(candidates) == 0 {
+		if saturated {
+			return nil, errors.New("all appropriate forwarders are saturated")
+		}
+		return nil, errors.New("no appropriate forwarders found")
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Forwarder.RegisteredName < candidates[j].Forwarder.RegisteredName
+	})
+
+	dp := m.GetForwarderSelector().SelectForwarder(request, candidates)
+	if dp == nil {
+		return nil, errors.New("no forwarders match selection policy")
+	}
+	return dp, nil
+}
+
+// ConnectionCount returns the number of client connections served by the endpoint. Connections to the endpoints
+// of other NSMs are known only partially, so the count advertised by their NSM is used if it is bigger.
+func (m *model) ConnectionCount(nse *registry.NetworkServiceEndpoint) int {
+	count := 0
+	for _, cc := range m.GetAllClientConnections() {
+		if cc.ConnectionState == ClientConnectionClosing {
+			continue
+		}
+		if cc.Endpoint.GetNetworkServiceEndpoint().GetName() == nse.GetName() {
+			count++
+		}
+	}
+
+	if nse.GetNetworkServiceManagerName() == m.GetNsm().GetName() {
+		return count
+	}
+	if advertised := selector.AdvertisedConnectionCount(nse); advertised > count {
+		return advertised
+	}
+	return count
+}
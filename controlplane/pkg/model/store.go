@@ -0,0 +1,328 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/registry"
+)
+
+// Kinds of the model objects kept in the Store
+const (
+	ClientConnectionKind = "client-connection"
+	EndpointKind         = "endpoint"
+	ForwarderKind        = "forwarder"
+)
+
+// Store persists model objects, so model state could be restored after NSMD restart.
+// Implementations should make every change durable before returning.
+type Store interface {
+	// Put stores value of the object of the given kind with the given key
+	Put(kind, key string, value []byte) error
+	// Delete removes the object of the given kind with the given key
+	Delete(kind, key string) error
+	// Load returns values of all stored objects of the given kind by their keys
+	Load(kind string) (map[string][]byte, error)
+	// Close releases resources held by the store
+	Close() error
+}
+
+// journal writes changes of the domain objects into the store. Changes are prepared under the domain
+// lock to keep their order and written after the lock is released, so store writes do not block the domain.
+type journal struct {
+	store  Store
+	kind   string
+	encode func(cloneable) ([]byte, error)
+
+	// writeMtx serializes store writes, mtx guards prepared changes and is never held during a write
+	writeMtx sync.Mutex
+	mtx      sync.Mutex
+	seq      uint64
+	// pending - sequence number of the latest prepared change by object key, older changes are not written
+	pending map[string]uint64
+}
+
+// journalChange is a change of the object prepared to be written into the store
+type journalChange struct {
+	journal *journal
+	key     string
+	value   []byte
+	deleted bool
+	seq     uint64
+}
+
+func (j *journal) put(key string, value cloneable) *journalChange {
+	if j == nil {
+		return nil
+	}
+	data, err := j.encode(value)
+	if err != nil {
+		logrus.Errorf("Failed to encode %s %s: %v", j.kind, key, err)
+		return nil
+	}
+	return j.prepare(&journalChange{key: key, value: data})
+}
+
+func (j *journal) delete(key string) *journalChange {
+	if j == nil {
+		return nil
+	}
+	return j.prepare(&journalChange{key: key, deleted: true})
+}
+
+func (j *journal) prepare(change *journalChange) *journalChange {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	if j.pending == nil {
+		j.pending = map[string]uint64{}
+	}
+	j.seq++
+	change.journal = j
+	change.seq = j.seq
+	j.pending[change.key] = change.seq
+	return change
+}
+
+// take returns true if the change is the latest prepared change of the object
+func (j *journal) take(c *journalChange) bool {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	if j.pending[c.key] != c.seq {
+		return false
+	}
+	delete(j.pending, c.key)
+	return true
+}
+
+// commit writes the change into the store unless a newer change of the same object is prepared
+func (c *journalChange) commit() {
+	if c == nil {
+		return
+	}
+	j := c.journal
+	j.writeMtx.Lock()
+	defer j.writeMtx.Unlock()
+
+	if !j.take(c) {
+		return
+	}
+
+	if c.deleted {
+		if err := j.store.Delete(j.kind, c.key); err != nil {
+			logrus.Errorf("Failed to delete persisted %s %s: %v", j.kind, c.key, err)
+		}
+		return
+	}
+	if err := j.store.Put(j.kind, c.key, c.value); err != nil {
+		logrus.Errorf("Failed to persist %s %s: %v", j.kind, c.key, err)
+	}
+}
+
+// clientConnectionRecord is a persisted form of ClientConnection, span and monitor are not persisted
+type clientConnectionRecord struct {
+	ConnectionID            string                `json:"id"`
+	Request                 []byte                `json:"request"`
+	Xcon                    []byte                `json:"xcon"`
+	RemoteNsm               []byte                `json:"remote_nsm"`
+	Endpoint                []byte                `json:"endpoint"`
+	ForwarderRegisteredName string                `json:"forwarder,omitempty"`
+	ConnectionState         ClientConnectionState `json:"connection_state"`
+	ForwarderState          ForwarderState        `json:"forwarder_state"`
+	HealPolicy              HealPolicy            `json:"heal_policy"`
+}
+
+type endpointRecord struct {
+	Endpoint       []byte `json:"endpoint"`
+	SocketLocation string `json:"socket,omitempty"`
+	Workspace      string `json:"workspace,omitempty"`
+}
+
+// forwarderRecord is a persisted binding of the forwarder, mechanisms are reported by the forwarder itself
+// once it is registered again
+type forwarderRecord struct {
+	RegisteredName string `json:"name"`
+	SocketLocation string `json:"socket,omitempty"`
+}
+
+// marshalProto encodes message, empty message is kept distinct from the nil one
+func marshalProto(msg proto.Message) ([]byte, error) {
+	data, err := proto.Marshal(msg)
+	if err == nil && data == nil {
+		data = []byte{}
+	}
+	return data, err
+}
+
+// unmarshalProto decodes message if it was persisted, nil messages are persisted as JSON null
+func unmarshalProto(data []byte, msg proto.Message) (bool, error) {
+	if data == nil {
+		return false, nil
+	}
+	return true, proto.Unmarshal(data, msg)
+}
+
+func encodeClientConnection(value cloneable) ([]byte, error) {
+	cc := value.(*ClientConnection)
+	record := &clientConnectionRecord{
+		ConnectionID:            cc.ConnectionID,
+		ForwarderRegisteredName: cc.ForwarderRegisteredName,
+		ConnectionState:         cc.ConnectionState,
+		ForwarderState:          cc.ForwarderState,
+		HealPolicy:              cc.HealPolicy,
+	}
+	var err error
+	if cc.Request != nil {
+		if record.Request, err = marshalProto(cc.Request); err != nil {
+			return nil, err
+		}
+	}
+	if cc.Xcon != nil {
+		if record.Xcon, err = marshalProto(cc.Xcon); err != nil {
+			return nil, err
+		}
+	}
+	if cc.RemoteNsm != nil {
+		if record.RemoteNsm, err = marshalProto(cc.RemoteNsm); err != nil {
+			return nil, err
+		}
+	}
+	if cc.Endpoint != nil {
+		if record.Endpoint, err = marshalProto(cc.Endpoint); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(record)
+}
+
+func decodeClientConnection(data []byte) (*ClientConnection, error) {
+	record := &clientConnectionRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, err
+	}
+	cc := &ClientConnection{
+		ConnectionID:            record.ConnectionID,
+		ForwarderRegisteredName: record.ForwarderRegisteredName,
+		ConnectionState:         record.ConnectionState,
+		ForwarderState:          record.ForwarderState,
+		HealPolicy:              record.HealPolicy,
+	}
+
+	request := &networkservice.NetworkServiceRequest{}
+	if ok, err := unmarshalProto(record.Request, request); err != nil {
+		return nil, err
+	} else if ok {
+		cc.Request = request
+	}
+	xcon := &crossconnect.CrossConnect{}
+	if ok, err := unmarshalProto(record.Xcon, xcon); err != nil {
+		return nil, err
+	} else if ok {
+		cc.Xcon = xcon
+	}
+	remoteNsm := &registry.NetworkServiceManager{}
+	if ok, err := unmarshalProto(record.RemoteNsm, remoteNsm); err != nil {
+		return nil, err
+	} else if ok {
+		cc.RemoteNsm = remoteNsm
+	}
+	endpoint := &registry.NSERegistration{}
+	if ok, err := unmarshalProto(record.Endpoint, endpoint); err != nil {
+		return nil, err
+	} else if ok {
+		cc.Endpoint = endpoint
+	}
+	return cc, nil
+}
+
+func encodeEndpoint(value cloneable) ([]byte, error) {
+	ep := value.(*Endpoint)
+	record := &endpointRecord{
+		SocketLocation: ep.SocketLocation,
+		Workspace:      ep.Workspace,
+	}
+	if ep.Endpoint != nil {
+		var err error
+		if record.Endpoint, err = marshalProto(ep.Endpoint); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(record)
+}
+
+func decodeEndpoint(data []byte) (*Endpoint, error) {
+	record := &endpointRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, err
+	}
+	ep := &Endpoint{
+		SocketLocation: record.SocketLocation,
+		Workspace:      record.Workspace,
+	}
+	endpoint := &registry.NSERegistration{}
+	if ok, err := unmarshalProto(record.Endpoint, endpoint); err != nil {
+		return nil, err
+	} else if ok {
+		ep.Endpoint = endpoint
+	}
+	return ep, nil
+}
+
+func encodeForwarder(value cloneable) ([]byte, error) {
+	dp := value.(*Forwarder)
+	return json.Marshal(&forwarderRecord{
+		RegisteredName: dp.RegisteredName,
+		SocketLocation: dp.SocketLocation,
+	})
+}
+
+// decodeForwarder restores forwarder binding. Restored forwarder has no mechanisms configured until
+// it is registered again, since we do not know if it is still alive.
+func decodeForwarder(data []byte) (*Forwarder, error) {
+	record := &forwarderRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, err
+	}
+	return &Forwarder{
+		RegisteredName: record.RegisteredName,
+		SocketLocation: record.SocketLocation,
+		restored:       true,
+	}, nil
+}
+
+func loadKind(store Store, kind string, decode func(data []byte) (cloneable, error)) (map[string]cloneable, error) {
+	values, err := store.Load(kind)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s objects", kind)
+	}
+	rv := map[string]cloneable{}
+	for key, data := range values {
+		value, err := decode(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode %s %s", kind, key)
+		}
+		rv[key] = value
+	}
+	return rv, nil
+}
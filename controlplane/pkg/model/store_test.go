@@ -0,0 +1,378 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/registry"
+)
+
+func newTestStorePath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "nsmd-model-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path.Join(dir, "model"), func() { _ = os.RemoveAll(dir) }
+}
+
+func TestFileStoreReopen(t *testing.T) {
+	g := NewWithT(t)
+
+	storePath, cleanup := newTestStorePath(t)
+	defer cleanup()
+
+	store, err := NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	g.Expect(store.Put(EndpointKind, "ep1", []byte("1"))).To(BeNil())
+	g.Expect(store.Put(EndpointKind, "ep2", []byte("2"))).To(BeNil())
+	g.Expect(store.Put(ForwarderKind, "dp1", []byte("3"))).To(BeNil())
+	g.Expect(store.Put(EndpointKind, "ep1", []byte("4"))).To(BeNil())
+	g.Expect(store.Delete(EndpointKind, "ep2")).To(BeNil())
+	g.Expect(store.Close()).To(BeNil())
+	g.Expect(store.Put(EndpointKind, "ep3", []byte("5"))).NotTo(BeNil())
+
+	store, err = NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	defer func() { _ = store.Close() }()
+
+	endpoints, err := store.Load(EndpointKind)
+	g.Expect(err).To(BeNil())
+	g.Expect(endpoints).To(Equal(map[string][]byte{"ep1": []byte("4")}))
+	forwarders, err := store.Load(ForwarderKind)
+	g.Expect(err).To(BeNil())
+	g.Expect(forwarders).To(Equal(map[string][]byte{"dp1": []byte("3")}))
+}
+
+func TestFileStoreDamagedLog(t *testing.T) {
+	g := NewWithT(t)
+
+	storePath, cleanup := newTestStorePath(t)
+	defer cleanup()
+
+	store, err := NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	g.Expect(store.Put(EndpointKind, "ep1", []byte("1"))).To(BeNil())
+	g.Expect(store.Put(EndpointKind, "ep2", []byte("2"))).To(BeNil())
+	g.Expect(store.Close()).To(BeNil())
+
+	// Simulate crash in the middle of writing the last record
+	data, err := ioutil.ReadFile(storePath + walSuffix)
+	g.Expect(err).To(BeNil())
+	g.Expect(ioutil.WriteFile(storePath+walSuffix, data[:len(data)-10], storeFileMode)).To(BeNil())
+
+	store, err = NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	defer func() { _ = store.Close() }()
+
+	endpoints, err := store.Load(EndpointKind)
+	g.Expect(err).To(BeNil())
+	g.Expect(endpoints).To(Equal(map[string][]byte{"ep1": []byte("1")}))
+}
+
+func TestFileStoreDamagedRecord(t *testing.T) {
+	g := NewWithT(t)
+
+	storePath, cleanup := newTestStorePath(t)
+	defer cleanup()
+
+	store, err := NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	g.Expect(store.Put(EndpointKind, "ep1", []byte("1"))).To(BeNil())
+	g.Expect(store.Put(EndpointKind, "ep2", []byte("2"))).To(BeNil())
+	g.Expect(store.Put(EndpointKind, "ep3", []byte("3"))).To(BeNil())
+	g.Expect(store.Close()).To(BeNil())
+
+	// Damaged record in the middle of the log does not drop the following ones
+	data, err := ioutil.ReadFile(storePath + walSuffix)
+	g.Expect(err).To(BeNil())
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	lines[1] = bytes.Replace(lines[1], []byte(`"ep2"`), []byte(`"epX"`), 1)
+	g.Expect(ioutil.WriteFile(storePath+walSuffix, bytes.Join(lines, nil), storeFileMode)).To(BeNil())
+
+	store, err = NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	defer func() { _ = store.Close() }()
+
+	endpoints, err := store.Load(EndpointKind)
+	g.Expect(err).To(BeNil())
+	g.Expect(endpoints).To(Equal(map[string][]byte{"ep1": []byte("1"), "ep3": []byte("3")}))
+}
+
+func TestFileStoreTruncatesPartialWrite(t *testing.T) {
+	g := NewWithT(t)
+
+	storePath, cleanup := newTestStorePath(t)
+	defer cleanup()
+
+	store, err := NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	defer func() { _ = store.Close() }()
+	g.Expect(store.Put(EndpointKind, "ep1", []byte("1"))).To(BeNil())
+
+	// Simulate failed write of the part of the record
+	_, err = store.wal.Write([]byte(`{"op":"put","kind":"endpoint"`))
+	g.Expect(err).To(BeNil())
+	store.truncate(store.written)
+	g.Expect(store.Put(EndpointKind, "ep2", []byte("2"))).To(BeNil())
+
+	data, err := ioutil.ReadFile(storePath + walSuffix)
+	g.Expect(err).To(BeNil())
+	lines := bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+	g.Expect(lines).To(HaveLen(2))
+	for _, line := range lines {
+		record := &walRecord{}
+		g.Expect(json.Unmarshal(line, record)).To(Succeed())
+		g.Expect(record.Checksum).To(Equal(record.checksum()))
+	}
+}
+
+func TestFileStoreConcurrentWrites(t *testing.T) {
+	g := NewWithT(t)
+
+	storePath, cleanup := newTestStorePath(t)
+	defer cleanup()
+
+	store, err := NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			g.Expect(store.Put(EndpointKind, strconv.Itoa(i), []byte(strconv.Itoa(i)))).To(BeNil())
+		}(i)
+	}
+	wg.Wait()
+	g.Expect(store.walRecords).To(Equal(50))
+	g.Expect(store.Close()).To(BeNil())
+
+	store, err = NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	defer func() { _ = store.Close() }()
+
+	endpoints, err := store.Load(EndpointKind)
+	g.Expect(err).To(BeNil())
+	g.Expect(endpoints).To(HaveLen(50))
+}
+
+func TestFileStoreCompact(t *testing.T) {
+	g := NewWithT(t)
+
+	storePath, cleanup := newTestStorePath(t)
+	defer cleanup()
+
+	store, err := NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	for i := 0; i < compactThreshold+1; i++ {
+		g.Expect(store.Put(EndpointKind, "ep1", []byte(strconv.Itoa(i)))).To(BeNil())
+	}
+	g.Expect(store.walRecords).To(Equal(1))
+	g.Expect(store.Close()).To(BeNil())
+
+	store, err = NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	defer func() { _ = store.Close() }()
+
+	endpoints, err := store.Load(EndpointKind)
+	g.Expect(err).To(BeNil())
+	g.Expect(endpoints).To(Equal(map[string][]byte{"ep1": []byte(strconv.Itoa(compactThreshold))}))
+}
+
+func TestModelRestore(t *testing.T) {
+	g := NewWithT(t)
+
+	storePath, cleanup := newTestStorePath(t)
+	defer cleanup()
+
+	store, err := NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+
+	m := NewModel()
+	g.Expect(m.Restore(store)).To(BeNil())
+
+	ctx := context.Background()
+	m.AddForwarder(ctx, &Forwarder{
+		RegisteredName:       "dp1",
+		SocketLocation:       "/var/lib/dp1.sock",
+		MechanismsConfigured: true,
+	})
+	m.AddEndpoint(ctx, &Endpoint{
+		Endpoint: &registry.NSERegistration{
+			NetworkService: &registry.NetworkService{
+				Name: "ns1",
+			},
+			NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{
+				Name:               "endp1",
+				NetworkServiceName: "ns1",
+			},
+		},
+		SocketLocation: "/var/lib/endp1.sock",
+		Workspace:      "ws1",
+	})
+	m.AddEndpoint(ctx, &Endpoint{
+		Endpoint: &registry.NSERegistration{
+			NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{
+				Name: "endp2",
+			},
+		},
+	})
+	m.DeleteEndpoint(ctx, "endp2")
+
+	id := m.ConnectionID()
+	m.AddClientConnection(ctx, &ClientConnection{
+		ConnectionID: id,
+		Request: &networkservice.NetworkServiceRequest{
+			Connection: &connection.Connection{
+				Id:             id,
+				NetworkService: "ns1",
+			},
+		},
+		Xcon: &crossconnect.CrossConnect{
+			Id: id,
+		},
+		ForwarderRegisteredName: "dp1",
+		ConnectionState:         ClientConnectionRequesting,
+		ForwarderState:          ForwarderStateReady,
+		HealPolicy:              HealPolicy{Mode: HealModeSameEndpoint, RetryCount: 3},
+	})
+	m.ApplyClientConnectionChanges(ctx, id, func(cc *ClientConnection) {
+		cc.ConnectionState = ClientConnectionHealing
+	})
+	g.Expect(store.Close()).To(BeNil())
+
+	store, err = NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	defer func() { _ = store.Close() }()
+
+	restored := NewModel()
+	g.Expect(restored.Restore(store)).To(BeNil())
+
+	dp := restored.GetForwarder("dp1")
+	g.Expect(dp).NotTo(BeNil())
+	g.Expect(dp.SocketLocation).To(Equal("/var/lib/dp1.sock"))
+	g.Expect(dp.MechanismsConfigured).To(BeFalse())
+
+	g.Expect(restored.GetEndpoint("endp2")).To(BeNil())
+	ep := restored.GetEndpoint("endp1")
+	g.Expect(ep).NotTo(BeNil())
+	g.Expect(ep.Workspace).To(Equal("ws1"))
+	g.Expect(ep.NetworkServiceName()).To(Equal("ns1"))
+
+	cc := restored.GetClientConnection(id)
+	g.Expect(cc).NotTo(BeNil())
+	g.Expect(cc.ConnectionState).To(Equal(ClientConnectionHealing))
+	g.Expect(cc.ForwarderRegisteredName).To(Equal("dp1"))
+	g.Expect(cc.Request.GetConnection().GetNetworkService()).To(Equal("ns1"))
+	g.Expect(cc.Xcon.GetId()).To(Equal(id))
+	g.Expect(cc.RemoteNsm).To(BeNil())
+	g.Expect(cc.Endpoint).To(BeNil())
+	g.Expect(cc.HealPolicy).To(Equal(HealPolicy{Mode: HealModeSameEndpoint, RetryCount: 3}))
+
+	// Connection IDs are not reused after restore
+	g.Expect(restored.ConnectionID()).NotTo(Equal(id))
+}
+
+type forwarderAddedListener struct {
+	ListenerImpl
+	added chan *Forwarder
+}
+
+func (l *forwarderAddedListener) ForwarderAdded(_ context.Context, dp *Forwarder) {
+	l.added <- dp
+}
+
+func TestModelRestoreForwarderRegisteredAgain(t *testing.T) {
+	g := NewWithT(t)
+
+	storePath, cleanup := newTestStorePath(t)
+	defer cleanup()
+
+	store, err := NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	defer func() { _ = store.Close() }()
+
+	m := NewModel()
+	g.Expect(m.Restore(store)).To(BeNil())
+	m.AddForwarder(context.Background(), &Forwarder{
+		RegisteredName: "dp1",
+		SocketLocation: "/var/lib/dp1.sock",
+	})
+
+	restored := NewModel()
+	g.Expect(restored.Restore(store)).To(BeNil())
+
+	listener := &forwarderAddedListener{added: make(chan *Forwarder, 10)}
+	restored.AddListener(listener)
+	g.Consistently(listener.added).ShouldNot(Receive())
+
+	restored.UpdateForwarder(context.Background(), &Forwarder{
+		RegisteredName: "dp1",
+		SocketLocation: "/var/lib/dp1.sock",
+	})
+	var dp *Forwarder
+	g.Eventually(listener.added).Should(Receive(&dp))
+	g.Expect(dp.RegisteredName).To(Equal("dp1"))
+
+	// Mechanisms updates of the registered forwarder are not announced as added forwarder
+	dp.MechanismsConfigured = true
+	restored.UpdateForwarder(context.Background(), dp)
+	g.Consistently(listener.added).ShouldNot(Receive())
+}
+
+func TestModelJournalKeepsLatestChange(t *testing.T) {
+	g := NewWithT(t)
+
+	storePath, cleanup := newTestStorePath(t)
+	defer cleanup()
+
+	store, err := NewFileStore(storePath)
+	g.Expect(err).To(BeNil())
+	defer func() { _ = store.Close() }()
+
+	m := NewModel()
+	g.Expect(m.Restore(store)).To(BeNil())
+
+	ctx := context.Background()
+	m.AddForwarder(ctx, &Forwarder{RegisteredName: "dp1"})
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m.UpdateForwarder(ctx, &Forwarder{RegisteredName: "dp1", SocketLocation: strconv.Itoa(i)})
+		}(i)
+	}
+	wg.Wait()
+	m.UpdateForwarder(ctx, &Forwarder{RegisteredName: "dp1", SocketLocation: "latest"})
+
+	restored := NewModel()
+	g.Expect(restored.Restore(store)).To(BeNil())
+	g.Expect(restored.GetForwarder("dp1").SocketLocation).To(Equal("latest"))
+}
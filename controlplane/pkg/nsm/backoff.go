@@ -0,0 +1,89 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsm
+
+import (
+	"time"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/model"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/properties"
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools/backoff"
+)
+
+// HealRequestBackoff returns backoff of heal request retries. Heal policy of the connection overrides retry count
+// and initial delay set by properties.
+func HealRequestBackoff(props *properties.Properties, cc *model.ClientConnection) backoff.Policy {
+	retryCount, retryDelay := props.HealRetryCount, props.HealRetryDelay
+	if cc.HealPolicy.RetryCount > 0 {
+		retryCount = cc.HealPolicy.RetryCount
+	}
+	if cc.HealPolicy.RetryDelay > 0 {
+		retryDelay = cc.HealPolicy.RetryDelay
+	}
+	if retryCount < 1 {
+		// At least one heal request is done
+		retryCount = 1
+	}
+	return backoff.Policy{
+		Initial:     retryDelay,
+		Max:         maxDuration(retryDelay, props.HealRetryMaxDelay),
+		Multiplier:  props.HealRetryMultiplier,
+		Jitter:      props.HealRetryJitter,
+		MaxAttempts: retryCount,
+	}
+}
+
+// WaitNSEBackoff returns backoff of checking registry for NSE to appear while healing
+func WaitNSEBackoff(props *properties.Properties) backoff.Policy {
+	return backoff.Policy{
+		Initial:    props.HealDSTNSEWaitTick,
+		Max:        maxDuration(props.HealDSTNSEWaitTick, props.HealDSTNSEWaitMaxTick),
+		Multiplier: props.HealRetryMultiplier,
+		Jitter:     props.HealRetryJitter,
+		Deadline:   props.HealDSTNSEWaitTimeout,
+	}
+}
+
+// RemoteConnectionLostBackoff returns backoff of the attempts to wait for remote connection to be healed from the
+// source side, connection is closed once attempts are exhausted or the deadline is passed
+func RemoteConnectionLostBackoff(props *properties.Properties) backoff.Policy {
+	return backoff.Policy{
+		Initial:     props.HealRetryDelay,
+		Max:         maxDuration(props.HealRetryDelay, props.HealRetryMaxDelay),
+		Multiplier:  props.HealRetryMultiplier,
+		Jitter:      props.HealRetryJitter,
+		MaxAttempts: props.RemoteHealMaxAttempts,
+		Deadline:    props.RemoteHealMaxTime,
+	}
+}
+
+// RemoteHealWaitBackoff returns backoff of checking remote connection to be healed from the source side during
+// a single attempt
+func RemoteHealWaitBackoff(props *properties.Properties) backoff.Policy {
+	return backoff.Policy{
+		Initial:    props.HealDSTNSEWaitTick,
+		Max:        maxDuration(props.HealDSTNSEWaitTick, props.HealDSTNSEWaitMaxTick),
+		Multiplier: props.HealRetryMultiplier,
+		Jitter:     props.HealRetryJitter,
+		Deadline:   props.HealTimeout,
+	}
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsm
+
+import (
+	"context"
+	"time"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/ipsec"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/common"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/model"
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools/spanhelper"
+)
+
+// rotateIPsecKeys - periodically updates IPsec connections requested by the local clients. New security
+// associations are negotiated on every connection request, so both forwarders are reprogrammed with the new keys.
+func (srv *networkServiceManager) rotateIPsecKeys(period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-srv.ctx.Done():
+			return
+		case <-ticker.C:
+			for _, cc := range srv.model.GetAllClientConnections() {
+				if isIPsecSourceConnection(cc) {
+					srv.rotateConnectionKeys(cc)
+				}
+			}
+		}
+	}
+}
+
+// isIPsecSourceConnection - keys are rotated by the source NSMD of the ready IPsec connections
+func isIPsecSourceConnection(cc *model.ClientConnection) bool {
+	if cc.ConnectionState != model.ClientConnectionReady || cc.Request == nil {
+		return false
+	}
+	if cc.Xcon.GetLocalSource() == nil {
+		return false
+	}
+	return cc.Xcon.GetRemoteDestination().GetMechanism().GetType() == ipsec.MECHANISM
+}
+
+func (srv *networkServiceManager) rotateConnectionKeys(cc *model.ClientConnection) {
+	span := spanhelper.FromContext(srv.ctx, "rotateIPsecKeys")
+	defer span.Finish()
+	span.LogValue("connection-id", cc.GetID())
+
+	ctx, cancel := context.WithTimeout(span.Context(), srv.props.HealRequestTimeout)
+	defer cancel()
+
+	// Rotation is in place update of the model connection, it is not closed if the new keys are not negotiated
+	ctx = common.WithModelConnection(ctx, cc)
+	ctx = common.WithConnectionUpdate(ctx)
+
+	request := cc.Request.Clone()
+	request.SetRequestConnection(cc.GetConnectionSource())
+	if _, err := srv.LocalManager(cc).Request(ctx, request); err != nil {
+		span.LogError(err)
+		span.Logger().Errorf("NSM: failed to rotate IPsec keys of connection %s: %v", cc.GetID(), err)
+		srv.restoreRotatedConnection(ctx, cc)
+	}
+}
+
+// restoreRotatedConnection - connection keeps the previous security associations if the rotation fails
+func (srv *networkServiceManager) restoreRotatedConnection(ctx context.Context, cc *model.ClientConnection) {
+	srv.model.ApplyClientConnectionChanges(ctx, cc.GetID(), func(modelCC *model.ClientConnection) {
+		if modelCC.ConnectionState != model.ClientConnectionHealing {
+			// Connection is closed or healed meanwhile
+			return
+		}
+		modelCC.ConnectionState = model.ClientConnectionReady
+		modelCC.Xcon = cc.Xcon
+		modelCC.Endpoint = cc.Endpoint
+		modelCC.RemoteNsm = cc.RemoteNsm
+		modelCC.ForwarderRegisteredName = cc.ForwarderRegisteredName
+		modelCC.ForwarderState = cc.ForwarderState
+		modelCC.Request = cc.Request
+	})
+}
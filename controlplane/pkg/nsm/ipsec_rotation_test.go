@@ -0,0 +1,77 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/ipsec"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/model"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/properties"
+)
+
+func TestRotateIPsecKeysFailureKeepsConnection(t *testing.T) {
+	g := NewWithT(t)
+	data := newHealTestData()
+	data.model.UpdateForwarder(context.Background(), &model.Forwarder{
+		RegisteredName:       forwarder1Name,
+		LocalMechanisms:      []*connection.Mechanism{{Type: kernel.MECHANISM}},
+		RemoteMechanisms:     []*connection.Mechanism{{Type: ipsec.MECHANISM}},
+		MechanismsConfigured: true,
+	})
+
+	nse1 := data.createEndpoint(nse1Name, remoteNSMName)
+	xcon := data.createCrossConnection(false, true, "id", "dst")
+	xcon.Source.NetworkService = networkServiceName
+	xcon.Source.Mechanism = &connection.Mechanism{Type: kernel.MECHANISM}
+	xcon.Destination.Mechanism = &connection.Mechanism{Type: ipsec.MECHANISM}
+	request := data.createRequest(false)
+	request.Connection.Id = "id"
+	request.MechanismPreferences = []*connection.Mechanism{{Type: kernel.MECHANISM}}
+	cc := data.createClientConnection("id", xcon, nse1, remoteNSMName, forwarder1Name, request)
+	cc.ConnectionState = model.ClientConnectionReady
+	data.model.AddClientConnection(context.Background(), cc)
+
+	// Remote NSMD could not be reached to negotiate the new keys
+	data.nseManager.clientError = errors.New("transient error")
+	srv := &networkServiceManager{
+		serviceRegistry: data.serviceRegistry,
+		model:           data.model,
+		nseManager:      data.nseManager,
+		props: &properties.Properties{
+			HealRequestTimeout: time.Second,
+		},
+		ctx: context.Background(),
+	}
+
+	ccs := data.model.GetAllClientConnections()
+	g.Expect(ccs).To(HaveLen(1))
+	g.Expect(isIPsecSourceConnection(ccs[0])).To(BeTrue())
+	srv.rotateConnectionKeys(ccs[0])
+
+	rotated := data.model.GetClientConnection("id")
+	g.Expect(rotated).NotTo(BeNil())
+	g.Expect(rotated.ConnectionState).To(Equal(model.ClientConnectionReady))
+	g.Expect(rotated.ForwarderState).To(Equal(model.ForwarderStateReady))
+	g.Expect(rotated.Xcon).To(Equal(cc.Xcon))
+	g.Expect(rotated.Endpoint).To(Equal(nse1))
+}
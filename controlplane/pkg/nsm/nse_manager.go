@@ -64,21 +64,26 @@ func (nsem *nseManager) GetEndpoint(ctx context.Context, requestConnection *conn
 		return nil, err
 	}
 
-	endpointSelect := func() *registry.NetworkServiceEndpoint {
+	endpointSelect := func() (*registry.NetworkServiceEndpoint, error) {
 		if len(targetEndpoint) > 0 {
 			for _, endpoint := range endpoints {
 				if endpoint.GetName() == targetEndpoint {
-					return endpoint
+					return endpoint, nil
 				}
 			}
 
-			return nil
+			return nil, nil
 		}
 
 		return nsem.model.GetSelector().SelectEndpoint(requestConnection, endpointResponse.GetNetworkService(), endpoints)
 	}
 
-	endpoint := endpointSelect()
+	endpoint, err := endpointSelect()
+	if err != nil {
+		err = errors.Wrapf(err, "failed to select NSE for NetworkService %s", requestConnection.GetNetworkService())
+		span.LogError(err)
+		return nil, err
+	}
 	if endpoint == nil {
 		err = errors.Errorf("failed to select NSE for NetworkService %s. Checked: %d of total NSEs: %d",
 			requestConnection.GetNetworkService(), len(ignoreEndpoints), len(endpoints))
@@ -27,16 +27,18 @@ import (
 	mechanismCommon "github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/common"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/srv6"
-	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/registry"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/admission"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/api/nsm"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/common"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/local"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/model"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/properties"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/serviceregistry"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/vni"
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools/backoff"
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools/spanhelper"
 	"github.com/networkservicemesh/networkservicemesh/sdk/monitor/connectionmonitor"
 )
@@ -58,9 +60,12 @@ type networkServiceManager struct {
 	stateRestored    chan bool
 	renamedEndpoints map[string]string
 	nseManager       nsm.NetworkServiceEndpointManager
+	// storedConnections - connections restored from the model store, but not yet restored with the forwarder
+	storedConnections map[string]bool
 
 	remoteService networkservice.NetworkServiceServer
 	ctx           context.Context
+	admission     *admission.Controller
 }
 
 func (srv *networkServiceManager) Context() context.Context {
@@ -72,6 +77,7 @@ func (srv *networkServiceManager) LocalManager(clientConnection nsm.ClientConnec
 		common.NewRequestValidator(),
 		common.NewMonitorService(clientConnection.(*model.ClientConnection).Monitor),
 		local.NewConnectionService(srv.model),
+		common.NewAdmissionService(srv.admission),
 		local.NewForwarderService(srv.model, srv.serviceRegistry),
 		local.NewEndpointSelectorService(srv.nseManager),
 		local.NewEndpointService(srv.nseManager, srv.props, srv.model),
@@ -101,6 +107,10 @@ func (srv *networkServiceManager) GetHealProperties() *properties.Properties {
 	return srv.props
 }
 
+func (srv *networkServiceManager) AdmissionController() *admission.Controller {
+	return srv.admission
+}
+
 // NewNetworkServiceManager creates an instance of NetworkServiceManager
 func NewNetworkServiceManager(ctx context.Context, model model.Model, serviceRegistry serviceregistry.ServiceRegistry) nsm.NetworkServiceManager {
 	properties := properties.NewNsmProperties()
@@ -111,13 +121,23 @@ func NewNetworkServiceManager(ctx context.Context, model model.Model, serviceReg
 	}
 
 	srv := &networkServiceManager{
-		serviceRegistry:  serviceRegistry,
-		model:            model,
-		props:            properties,
-		stateRestored:    make(chan bool, 1),
-		renamedEndpoints: make(map[string]string),
-		nseManager:       nseManager,
-		ctx:              ctx,
+		serviceRegistry:   serviceRegistry,
+		model:             model,
+		props:             properties,
+		stateRestored:     make(chan bool, 1),
+		renamedEndpoints:  make(map[string]string),
+		storedConnections: make(map[string]bool),
+		nseManager:        nseManager,
+		ctx:               ctx,
+		admission: admission.NewController(admission.Quotas{
+			Workspace:      properties.MaxWorkspaceConnections,
+			NetworkService: properties.MaxNetworkServiceConnections,
+			RemoteNsm:      properties.MaxRemoteNsmConnections,
+		}),
+	}
+	for _, cc := range model.GetAllClientConnections() {
+		srv.storedConnections[cc.GetID()] = true
+		srv.restoreAdmission(cc)
 	}
 
 	srv.NetworkServiceHealProcessor = newNetworkServiceHealProcessor(
@@ -128,6 +148,10 @@ func NewNetworkServiceManager(ctx context.Context, model model.Model, serviceReg
 		nseManager,
 	)
 
+	if properties.IPsecKeyRotationPeriod > 0 {
+		go srv.rotateIPsecKeys(properties.IPsecKeyRotationPeriod)
+	}
+
 	return srv
 }
 
@@ -168,6 +192,7 @@ func (srv *networkServiceManager) RestoreConnections(xcons []*crossconnect.Cross
 	for _, xcon := range xcons {
 		srv.restoreXconnection(span.Context(), xcon, logger, forwarder, manager)
 	}
+	srv.restoreLostConnections(span.Context(), logger, forwarder, manager)
 	logger.Infof("All connections are recovered...")
 	// Notify state is restored
 	srv.stateRestored <- true
@@ -182,6 +207,10 @@ func (srv *networkServiceManager) restoreXconnection(ctx context.Context, xcon *
 	span.LogObject("xcon", xcon)
 
 	existing := srv.model.GetClientConnection(xcon.GetId())
+	if existing != nil && srv.takeStoredConnection(existing.GetID()) {
+		srv.restoreStoredConnection(span.Context(), existing, xcon, logger, forwarder, manager)
+		return
+	}
 	if existing == nil {
 		span.Logger().Infof("Restoring state of active connection %v", xcon)
 
@@ -216,6 +245,7 @@ func (srv *networkServiceManager) restoreXconnection(ctx context.Context, xcon *
 		monitor := manager.LocalConnectionMonitor(workspaceName)
 		clientConnection := srv.createConnection(xcon, request, endpoint, dp, connectionState, monitor)
 		srv.model.AddClientConnection(span.Context(), clientConnection)
+		srv.restoreAdmission(clientConnection)
 
 		if monitor == nil {
 			span.LogError(errors.Errorf("failed to restore connection %v. Workspace could be found for %v. closing", xcon, workspaceName))
@@ -228,6 +258,113 @@ func (srv *networkServiceManager) restoreXconnection(ctx context.Context, xcon *
 	}
 }
 
+// takeStoredConnection returns true only once for every connection restored from the model store
+func (srv *networkServiceManager) takeStoredConnection(id string) bool {
+	srv.Lock()
+	defer srv.Unlock()
+
+	if !srv.storedConnections[id] {
+		return false
+	}
+	delete(srv.storedConnections, id)
+	return true
+}
+
+// restoreStoredConnection attaches connection restored from the model store to the cross connect still programmed
+// in the forwarder. Heal is resumed with the stored endpoint, since we do not need to discover it again.
+func (srv *networkServiceManager) restoreStoredConnection(ctx context.Context, cc *model.ClientConnection, xcon *crossconnect.CrossConnect, logger logrus.FieldLogger, forwarder string, manager nsm.MonitorManager) {
+	span := spanhelper.FromContext(ctx, "restoreStoredConnection")
+	defer span.Finish()
+	span.Logger().Infof("Restoring stored connection %v with active cross connect %v", cc.GetID(), xcon)
+
+	closing := cc.ConnectionState == model.ClientConnectionClosing
+
+	// Allocated VNIs and SIDs are not persisted, so they are restored from the cross connect.
+	srv.getConnectionParameters(xcon, logger)
+	monitor := srv.storedConnectionMonitor(cc, manager)
+	cc = srv.model.ApplyClientConnectionChanges(span.Context(), cc.GetID(), func(modelCC *model.ClientConnection) {
+		modelCC.Xcon = xcon
+		modelCC.ForwarderRegisteredName = forwarder
+		modelCC.ForwarderState = model.ForwarderStateReady
+		modelCC.ConnectionState = model.ClientConnectionReady
+		modelCC.Monitor = monitor
+	})
+
+	if closing {
+		// Connection is marked Ready above, since closing connection could not be closed again.
+		srv.closeRestoredConnection(span.Context(), cc)
+		return
+	}
+	if monitor == nil && !cc.GetConnectionSource().IsRemote() {
+		span.LogError(errors.Errorf("failed to restore connection %v, no workspace monitor found, closing", cc.GetID()))
+		srv.closeRestoredConnection(span.Context(), cc)
+		return
+	}
+	srv.performHeal(span.Context(), xcon, cc.Endpoint, false, cc, logger)
+}
+
+// restoreLostConnections heals connections restored from the model store which are not programmed in the forwarder
+// anymore, so connections being requested or healed before NSMD restart are recovered too.
+func (srv *networkServiceManager) restoreLostConnections(ctx context.Context, logger logrus.FieldLogger, forwarder string, manager nsm.MonitorManager) {
+	for _, cc := range srv.model.GetAllClientConnections() {
+		if cc.ForwarderRegisteredName != forwarder && cc.ForwarderRegisteredName != "" {
+			continue
+		}
+		if !srv.takeStoredConnection(cc.GetID()) {
+			continue
+		}
+		logger.Infof("Stored connection %v is not found in the forwarder %v, healing", cc.GetID(), forwarder)
+
+		if cc.Xcon == nil || cc.ConnectionState == model.ClientConnectionClosing {
+			// Connection was never programmed or was closing, nothing to heal.
+			srv.model.DeleteClientConnection(ctx, cc.GetID())
+			srv.admission.Release(cc.GetID())
+			continue
+		}
+
+		monitor := srv.storedConnectionMonitor(cc, manager)
+		cc = srv.model.ApplyClientConnectionChanges(ctx, cc.GetID(), func(modelCC *model.ClientConnection) {
+			modelCC.ForwarderState = model.ForwarderStateNone
+			modelCC.ConnectionState = model.ClientConnectionReady
+			modelCC.Monitor = monitor
+		})
+
+		if cc.GetConnectionSource().IsRemote() {
+			srv.RemoteConnectionLost(ctx, cc)
+			continue
+		}
+		if monitor == nil {
+			logger.Errorf("Failed to restore connection %v, no workspace monitor found, closing", cc.GetID())
+			srv.closeRestoredConnection(ctx, cc)
+			continue
+		}
+		srv.Heal(ctx, cc, nsm.HealStateForwarderDown)
+	}
+}
+
+// closeRestoredConnection closes connection restored from the model store. Admission is released even if close
+// fails before reaching the admission service.
+func (srv *networkServiceManager) closeRestoredConnection(ctx context.Context, cc *model.ClientConnection) {
+	_ = srv.CloseConnection(ctx, cc)
+	srv.admission.Release(cc.GetID())
+}
+
+// restoreAdmission counts the restored connection against the connection quotas
+func (srv *networkServiceManager) restoreAdmission(cc *model.ClientConnection) {
+	src := cc.GetConnectionSource()
+	workspaceName := ""
+	if !src.IsRemote() {
+		workspaceName = src.GetMechanism().GetParameters()[mechanismCommon.Workspace]
+	}
+	srv.admission.Restore(cc.GetID(), src, workspaceName)
+}
+
+// storedConnectionMonitor returns monitor of the workspace of the local connection, monitors are not persisted
+func (srv *networkServiceManager) storedConnectionMonitor(cc *model.ClientConnection, manager nsm.MonitorManager) connectionmonitor.MonitorServer {
+	workspaceName := cc.GetConnectionSource().GetMechanism().GetParameters()[mechanismCommon.Workspace]
+	return manager.LocalConnectionMonitor(workspaceName)
+}
+
 func (srv *networkServiceManager) findEndpoint(ctx context.Context, endpointName string, networkServiceName string, discovery registry.NetworkServiceDiscoveryClient, xcon *crossconnect.CrossConnect, span spanhelper.SpanHelper) (*registry.NSERegistration, bool) {
 	var endpoint *registry.NSERegistration
 	endpointRenamed := false
@@ -288,6 +425,10 @@ func (srv *networkServiceManager) performHeal(ctx context.Context, xcon *crossco
 }
 
 func (srv *networkServiceManager) createConnection(xcon *crossconnect.CrossConnect, request *networkservice.NetworkServiceRequest, endpoint *registry.NSERegistration, dp *model.Forwarder, state model.ClientConnectionState, monitor connectionmonitor.MonitorServer) *model.ClientConnection {
+	healPolicy, err := srv.model.GetHealPolicies().RequestPolicy(request)
+	if err != nil {
+		logrus.Warnf("Restored connection %v has invalid heal policy labels, using NetworkService policy: %v", xcon.GetId(), err)
+	}
 	return &model.ClientConnection{
 		ConnectionID:            xcon.GetId(),
 		Request:                 request,
@@ -296,6 +437,7 @@ func (srv *networkServiceManager) createConnection(xcon *crossconnect.CrossConne
 		ForwarderRegisteredName: dp.RegisteredName,
 		ConnectionState:         state,
 		ForwarderState:          model.ForwarderStateReady, // It is configured already.
+		HealPolicy:              healPolicy,
 		Monitor:                 monitor,
 	}
 }
@@ -340,6 +482,15 @@ func (srv *networkServiceManager) getConnectionParameters(xcon *crossconnect.Cro
 
 		networkServiceName = src.GetNetworkService()
 		endpointName = src.GetNetworkServiceEndpointName()
+
+		// VNI or key of the remote connection is allocated by us, so it should not be allocated again.
+		mm := src.GetMechanism()
+		if vniID, ok, err := vni.FromMechanism(mm); err != nil {
+			logrus.Errorf("Error retrieving VNI from Remote connection %v", err)
+		} else if ok {
+			localIP, remoteIP := vni.Peers(mm.GetParameters())
+			srv.serviceRegistry.VniAllocator().Restore(localIP, remoteIP, vniID)
+		}
 	} else if dst := xcon.GetDestination(); dst != nil && !dst.IsRemote() {
 		// Local NSE, connection is Ready
 		networkServiceName = dst.GetNetworkService()
@@ -349,19 +500,10 @@ func (srv *networkServiceManager) getConnectionParameters(xcon *crossconnect.Cro
 		networkServiceName = xcon.GetDestination().GetNetworkService()
 		endpointName = xcon.GetDestination().GetNetworkServiceEndpointName()
 
-		// In case VxLan is used we need to correct vlanId id generator.
+		// VNI or key of the remote connection is allocated by the remote NSM, it is restored there.
+		// In case SRv6 is used we need to correct SID generator.
 		mm := dst.Mechanism
 		switch mm.GetType() {
-		case vxlan.MECHANISM:
-			m := vxlan.ToMechanism(mm)
-			srcIP, err := m.SrcIP()
-			dstIP, err2 := m.DstIP()
-			vni, err3 := m.VNI()
-			if err != nil || err2 != nil || err3 != nil {
-				logrus.Errorf("Error retrieving SRC/DST IP or VNI from Remote connection %v %v", err, err2)
-			} else {
-				srv.serviceRegistry.VniAllocator().Restore(srcIP, dstIP, vni)
-			}
 		case srv6.MECHANISM:
 			m := srv6.ToMechanism(mm)
 			hardwareAddress, err := m.DstHardwareAddress()
@@ -386,6 +528,13 @@ func (srv *networkServiceManager) closeLocalMissingNSE(ctx context.Context, cc n
 }
 
 func (srv *networkServiceManager) RemoteConnectionLost(ctx context.Context, clientConnection nsm.ClientConnection) {
+	if modelCC := srv.model.GetClientConnection(clientConnection.GetID()); modelCC != nil && modelCC.HealPolicy.Mode == model.HealModeNone {
+		logrus.Infof("NSM: Remote opened connection is not monitored and is not healed by policy, closing %v", clientConnection)
+		if err := srv.CloseConnection(ctx, clientConnection); err != nil {
+			logrus.Errorf("NSM: Error closing connection %v", err)
+		}
+		return
+	}
 	logrus.Infof("NSM: Remote opened connection is not monitored and put into Healing state %v", clientConnection)
 
 	srv.model.ApplyClientConnectionChanges(ctx, clientConnection.GetID(), func(modelCC *model.ClientConnection) {
@@ -393,10 +542,16 @@ func (srv *networkServiceManager) RemoteConnectionLost(ctx context.Context, clie
 	})
 
 	go func() {
-		<-time.After(srv.props.HealTimeout)
+		id := clientConnection.GetID()
+		err := retryRemoteHeal(context.Background(), RemoteConnectionLostBackoff(srv.props), func(ctx context.Context) error {
+			return srv.waitRemoteHeal(ctx, id)
+		})
+		if err == nil {
+			return
+		}
 
-		if modelCC := srv.model.GetClientConnection(clientConnection.GetID()); modelCC != nil && modelCC.ConnectionState == model.ClientConnectionHealingBegin {
-			logrus.Errorf("NSM: Timeout happened for checking connection status from Healing.. %v. Closing connection...", clientConnection)
+		if modelCC := srv.model.GetClientConnection(id); modelCC != nil && modelCC.ConnectionState == model.ClientConnectionHealingBegin {
+			logrus.Errorf("NSM: Connection %v is not healed from the source side: %v. Closing connection...", clientConnection, err)
 			// Nobody was healed connection from Remote side.
 			if err := srv.CloseConnection(ctx, clientConnection); err != nil {
 				logrus.Errorf("NSM: Error closing connection %v", err)
@@ -405,6 +560,41 @@ func (srv *networkServiceManager) RemoteConnectionLost(ctx context.Context, clie
 	}()
 }
 
+// retryRemoteHeal performs heal attempts with backoff until one of them succeeds or attempts are exhausted
+func retryRemoteHeal(ctx context.Context, policy backoff.Policy, attempt func(ctx context.Context) error) error {
+	retry := policy.Start()
+	for {
+		err := attempt(ctx)
+		if err == nil {
+			return nil
+		}
+		delay, ok := retry.Next()
+		if !ok {
+			return errors.Wrapf(err, "no attempts left after %d attempts", retry.Attempts())
+		}
+		logrus.Warnf("NSM: Remote heal attempt %d failed: %v. Delaying: %v", retry.Attempts()-1, err, delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// waitRemoteHeal checks connection with growing intervals to stop waiting as soon as it is healed or closed,
+// returns error if connection is still waiting to be healed from the source side after the heal timeout
+func (srv *networkServiceManager) waitRemoteHeal(ctx context.Context, id string) error {
+	wait := RemoteHealWaitBackoff(srv.props).Start()
+	for {
+		if modelCC := srv.model.GetClientConnection(id); modelCC == nil || modelCC.ConnectionState != model.ClientConnectionHealingBegin {
+			return nil
+		}
+		if !wait.Wait(ctx) {
+			return errors.Errorf("connection %v is not healed in %v", id, srv.props.HealTimeout)
+		}
+	}
+}
+
 func (srv *networkServiceManager) NotifyRenamedEndpoint(nseOldName, nseNewName string) {
 	logrus.Infof("Notified about renamed endpoint %v => %v", nseOldName, nseNewName)
 	srv.renamedEndpoints[nseOldName] = nseNewName
@@ -95,6 +95,12 @@ func (p *healProcessor) Heal(ctx context.Context, clientConnection nsm.ClientCon
 		return
 	}
 
+	if cc.HealPolicy.Mode == model.HealModeNone {
+		logger.Infof("NSM_Heal(%v) Is Disabled by %v policy/Closing connection %v", healID, cc.HealPolicy.Mode, cc)
+		_ = p.CloseConnection(ctx, cc)
+		return
+	}
+
 	if modelCC := p.model.GetClientConnection(cc.GetID()); modelCC == nil {
 		logger.Errorf("NSM_Heal(%v) Trying to heal not existing connection", healID)
 		return
@@ -221,10 +227,21 @@ func (p *healProcessor) healDstDown(ctx context.Context, cc *model.ClientConnect
 	}
 	logger.Infof("NSM_Heal(2.2) Starting DST Heal...")
 	// We are client NSMd, we need to try recover our connection srv.
-	// Wait for NSE not equal to down one, since we know it will be re-registered with new endpoint name.
-	ctx = p.waitForNSEUpdateContext(ctx, cc.Endpoint, cc)
+	if cc.HealPolicy.Mode == model.HealModeSameEndpoint {
+		// Connection could not be moved to another NSE, so wait for the same one to be registered again.
+		waitCtx, waitCancel := context.WithTimeout(ctx, p.props.HealTimeout*3)
+		defer waitCancel()
+		if !p.waitNSE(waitCtx, cc.Endpoint.GetNetworkServiceEndpoint().GetName(), cc.GetNetworkService(), p.nseIsSameAndAvailable) {
+			span.LogValue("waitNSE", "failed to find the same endpoint with timeout")
+			return false
+		}
+	} else {
+		// Wait for NSE not equal to down one, since we know it will be re-registered with new endpoint name.
+		ctx = p.waitForNSEUpdateContext(ctx, cc.Endpoint, cc)
+	}
 	// Fallback to heal with choose of new NSE.
-	for attempt := 0; attempt < p.props.HealRetryCount; attempt++ {
+	retry := HealRequestBackoff(p.props, cc).Start()
+	for attempt := 0; ; attempt++ {
 		// If client context is cancelled, we need to stop attempts.
 		if ctx.Err() != nil {
 			logger.Info("Client context is broken, stopping heal attempts")
@@ -242,12 +259,14 @@ func (p *healProcessor) healDstDown(ctx context.Context, cc *model.ClientConnect
 		if err == nil {
 			return true
 		}
-		logger.Errorf("NSM_Heal(2.3.1) Failed to heal connection: %v. Delaying: %v", err, p.props.HealRetryDelay)
-		if attempt+1 < p.props.HealRetryCount {
-			attemptSpan.Finish()
-			<-time.After(p.props.HealRetryDelay)
-			continue
+		delay, ok := retry.Next()
+		if !ok {
+			logger.Errorf("NSM_Heal(2.3.1) Failed to heal connection: %v. No attempts left", err)
+			break
 		}
+		logger.Errorf("NSM_Heal(2.3.1) Failed to heal connection: %v. Delaying: %v", err, delay)
+		attemptSpan.Finish()
+		<-time.After(delay)
 	}
 	return false
 }
@@ -352,12 +371,17 @@ func (p *healProcessor) healDstMgrDown(ctx context.Context, cc *model.ClientConn
 		defer waitCancel()
 		if !p.waitNSE(waitCtx, endpointName, cc.GetNetworkService(), p.nseIsSameAndAvailable) {
 			span.LogValue("waitNSE", "failed to find endpoint by name with timeout")
+			if cc.HealPolicy.Mode == model.HealModeSameEndpoint {
+				// Connection could not be moved to another NSE.
+				return false
+			}
 			ctx = common.WithIgnoredEndpoints(ctx, map[registry.EndpointNSMName]*registry.NSERegistration{
 				cc.Endpoint.GetEndpointNSMName(): cc.Endpoint,
 			})
 		}
 	}
-	for attempt := 0; attempt < p.props.HealRetryCount; attempt++ {
+	retry := HealRequestBackoff(p.props, cc).Start()
+	for attempt := 0; ; attempt++ {
 		attemptSpan := spanhelper.FromContext(ctx, fmt.Sprintf("healing-attempt-%v", attempt))
 		requestCtx, requestCancel := context.WithTimeout(attemptSpan.Context(), p.props.HealRequestTimeout)
 		defer requestCancel()
@@ -367,14 +391,15 @@ func (p *healProcessor) healDstMgrDown(ctx context.Context, cc *model.ClientConn
 			attemptSpan.LogObject("state", "healed")
 			return true
 		}
-		err = errors.Errorf("heal(6.2.3) Failed to heal connection: %v. Delaying: %v", err, p.props.HealRetryDelay)
+		delay, ok := retry.Next()
+		if !ok {
+			span.LogError(errors.Errorf("heal(6.2.3) Failed to heal connection: %v. No attempts left", err))
+			break
+		}
+		err = errors.Errorf("heal(6.2.3) Failed to heal connection: %v. Delaying: %v", err, delay)
 		span.LogError(err)
 		attemptSpan.Finish()
-		if attempt+1 < p.props.HealRetryCount {
-			attemptSpan.Finish()
-			<-time.After(p.props.HealRetryDelay)
-			continue
-		}
+		<-time.After(delay)
 	}
 
 	return true
@@ -431,6 +456,7 @@ func (p *healProcessor) waitNSE(ctx context.Context, endpointName, networkServic
 	}
 
 	st := time.Now()
+	retry := WaitNSEBackoff(p.props).Start()
 
 	nseRequest := &registry.FindNetworkServiceRequest{
 		NetworkServiceName: networkService,
@@ -464,12 +490,13 @@ func (p *healProcessor) waitNSE(ctx context.Context, endpointName, networkServic
 			}
 		}
 
-		if time.Since(st) > p.props.HealDSTNSEWaitTimeout {
+		delay, ok := retry.Next()
+		if !ok || time.Since(st) > p.props.HealDSTNSEWaitTimeout {
 			span.LogError(errors.Errorf("timeout waiting for NetworkService: %v timeout: %v", networkService, time.Since(st)))
 			return false
 		}
 		// Wait a bit
-		<-time.After(p.props.HealDSTNSEWaitTick)
+		<-time.After(delay)
 	}
 }
 
@@ -285,6 +285,58 @@ func TestHealDstDown_LocalClientRemoteEndpoint_NoNSEFound(t *testing.T) {
 		Verify(t)
 }
 
+func TestRemoteHealSucceedsAfterFailedAttempts(t *testing.T) {
+	g := NewWithT(t)
+	data := newHealTestData()
+
+	nse1 := data.createEndpoint(nse1Name, localNSMName)
+	xcon := data.createCrossConnection(true, false, "src", "dst")
+	connection := data.createClientConnection("id", xcon, nse1, remoteNSMName, forwarder1Name, data.createRequest(true))
+	connection.ConnectionState = model.ClientConnectionHealingBegin
+	data.model.AddClientConnection(context.Background(), connection)
+
+	props := properties.NewNsmProperties()
+	props.HealTimeout = 100 * time.Millisecond
+	props.HealDSTNSEWaitTick = 5 * time.Millisecond
+	props.HealRetryDelay = time.Millisecond
+	props.HealRetryJitter = 0
+	srv := &networkServiceManager{
+		model: data.model,
+		props: props,
+	}
+
+	attempts := 0
+	err := retryRemoteHeal(context.Background(), RemoteConnectionLostBackoff(props), func(ctx context.Context) error {
+		attempts++
+		if attempts == 3 {
+			// Source side heals the connection during the third attempt
+			go data.model.ApplyClientConnectionChanges(context.Background(), "id", func(modelCC *model.ClientConnection) {
+				modelCC.ConnectionState = model.ClientConnectionReady
+			})
+		}
+		return srv.waitRemoteHeal(ctx, "id")
+	})
+	g.Expect(err).To(BeNil())
+	g.Expect(attempts).To(Equal(3))
+	g.Expect(data.model.GetClientConnection("id").ConnectionState).To(Equal(model.ClientConnectionReady))
+}
+
+func TestRemoteHealAttemptsAreExhausted(t *testing.T) {
+	g := NewWithT(t)
+
+	props := properties.NewNsmProperties()
+	props.HealRetryDelay = time.Millisecond
+	props.RemoteHealMaxAttempts = 2
+
+	attempts := 0
+	err := retryRemoteHeal(context.Background(), RemoteConnectionLostBackoff(props), func(ctx context.Context) error {
+		attempts++
+		return errors.New("not healed")
+	})
+	g.Expect(err).NotTo(BeNil())
+	g.Expect(attempts).To(Equal(2))
+}
+
 type discoveryClientStub struct {
 	response *registry.FindNetworkServiceResponse
 	error    error
@@ -0,0 +1,109 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsm
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/admission"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/model"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/serviceregistry"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/vni"
+)
+
+type vniAllocatorStub struct {
+	restored []uint32
+
+	vni.VniAllocator
+}
+
+func (stub *vniAllocatorStub) Restore(localIP, remoteIP string, vniID uint32) {
+	stub.restored = append(stub.restored, vniID)
+}
+
+type vniServiceRegistryStub struct {
+	vniAllocator *vniAllocatorStub
+
+	serviceregistry.ServiceRegistry
+}
+
+func (stub *vniServiceRegistryStub) VniAllocator() vni.VniAllocator {
+	return stub.vniAllocator
+}
+
+func TestRestoreLostConnectionsReleasesAdmission(t *testing.T) {
+	g := NewWithT(t)
+	data := newHealTestData()
+
+	nse1 := data.createEndpoint(nse1Name, localNSMName)
+	xcon := data.createCrossConnection(false, false, "id", "dst")
+	xcon.Source.NetworkService = networkServiceName
+	cc := data.createClientConnection("id", xcon, nse1, localNSMName, forwarder1Name, data.createRequest(false))
+	// Connection was closing before NSMD restart
+	cc.ConnectionState = model.ClientConnectionClosing
+	data.model.AddClientConnection(context.Background(), cc)
+
+	srv := &networkServiceManager{
+		model:             data.model,
+		admission:         admission.NewController(admission.Quotas{}),
+		storedConnections: map[string]bool{"id": true},
+	}
+	srv.restoreAdmission(cc)
+	g.Expect(srv.admission.Count(admission.NetworkServiceScope, networkServiceName)).To(Equal(1))
+
+	srv.restoreLostConnections(context.Background(), logrus.New(), forwarder1Name, nil)
+
+	g.Expect(data.model.GetClientConnection("id")).To(BeNil())
+	g.Expect(srv.admission.Count(admission.NetworkServiceScope, networkServiceName)).To(Equal(0))
+}
+
+func TestGetConnectionParametersRestoresLocalVNIs(t *testing.T) {
+	g := NewWithT(t)
+	data := newHealTestData()
+
+	registryStub := &vniServiceRegistryStub{vniAllocator: &vniAllocatorStub{}}
+	srv := &networkServiceManager{
+		serviceRegistry: registryStub,
+	}
+
+	// VNI of the remote source connection is allocated by us
+	xcon := data.createCrossConnection(true, false, "id", "dst")
+	xcon.Source.Mechanism = vxlanMechanism("1")
+	srv.getConnectionParameters(xcon, logrus.New())
+	g.Expect(registryStub.vniAllocator.restored).To(Equal([]uint32{1}))
+
+	// VNI of the remote destination connection is allocated by the remote NSM
+	xcon = data.createCrossConnection(false, true, "id", "dst")
+	xcon.Destination.Mechanism = vxlanMechanism("2")
+	srv.getConnectionParameters(xcon, logrus.New())
+	g.Expect(registryStub.vniAllocator.restored).To(Equal([]uint32{1}))
+}
+
+func vxlanMechanism(vniID string) *connection.Mechanism {
+	return &connection.Mechanism{
+		Type: vxlan.MECHANISM,
+		Parameters: map[string]string{
+			vxlan.SrcIP: "10.0.0.1",
+			vxlan.DstIP: "10.0.0.2",
+			vxlan.VNI:   vniID,
+		},
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsmd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/registry"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/model"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/serviceregistry"
+	"github.com/networkservicemesh/networkservicemesh/utils"
+)
+
+const (
+	// ConnectionCountAdvertiseDelayEnv - environment variable name - delay of advertising connections count changes,
+	// changes during the delay are sent with a single registry update
+	ConnectionCountAdvertiseDelayEnv = utils.EnvVar("NSMD_CONNECTION_COUNT_ADVERTISE_DELAY")
+	// DefaultConnectionCountAdvertiseDelay - default delay of advertising connections count changes
+	DefaultConnectionCountAdvertiseDelay = time.Second
+)
+
+// ConnectionCountAdvertiser is a model listener updating registrations of local endpoints
+// with the number of connections they are serving, so remote NSMs could take it into account
+// during endpoint selection
+type ConnectionCountAdvertiser struct {
+	model.ListenerImpl
+
+	model           model.Model
+	serviceRegistry serviceregistry.ServiceRegistry
+
+	delay      time.Duration
+	mtx        sync.Mutex
+	pending    map[string]bool
+	advertised map[string]int
+	notify     chan struct{}
+}
+
+// NewConnectionCountAdvertiser creates a new ConnectionCountAdvertiser and starts advertising, changes are
+// advertised after NSMD_CONNECTION_COUNT_ADVERTISE_DELAY
+func NewConnectionCountAdvertiser(ctx context.Context, mdl model.Model, serviceRegistry serviceregistry.ServiceRegistry) *ConnectionCountAdvertiser {
+	return newConnectionCountAdvertiser(ctx, mdl, serviceRegistry, ConnectionCountAdvertiseDelayEnv.GetOrDefaultDuration(DefaultConnectionCountAdvertiseDelay))
+}
+
+func newConnectionCountAdvertiser(ctx context.Context, mdl model.Model, serviceRegistry serviceregistry.ServiceRegistry, delay time.Duration) *ConnectionCountAdvertiser {
+	a := &ConnectionCountAdvertiser{
+		model:           mdl,
+		serviceRegistry: serviceRegistry,
+		delay:           delay,
+		pending:         map[string]bool{},
+		advertised:      map[string]int{},
+		notify:          make(chan struct{}, 1),
+	}
+	go a.serve(ctx)
+	return a
+}
+
+// ClientConnectionAdded schedules advertising for the endpoint of added connection
+func (a *ConnectionCountAdvertiser) ClientConnectionAdded(_ context.Context, cc *model.ClientConnection) {
+	a.schedule(cc)
+}
+
+// ClientConnectionUpdated schedules advertising for the endpoints of updated connection
+func (a *ConnectionCountAdvertiser) ClientConnectionUpdated(_ context.Context, old, new *model.ClientConnection) {
+	a.schedule(old)
+	a.schedule(new)
+}
+
+// ClientConnectionDeleted schedules advertising for the endpoint of deleted connection
+func (a *ConnectionCountAdvertiser) ClientConnectionDeleted(_ context.Context, cc *model.ClientConnection) {
+	a.schedule(cc)
+}
+
+// EndpointDeleted forgets advertised value of deleted endpoint
+func (a *ConnectionCountAdvertiser) EndpointDeleted(_ context.Context, endpoint *model.Endpoint) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	delete(a.advertised, endpoint.EndpointName())
+}
+
+func (a *ConnectionCountAdvertiser) schedule(cc *model.ClientConnection) {
+	nse := cc.Endpoint.GetNetworkServiceEndpoint()
+	if nse == nil || nse.GetNetworkServiceManagerName() != a.model.GetNsm().GetName() {
+		return
+	}
+
+	a.mtx.Lock()
+	a.pending[nse.GetName()] = true
+	a.mtx.Unlock()
+
+	select {
+	case a.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (a *ConnectionCountAdvertiser) serve(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.notify:
+		}
+		// Connections added or deleted during the delay are advertised with a single update
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(a.delay):
+		}
+		select {
+		case <-a.notify:
+		default:
+		}
+
+		a.mtx.Lock()
+		pending := a.pending
+		a.pending = map[string]bool{}
+		a.mtx.Unlock()
+
+		for name := range pending {
+			a.advertise(ctx, name)
+		}
+	}
+}
+
+func (a *ConnectionCountAdvertiser) advertise(ctx context.Context, name string) {
+	endpoint := a.model.GetEndpoint(name)
+	if endpoint == nil {
+		return
+	}
+	count := a.model.ConnectionCount(endpoint.Endpoint.GetNetworkServiceEndpoint())
+
+	a.mtx.Lock()
+	last, ok := a.advertised[name]
+	a.mtx.Unlock()
+	if ok && last == count {
+		return
+	}
+
+	client, err := a.serviceRegistry.NseRegistryClient(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to advertise connections count of %s: %v", name, err)
+		return
+	}
+
+	reg := proto.Clone(endpoint.Endpoint).(*registry.NSERegistration)
+	reg.NetworkServiceEndpoint.ConnectionCount = uint32(count)
+
+	if _, err := client.RegisterNSE(ctx, reg); err != nil {
+		logrus.Errorf("Failed to advertise connections count of %s: %v", name, err)
+		return
+	}
+	logrus.Infof("Advertised %d connections of endpoint %s", count, name)
+
+	a.mtx.Lock()
+	a.advertised[name] = count
+	a.mtx.Unlock()
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsmd
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/registry"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/model"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/serviceregistry"
+)
+
+type nseRegistryClientStub struct {
+	mtx        sync.Mutex
+	advertised []uint32
+
+	registry.NetworkServiceRegistryClient
+}
+
+func (stub *nseRegistryClientStub) RegisterNSE(ctx context.Context, in *registry.NSERegistration, opts ...grpc.CallOption) (*registry.NSERegistration, error) {
+	stub.mtx.Lock()
+	defer stub.mtx.Unlock()
+
+	stub.advertised = append(stub.advertised, in.GetNetworkServiceEndpoint().GetConnectionCount())
+	return in, nil
+}
+
+func (stub *nseRegistryClientStub) getAdvertised() []uint32 {
+	stub.mtx.Lock()
+	defer stub.mtx.Unlock()
+
+	return append([]uint32(nil), stub.advertised...)
+}
+
+type advertiserRegistryStub struct {
+	client *nseRegistryClientStub
+
+	serviceregistry.ServiceRegistry
+}
+
+func (stub *advertiserRegistryStub) NseRegistryClient(ctx context.Context) (registry.NetworkServiceRegistryClient, error) {
+	return stub.client, nil
+}
+
+func TestConnectionCountAdvertiserDebounce(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mdl := model.NewModel()
+	mdl.SetNsm(&registry.NetworkServiceManager{Name: "nsm"})
+	nse := &registry.NSERegistration{
+		NetworkService: &registry.NetworkService{Name: "ns"},
+		NetworkServiceEndpoint: &registry.NetworkServiceEndpoint{
+			Name:                      "nse",
+			NetworkServiceManagerName: "nsm",
+		},
+	}
+	mdl.AddEndpoint(ctx, &model.Endpoint{Endpoint: nse})
+
+	registryStub := &advertiserRegistryStub{client: &nseRegistryClientStub{}}
+	mdl.AddListener(newConnectionCountAdvertiser(ctx, mdl, registryStub, 200*time.Millisecond))
+
+	// Connections added during the delay are advertised with a single update
+	for _, id := range []string{"1", "2", "3"} {
+		mdl.AddClientConnection(ctx, &model.ClientConnection{ConnectionID: id, Endpoint: nse})
+	}
+	g.Eventually(registryStub.client.getAdvertised, time.Second).Should(Equal([]uint32{3}))
+	g.Consistently(registryStub.client.getAdvertised, 300*time.Millisecond).Should(Equal([]uint32{3}))
+
+	// Count returned to the advertised value is not advertised again
+	mdl.DeleteClientConnection(ctx, "3")
+	mdl.AddClientConnection(ctx, &model.ClientConnection{ConnectionID: "4", Endpoint: nse})
+	g.Consistently(registryStub.client.getAdvertised, 500*time.Millisecond).Should(Equal([]uint32{3}))
+
+	mdl.DeleteClientConnection(ctx, "4")
+	g.Eventually(registryStub.client.getAdvertised, time.Second).Should(Equal([]uint32{3, 2}))
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"net"
 	"path"
+	"sync"
 	"time"
 
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools/spanhelper"
@@ -33,6 +34,7 @@ import (
 	forwarderapi "github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
 	forwarderregistrarapi "github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarderregistrar"
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
+	"github.com/networkservicemesh/networkservicemesh/utils"
 )
 
 const (
@@ -42,6 +44,13 @@ const (
 	ForwarderRegistrarSocket = "nsm.forwarder-registrar.io.sock"
 	socketMask               = 0077
 	livenessInterval         = 5
+
+	// RestoredForwarderTimeoutEnv - environment variable name - time forwarders restored from the model store are
+	// kept waiting to register again, the ones not registered are deleted
+	RestoredForwarderTimeoutEnv = utils.EnvVar("NSMD_RESTORED_FORWARDER_TIMEOUT")
+	// DefaultRestoredForwarderTimeout - default time forwarders restored from the model store are kept waiting to
+	// register again
+	DefaultRestoredForwarderTimeout = 2 * time.Minute
 )
 
 // ForwarderRegistrarServer - NSMgr registration service
@@ -50,6 +59,11 @@ type ForwarderRegistrarServer struct {
 	grpcServer                   *grpc.Server
 	forwarderRegistrarSocketPath string
 	sock                         net.Listener
+	// restoredForwarders - forwarders restored from the model store and expected to register again
+	restoredForwarders map[string]bool
+	// restoredTimer - deletes restored forwarders not registered again in time
+	restoredTimer *time.Timer
+	sync.Mutex
 }
 
 // forwarderMonitor is per registered forwarder monitoring routine. It creates a grpc client
@@ -113,6 +127,18 @@ func (r *ForwarderRegistrarServer) RequestLiveness(liveness forwarderregistrarap
 // RequestForwarderRegistration - request forwarder to be registered.
 func (r *ForwarderRegistrarServer) RequestForwarderRegistration(ctx context.Context, req *forwarderregistrarapi.ForwarderRegistrationRequest) (*forwarderregistrarapi.ForwarderRegistrationReply, error) {
 	logrus.Infof("Received new forwarder registration requests from %s", req.ForwarderName)
+	// Forwarder restored from the model store registers again after NSMD restart
+	if r.takeRestoredForwarder(req.ForwarderName, req.ForwarderSocket) {
+		logrus.Infof("Forwarder %s restored from the model store is registered again", req.ForwarderName)
+		r.model.UpdateForwarder(ctx, &model.Forwarder{
+			RegisteredName: req.ForwarderName,
+			SocketLocation: req.ForwarderSocket,
+			Capacity:       req.GetCapacity(),
+			Labels:         req.GetLabels(),
+		})
+		go forwarderMonitor(r.model, req.ForwarderName)
+		return &forwarderregistrarapi.ForwarderRegistrationReply{Registered: true}, nil
+	}
 	// Need to check if name of forwarder already exists in the object store
 	if r.model.GetForwarder(req.ForwarderName) != nil {
 		logrus.Errorf("forwarder with name %s already exist", req.ForwarderName)
@@ -123,6 +149,8 @@ func (r *ForwarderRegistrarServer) RequestForwarderRegistration(ctx context.Cont
 	forwarder := &model.Forwarder{
 		RegisteredName: req.ForwarderName,
 		SocketLocation: req.ForwarderSocket,
+		Capacity:       req.GetCapacity(),
+		Labels:         req.GetLabels(),
 	}
 
 	r.model.AddForwarder(ctx, forwarder)
@@ -135,6 +163,52 @@ func (r *ForwarderRegistrarServer) RequestForwarderRegistration(ctx context.Cont
 	return &forwarderregistrarapi.ForwarderRegistrationReply{Registered: true}, nil
 }
 
+func (r *ForwarderRegistrarServer) takeRestoredForwarder(name, socket string) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	if !r.restoredForwarders[name] {
+		return false
+	}
+	if forwarder := r.model.GetForwarder(name); forwarder == nil || forwarder.SocketLocation != socket {
+		return false
+	}
+	delete(r.restoredForwarders, name)
+	return true
+}
+
+// expireRestoredForwarders deletes restored forwarders not registered again after the timeout, so they are not
+// selected for the new connections
+func (r *ForwarderRegistrarServer) expireRestoredForwarders(timeout time.Duration) {
+	r.Lock()
+	defer r.Unlock()
+
+	if len(r.restoredForwarders) == 0 {
+		return
+	}
+	r.restoredTimer = time.AfterFunc(timeout, func() {
+		r.Lock()
+		expired := r.restoredForwarders
+		r.restoredForwarders = map[string]bool{}
+		r.Unlock()
+
+		for name := range expired {
+			logrus.Warnf("Forwarder %s restored from the model store is not registered again in %v, deleting it", name, timeout)
+			r.model.DeleteForwarder(context.Background(), name)
+		}
+	})
+}
+
+// restoredForwarders returns forwarders known at start, they are restored from the model store
+func restoredForwarders(m model.Model) map[string]bool {
+	rv := map[string]bool{}
+	_, _ = m.SelectForwarder(func(dp *model.Forwarder) bool {
+		rv[dp.RegisteredName] = true
+		return false
+	})
+	return rv
+}
+
 // RequestForwarderUnRegistration - request forwarder to be unregistered
 func (r *ForwarderRegistrarServer) RequestForwarderUnRegistration(ctx context.Context, req *forwarderregistrarapi.ForwarderUnRegistrationRequest) (*forwarderregistrarapi.ForwarderUnRegistrationReply, error) {
 	logrus.Infof("Received forwarder un-registration requests from %s", req.ForwarderName)
@@ -194,6 +268,12 @@ func (r *ForwarderRegistrarServer) startForwarderRegistrarServer(ctx context.Con
 func (r *ForwarderRegistrarServer) Stop() {
 	r.grpcServer.Stop() // We do not need to do it gracefully, to speedup forwarder termination.
 	_ = r.sock.Close()
+
+	r.Lock()
+	if r.restoredTimer != nil {
+		r.restoredTimer.Stop()
+	}
+	r.Unlock()
 }
 
 // StartForwarderRegistrarServer -  registers and starts gRPC server which is listening for
@@ -207,7 +287,9 @@ func StartForwarderRegistrarServer(ctx context.Context, model model.Model) (*For
 		grpcServer:                   server,
 		forwarderRegistrarSocketPath: path.Join(ForwarderRegistrarSocketBaseDir, ForwarderRegistrarSocket),
 		model:                        model,
+		restoredForwarders:           restoredForwarders(model),
 	}
+	forwarderRegistrarServer.expireRestoredForwarders(RestoredForwarderTimeoutEnv.GetOrDefaultDuration(DefaultRestoredForwarderTimeout))
 
 	var err error
 	// Starting forwarder registrar server, if it fails to start, inform Plugin by returning error
@@ -0,0 +1,129 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/model"
+	forwarderregistrarapi "github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarderregistrar"
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
+)
+
+type forwarderAddedListener struct {
+	model.ListenerImpl
+	added chan string
+}
+
+func (l *forwarderAddedListener) ForwarderAdded(_ context.Context, dp *model.Forwarder) {
+	l.added <- dp.RegisteredName
+}
+
+func TestRestoredForwarderRegistration(t *testing.T) {
+	_ = os.Setenv(tools.InsecureEnv, "true")
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "nsmd-forwarders")
+	g.Expect(err).To(BeNil())
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	store, err := model.NewFileStore(path.Join(dir, "model"))
+	g.Expect(err).To(BeNil())
+	defer func() { _ = store.Close() }()
+
+	socket := path.Join(dir, "dp1.sock")
+	before := model.NewModel()
+	g.Expect(before.Restore(store)).To(BeNil())
+	before.AddForwarder(context.Background(), &model.Forwarder{
+		RegisteredName: "dp1",
+		SocketLocation: socket,
+	})
+
+	// NSMD restart
+	mdl := model.NewModel()
+	g.Expect(mdl.Restore(store)).To(BeNil())
+	listener := &forwarderAddedListener{added: make(chan string, 10)}
+	mdl.AddListener(listener)
+
+	server := &ForwarderRegistrarServer{
+		model:              mdl,
+		restoredForwarders: restoredForwarders(mdl),
+	}
+	g.Expect(server.restoredForwarders).To(HaveKey("dp1"))
+
+	reply, err := server.RequestForwarderRegistration(context.Background(), &forwarderregistrarapi.ForwarderRegistrationRequest{
+		ForwarderName:   "dp1",
+		ForwarderSocket: socket,
+	})
+	g.Expect(err).To(BeNil())
+	g.Expect(reply.Registered).To(BeTrue())
+	g.Eventually(listener.added).Should(Receive(Equal("dp1")))
+	g.Expect(server.restoredForwarders).To(BeEmpty())
+}
+
+type forwarderDeletedListener struct {
+	model.ListenerImpl
+	deleted chan string
+}
+
+func (l *forwarderDeletedListener) ForwarderDeleted(_ context.Context, dp *model.Forwarder) {
+	l.deleted <- dp.RegisteredName
+}
+
+func TestRestoredForwarderExpiration(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "nsmd-forwarders")
+	g.Expect(err).To(BeNil())
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	store, err := model.NewFileStore(path.Join(dir, "model"))
+	g.Expect(err).To(BeNil())
+	defer func() { _ = store.Close() }()
+
+	before := model.NewModel()
+	g.Expect(before.Restore(store)).To(BeNil())
+	for _, name := range []string{"dp1", "dp2"} {
+		before.AddForwarder(context.Background(), &model.Forwarder{
+			RegisteredName: name,
+			SocketLocation: path.Join(dir, name+".sock"),
+		})
+	}
+
+	// NSMD restart, only dp1 registers again
+	mdl := model.NewModel()
+	g.Expect(mdl.Restore(store)).To(BeNil())
+	listener := &forwarderDeletedListener{deleted: make(chan string, 10)}
+	mdl.AddListener(listener)
+
+	server := &ForwarderRegistrarServer{
+		model:              mdl,
+		restoredForwarders: restoredForwarders(mdl),
+	}
+	server.expireRestoredForwarders(100 * time.Millisecond)
+	g.Expect(server.takeRestoredForwarder("dp1", path.Join(dir, "dp1.sock"))).To(BeTrue())
+
+	g.Eventually(listener.deleted).Should(Receive(Equal("dp2")))
+	g.Expect(mdl.GetForwarder("dp2")).To(BeNil())
+	g.Expect(mdl.GetForwarder("dp1")).NotTo(BeNil())
+	g.Consistently(listener.deleted).ShouldNot(Receive())
+}
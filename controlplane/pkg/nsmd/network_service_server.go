@@ -16,6 +16,7 @@ func NewNetworkServiceServer(model model.Model, ws *Workspace,
 		common.NewMonitorService(ws.MonitorConnectionServer()),
 		local.NewWorkspaceService(ws.Name()),
 		local.NewConnectionService(model),
+		common.NewAdmissionService(nsmManager.AdmissionController()),
 		local.NewForwarderService(model, nsmManager.ServiceRegistry()),
 		local.NewEndpointSelectorService(nsmManager.NseManager()),
 		common.NewExcludedPrefixesService(),
@@ -97,6 +97,10 @@ func (es *registryServer) RegisterNSEWithClient(ctx context.Context, request *re
 	request.NetworkServiceManager = &registry.NetworkServiceManager{
 		Url: es.nsm.serviceRegistry.GetPublicAPI(),
 	}
+	// Endpoint registered again keeps advertising the connections it is serving
+	if nse := request.GetNetworkServiceEndpoint(); nse != nil && nse.GetName() != "" {
+		nse.ConnectionCount = uint32(es.nsm.model.ConnectionCount(nse))
+	}
 
 	registration, err := client.RegisterNSE(ctx, request)
 	if err != nil {
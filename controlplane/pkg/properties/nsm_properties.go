@@ -16,6 +16,7 @@
 package properties
 
 import (
+	"math"
 	"os"
 	"strconv"
 	"time"
@@ -30,6 +31,30 @@ const (
 	NsmdHealDSTWaitTimeout = "NSMD_HEAL_DST_TIMEOUTs" // Wait timeout for DST in seconds
 	// NsmdHealRetryCount - amount of times healing will retry
 	NsmdHealRetryCount = "NSMD_HEAL_RETRY_COUNT"
+	// NsmdHealRetryDelay - environment variable name - initial delay between heal retries
+	NsmdHealRetryDelay = "NSMD_HEAL_RETRY_DELAY"
+	// NsmdHealRetryMaxDelay - environment variable name - maximum delay between heal retries
+	NsmdHealRetryMaxDelay = "NSMD_HEAL_RETRY_MAX_DELAY"
+	// NsmdHealRetryMultiplier - environment variable name - growth of the delays between heal retries
+	NsmdHealRetryMultiplier = "NSMD_HEAL_RETRY_MULTIPLIER"
+	// NsmdHealRetryJitter - environment variable name - fraction of the heal retry delays randomized
+	NsmdHealRetryJitter = "NSMD_HEAL_RETRY_JITTER"
+	// NsmdHealDSTWaitTick - environment variable name - initial delay between checks of NSE to re-appear
+	NsmdHealDSTWaitTick = "NSMD_HEAL_DST_WAIT_TICK"
+	// NsmdHealDSTWaitMaxTick - environment variable name - maximum delay between checks of NSE to re-appear
+	NsmdHealDSTWaitMaxTick = "NSMD_HEAL_DST_WAIT_MAX_TICK"
+	// NsmdRemoteHealMaxAttempts - environment variable name - maximum number of attempts to wait for remote connection heal
+	NsmdRemoteHealMaxAttempts = "NSMD_REMOTE_HEAL_MAX_ATTEMPTS"
+	// NsmdRemoteHealMaxTime - environment variable name - maximum time to retry waiting for remote connection heal
+	NsmdRemoteHealMaxTime = "NSMD_REMOTE_HEAL_MAX_TIME"
+	// NsmdMaxWorkspaceConnections - environment variable name - maximum number of connections of a workspace
+	NsmdMaxWorkspaceConnections = "NSMD_MAX_WORKSPACE_CONNECTIONS"
+	// NsmdMaxNetworkServiceConnections - environment variable name - maximum number of connections to a NetworkService
+	NsmdMaxNetworkServiceConnections = "NSMD_MAX_NETWORK_SERVICE_CONNECTIONS"
+	// NsmdMaxRemoteNsmConnections - environment variable name - maximum number of connections requested by a remote NSM
+	NsmdMaxRemoteNsmConnections = "NSMD_MAX_REMOTE_NSM_CONNECTIONS"
+	// NsmdIPsecKeyRotationPeriod - environment variable name - period of IPsec connections keys rotation
+	NsmdIPsecKeyRotationPeriod = "NSMD_IPSEC_KEY_ROTATION_PERIOD"
 )
 
 // Properties - holds properties of NSM connection events processing
@@ -40,14 +65,33 @@ type Properties struct {
 	HealRequestConnectTimeout      time.Duration
 	HealRetryCount                 int
 	HealRetryDelay                 time.Duration
+	HealRetryMaxDelay              time.Duration
 	HealRequestConnectCheckTimeout time.Duration
 	HealForwarderTimeout           time.Duration
 
 	// Total DST heal timeout is 20 seconds.
 	HealDSTNSEWaitTimeout time.Duration
 	HealDSTNSEWaitTick    time.Duration
+	HealDSTNSEWaitMaxTick time.Duration
+
+	// Growth of the retry delays, delays are multiplied after every retry until capped
+	HealRetryMultiplier float64
+	// Fraction of the retry delays randomized, so NSMDs do not retry in lockstep
+	HealRetryJitter float64
+
+	// Remote connection heal attempts, every attempt waits HealTimeout for the source side to heal the connection
+	RemoteHealMaxAttempts int
+	RemoteHealMaxTime     time.Duration
 
 	HealEnabled bool
+
+	// Connection quotas, zero means unlimited
+	MaxWorkspaceConnections      int
+	MaxNetworkServiceConnections int
+	MaxRemoteNsmConnections      int
+
+	// Period of IPsec connections keys rotation, zero disables rotation
+	IPsecKeyRotationPeriod time.Duration
 }
 
 // NewNsmProperties creates NsmProperties with defined default values and reading values from environment variables
@@ -61,11 +105,20 @@ func NewNsmProperties() *Properties {
 		HealForwarderTimeout:           time.Minute * 1,
 		HealRetryCount:                 10,
 		HealRetryDelay:                 time.Second * 5,
+		HealRetryMaxDelay:              time.Second * 30,
 
 		// Total DST heal timeout is 20 seconds.
 		HealDSTNSEWaitTimeout: time.Second * 30,       // Maximum time to wait for NSMD/NSE to re-appear
 		HealDSTNSEWaitTick:    500 * time.Millisecond, // Wait timeout to appear of NSE
+		HealDSTNSEWaitMaxTick: time.Second * 4,        // Wait timeout grows up to
+		HealRetryMultiplier:   2,
+		HealRetryJitter:       0.3,
 		HealEnabled:           true,
+
+		RemoteHealMaxAttempts: 3,
+		RemoteHealMaxTime:     time.Minute * 5,
+
+		IPsecKeyRotationPeriod: time.Hour,
 	}
 
 	// Parse few Environment variables.
@@ -92,5 +145,83 @@ func NewNsmProperties() *Properties {
 		values.HealRetryCount = int(value)
 	}
 
+	values.MaxWorkspaceConnections = parseQuota(NsmdMaxWorkspaceConnections)
+	values.MaxNetworkServiceConnections = parseQuota(NsmdMaxNetworkServiceConnections)
+	values.MaxRemoteNsmConnections = parseQuota(NsmdMaxRemoteNsmConnections)
+
+	parseDuration(NsmdHealRetryDelay, &values.HealRetryDelay)
+	parseDuration(NsmdHealRetryMaxDelay, &values.HealRetryMaxDelay)
+	parseDuration(NsmdHealDSTWaitTick, &values.HealDSTNSEWaitTick)
+	parseDuration(NsmdHealDSTWaitMaxTick, &values.HealDSTNSEWaitMaxTick)
+	parseFloat(NsmdHealRetryMultiplier, &values.HealRetryMultiplier, 1, math.MaxFloat64)
+	parseFloat(NsmdHealRetryJitter, &values.HealRetryJitter, 0, 1)
+	parseAttempts(NsmdRemoteHealMaxAttempts, &values.RemoteHealMaxAttempts)
+	parseDuration(NsmdRemoteHealMaxTime, &values.RemoteHealMaxTime)
+
+	parseDuration(NsmdIPsecKeyRotationPeriod, &values.IPsecKeyRotationPeriod)
+
 	return values
 }
+
+// parseDuration overrides the value with the environment variable if it is set and valid
+func parseDuration(env string, value *time.Duration) {
+	durationVal := os.Getenv(env)
+	if durationVal == "" {
+		return
+	}
+	duration, err := time.ParseDuration(durationVal)
+	if err != nil {
+		logrus.Errorf("Failed to parse %s value, using default %v... %v", env, *value, err)
+		return
+	}
+	if duration < 0 {
+		logrus.Errorf("Negative %s value %v, using default %v", env, duration, *value)
+		return
+	}
+	*value = duration
+}
+
+// parseFloat overrides the value with the environment variable if it is set and within [min, max]
+func parseFloat(env string, value *float64, min, max float64) {
+	floatVal := os.Getenv(env)
+	if floatVal == "" {
+		return
+	}
+	parsed, err := strconv.ParseFloat(floatVal, 64)
+	if err != nil {
+		logrus.Errorf("Failed to parse %s value, using default %v... %v", env, *value, err)
+		return
+	}
+	if parsed < min || parsed > max {
+		logrus.Errorf("%s value %v is out of [%v, %v], using default %v", env, parsed, min, max, *value)
+		return
+	}
+	*value = parsed
+}
+
+// parseAttempts overrides the value with the environment variable if it is set and is a positive number
+func parseAttempts(env string, value *int) {
+	attemptsVal := os.Getenv(env)
+	if attemptsVal == "" {
+		return
+	}
+	parsed, err := strconv.ParseUint(attemptsVal, 10, 31)
+	if err != nil || parsed == 0 {
+		logrus.Errorf("Invalid %s value %q, using default %v... %v", env, attemptsVal, *value, err)
+		return
+	}
+	*value = int(parsed)
+}
+
+func parseQuota(env string) int {
+	quotaVal := os.Getenv(env)
+	if quotaVal == "" {
+		return 0
+	}
+	value, err := strconv.ParseUint(quotaVal, 10, 31)
+	if err != nil {
+		logrus.Errorf("Failed to parse %s value, connections are not limited... %v", env, err)
+		return 0
+	}
+	return int(value)
+}
@@ -50,6 +50,12 @@ func (cce *connectionService) Request(ctx context.Context, request *networkservi
 	id := request.GetRequestConnection().GetId()
 	span.LogValue("connection-id", id)
 
+	healPolicy, err := cce.model.GetHealPolicies().RequestPolicy(request)
+	if err != nil {
+		span.LogError(err)
+		return nil, err
+	}
+
 	// We need to take updated connection in case of updates
 	clientConnection := cce.model.GetClientConnection(id)
 	if clientConnection != nil {
@@ -70,8 +76,9 @@ func (cce *connectionService) Request(ctx context.Context, request *networkservi
 		cce.model.AddClientConnection(ctx, clientConnection)
 	}
 
-	// 8. Remember original Request for Heal cases.
+	// 8. Remember original Request and heal policy for Heal cases.
 	clientConnection.Request = request
+	clientConnection.HealPolicy = healPolicy
 	ctx = common.WithModelConnection(ctx, clientConnection)
 
 	conn, err := common.ProcessNext(ctx, request)
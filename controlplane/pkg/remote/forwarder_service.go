@@ -20,12 +20,16 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/geneve"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/gre"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/ipsec"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/srv6"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
@@ -34,6 +38,7 @@ import (
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/common"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/model"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/serviceregistry"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/vni"
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools/spanhelper"
 	"github.com/networkservicemesh/networkservicemesh/utils"
 )
@@ -58,15 +63,9 @@ type forwarderService struct {
 }
 
 func (cce *forwarderService) selectForwarder(request *networkservice.NetworkServiceRequest) (*model.Forwarder, error) {
-	dp, err := cce.model.SelectForwarder(func(dp *model.Forwarder) bool {
-		for _, m := range request.GetRequestMechanismPreferences() {
-			if cce.findMechanism(dp.RemoteMechanisms, m.GetType()) != nil {
-				return true
-			}
-		}
-		return false
+	return cce.model.SelectRequestForwarder(request, func(dp *model.Forwarder) []*connection.Mechanism {
+		return dp.RemoteMechanisms
 	})
-	return dp, err
 }
 func (cce *forwarderService) findMechanism(mechanismPreferences []*connection.Mechanism, mechanismType string) *connection.Mechanism {
 	for _, m := range mechanismPreferences {
@@ -113,44 +112,50 @@ func (cce *forwarderService) selectRemoteMechanism(request *networkservice.Netwo
 	dpParameters := dpMechanism.GetParameters()
 
 	switch mechanism.GetType() {
-	case vxlan.MECHANISM:
-		cce.configureVXLANParameters(parameters, dpParameters)
+	case vxlan.MECHANISM, gre.MECHANISM, geneve.MECHANISM:
+		if err := cce.configureTunnelParameters(mechanism.GetType(), parameters, dpParameters); err != nil {
+			return nil, err
+		}
 
 	case srv6.MECHANISM:
 		cce.configureSRv6Parameters(connectionID, parameters, dpParameters)
 
 	case wireguard.MECHANISM:
 		cce.configureWireguardParameters(connectionID, parameters, dpParameters)
+
+	case ipsec.MECHANISM:
+		if err := cce.configureIPsecParameters(parameters, dpParameters); err != nil {
+			return nil, err
+		}
 	}
 
 	logrus.Infof("NSM:(5.1) Remote mechanism selected %v", mechanism)
 	return mechanism, nil
 }
 
-func (cce *forwarderService) configureVXLANParameters(parameters, dpParameters map[string]string) {
+// configureTunnelParameters - sets destination IP and allocates VNI or key of the VXLAN, Geneve or GRE tunnel
+func (cce *forwarderService) configureTunnelParameters(mechanismType string, parameters, dpParameters map[string]string) error {
 	parameters[vxlan.DstIP] = dpParameters[vxlan.SrcIP]
 
-	extSrcIP := parameters[vxlan.SrcIP]
-	extDstIP := dpParameters[vxlan.SrcIP]
-	srcIP := parameters[vxlan.SrcIP]
-	dstIP := dpParameters[vxlan.SrcIP]
-
-	if ip, ok := parameters[vxlan.SrcOriginalIP]; ok {
-		srcIP = ip
+	localIP, remoteIP := vni.Peers(parameters)
+	vniID, err := cce.serviceRegistry.VniAllocator().Vni(localIP, remoteIP)
+	if err != nil {
+		return err
 	}
 
-	if ip, ok := parameters[vxlan.DstExternalIP]; ok {
-		extDstIP = ip
-	}
+	vniParameter, _ := vni.Parameter(mechanismType)
+	parameters[vniParameter] = strconv.FormatUint(uint64(vniID), 10)
+	return nil
+}
 
-	var vni uint32
-	if extDstIP != extSrcIP {
-		vni = cce.serviceRegistry.VniAllocator().Vni(extDstIP, extSrcIP)
-	} else {
-		vni = cce.serviceRegistry.VniAllocator().Vni(dstIP, srcIP)
+// releaseVNI - returns VNI or key of the remote VXLAN, Geneve or GRE connection back to the allocator
+func (cce *forwarderService) releaseVNI(mechanism *connection.Mechanism) {
+	vniID, ok, err := vni.FromMechanism(mechanism)
+	if !ok || err != nil {
+		return
 	}
-
-	parameters[vxlan.VNI] = strconv.FormatUint(uint64(vni), 10)
+	localIP, remoteIP := vni.Peers(mechanism.GetParameters())
+	cce.serviceRegistry.VniAllocator().Release(localIP, remoteIP, vniID)
 }
 
 func (cce *forwarderService) configureSRv6Parameters(connectionID string, parameters, dpParameters map[string]string) {
@@ -175,6 +180,14 @@ func (cce *forwarderService) configureWireguardParameters(connectionID string, p
 	parameters[wireguard.DstPort] = wireguard.AssignPort(connectionID)
 }
 
+// configureIPsecParameters - sets destination IP and public key of the destination forwarder and generates SPI of
+// the security association to the destination host
+func (cce *forwarderService) configureIPsecParameters(parameters, dpParameters map[string]string) error {
+	parameters[ipsec.DstIP] = dpParameters[ipsec.SrcIP]
+	parameters[ipsec.DstPublicKey] = dpParameters[ipsec.SrcPublicKey]
+	return ipsec.SetDstSPI(parameters)
+}
+
 func (cce *forwarderService) updateMechanism(request *networkservice.NetworkServiceRequest, dp *model.Forwarder) error {
 	conn := request.GetConnection()
 	// 5.x
@@ -212,6 +225,9 @@ func (cce *forwarderService) Request(ctx context.Context, request *networkservic
 		return nil, err
 	}
 
+	// Mechanism of the existing connection in case of Heal/Update, its VNI should be released once replaced
+	previousMechanism := clientConnection.GetConnectionSource().GetMechanism()
+
 	// 5. Select a local forwarder and put it into conn object
 	err = cce.updateMechanism(request, dp)
 	if err != nil {
@@ -226,10 +242,19 @@ func (cce *forwarderService) Request(ctx context.Context, request *networkservic
 	conn, connErr := common.ProcessNext(ctx, request)
 	if connErr != nil {
 		cce.doFailureClose(ctx)
+		cce.releaseVNI(request.GetConnection().GetMechanism())
 		return conn, connErr
 	}
 	// We need to program forwarder.
-	return cce.programForwarder(ctx, conn, dp, clientConnection)
+	conn, err = cce.programForwarder(ctx, conn, dp, clientConnection)
+	if err != nil {
+		cce.releaseVNI(request.GetConnection().GetMechanism())
+		return conn, err
+	}
+	if !proto.Equal(previousMechanism, request.GetConnection().GetMechanism()) {
+		cce.releaseVNI(previousMechanism)
+	}
+	return conn, nil
 }
 
 func (cce *forwarderService) doFailureClose(ctx context.Context) {
@@ -258,6 +283,7 @@ func (cce *forwarderService) Close(ctx context.Context, conn *connection.Connect
 	if closeErr := cce.performClose(ctx, cc, logger); closeErr != nil {
 		logger.Errorf("Failed to close: %v", closeErr)
 	}
+	cce.releaseVNI(cc.GetConnectionSource().GetMechanism())
 	return empt, err
 }
 
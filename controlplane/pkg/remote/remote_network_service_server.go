@@ -27,6 +27,7 @@ func NewRemoteNetworkServiceServer(manager nsm.NetworkServiceManager, connection
 		common.NewRequestValidator(),
 		common.NewMonitorService(connectionMonitor),
 		NewConnectionService(manager.Model()),
+		common.NewAdmissionService(manager.AdmissionController()),
 		NewForwarderService(manager.Model(), manager.ServiceRegistry()),
 		NewEndpointSelectorService(manager.NseManager(), manager.Model()),
 		common.NewExcludedPrefixesService(),
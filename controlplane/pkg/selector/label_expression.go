@@ -0,0 +1,202 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Label selector operators. Selector value of the form `Operator(arguments)` is treated as a set-based
+// requirement on the label, any other value requires label to be equal to it. Kubernetes label values can't
+// contain parentheses, so plain values can't be mistaken for expressions.
+//
+//	zone: "In(us-east, us-west)"  - label is present and equals to one of the values
+//	zone: "NotIn(us-east)"        - label is absent or doesn't equal to any of the values
+//	zone: "Exists()"              - label is present with any value
+//	zone: "DoesNotExist()"        - label is absent
+//	zone: "Regex(us-.*)"          - label is present and fully matches the regular expression
+const (
+	OperatorIn           = "In"
+	OperatorNotIn        = "NotIn"
+	OperatorExists       = "Exists"
+	OperatorDoesNotExist = "DoesNotExist"
+	OperatorRegex        = "Regex"
+
+	operatorEquals = "="
+)
+
+// labelRequirement is a single requirement of label selector on the label with the given key
+type labelRequirement struct {
+	key      string
+	operator string
+	values   map[string]bool
+	regex    *regexp.Regexp
+}
+
+// splitLabelExpression splits selector value of the form `Operator(arguments)` into operator and arguments
+func splitLabelExpression(value string) (operator, args string, ok bool) {
+	value = strings.TrimSpace(value)
+	open := strings.Index(value, "(")
+	if open <= 0 || !strings.HasSuffix(value, ")") {
+		return "", "", false
+	}
+	for _, c := range value[:open] {
+		if !unicode.IsLetter(c) {
+			return "", "", false
+		}
+	}
+	return value[:open], strings.TrimSpace(value[open+1 : len(value)-1]), true
+}
+
+// parseLabelRequirement parses selector value into the requirement, values without operator
+// are parsed as equality requirement
+func parseLabelRequirement(key, value string) (*labelRequirement, error) {
+	operator, args, ok := splitLabelExpression(value)
+	if !ok {
+		return &labelRequirement{
+			key:      key,
+			operator: operatorEquals,
+			values:   map[string]bool{value: true},
+		}, nil
+	}
+
+	r := &labelRequirement{
+		key:    key,
+		values: map[string]bool{},
+	}
+	switch {
+	case strings.EqualFold(operator, OperatorIn), strings.EqualFold(operator, OperatorNotIn):
+		r.operator = OperatorIn
+		if strings.EqualFold(operator, OperatorNotIn) {
+			r.operator = OperatorNotIn
+		}
+		for _, v := range strings.Split(args, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				r.values[v] = true
+			}
+		}
+		if len(r.values) == 0 {
+			return nil, errors.Errorf("label %s: operator %s requires at least one value", key, operator)
+		}
+	case strings.EqualFold(operator, OperatorExists), strings.EqualFold(operator, OperatorDoesNotExist):
+		r.operator = OperatorExists
+		if strings.EqualFold(operator, OperatorDoesNotExist) {
+			r.operator = OperatorDoesNotExist
+		}
+		if args != "" {
+			return nil, errors.Errorf("label %s: operator %s doesn't accept values", key, operator)
+		}
+	case strings.EqualFold(operator, OperatorRegex):
+		regex, err := regexp.Compile("^(?:" + args + ")$")
+		if err != nil {
+			return nil, errors.Wrapf(err, "label %s: invalid regular expression %s", key, args)
+		}
+		r.operator = OperatorRegex
+		r.regex = regex
+	default:
+		return nil, errors.Errorf("label %s: unknown operator %s", key, operator)
+	}
+	return r, nil
+}
+
+// matches checks if labels satisfy the requirement
+func (r *labelRequirement) matches(labels map[string]string) bool {
+	value, exists := labels[r.key]
+	switch r.operator {
+	case operatorEquals:
+		return r.values[value]
+	case OperatorIn:
+		return exists && r.values[value]
+	case OperatorNotIn:
+		return !exists || !r.values[value]
+	case OperatorExists:
+		return exists
+	case OperatorDoesNotExist:
+		return !exists
+	case OperatorRegex:
+		return exists && r.regex.MatchString(value)
+	}
+	return false
+}
+
+// requirementCache keeps parsed label expressions, so regular expressions are compiled once
+type requirementCache struct {
+	requirements sync.Map
+}
+
+func newRequirementCache() *requirementCache {
+	return &requirementCache{}
+}
+
+// parse returns requirement of the selector value, parsed label expressions are cached by label key and value
+func (c *requirementCache) parse(key, value string) (*labelRequirement, error) {
+	if _, _, ok := splitLabelExpression(value); !ok {
+		// Equality requirements are cheap to create, don't keep every requested label value
+		return parseLabelRequirement(key, value)
+	}
+	cacheKey := key + "=" + value
+	if r, ok := c.requirements.Load(cacheKey); ok {
+		return r.(*labelRequirement), nil
+	}
+
+	r, err := parseLabelRequirement(key, value)
+	if err != nil {
+		return nil, err
+	}
+	c.requirements.Store(cacheKey, r)
+	return r, nil
+}
+
+// LabelSelector is a parsed label selector, label expressions are parsed once and reused for every match
+type LabelSelector struct {
+	requirements []*labelRequirement
+}
+
+// ParseLabelSelector parses all requirements of the selector, selector values could be label expressions
+func ParseLabelSelector(selector map[string]string) (*LabelSelector, error) {
+	s := &LabelSelector{}
+	for key, value := range selector {
+		requirement, err := parseLabelRequirement(key, value)
+		if err != nil {
+			return nil, err
+		}
+		s.requirements = append(s.requirements, requirement)
+	}
+	return s, nil
+}
+
+// Matches checks if labels satisfy all requirements of the selector
+func (s *LabelSelector) Matches(labels map[string]string) bool {
+	for _, requirement := range s.requirements {
+		if !requirement.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchLabels checks if labels satisfy all requirements of the selector, selector values could be label expressions
+func MatchLabels(labels, selector map[string]string) (bool, error) {
+	s, err := ParseLabelSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	return s.Matches(labels), nil
+}
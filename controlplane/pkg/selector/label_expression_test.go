@@ -0,0 +1,175 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/registry"
+)
+
+func Test_isSubset_LabelExpressions(t *testing.T) {
+	labels := map[string]string{
+		"app":  "firewall",
+		"zone": "us-east",
+	}
+	tests := []struct {
+		name     string
+		selector map[string]string
+		want     bool
+		wantErr  bool
+	}{
+		{"equals", map[string]string{"app": "firewall"}, true, false},
+		{"equals mismatch", map[string]string{"app": "vpn"}, false, false},
+		{"in", map[string]string{"zone": "In(us-west, us-east)"}, true, false},
+		{"in mismatch", map[string]string{"zone": "In(us-west, eu-west)"}, false, false},
+		{"in absent", map[string]string{"region": "In(us)"}, false, false},
+		{"notin", map[string]string{"zone": "NotIn(us-west)"}, true, false},
+		{"notin mismatch", map[string]string{"zone": "NotIn(us-east)"}, false, false},
+		{"notin absent", map[string]string{"region": "NotIn(us)"}, true, false},
+		{"exists", map[string]string{"zone": "Exists()"}, true, false},
+		{"exists absent", map[string]string{"region": "Exists()"}, false, false},
+		{"does not exist", map[string]string{"region": "DoesNotExist()"}, true, false},
+		{"does not exist present", map[string]string{"zone": "DoesNotExist()"}, false, false},
+		{"regex", map[string]string{"zone": "Regex(us-.*)"}, true, false},
+		{"regex is anchored", map[string]string{"zone": "Regex(east)"}, false, false},
+		{"case insensitive operator", map[string]string{"zone": "notin(eu-west)"}, true, false},
+		{"template in expression", map[string]string{"zone": "In({{index . \"zone\"}})"}, true, false},
+		{"all requirements", map[string]string{"app": "firewall", "zone": "NotIn(us-east)"}, false, false},
+		{"unknown operator", map[string]string{"zone": "Like(us-east)"}, false, true},
+		{"invalid regex", map[string]string{"zone": "Regex(us-(east)"}, false, true},
+		{"in without values", map[string]string{"zone": "In()"}, false, true},
+	}
+	m := &matchSelector{templates: newTemplateCache(), requirements: newRequirementCache()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.isSubset(labels, tt.selector, labels)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("isSubset(%v) error = %v, wantErr %v", tt.selector, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("isSubset(%v) = %v, want %v", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_requirementCache_ParsesExpressionOnce(t *testing.T) {
+	c := newRequirementCache()
+
+	first, err := c.parse("zone", "Regex(us-.*)")
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	second, err := c.parse("zone", "Regex(us-.*)")
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	if first != second || first.regex == nil {
+		t.Errorf("parse() should return the cached requirement with compiled regular expression")
+	}
+	if other, _ := c.parse("region", "Regex(us-.*)"); other == first {
+		t.Errorf("parse() should not share requirements between label keys")
+	}
+
+	if _, err := c.parse("zone", "Regex(us-(east)"); err == nil {
+		t.Errorf("parse() should fail on invalid regular expression")
+	}
+	if _, ok := c.requirements.Load("zone=Regex(us-(east)"); ok {
+		t.Errorf("invalid requirement should not be cached")
+	}
+	if _, err := c.parse("app", "firewall"); err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	if _, ok := c.requirements.Load("app=firewall"); ok {
+		t.Errorf("equality requirement should not be cached")
+	}
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	s, err := ParseLabelSelector(map[string]string{"app": "firewall", "zone": "Regex(us-.*)"})
+	if err != nil {
+		t.Fatalf("ParseLabelSelector() error = %v", err)
+	}
+	if !s.Matches(map[string]string{"app": "firewall", "zone": "us-east"}) {
+		t.Errorf("Matches() should match labels satisfying all requirements")
+	}
+	if s.Matches(map[string]string{"app": "firewall", "zone": "eu-west"}) {
+		t.Errorf("Matches() should not match labels not satisfying regular expression")
+	}
+	if _, err := ParseLabelSelector(map[string]string{"zone": "Regex(us-(east)"}); err == nil {
+		t.Errorf("ParseLabelSelector() should fail on invalid regular expression")
+	}
+}
+
+func Test_matchSelector_NotInSourceSelector(t *testing.T) {
+	ns := &registry.NetworkService{
+		Name: "firewall-ns",
+		Matches: []*registry.Match{
+			{
+				SourceSelector: map[string]string{
+					"zone": "NotIn(us-east)",
+				},
+				Routes: []*registry.Destination{
+					{
+						DestinationSelector: map[string]string{
+							"app": "generic-firewall",
+						},
+					},
+				},
+			},
+			{
+				Routes: []*registry.Destination{
+					{
+						DestinationSelector: map[string]string{
+							"app":  "firewall",
+							"zone": "Exists()",
+						},
+					},
+				},
+			},
+		},
+	}
+	endpoints := []*registry.NetworkServiceEndpoint{
+		{
+			Name:   "generic-firewall",
+			Labels: map[string]string{"app": "generic-firewall"},
+		},
+		{
+			Name:   "firewall-us-east",
+			Labels: map[string]string{"app": "firewall", "zone": "us-east"},
+		},
+	}
+	tests := []struct {
+		labels map[string]string
+		want   string
+	}{
+		{map[string]string{"zone": "us-east"}, "firewall-us-east"},
+		{map[string]string{"zone": "eu-west"}, "generic-firewall"},
+		{map[string]string{}, "generic-firewall"},
+	}
+
+	m := NewMatchSelector()
+	for _, tt := range tests {
+		got, err := m.SelectEndpoint(&connection.Connection{Labels: tt.labels}, ns, endpoints)
+		if err != nil {
+			t.Fatalf("matchSelector.SelectEndpoint(%v) error = %v", tt.labels, err)
+		}
+		if got.GetName() != tt.want {
+			t.Errorf("matchSelector.SelectEndpoint(%v) = %v, want %v", tt.labels, got.GetName(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+const templateDelimiter = "{{"
+
+// templateCache keeps compiled label selector templates
+type templateCache struct {
+	templates sync.Map
+}
+
+func newTemplateCache() *templateCache {
+	return &templateCache{}
+}
+
+// process executes selector template with the given variables, values without templates are returned as is
+func (c *templateCache) process(str string, vars interface{}) (string, error) {
+	if !strings.Contains(str, templateDelimiter) {
+		return str, nil
+	}
+	if tmpl, ok := c.templates.Load(str); ok {
+		return process(tmpl.(*template.Template), vars)
+	}
+
+	tmpl, err := parseTemplate(str)
+	if err != nil {
+		return "", err
+	}
+	c.templates.Store(str, tmpl)
+	return process(tmpl, vars)
+}
+
+// ProcessLabels generates matches based on destination label selectors that specify templating.
+func ProcessLabels(str string, vars interface{}) (string, error) {
+	tmpl, err := parseTemplate(str)
+	if err != nil {
+		return "", err
+	}
+	return process(tmpl, vars)
+}
+
+func parseTemplate(str string) (*template.Template, error) {
+	tmpl, err := template.New("tmpl").Parse(str)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse label selector template %q", str)
+	}
+	return tmpl, nil
+}
+
+func process(t *template.Template, vars interface{}) (string, error) {
+	var tmplBytes bytes.Buffer
+
+	if err := t.Execute(&tmplBytes, vars); err != nil {
+		return "", errors.Wrapf(err, "failed to execute label selector template %q", t.Root.String())
+	}
+	return tmplBytes.String(), nil
+}
+
+// ValidateSelector checks that all templates and label expressions of the selector are valid
+func ValidateSelector(selector map[string]string) error {
+	for k, v := range selector {
+		if strings.Contains(v, templateDelimiter) {
+			// Label expressions of templates are known only for the particular request labels,
+			// so check that template could be executed at all
+			if _, err := ProcessLabels(v, map[string]string{}); err != nil {
+				return errors.Wrapf(err, "label %s", k)
+			}
+			continue
+		}
+		if _, err := parseLabelRequirement(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
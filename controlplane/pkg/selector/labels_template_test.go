@@ -0,0 +1,96 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/registry"
+)
+
+func TestProcessLabels(t *testing.T) {
+	labels := map[string]string{"app": "firewall"}
+	tests := []struct {
+		name    string
+		str     string
+		want    string
+		wantErr bool
+	}{
+		{"plain", "vpn", "vpn", false},
+		{"template", "{{index . \"app\"}}", "firewall", false},
+		{"parse error", "{{index . \"app\"", "", true},
+		{"execution error", "{{.app.name}}", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProcessLabels(tt.str, labels)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ProcessLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector map[string]string
+		wantErr  bool
+	}{
+		{"valid expression", map[string]string{"zone": "In(us-east)"}, false},
+		{"valid template", map[string]string{"app": "{{index . \"app\"}}"}, false},
+		{"invalid template", map[string]string{"app": "{{.app"}, true},
+		{"invalid template action", map[string]string{"app": "{{end}}"}, true},
+		{"invalid expression", map[string]string{"zone": "Regex(us-(east)"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSelector(tt.selector); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_matchSelector_InvalidTemplate(t *testing.T) {
+	ns := &registry.NetworkService{
+		Name: "ns",
+		Matches: []*registry.Match{
+			{
+				Routes: []*registry.Destination{
+					{DestinationSelector: map[string]string{"app": "{{index . \"app\""}},
+				},
+			},
+		},
+	}
+	endpoints := []*registry.NetworkServiceEndpoint{
+		{
+			Name:   "firewall",
+			Labels: map[string]string{"app": "firewall"},
+		},
+	}
+
+	m := NewMatchSelector()
+	for i := 0; i < 2; i++ {
+		got, err := m.SelectEndpoint(&connection.Connection{}, ns, endpoints)
+		if err == nil || got != nil {
+			t.Errorf("matchSelector.SelectEndpoint() = %v, %v, want error", got, err)
+		}
+	}
+}
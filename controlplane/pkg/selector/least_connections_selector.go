@@ -0,0 +1,80 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/registry"
+)
+
+// ConnectionCounter provides the number of connections an endpoint is serving at the moment
+type ConnectionCounter interface {
+	ConnectionCount(nse *registry.NetworkServiceEndpoint) int
+}
+
+// AdvertisedConnectionCount returns the number of connections advertised by NSM of the endpoint. It is not a part
+// of the endpoint labels, so it is never matched by the destination selectors.
+func AdvertisedConnectionCount(nse *registry.NetworkServiceEndpoint) int {
+	return int(nse.GetConnectionCount())
+}
+
+type leastConnectionsSelector struct {
+	counter    ConnectionCounter
+	roundRobin Selector
+}
+
+// NewLeastConnectionsSelector creates a selector choosing the endpoint with the least number of connections,
+// endpoints with the same number of connections are selected in round-robin manner
+func NewLeastConnectionsSelector(counter ConnectionCounter) Selector {
+	return &leastConnectionsSelector{
+		counter:    counter,
+		roundRobin: NewRoundRobinSelector(),
+	}
+}
+
+// NewLeastConnectionsMatchSelector creates a match selector choosing between the matched endpoints
+// with the least connections selector
+func NewLeastConnectionsMatchSelector(counter ConnectionCounter) Selector {
+	return &matchSelector{
+		balancer:     NewLeastConnectionsSelector(counter),
+		weighted:     newWeightedSelector(),
+		templates:    newTemplateCache(),
+		requirements: newRequirementCache(),
+	}
+}
+
+func (lc *leastConnectionsSelector) SelectEndpoint(requestConnection *connection.Connection, ns *registry.NetworkService, networkServiceEndpoints []*registry.NetworkServiceEndpoint) (*registry.NetworkServiceEndpoint, error) {
+	if len(networkServiceEndpoints) == 0 {
+		return nil, nil
+	}
+
+	var leastLoaded []*registry.NetworkServiceEndpoint
+	leastCount := -1
+	for _, nse := range networkServiceEndpoints {
+		count := lc.counter.ConnectionCount(nse)
+		switch {
+		case leastCount == -1 || count < leastCount:
+			leastCount = count
+			leastLoaded = []*registry.NetworkServiceEndpoint{nse}
+		case count == leastCount:
+			leastLoaded = append(leastLoaded, nse)
+		}
+	}
+
+	logrus.Infof("LeastConnections found %d endpoints with %d connections", len(leastLoaded), leastCount)
+	return lc.roundRobin.SelectEndpoint(requestConnection, ns, leastLoaded)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/registry"
+)
+
+type testCounter map[string]int
+
+func (c testCounter) ConnectionCount(nse *registry.NetworkServiceEndpoint) int {
+	if count := AdvertisedConnectionCount(nse); count > 0 {
+		return count
+	}
+	return c[nse.GetName()]
+}
+
+func Test_leastConnectionsSelector_SelectEndpoint(t *testing.T) {
+	counter := testCounter{
+		"NSE-1": 3,
+		"NSE-2": 1,
+		"NSE-3": 1,
+	}
+	endpoints := []*registry.NetworkServiceEndpoint{
+		{Name: "NSE-1"},
+		{Name: "NSE-2"},
+		{Name: "NSE-3"},
+	}
+	ns := &registry.NetworkService{Name: "network-service-1"}
+
+	lc := NewLeastConnectionsSelector(counter)
+	for _, want := range []string{"NSE-2", "NSE-3", "NSE-2"} {
+		if got, _ := lc.SelectEndpoint(&connection.Connection{}, ns, endpoints); got.GetName() != want {
+			t.Errorf("leastConnectionsSelector.SelectEndpoint() = %v, want %v", got.GetName(), want)
+		}
+	}
+
+	counter["NSE-2"] = 5
+	counter["NSE-3"] = 4
+	if got, _ := lc.SelectEndpoint(&connection.Connection{}, ns, endpoints); got.GetName() != "NSE-1" {
+		t.Errorf("leastConnectionsSelector.SelectEndpoint() = %v, want NSE-1", got.GetName())
+	}
+
+	if got, _ := lc.SelectEndpoint(&connection.Connection{}, ns, nil); got != nil {
+		t.Errorf("leastConnectionsSelector.SelectEndpoint() = %v, want nil", got)
+	}
+}
+
+func Test_leastConnectionsSelector_Advertised(t *testing.T) {
+	endpoints := []*registry.NetworkServiceEndpoint{
+		{
+			Name:            "NSE-1",
+			Labels:          map[string]string{"app": "firewall"},
+			ConnectionCount: 10,
+		},
+		{
+			Name:            "NSE-2",
+			Labels:          map[string]string{"app": "firewall"},
+			ConnectionCount: 2,
+		},
+		{
+			Name:   "NSE-3",
+			Labels: map[string]string{"app": "firewall"},
+		},
+	}
+	counter := testCounter{
+		"NSE-3": 4,
+	}
+
+	m := NewLeastConnectionsMatchSelector(counter)
+	if got, _ := m.SelectEndpoint(&connection.Connection{}, &registry.NetworkService{Name: "ns"}, endpoints); got.GetName() != "NSE-2" {
+		t.Errorf("matchSelector.SelectEndpoint() = %v, want NSE-2", got.GetName())
+	}
+
+	// Destination selectors match the labels only, advertised counts do not change the match
+	ns := &registry.NetworkService{
+		Name: "ns",
+		Matches: []*registry.Match{
+			{
+				Routes: []*registry.Destination{
+					{DestinationSelector: map[string]string{"app": "firewall"}},
+				},
+			},
+		},
+	}
+	if got, _ := m.SelectEndpoint(&connection.Connection{}, ns, endpoints); got.GetName() != "NSE-2" {
+		t.Errorf("matchSelector.SelectEndpoint() = %v, want NSE-2", got.GetName())
+	}
+}
@@ -16,10 +16,10 @@
 package selector
 
 import (
-	"bytes"
+	"fmt"
 	"sync"
-	"text/template"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
@@ -28,40 +28,55 @@ import (
 
 type matchSelector struct {
 	sync.Mutex
-	roundRobin Selector
+	balancer     Selector
+	weighted     *weightedSelector
+	templates    *templateCache
+	requirements *requirementCache
 }
 
 // NewMatchSelector creates a new
 func NewMatchSelector() Selector {
 	return &matchSelector{
-		roundRobin: NewRoundRobinSelector(),
+		balancer:     NewRoundRobinSelector(),
+		weighted:     newWeightedSelector(),
+		templates:    newTemplateCache(),
+		requirements: newRequirementCache(),
 	}
 }
 
-// isSubset checks if B is a subset of A. TODO: reconsider this as a part of "tools"
-func isSubset(a, b, nsLabels map[string]string) bool {
-	if len(a) < len(b) {
-		return false
-	}
+// isSubset checks if labels A satisfy all requirements of selector B. TODO: reconsider this as a part of "tools"
+func (m *matchSelector) isSubset(a, b, nsLabels map[string]string) (bool, error) {
 	for k, v := range b {
-		if a[k] != v {
-			result := ProcessLabels(v, nsLabels)
-			if a[k] != result {
-				return false
-			}
+		if a[k] == v {
+			continue
+		}
+		value, err := m.templates.process(v, nsLabels)
+		if err != nil {
+			return false, err
+		}
+		requirement, err := m.requirements.parse(k, value)
+		if err != nil {
+			return false, err
+		}
+		if !requirement.matches(a) {
+			return false, nil
 		}
 	}
-	return true
+	return true, nil
 }
 
-func (m *matchSelector) matchEndpoint(nsLabels map[string]string, ns *registry.NetworkService, networkServiceEndpoints []*registry.NetworkServiceEndpoint) *registry.NetworkServiceEndpoint {
+func (m *matchSelector) matchEndpoint(nsLabels map[string]string, ns *registry.NetworkService, networkServiceEndpoints []*registry.NetworkServiceEndpoint) (*registry.NetworkServiceEndpoint, error) {
 	logrus.Infof("Matching endpoint for labels %v", nsLabels)
 
 	matchedNonEmptySelector := false
 	//Iterate through the matches
-	for _, match := range ns.GetMatches() {
+	for matchIdx, match := range ns.GetMatches() {
 		// All match source selector labels should be present in the requested labels map
-		if !isSubset(nsLabels, match.GetSourceSelector(), nsLabels) {
+		matched, err := m.isSubset(nsLabels, match.GetSourceSelector(), nsLabels)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid source selector of NetworkService %s", ns.GetName())
+		}
+		if !matched {
 			continue
 		}
 
@@ -74,50 +89,63 @@ func (m *matchSelector) matchEndpoint(nsLabels map[string]string, ns *registry.N
 			matchedNonEmptySelector = true
 		}
 
-		nseCandidates := []*registry.NetworkServiceEndpoint{}
+		routes := make([]*weightedRoute, 0, len(match.GetRoutes()))
 		// Check all Destinations in that match
 		for _, destination := range match.GetRoutes() {
+			route := &weightedRoute{
+				weight: destination.GetWeight(),
+			}
 			// Each NSE should be matched against that destination
 			for _, nse := range networkServiceEndpoints {
-				if isSubset(nse.GetLabels(), destination.GetDestinationSelector(), nsLabels) {
-					nseCandidates = append(nseCandidates, nse)
+				matched, err := m.isSubset(nse.GetLabels(), destination.GetDestinationSelector(), nsLabels)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid destination selector of NetworkService %s", ns.GetName())
+				}
+				if matched {
+					route.endpoints = append(route.endpoints, nse)
 				}
 			}
+			routes = append(routes, route)
 		}
 
-		if len(nseCandidates) > 0 {
-			// We found candidates. Use RoundRobin to select one
-			return m.roundRobin.SelectEndpoint(nil, ns, nseCandidates)
+		endpoint, err := m.selectRouteEndpoint(ns, matchIdx, routes)
+		if err != nil || endpoint != nil {
+			return endpoint, err
 		}
 	}
-	return nil
+	return nil, nil
 }
 
-func (m *matchSelector) SelectEndpoint(requestConnection *connection.Connection, ns *registry.NetworkService, networkServiceEndpoints []*registry.NetworkServiceEndpoint) *registry.NetworkServiceEndpoint {
-	logrus.Infof("Selecting endpoint for %s with %d matches.", requestConnection.GetNetworkService(), len(ns.GetMatches()))
-	if len(ns.GetMatches()) == 0 {
-		return m.roundRobin.SelectEndpoint(nil, ns, networkServiceEndpoints)
+func (m *matchSelector) selectRouteEndpoint(ns *registry.NetworkService, matchIdx int, routes []*weightedRoute) (*registry.NetworkServiceEndpoint, error) {
+	if hasWeights(routes) {
+		key := fmt.Sprintf("%s/%d", ns.GetName(), matchIdx)
+		if routeIdx := m.weighted.selectRoute(key, routes); routeIdx >= 0 {
+			// Use balancer to select one of the route candidates
+			routeNs := &registry.NetworkService{
+				Name: fmt.Sprintf("%s/%d", key, routeIdx),
+			}
+			return m.balancer.SelectEndpoint(nil, routeNs, routes[routeIdx].endpoints)
+		}
+		// None of the weighted routes has candidates, so fall back to the routes without weight
 	}
 
-	return m.matchEndpoint(requestConnection.GetLabels(), ns, networkServiceEndpoints)
-}
-
-// ProcessLabels generates matches based on destination label selectors that specify templating.
-func ProcessLabels(str string, vars interface{}) string {
-	tmpl, err := template.New("tmpl").Parse(str)
+	nseCandidates := []*registry.NetworkServiceEndpoint{}
+	for _, route := range routes {
+		nseCandidates = append(nseCandidates, route.endpoints...)
+	}
 
-	if err != nil {
-		panic(err)
+	if len(nseCandidates) > 0 {
+		// We found candidates. Use balancer to select one
+		return m.balancer.SelectEndpoint(nil, ns, nseCandidates)
 	}
-	return process(tmpl, vars)
+	return nil, nil
 }
 
-func process(t *template.Template, vars interface{}) string {
-	var tmplBytes bytes.Buffer
-
-	err := t.Execute(&tmplBytes, vars)
-	if err != nil {
-		panic(err)
+func (m *matchSelector) SelectEndpoint(requestConnection *connection.Connection, ns *registry.NetworkService, networkServiceEndpoints []*registry.NetworkServiceEndpoint) (*registry.NetworkServiceEndpoint, error) {
+	logrus.Infof("Selecting endpoint for %s with %d matches.", requestConnection.GetNetworkService(), len(ns.GetMatches()))
+	if len(ns.GetMatches()) == 0 {
+		return m.balancer.SelectEndpoint(nil, ns, networkServiceEndpoints)
 	}
-	return tmplBytes.String()
+
+	return m.matchEndpoint(requestConnection.GetLabels(), ns, networkServiceEndpoints)
 }
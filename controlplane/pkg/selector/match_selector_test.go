@@ -603,7 +603,11 @@ func Test_matchSelector_SelectEndpoint(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := m.SelectEndpoint(tt.args.requestConnection, tt.args.ns, tt.args.networkServiceEndpoints); !reflect.DeepEqual(got, tt.want) {
+			got, err := m.SelectEndpoint(tt.args.requestConnection, tt.args.ns, tt.args.networkServiceEndpoints)
+			if err != nil {
+				t.Fatalf("matchSelector.SelectEndpoint() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("matchSelector.SelectEndpoint() = %v, want %v", got, tt.want)
 			}
 		})
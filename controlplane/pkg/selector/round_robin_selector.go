@@ -20,21 +20,21 @@ func NewRoundRobinSelector() Selector {
 	}
 }
 
-func (rr *roundRobinSelector) SelectEndpoint(requestConnection *connection.Connection, ns *registry.NetworkService, networkServiceEndpoints []*registry.NetworkServiceEndpoint) *registry.NetworkServiceEndpoint {
+func (rr *roundRobinSelector) SelectEndpoint(requestConnection *connection.Connection, ns *registry.NetworkService, networkServiceEndpoints []*registry.NetworkServiceEndpoint) (*registry.NetworkServiceEndpoint, error) {
 	if rr == nil {
-		return nil
+		return nil, nil
 	}
 	if len(networkServiceEndpoints) == 0 {
-		return nil
+		return nil, nil
 	}
 	rr.Lock()
 	defer rr.Unlock()
 	idx := rr.roundRobin[ns.GetName()] % len(networkServiceEndpoints)
 	endpoint := networkServiceEndpoints[idx]
 	if endpoint == nil {
-		return nil
+		return nil, nil
 	}
 	rr.roundRobin[ns.GetName()] = rr.roundRobin[ns.GetName()] + 1
 	logrus.Infof("RoundRobin selected %v", endpoint)
-	return endpoint
+	return endpoint, nil
 }
@@ -225,7 +225,11 @@ func Test_roundRobinSelector_SelectEndpoint(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := rr.SelectEndpoint(tt.args.requestConnection, tt.args.ns, tt.args.networkServiceEndpoints); !reflect.DeepEqual(got, tt.want) {
+			got, err := rr.SelectEndpoint(tt.args.requestConnection, tt.args.ns, tt.args.networkServiceEndpoints)
+			if err != nil {
+				t.Fatalf("roundRobinSelector.SelectEndpoint() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("roundRobinSelector.SelectEndpoint() = %v, want %v", got, tt.want)
 			}
 		})
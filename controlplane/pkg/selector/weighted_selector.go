@@ -0,0 +1,87 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/registry"
+)
+
+// weightedRoute is a single Destination of a Match with the endpoints it has matched
+type weightedRoute struct {
+	weight    uint32
+	endpoints []*registry.NetworkServiceEndpoint
+}
+
+// weightedSelector spreads requests across the routes of a Match in proportion to their weights
+// using smooth weighted round-robin, so a 90/10 split never sends a burst of requests to the
+// smaller route.
+type weightedSelector struct {
+	sync.Mutex
+	currentWeights map[string][]int64
+}
+
+func newWeightedSelector() *weightedSelector {
+	return &weightedSelector{
+		currentWeights: make(map[string][]int64),
+	}
+}
+
+// hasWeights checks if any of routes has a weight specified
+func hasWeights(routes []*weightedRoute) bool {
+	for _, route := range routes {
+		if route.weight > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// selectRoute returns the index of the route to be used for the next request. Routes without
+// matched endpoints or without weight are skipped. Returns -1 if there is no such route.
+func (ws *weightedSelector) selectRoute(key string, routes []*weightedRoute) int {
+	ws.Lock()
+	defer ws.Unlock()
+
+	current := ws.currentWeights[key]
+	if len(current) != len(routes) {
+		// Either the first request or NetworkService routes have been changed
+		current = make([]int64, len(routes))
+		ws.currentWeights[key] = current
+	}
+
+	selected := -1
+	var total int64
+	for i, route := range routes {
+		if route.weight == 0 || len(route.endpoints) == 0 {
+			continue
+		}
+		current[i] += int64(route.weight)
+		total += int64(route.weight)
+		if selected == -1 || current[i] > current[selected] {
+			selected = i
+		}
+	}
+	if selected == -1 {
+		return -1
+	}
+	current[selected] -= total
+
+	logrus.Infof("Weighted selector selected route %d of %s with weight %d", selected, key, routes[selected].weight)
+	return selected
+}
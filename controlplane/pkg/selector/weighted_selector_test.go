@@ -0,0 +1,113 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/registry"
+)
+
+func canaryNetworkService(stableWeight, canaryWeight uint32) *registry.NetworkService {
+	return &registry.NetworkService{
+		Name: "canary-ns",
+		Matches: []*registry.Match{
+			{
+				Routes: []*registry.Destination{
+					{
+						DestinationSelector: map[string]string{
+							"version": "v1",
+						},
+						Weight: stableWeight,
+					},
+					{
+						DestinationSelector: map[string]string{
+							"version": "v2",
+						},
+						Weight: canaryWeight,
+					},
+				},
+			},
+		},
+	}
+}
+
+func canaryEndpoints() []*registry.NetworkServiceEndpoint {
+	return []*registry.NetworkServiceEndpoint{
+		{
+			Name:   "nse-v1-1",
+			Labels: map[string]string{"version": "v1"},
+		},
+		{
+			Name:   "nse-v1-2",
+			Labels: map[string]string{"version": "v1"},
+		},
+		{
+			Name:   "nse-v2-1",
+			Labels: map[string]string{"version": "v2"},
+		},
+	}
+}
+
+func selectN(s Selector, ns *registry.NetworkService, endpoints []*registry.NetworkServiceEndpoint, n int) map[string]int {
+	result := map[string]int{}
+	for i := 0; i < n; i++ {
+		nse, _ := s.SelectEndpoint(&connection.Connection{}, ns, endpoints)
+		result[nse.GetName()]++
+	}
+	return result
+}
+
+func Test_matchSelector_WeightedSplit(t *testing.T) {
+	got := selectN(NewMatchSelector(), canaryNetworkService(90, 10), canaryEndpoints(), 100)
+
+	if got["nse-v1-1"]+got["nse-v1-2"] != 90 || got["nse-v2-1"] != 10 {
+		t.Errorf("matchSelector.SelectEndpoint() split = %v, want 90/10", got)
+	}
+	if got["nse-v1-1"] != 45 || got["nse-v1-2"] != 45 {
+		t.Errorf("matchSelector.SelectEndpoint() route split = %v, want 45/45", got)
+	}
+}
+
+func Test_matchSelector_WeightedSmooth(t *testing.T) {
+	m := NewMatchSelector()
+	ns := canaryNetworkService(3, 1)
+	endpoints := canaryEndpoints()
+
+	want := []string{"v1", "v1", "v2", "v1"}
+	for i := 0; i < 2*len(want); i++ {
+		nse, _ := m.SelectEndpoint(&connection.Connection{}, ns, endpoints)
+		if got := nse.GetLabels()["version"]; got != want[i%len(want)] {
+			t.Errorf("matchSelector.SelectEndpoint() pass %d = %v, want %v", i, got, want[i%len(want)])
+		}
+	}
+}
+
+func Test_matchSelector_WeightedFallback(t *testing.T) {
+	ns := canaryNetworkService(100, 0)
+	endpoints := canaryEndpoints()
+
+	got := selectN(NewMatchSelector(), ns, endpoints, 10)
+	if got["nse-v2-1"] != 0 {
+		t.Errorf("matchSelector.SelectEndpoint() selected route without weight: %v", got)
+	}
+
+	// No endpoints for the weighted route, the route without weight should be used
+	got = selectN(NewMatchSelector(), ns, endpoints[2:], 10)
+	if got["nse-v2-1"] != 10 {
+		t.Errorf("matchSelector.SelectEndpoint() fallback = %v, want all to nse-v2-1", got)
+	}
+}
@@ -234,7 +234,7 @@ func (impl *localTestNSENetworkServiceClient) Request(ctx context.Context, in *n
 	}
 
 	// TODO take into consideration LocalMechnism preferences sent in request
-	srcIP, dstIP, requested, err := impl.prefixPool.Extract(in.Connection.Id, connectioncontext.IpFamily_IPV4, in.Connection.GetContext().GetIpContext().ExtraPrefixRequest...)
+	srcIP, dstIP, requested, err := impl.prefixPool.Extract(in.Connection.Id, connectioncontext.IpFamily_IPV4, nil, in.Connection.GetContext().GetIpContext().ExtraPrefixRequest...)
 	if err != nil {
 		return nil, err
 	}
@@ -17,43 +17,136 @@ package vni
 import (
 	"net"
 	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/geneve"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/gre"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
+	"github.com/networkservicemesh/networkservicemesh/utils"
+)
+
+const (
+	// MinVni - minimal VXLAN network identifier allocated by default
+	MinVni uint32 = 1
+	// MaxVni - maximal VXLAN network identifier, VNI is a 24-bit value
+	MaxVni uint32 = 1<<24 - 1
+	// MinVniEnv - environment variable contains minimal VNI to allocate
+	MinVniEnv = utils.EnvVar("NSMD_VNI_MIN")
+	// MaxVniEnv - environment variable contains maximal VNI to allocate
+	MaxVniEnv = utils.EnvVar("NSMD_VNI_MAX")
 )
 
 type VniAllocator interface {
-	Vni(local_ip string, remote_ip string) uint32
+	Vni(local_ip string, remote_ip string) (uint32, error)
+	Release(local_ip string, remote_ip string, vniId uint32)
 	Restore(local_ip string, remote_ip string, vniId uint32)
 }
 
+// vniPool - VNIs allocated for connections with a single remote peer
+type vniPool struct {
+	used    map[uint32]bool
+	lastVni uint32
+}
+
 type vniAllocator struct {
-	lastVni map[string]uint32
+	pools  map[string]*vniPool
+	minVni uint32
+	maxVni uint32
 	sync.Mutex
 }
 
+// NewVniAllocator - creates VNI allocator with the range configured by NSMD_VNI_MIN and NSMD_VNI_MAX
 func NewVniAllocator() VniAllocator {
-	return &vniAllocator{
-		lastVni: make(map[string]uint32),
+	minVni := MinVniEnv.GetIntOrDefault(int(MinVni))
+	maxVni := MaxVniEnv.GetIntOrDefault(int(MaxVni))
+	if minVni >= 0 && maxVni >= 0 && maxVni <= int(MaxVni) {
+		a, err := NewVniAllocatorWithRange(uint32(minVni), uint32(maxVni))
+		if err == nil {
+			return a
+		}
+	}
+	logrus.Errorf("Invalid VNI range [%d, %d], using default [%d, %d]", minVni, maxVni, MinVni, MaxVni)
+	a, _ := NewVniAllocatorWithRange(MinVni, MaxVni)
+	return a
+}
+
+// NewVniAllocatorWithRange - creates VNI allocator allocating VNIs from [minVni, maxVni]
+func NewVniAllocatorWithRange(minVni, maxVni uint32) (VniAllocator, error) {
+	if minVni == 0 || maxVni > MaxVni || minVni >= maxVni {
+		return nil, errors.Errorf("VNI range [%d, %d] should be within [%d, %d] and contain both odd and even values", minVni, maxVni, MinVni, MaxVni)
 	}
+	return &vniAllocator{
+		pools:  make(map[string]*vniPool),
+		minVni: minVni,
+		maxVni: maxVni,
+	}, nil
 }
 
 // Vni - Allocate a new VNI, odd if local_ip < remote_ip, even otherwise
-func (a *vniAllocator) Vni(localIP, remoteIP string) uint32 {
+func (a *vniAllocator) Vni(localIP, remoteIP string) (uint32, error) {
 	a.Lock()
 	defer a.Unlock()
-	lip := net.ParseIP(localIP)
-	rip := net.ParseIP(remoteIP)
-	lastVni := a.lastVni[remoteIP]
-	if lastVni == 0 {
-		if compareIps(lip, rip) < 0 {
-			lastVni = 1
+
+	first := a.firstVni(localIP, remoteIP)
+	pool := a.pool(remoteIP)
+	vni := pool.lastVni + 2
+	for i := uint32(0); i <= (a.maxVni-first)/2; i++ {
+		if vni < first || vni > a.maxVni || vni%2 != first%2 {
+			vni = first
+		}
+		if !pool.used[vni] {
+			pool.used[vni] = true
+			pool.lastVni = vni
+			return vni, nil
 		}
+		vni += 2
 	}
-	a.lastVni[remoteIP] = lastVni + 2
-	return a.lastVni[remoteIP]
+	return 0, errors.Errorf("no free VNI left for remote %s in range [%d, %d]", remoteIP, a.minVni, a.maxVni)
 }
 
-// Restore value of last Vni based on connections we have at the moment.
+// Release - return VNI of the closed connection back to the pool
+func (a *vniAllocator) Release(localIP, remoteIP string, vniID uint32) {
+	a.Lock()
+	defer a.Unlock()
+
+	if pool, ok := a.pools[remoteIP]; ok {
+		delete(pool.used, vniID)
+	}
+}
+
+// Restore - mark VNI used by the connection we have at the moment, so it will not be allocated again.
 func (a *vniAllocator) Restore(localIP, remoteIP string, vniID uint32) {
-	a.lastVni[remoteIP] = vniID
+	a.Lock()
+	defer a.Unlock()
+
+	pool := a.pool(remoteIP)
+	pool.used[vniID] = true
+	if vniID%2 == a.firstVni(localIP, remoteIP)%2 && vniID > pool.lastVni {
+		pool.lastVni = vniID
+	}
+}
+
+func (a *vniAllocator) pool(remoteIP string) *vniPool {
+	pool, ok := a.pools[remoteIP]
+	if !ok {
+		pool = &vniPool{
+			used: make(map[uint32]bool),
+		}
+		a.pools[remoteIP] = pool
+	}
+	return pool
+}
+
+// firstVni - the first VNI of the range with parity defined by local and remote IPs
+func (a *vniAllocator) firstVni(localIP, remoteIP string) uint32 {
+	odd := compareIps(net.ParseIP(localIP), net.ParseIP(remoteIP)) < 0
+	if (a.minVni%2 == 1) == odd {
+		return a.minVni
+	}
+	return a.minVni + 1
 }
 
 func compareIps(ip1, ip2 net.IP) int {
@@ -67,3 +160,54 @@ func compareIps(ip1, ip2 net.IP) int {
 	}
 	return 0
 }
+
+// Parameter - returns name of the remote mechanism parameter keeping identifier allocated by VniAllocator. VXLAN
+// and Geneve VNIs and GRE keys are allocated from the same pool of the peers.
+func Parameter(mechanismType string) (string, bool) {
+	switch mechanismType {
+	case vxlan.MECHANISM:
+		return vxlan.VNI, true
+	case geneve.MECHANISM:
+		return geneve.VNI, true
+	case gre.MECHANISM:
+		return gre.Key, true
+	}
+	return "", false
+}
+
+// FromMechanism - returns identifier allocated by VniAllocator for the remote mechanism, ok is false if mechanism
+// identifier is not allocated by VniAllocator
+func FromMechanism(m *connection.Mechanism) (vniID uint32, ok bool, err error) {
+	switch m.GetType() {
+	case vxlan.MECHANISM:
+		vniID, err = vxlan.ToMechanism(m).VNI()
+	case geneve.MECHANISM:
+		vniID, err = geneve.ToMechanism(m).VNI()
+	case gre.MECHANISM:
+		vniID, err = gre.ToMechanism(m).Key()
+	default:
+		return 0, false, nil
+	}
+	return vniID, true, err
+}
+
+// Peers - returns local and remote IPs VNI of the remote VXLAN, Geneve or GRE connection is allocated for
+func Peers(parameters map[string]string) (localIP, remoteIP string) {
+	extSrcIP := parameters[vxlan.SrcIP]
+	extDstIP := parameters[vxlan.DstIP]
+	srcIP := parameters[vxlan.SrcIP]
+	dstIP := parameters[vxlan.DstIP]
+
+	if ip, ok := parameters[vxlan.SrcOriginalIP]; ok {
+		srcIP = ip
+	}
+
+	if ip, ok := parameters[vxlan.DstExternalIP]; ok {
+		extDstIP = ip
+	}
+
+	if extDstIP != extSrcIP {
+		return extDstIP, extSrcIP
+	}
+	return dstIP, srcIP
+}
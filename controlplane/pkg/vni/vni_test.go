@@ -0,0 +1,136 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vni
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/geneve"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/gre"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
+)
+
+const (
+	lowerIP  = "10.0.0.1"
+	higherIP = "10.0.0.2"
+)
+
+func TestVniParity(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewVniAllocator()
+	for i := 0; i < 3; i++ {
+		odd, err := a.Vni(lowerIP, higherIP)
+		g.Expect(err).To(BeNil())
+		g.Expect(odd % 2).To(Equal(uint32(1)))
+
+		even, err := a.Vni(higherIP, lowerIP)
+		g.Expect(err).To(BeNil())
+		g.Expect(even % 2).To(Equal(uint32(0)))
+	}
+}
+
+func TestVniReleaseAndReuse(t *testing.T) {
+	g := NewWithT(t)
+
+	a, err := NewVniAllocatorWithRange(10, 15)
+	g.Expect(err).To(BeNil())
+
+	var allocated []uint32
+	for i := 0; i < 3; i++ {
+		vni, err := a.Vni(higherIP, lowerIP)
+		g.Expect(err).To(BeNil())
+		allocated = append(allocated, vni)
+	}
+	g.Expect(allocated).To(Equal([]uint32{10, 12, 14}))
+
+	_, err = a.Vni(higherIP, lowerIP)
+	g.Expect(err).NotTo(BeNil())
+
+	// Pools are per remote peer
+	vni, err := a.Vni(higherIP, "10.0.0.0")
+	g.Expect(err).To(BeNil())
+	g.Expect(vni).To(Equal(uint32(10)))
+
+	a.Release(higherIP, lowerIP, 12)
+	vni, err = a.Vni(higherIP, lowerIP)
+	g.Expect(err).To(BeNil())
+	g.Expect(vni).To(Equal(uint32(12)))
+}
+
+func TestVniRestore(t *testing.T) {
+	g := NewWithT(t)
+
+	a, err := NewVniAllocatorWithRange(1, 10)
+	g.Expect(err).To(BeNil())
+
+	a.Restore(lowerIP, higherIP, 5)
+	a.Restore(lowerIP, higherIP, 1)
+
+	var allocated []uint32
+	for i := 0; i < 3; i++ {
+		vni, err := a.Vni(lowerIP, higherIP)
+		g.Expect(err).To(BeNil())
+		allocated = append(allocated, vni)
+	}
+	// Allocation continues after the restored VNI, wraps and skips VNIs still in use
+	g.Expect(allocated).To(Equal([]uint32{7, 9, 3}))
+}
+
+func TestVniInvalidRange(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := NewVniAllocatorWithRange(0, 10)
+	g.Expect(err).NotTo(BeNil())
+	_, err = NewVniAllocatorWithRange(10, 10)
+	g.Expect(err).NotTo(BeNil())
+	_, err = NewVniAllocatorWithRange(1, MaxVni+1)
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestFromMechanism(t *testing.T) {
+	g := NewWithT(t)
+
+	for _, mechanismType := range []string{gre.MECHANISM, geneve.MECHANISM} {
+		parameter, ok := Parameter(mechanismType)
+		g.Expect(ok).To(BeTrue())
+
+		m := &connection.Mechanism{
+			Type: mechanismType,
+			Parameters: map[string]string{
+				gre.SrcIP: lowerIP,
+				gre.DstIP: higherIP,
+				parameter: "42",
+			},
+		}
+		g.Expect(m.IsValid()).To(BeNil())
+		vniID, ok, err := FromMechanism(m)
+		g.Expect(err).To(BeNil())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(vniID).To(Equal(uint32(42)))
+
+		m.Parameters[parameter] = "-1"
+		g.Expect(m.IsValid()).NotTo(BeNil())
+		_, _, err = FromMechanism(m)
+		g.Expect(err).NotTo(BeNil())
+	}
+
+	_, ok, err := FromMechanism(&connection.Mechanism{Type: wireguard.MECHANISM})
+	g.Expect(err).To(BeNil())
+	g.Expect(ok).To(BeFalse())
+}
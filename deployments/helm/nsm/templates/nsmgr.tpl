@@ -53,6 +53,8 @@ spec:
               value: "6831"
             - name: PREFERRED_REMOTE_MECHANISM
               value: {{ .Values.preferredRemoteMechanism | quote }}
+            - name: PROMETHEUS
+              value: {{ .Values.prometheus | default false | quote }}
           volumeMounts:
             - name: nsm-socket
               mountPath: /var/lib/networkservicemesh
@@ -39,8 +39,7 @@ spec:
             limits:
               networkservicemesh.io/socket: 1
           volumeMounts:
-            - mountPath: /etc/vppagent-firewall/config.yaml
-              subPath: config.yaml
+            - mountPath: /etc/vppagent-firewall
               name: vppagent-firewall-config-volume
       volumes:
         - name: vppagent-firewall-config-volume
@@ -57,6 +56,12 @@ metadata:
   namespace: {{ .Release.Namespace }}
 data:
   config.yaml: |
-    aclRules:
-      "Allow ICMP": "action=reflect,icmptype=8"
-      "Allow TCP 80": "action=reflect,tcplowport=80,tcpupport=80"
+    rules:
+      - name: allow-icmp
+        action: reflect
+        protocols: [icmp]
+        icmpType: 8
+      - name: allow-http
+        action: reflect
+        protocols: [tcp]
+        destinationPorts: ["80"]
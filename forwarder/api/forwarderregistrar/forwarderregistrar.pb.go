@@ -29,11 +29,15 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 // to advertise itself and inform NSM about the location of the forwarder socket
 // and its initially supported parameters.
 type ForwarderRegistrationRequest struct {
-	ForwarderName        string   `protobuf:"bytes,1,opt,name=forwarder_name,json=forwarderName,proto3" json:"forwarder_name,omitempty"`
-	ForwarderSocket      string   `protobuf:"bytes,2,opt,name=forwarder_socket,json=forwarderSocket,proto3" json:"forwarder_socket,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	ForwarderName   string `protobuf:"bytes,1,opt,name=forwarder_name,json=forwarderName,proto3" json:"forwarder_name,omitempty"`
+	ForwarderSocket string `protobuf:"bytes,2,opt,name=forwarder_socket,json=forwarderSocket,proto3" json:"forwarder_socket,omitempty"`
+	// capacity is the maximum number of cross connects the forwarder could serve, 0 means unlimited
+	Capacity uint32 `protobuf:"varint,3,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// labels are used by NSM to select the forwarder by affinity
+	Labels               map[string]string `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *ForwarderRegistrationRequest) Reset()         { *m = ForwarderRegistrationRequest{} }
@@ -75,6 +79,20 @@ func (m *ForwarderRegistrationRequest) GetForwarderSocket() string {
 	return ""
 }
 
+func (m *ForwarderRegistrationRequest) GetCapacity() uint32 {
+	if m != nil {
+		return m.Capacity
+	}
+	return 0
+}
+
+func (m *ForwarderRegistrationRequest) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
 type ForwarderRegistrationReply struct {
 	Registered           bool     `protobuf:"varint,1,opt,name=registered,proto3" json:"registered,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -196,6 +214,7 @@ func (m *ForwarderUnRegistrationReply) GetUnRegistered() bool {
 
 func init() {
 	proto.RegisterType((*ForwarderRegistrationRequest)(nil), "forwarderregistrar.ForwarderRegistrationRequest")
+	proto.RegisterMapType((map[string]string)(nil), "forwarderregistrar.ForwarderRegistrationRequest.LabelsEntry")
 	proto.RegisterType((*ForwarderRegistrationReply)(nil), "forwarderregistrar.ForwarderRegistrationReply")
 	proto.RegisterType((*ForwarderUnRegistrationRequest)(nil), "forwarderregistrar.ForwarderUnRegistrationRequest")
 	proto.RegisterType((*ForwarderUnRegistrationReply)(nil), "forwarderregistrar.ForwarderUnRegistrationReply")
@@ -204,26 +223,31 @@ func init() {
 func init() { proto.RegisterFile("forwarderregistrar.proto", fileDescriptor_bf2c0f4975ef21fe) }
 
 var fileDescriptor_bf2c0f4975ef21fe = []byte{
-	// 304 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x92, 0xcf, 0x4a, 0xc3, 0x40,
-	0x18, 0xc4, 0x59, 0x0f, 0xa2, 0x1f, 0xd6, 0xca, 0x82, 0x1a, 0x42, 0x09, 0x12, 0x11, 0xea, 0x65,
-	0x0d, 0xf1, 0xea, 0x4d, 0xaa, 0x17, 0xf1, 0x10, 0xf1, 0x5c, 0x52, 0xfb, 0x35, 0x04, 0x93, 0xdd,
-	0x75, 0x77, 0xa3, 0xe4, 0xe6, 0xc9, 0x07, 0xf1, 0xcd, 0x7c, 0x13, 0xc9, 0x1f, 0x63, 0x49, 0x93,
-	0x42, 0x7b, 0xc9, 0x61, 0xf2, 0x9b, 0xdd, 0x99, 0x4c, 0xc0, 0x5a, 0x08, 0xf5, 0x11, 0xaa, 0x39,
-	0x2a, 0x85, 0x51, 0xac, 0x8d, 0x0a, 0x15, 0x93, 0x4a, 0x18, 0x41, 0xe9, 0xea, 0x1b, 0xdb, 0x92,
-	0x26, 0x97, 0xa8, 0xaf, 0x30, 0x95, 0x26, 0xaf, 0x9e, 0x15, 0xed, 0x4a, 0x18, 0xdd, 0xfd, 0xf1,
-	0x41, 0xcd, 0x9b, 0x58, 0xf0, 0x00, 0xdf, 0x32, 0xd4, 0x86, 0x5e, 0xc0, 0x61, 0x73, 0xde, 0x94,
-	0x87, 0x29, 0x5a, 0xe4, 0x8c, 0x8c, 0xf7, 0x83, 0x41, 0xa3, 0x3e, 0x86, 0x29, 0xd2, 0x4b, 0x38,
-	0xfa, 0xc7, 0xb4, 0x78, 0x79, 0x45, 0x63, 0xed, 0x94, 0xe0, 0xb0, 0xd1, 0x9f, 0x4a, 0xd9, 0xbd,
-	0x01, 0xbb, 0xe7, 0x46, 0x99, 0xe4, 0xd4, 0x01, 0xa8, 0x62, 0xa3, 0xc2, 0x79, 0x79, 0xd7, 0x5e,
-	0xb0, 0xa4, 0xb8, 0xf7, 0xe0, 0x34, 0xee, 0x67, 0xbe, 0x7d, 0x62, 0xf7, 0x76, 0xa9, 0x78, 0xfb,
-	0xa0, 0x22, 0xc8, 0x39, 0x0c, 0x32, 0x3e, 0x5d, 0xc9, 0x72, 0x90, 0xd5, 0x6c, 0xa1, 0xf9, 0x3f,
-	0x04, 0x8e, 0x3b, 0xcb, 0xd0, 0x4f, 0x02, 0xa3, 0x3a, 0x51, 0x37, 0xe0, 0xb1, 0x8e, 0x05, 0xd7,
-	0x4d, 0x61, 0xb3, 0x0d, 0x1c, 0x45, 0x83, 0x09, 0x0c, 0x6b, 0xeb, 0x43, 0xfc, 0x8e, 0x1c, 0xb5,
-	0xa6, 0x27, 0x2c, 0x12, 0x22, 0x4a, 0xb0, 0x1a, 0x7f, 0x96, 0x2d, 0xd8, 0xa4, 0xf8, 0x17, 0xec,
-	0x1e, 0x7d, 0x4c, 0x3c, 0xe2, 0x7f, 0x13, 0x38, 0xed, 0xf9, 0x52, 0xf4, 0x8b, 0x80, 0xd3, 0x6e,
-	0xd9, 0x42, 0xfc, 0xb5, 0xa9, 0x3b, 0x27, 0xb4, 0xbd, 0x8d, 0x3c, 0x32, 0xc9, 0x67, 0xbb, 0x65,
-	0xf0, 0xeb, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0xc9, 0xaf, 0x67, 0xb2, 0x17, 0x03, 0x00, 0x00,
+	// 378 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x92, 0xcd, 0x4e, 0xfa, 0x40,
+	0x14, 0xc5, 0x33, 0xf0, 0xff, 0x13, 0xbc, 0x88, 0x90, 0x89, 0x1f, 0x4d, 0x43, 0x08, 0xa9, 0x31,
+	0xa9, 0x9b, 0x4a, 0xea, 0x46, 0x0d, 0x3b, 0x83, 0x6e, 0x88, 0x8b, 0xaa, 0x6b, 0x32, 0xc0, 0x85,
+	0x34, 0x94, 0xb6, 0x4e, 0xa7, 0x98, 0xd9, 0xb9, 0xf2, 0x2d, 0xdc, 0xf8, 0x66, 0xbe, 0x89, 0xe9,
+	0x87, 0x95, 0x40, 0x4b, 0x82, 0x9b, 0xa6, 0x73, 0x7a, 0xce, 0xed, 0xef, 0xcc, 0x0c, 0x28, 0x53,
+	0x8f, 0xbf, 0x32, 0x3e, 0x41, 0xce, 0x71, 0x66, 0x07, 0x82, 0x33, 0x6e, 0xf8, 0xdc, 0x13, 0x1e,
+	0xa5, 0x9b, 0x5f, 0x54, 0xc5, 0x17, 0xd2, 0xc7, 0xe0, 0x02, 0x17, 0xbe, 0x90, 0xc9, 0x33, 0x71,
+	0x6b, 0x1f, 0x25, 0x68, 0xdd, 0xfd, 0x04, 0xac, 0x34, 0x20, 0x6c, 0xcf, 0xb5, 0xf0, 0x25, 0xc4,
+	0x40, 0xd0, 0x33, 0x38, 0xc8, 0x06, 0x0e, 0x5d, 0xb6, 0x40, 0x85, 0x74, 0x88, 0xbe, 0x67, 0xd5,
+	0x33, 0xf5, 0x81, 0x2d, 0x90, 0x9e, 0x43, 0xf3, 0xd7, 0x16, 0x78, 0xe3, 0x39, 0x0a, 0xa5, 0x14,
+	0x1b, 0x1b, 0x99, 0xfe, 0x18, 0xcb, 0x54, 0x85, 0xea, 0x98, 0xf9, 0x6c, 0x6c, 0x0b, 0xa9, 0x94,
+	0x3b, 0x44, 0xaf, 0x5b, 0xd9, 0x9a, 0x3e, 0x41, 0xc5, 0x61, 0x23, 0x74, 0x02, 0xe5, 0x5f, 0xa7,
+	0xac, 0xd7, 0xcc, 0x9e, 0x91, 0xd3, 0x73, 0x1b, 0xaf, 0x31, 0x88, 0xe3, 0x7d, 0x57, 0x70, 0x69,
+	0xa5, 0xb3, 0xd4, 0x6b, 0xa8, 0xad, 0xc8, 0xb4, 0x09, 0xe5, 0x39, 0xca, 0xb4, 0x47, 0xf4, 0x4a,
+	0x0f, 0xe1, 0xff, 0x92, 0x39, 0x21, 0xa6, 0xc8, 0xc9, 0xe2, 0xa6, 0x74, 0x45, 0xb4, 0x1e, 0xa8,
+	0x05, 0xbf, 0xf3, 0x1d, 0x49, 0xdb, 0x00, 0x09, 0x16, 0x72, 0x9c, 0xc4, 0x03, 0xab, 0xd6, 0x8a,
+	0xa2, 0xdd, 0x43, 0x3b, 0x4b, 0x3f, 0xbb, 0x7f, 0xdf, 0x5e, 0xed, 0x16, 0x5a, 0x85, 0x83, 0x22,
+	0x90, 0x53, 0xa8, 0x87, 0xee, 0x70, 0x83, 0x65, 0x3f, 0x74, 0xad, 0x4c, 0x33, 0xbf, 0x08, 0x1c,
+	0xe5, 0x96, 0xa1, 0x6f, 0x04, 0x5a, 0x29, 0x51, 0xbe, 0xa1, 0xbb, 0xeb, 0x39, 0xa8, 0xc6, 0x0e,
+	0x89, 0xa8, 0x41, 0x1f, 0x1a, 0x69, 0x74, 0x60, 0x2f, 0xd1, 0xc5, 0x20, 0xa0, 0xc7, 0xc6, 0xcc,
+	0xf3, 0x66, 0x0e, 0x26, 0x57, 0x75, 0x14, 0x4e, 0x8d, 0x7e, 0x74, 0x73, 0xd5, 0x02, 0x5d, 0x27,
+	0x5d, 0x62, 0x7e, 0x12, 0x38, 0x29, 0xd8, 0x29, 0xfa, 0x4e, 0xa0, 0xbd, 0xde, 0x72, 0xcd, 0x62,
+	0x6e, 0xa5, 0xce, 0x3d, 0x42, 0xb5, 0xbb, 0x53, 0xc6, 0x77, 0xe4, 0xa8, 0x12, 0x83, 0x5f, 0x7e,
+	0x0f, 0x00, 0xba, 0xdf, 0xf8, 0xd3, 0xc5, 0x03, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
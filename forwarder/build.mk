@@ -12,10 +12,10 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
-forwarder_images = vppagent-forwarder kernel-forwarder
+forwarder_images = vppagent-forwarder kernel-forwarder simulated-forwarder
 
 # TODO: files in forwarder doesn't follow the regular structure: ./module/cmd/app,
-# after fixing 'kernel-forwarder' and 'vppagent-forwarder' targets could be eliminated
+# after fixing 'kernel-forwarder', 'simulated-forwarder' and 'vppagent-forwarder' targets could be eliminated
 .PHONY: go-kernel-forwarder-build
 go-kernel-forwarder-build: go-%-build:
 	$(info ----------------------  Building forwarder::$* via Cross compile ----------------------)
@@ -23,6 +23,13 @@ go-kernel-forwarder-build: go-%-build:
 	${GO_BUILD} -o $(BIN_DIR)/$*/$* ./kernel-forwarder/cmd/ && \
 	popd
 
+.PHONY: go-simulated-forwarder-build
+go-simulated-forwarder-build: go-%-build:
+	$(info ----------------------  Building forwarder::$* via Cross compile ----------------------)
+	@pushd ./forwarder && \
+	${GO_BUILD} -o $(BIN_DIR)/$*/$* ./simulated-forwarder/cmd/ && \
+	popd
+
 .PHONY: go-vppagent-forwarder-build
 go-vppagent-forwarder-build: go-%-build:
 	$(info ----------------------  Building forwarder::$* via Cross compile ----------------------)
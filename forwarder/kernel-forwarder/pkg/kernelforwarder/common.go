@@ -34,7 +34,7 @@ type LinkData struct {
 	nsHandle  netns.NsHandle // Desired namespace handler
 	name      string
 	tempName  string // Used in case src and dst name are the same causing the VETH creation to fail
-	ip        string
+	ips       []string
 	routes    []*connectioncontext.Route
 	neighbors []*connectioncontext.IpNeighbor
 }
@@ -46,10 +46,10 @@ func SetupInterface(ifaceName, tempName string, conn *connection.Connection, isD
 	netNsInode := conn.GetMechanism().GetParameters()[common.NetNsInodeKey]
 	link.neighbors = conn.GetContext().GetIpContext().GetIpNeighbors()
 	if isDst {
-		link.ip = conn.GetContext().GetIpContext().GetDstIpAddr()
+		link.ips = conn.GetContext().GetIpContext().DstIPAddrs()
 		link.routes = conn.GetContext().GetIpContext().GetSrcRoutes()
 	} else {
-		link.ip = conn.GetContext().GetIpContext().GetSrcIpAddr()
+		link.ips = conn.GetContext().GetIpContext().SrcIPAddrs()
 		link.routes = conn.GetContext().GetIpContext().GetDstRoutes()
 	}
 
@@ -82,7 +82,7 @@ func ClearInterfaceSetup(ifaceName string, conn *connection.Connection) (string,
 	var err error
 	link := &LinkData{name: ifaceName}
 	netNsInode := conn.GetMechanism().GetParameters()[common.NetNsInodeKey]
-	link.ip = conn.GetContext().GetIpContext().GetSrcIpAddr()
+	link.ips = conn.GetContext().GetIpContext().SrcIPAddrs()
 
 	/* Get namespace handler - source */
 	link.nsHandle, err = fs.GetNsHandleFromInode(netNsInode)
@@ -179,7 +179,6 @@ func setupLinkInNs(link *LinkData, inject bool) error {
 // setupLink configures the link - name, IP, routes, etc.
 func setupLink(l netlink.Link, link *LinkData) error {
 	var err error
-	var addr *netlink.Addr
 	/* Rename back the interface in case there was a naming conflict */
 	if link.tempName != "" {
 		if err = netlink.LinkSetName(l, link.tempName); err != nil {
@@ -189,16 +188,21 @@ func setupLink(l netlink.Link, link *LinkData) error {
 		}
 		link.name = link.tempName
 	}
-	/* Parse the IP address */
-	addr, err = netlink.ParseAddr(link.ip)
-	if err != nil {
-		logrus.Errorf("common: failed to parse IP %q: %v", link.ip, err)
-		return err
-	}
-	/* Set IP address */
-	if err = netlink.AddrAdd(l, addr); err != nil {
-		logrus.Errorf("common: failed to set IP %q: %v", link.ip, err)
-		return err
+	/* Set IP addresses, dual-stack connections have an address per IP family */
+	addrs := make([]*netlink.Addr, 0, len(link.ips))
+	for _, ip := range link.ips {
+		/* Parse the IP address */
+		addr, err := netlink.ParseAddr(ip)
+		if err != nil {
+			logrus.Errorf("common: failed to parse IP %q: %v", ip, err)
+			return err
+		}
+		/* Set IP address */
+		if err = netlink.AddrAdd(l, addr); err != nil {
+			logrus.Errorf("common: failed to set IP %q: %v", ip, err)
+			return err
+		}
+		addrs = append(addrs, addr)
 	}
 	/* Bring the interface UP */
 	if err = netlink.LinkSetUp(l); err != nil {
@@ -206,7 +210,7 @@ func setupLink(l netlink.Link, link *LinkData) error {
 		return err
 	}
 	/* Add routes */
-	if err = addRoutes(l, addr, link.routes); err != nil {
+	if err = addRoutes(l, addrs, link.routes); err != nil {
 		logrus.Error("common: failed adding routes:", err)
 		return err
 	}
@@ -218,8 +222,8 @@ func setupLink(l netlink.Link, link *LinkData) error {
 	return err
 }
 
-// addRoutes adds routes
-func addRoutes(link netlink.Link, addr *netlink.Addr, routes []*connectioncontext.Route) error {
+// addRoutes adds routes via the address of the same IP family
+func addRoutes(link netlink.Link, addrs []*netlink.Addr, routes []*connectioncontext.Route) error {
 	for _, route := range routes {
 		_, routeNet, err := net.ParseCIDR(route.GetPrefix())
 		if err != nil {
@@ -232,7 +236,7 @@ func addRoutes(link netlink.Link, addr *netlink.Addr, routes []*connectioncontex
 				IP:   routeNet.IP,
 				Mask: routeNet.Mask,
 			},
-			Src: addr.IP,
+			Src: routeSrc(addrs, routeNet.IP),
 		}
 		if err = netlink.RouteAdd(&route); err != nil {
 			logrus.Error("common: failed adding routes:", err)
@@ -242,6 +246,16 @@ func addRoutes(link netlink.Link, addr *netlink.Addr, routes []*connectioncontex
 	return nil
 }
 
+// routeSrc returns the address of the same IP family as the route destination
+func routeSrc(addrs []*netlink.Addr, dst net.IP) net.IP {
+	for _, addr := range addrs {
+		if (addr.IP.To4() == nil) == (dst.To4() == nil) {
+			return addr.IP
+		}
+	}
+	return nil
+}
+
 // addNeighbors adds neighbors
 func addNeighbors(link netlink.Link, neighbors []*connectioncontext.IpNeighbor) error {
 	for _, neighbor := range neighbors {
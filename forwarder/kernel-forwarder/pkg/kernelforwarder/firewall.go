@@ -0,0 +1,148 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernelforwarder
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/common"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
+	"github.com/networkservicemesh/networkservicemesh/sdk/firewall"
+	"github.com/networkservicemesh/networkservicemesh/utils"
+	"github.com/networkservicemesh/networkservicemesh/utils/fs"
+)
+
+// FirewallConfigFile - path to the firewall configuration applied to the client interfaces, firewall is disabled if
+// empty
+var FirewallConfigFile = utils.EnvVar("FIREWALL_CONFIG_FILE")
+
+// configureFirewall loads the firewall configuration if it is set
+func (k *KernelForwarder) configureFirewall() error {
+	path := FirewallConfigFile.StringValue()
+	if path == "" {
+		return nil
+	}
+	config, err := firewall.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("kernel-forwarder: loaded %d firewall rules from %s", len(config.Rules), path)
+	k.firewall = config
+	return nil
+}
+
+// useFirewall returns handler programming nftables rules for the client interface of the local source in the client
+// network namespace, firewall is disabled if config is nil. Rules are configured for the endpoint side, so their
+// directions are reversed. Interface does not need to exist, so the rules are applied before the connection is created
+// by the next handlers and removed after it is deleted.
+func useFirewall(config *firewall.Config) forwarder.ForwarderServer {
+	return &firewallHandler{
+		config:  config,
+		applied: map[string][]*firewall.Match{},
+	}
+}
+
+type firewallHandler struct {
+	config *firewall.Config
+	mtx    sync.Mutex
+	// applied - matches applied for the established cross connects
+	applied map[string][]*firewall.Match
+}
+
+func (f *firewallHandler) Request(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
+	matches, err := f.bind(crossConnect)
+	if err != nil {
+		return nil, err
+	}
+	f.mtx.Lock()
+	previous, update := f.applied[crossConnect.GetId()]
+	f.mtx.Unlock()
+
+	if err = f.apply(crossConnect, matches); err != nil {
+		return nil, err
+	}
+	resp, err := chain.NextRequest(ctx, crossConnect)
+	if err != nil {
+		// Failed update keeps the cross connect, so it keeps the previous rules
+		if update {
+			if firewallErr := f.apply(crossConnect, previous); firewallErr != nil {
+				logrus.Errorf("kernel-forwarder: failed to restore firewall rules: %v", firewallErr)
+			}
+			return nil, err
+		}
+		if firewallErr := f.apply(crossConnect, nil); firewallErr != nil {
+			logrus.Errorf("kernel-forwarder: failed to remove firewall rules: %v", firewallErr)
+		}
+		return nil, err
+	}
+
+	f.mtx.Lock()
+	f.applied[crossConnect.GetId()] = matches
+	f.mtx.Unlock()
+	return resp, nil
+}
+
+func (f *firewallHandler) Close(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
+	resp, err := chain.NextClose(ctx, crossConnect)
+	f.mtx.Lock()
+	delete(f.applied, crossConnect.GetId())
+	f.mtx.Unlock()
+	if firewallErr := f.apply(crossConnect, nil); firewallErr != nil {
+		logrus.Errorf("kernel-forwarder: failed to remove firewall rules: %v", firewallErr)
+	}
+	return resp, err
+}
+
+// bind returns the client side matches for the local source
+func (f *firewallHandler) bind(crossConnect *crossconnect.CrossConnect) ([]*firewall.Match, error) {
+	src := crossConnect.GetLocalSource()
+	if f.config == nil || src.GetMechanism().GetType() != kernel.MECHANISM {
+		return nil, nil
+	}
+	matches, err := f.config.Bind(firewall.NewContexts(src.GetContext().GetIpContext())...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "firewall: failed to bind rules for %s", src.GetMechanism().GetParameters()[common.InterfaceNameKey])
+	}
+	return firewall.Reverse(matches), nil
+}
+
+// apply programs nftables rules for the client interface of the local source, rules are removed if there are no matches
+func (f *firewallHandler) apply(crossConnect *crossconnect.CrossConnect, matches []*firewall.Match) error {
+	src := crossConnect.GetLocalSource()
+	if f.config == nil || src.GetMechanism().GetType() != kernel.MECHANISM {
+		return nil
+	}
+	ifaceName := src.GetMechanism().GetParameters()[common.InterfaceNameKey]
+
+	nsHandle, err := fs.GetNsHandleFromInode(src.GetMechanism().GetParameters()[common.NetNsInodeKey])
+	if err != nil {
+		return errors.Wrapf(err, "firewall: failed to get client namespace handle of %s", ifaceName)
+	}
+	defer func() {
+		if err := nsHandle.Close(); err != nil {
+			logrus.Error("firewall: error when closing client namespace handle: ", err)
+		}
+	}()
+
+	return firewall.ApplyNftables(int(nsHandle), ifaceName, matches)
+}
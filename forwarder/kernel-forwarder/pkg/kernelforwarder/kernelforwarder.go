@@ -16,115 +16,79 @@
 package kernelforwarder
 
 import (
-	"context"
-
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/status"
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/geneve"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/gre"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/ipsec"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/srv6"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
-	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/kernel-forwarder/pkg/kernelforwarder/local"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/kernel-forwarder/pkg/kernelforwarder/remote"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/kernel-forwarder/pkg/monitoring"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/common"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
+	"github.com/networkservicemesh/networkservicemesh/sdk/firewall"
+	"github.com/networkservicemesh/networkservicemesh/utils"
 )
 
+// IPsecEncapsulation - encapsulation of the IPsec connections requested by the forwarder: "" or "VXLAN"
+var IPsecEncapsulation = utils.EnvVar("IPSEC_ENCAPSULATION")
+
 // KernelForwarder instance
 type KernelForwarder struct {
-	common        *common.ForwarderConfig
-	monitoring    *monitoring.Metrics
-	localConnect  *local.Connect
-	remoteConnect *remote.Connect
+	common     *common.ForwarderConfig
+	monitoring *monitoring.Metrics
+	firewall   *firewall.Config
+	ipsecKeys  *remote.IPsecKeyPair
 }
 
 // CreateKernelForwarder creates an instance of the KernelForwarder
 func CreateKernelForwarder() *KernelForwarder {
-	return &KernelForwarder{
-		localConnect:  local.NewConnect(),
-		remoteConnect: remote.NewConnect(),
-	}
+	return &KernelForwarder{}
 }
 
 // Init initializes the Kernel forwarding plane
 func (k *KernelForwarder) Init(common *common.ForwarderConfig) error {
 	k.common = common
 	k.common.Name = "kernel-forwarder"
-	k.configureKernelForwarder()
-	return nil
-}
-
-// CreateForwarderServer creates an instance of ForwarderServer
-func (k *KernelForwarder) CreateForwarderServer(config *common.ForwarderConfig) forwarder.ForwarderServer {
-	return k
-}
-
-// Request handler for connections
-func (k *KernelForwarder) Request(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
-	logrus.Infof("Request() called with %v", crossConnect)
-
-	if err := crossConnect.IsValid(); err != nil {
-		logrus.Errorf("Close: %v is not valid, reason: %v", crossConnect, err)
-		return crossConnect, err
-	}
-
-	err := k.connectOrDisconnect(crossConnect, true)
-	if err != nil {
-		logrus.Warn("error while handling Request() connection:", err)
-		return nil, err
+	if err := k.configureFirewall(); err != nil {
+		return err
 	}
-	k.common.Monitor.Update(ctx, crossConnect)
-	return crossConnect, err
-}
-
-// Close handler for connections
-func (k *KernelForwarder) Close(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
-	logrus.Infof("Close() called with %#v", crossConnect)
-	err := k.connectOrDisconnect(crossConnect, false)
+	ipsecKeys, err := remote.NewIPsecKeyPair()
 	if err != nil {
-		logrus.Warn("error while handling Close() connection:", err)
-	}
-	k.common.Monitor.Delete(ctx, crossConnect)
-	return &empty.Empty{}, nil
-}
-
-func (k *KernelForwarder) connectOrDisconnect(crossConnect *crossconnect.CrossConnect, connect bool) error {
-	var err error
-	var devices map[string]monitoring.Device
-
-	if k.common.MetricsEnabled {
-		k.monitoring.GetDevices().Lock()
-		defer k.monitoring.GetDevices().Unlock()
-	}
-
-	/* 0. Sanity check whether the forwarding plane supports the connection type in the request */
-	if err = common.SanityCheckConnectionType(k.common.Mechanisms, crossConnect); err != nil {
 		return err
 	}
+	k.ipsecKeys = ipsecKeys
+	k.configureKernelForwarder()
+	return nil
+}
 
-	/* 1. Handle local connection */
-	if crossConnect.GetSource().GetMechanism().GetType() == kernel.MECHANISM && crossConnect.GetDestination().GetMechanism().GetType() == kernel.MECHANISM {
-		devices, err = k.handleLocalConnection(crossConnect, connect)
-	} else {
-		/* 2. Handle remote connection */
-		devices, err = k.handleRemoteConnection(crossConnect, connect)
-	}
-	if devices != nil && err == nil {
-		if connect {
-			logrus.Info("kernel-forwarder: created devices: ", devices)
-		} else {
-			logrus.Info("kernel-forwarder: deleted devices: ", devices)
-		}
-		// Metrics monitoring
-		if k.common.MetricsEnabled {
-			k.monitoring.GetDevices().UpdateDeviceList(devices, connect)
-		}
-	}
-	return err
+// CreateForwarderServer creates an instance of ForwarderServer. Each mechanism is handled by its own chain handler,
+// handlers ignore cross connects of the other mechanisms. Cross connects not handled by any of them are rejected.
+// Monitor goes first, so closed cross connect is always deleted from the monitor.
+func (k *KernelForwarder) CreateForwarderServer(config *common.ForwarderConfig) forwarder.ForwarderServer {
+	return chain.ChainOf(
+		chain.UseCrossConnectMonitor(config.Monitor),
+		chain.RequestValidator(),
+		chain.CheckMechanisms(config),
+		useMetrics(k.monitoring),
+		useFirewall(k.firewall),
+		chain.UseQoS(),
+		localInterfaces(local.NewConnect()),
+		remoteInterfaces(remote.NewVXLAN()),
+		remoteInterfaces(remote.NewWireguard()),
+		remoteInterfaces(remote.NewGRE()),
+		remoteInterfaces(remote.NewGeneve()),
+		remoteInterfaces(remote.NewSRv6()),
+		remoteInterfaces(remote.NewIPsec(k.ipsecKeys)),
+		unhandledConnections())
 }
 
 // configureKernelForwarder setups the Kernel forwarding plane
@@ -149,8 +113,29 @@ func (k *KernelForwarder) configureKernelForwarder() {
 					wireguard.SrcIP: k.common.EgressInterface.SrcIPNet().IP.String(),
 				},
 			},
+			{
+				Type: gre.MECHANISM,
+				Parameters: map[string]string{
+					gre.SrcIP: k.common.EgressInterface.SrcIPNet().IP.String(),
+				},
+			},
+			{
+				Type: geneve.MECHANISM,
+				Parameters: map[string]string{
+					geneve.SrcIP: k.common.EgressInterface.SrcIPNet().IP.String(),
+				},
+			},
+			{
+				Type: ipsec.MECHANISM,
+				Parameters: map[string]string{
+					ipsec.SrcIP:         k.common.EgressInterface.SrcIPNet().IP.String(),
+					ipsec.SrcPublicKey:  k.ipsecKeys.PublicKey(),
+					ipsec.Encapsulation: IPsecEncapsulation.StringValue(),
+				},
+			},
 		},
 	}
+	k.configureSRv6()
 	// Metrics monitoring
 	if k.common.MetricsEnabled {
 		k.monitoring = monitoring.CreateMetricsMonitor(k.common.MetricsPeriod)
@@ -160,6 +145,26 @@ func (k *KernelForwarder) configureKernelForwarder() {
 	common.CreateNSMonitor(k.common.Monitor, nsmonitorCallback)
 }
 
+// configureSRv6 advertises SRv6 remote mechanism with the same parameters as the vppagent forwarder
+func (k *KernelForwarder) configureSRv6() {
+	if k.common.EgressInterface.SrcLocalSID() == nil {
+		logrus.Warnf("SRv6 remote mechanism is not supported: Mgmt Interface does not have local IPv6 address")
+		return
+	}
+	if err := remote.EnableSRv6(k.common.EgressInterface.Name()); err != nil {
+		logrus.Warnf("SRv6 remote mechanism is not supported: %v", err)
+		return
+	}
+	k.common.Mechanisms.RemoteMechanisms = append(k.common.Mechanisms.RemoteMechanisms, &connection.Mechanism{
+		Type: srv6.MECHANISM,
+		Parameters: map[string]string{
+			srv6.SrcHostIP:          k.common.EgressInterface.SrcIPV6Net().IP.String(),
+			srv6.SrcHostLocalSID:    k.common.EgressInterface.SrcLocalSID().String(),
+			srv6.SrcHardwareAddress: k.common.EgressInterface.HardwareAddr().String(),
+		},
+	})
+}
+
 // MonitorMechanisms handler
 func (k *KernelForwarder) MonitorMechanisms(empty *empty.Empty, updateSrv forwarder.MechanismsMonitor_MonitorMechanismsServer) error {
 	initialUpdate := &forwarder.MechanismUpdate{
@@ -16,44 +16,82 @@
 package kernelforwarder
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"runtime"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/common"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/kernel-forwarder/pkg/kernelforwarder/local"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/kernel-forwarder/pkg/monitoring"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
 )
 
-// handleLocalConnection either creates or deletes a local connection - same host
-func (k *KernelForwarder) handleLocalConnection(crossConnect *crossconnect.CrossConnect, connect bool) (map[string]monitoring.Device, error) {
+// localInterfaces returns handler creating and deleting VETH pair of the local connection - same host
+func localInterfaces(connect *local.Connect) forwarder.ForwarderServer {
+	return &localHandler{
+		connect: connect,
+	}
+}
+
+type localHandler struct {
+	connect *local.Connect
+}
+
+func (l *localHandler) Request(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
+	if !isLocalConnection(crossConnect) {
+		return chain.NextRequest(ctx, crossConnect)
+	}
 	logrus.Info("local: connection type - local source/local destination")
-	var devices map[string]monitoring.Device
-	var err error
-	if connect {
-		/* 2. Create a connection */
-		devices, err = k.createLocalConnection(crossConnect)
-		if err != nil {
-			logrus.Errorf("local: failed to create connection - %v", err)
-			devices = nil
-		}
-	} else {
-		/* 3. Delete a connection */
-		devices, err = k.deleteLocalConnection(crossConnect)
-		if err != nil {
-			logrus.Errorf("local: failed to delete connection - %v", err)
-			devices = nil
+	devices, err := l.createLocalConnection(crossConnect)
+	if err != nil {
+		logrus.Errorf("local: failed to create connection - %v", err)
+		return nil, err
+	}
+	resp, err := chain.NextRequest(withHandled(ctx), crossConnect)
+	if err != nil {
+		if _, deleteErr := l.deleteLocalConnection(crossConnect); deleteErr != nil {
+			logrus.Errorf("local: failed to delete connection - %v", deleteErr)
 		}
+		return nil, err
+	}
+	logrus.Info("kernel-forwarder: created devices: ", devices)
+	addDevices(ctx, devices)
+	return resp, nil
+}
+
+func (l *localHandler) Close(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
+	if !isLocalConnection(crossConnect) {
+		return chain.NextClose(ctx, crossConnect)
 	}
-	return devices, err
+	logrus.Info("local: connection type - local source/local destination")
+	resp, err := chain.NextClose(withHandled(ctx), crossConnect)
+	devices, deleteErr := l.deleteLocalConnection(crossConnect)
+	if deleteErr != nil {
+		logrus.Errorf("local: failed to delete connection - %v", deleteErr)
+		return nil, deleteErr
+	}
+	logrus.Info("kernel-forwarder: deleted devices: ", devices)
+	addDevices(ctx, devices)
+	return resp, err
+}
+
+// isLocalConnection returns true if both source and destination use kernel mechanism
+func isLocalConnection(crossConnect *crossconnect.CrossConnect) bool {
+	return crossConnect.GetSource().GetMechanism().GetType() == kernel.MECHANISM &&
+		crossConnect.GetDestination().GetMechanism().GetType() == kernel.MECHANISM
 }
 
 // createLocalConnection handles creating a local connection
-func (k *KernelForwarder) createLocalConnection(crossConnect *crossconnect.CrossConnect) (map[string]monitoring.Device, error) {
+func (l *localHandler) createLocalConnection(crossConnect *crossconnect.CrossConnect) (map[string]monitoring.Device, error) {
 	logrus.Info("local: creating connection...")
 	/* Lock the OS thread so we don't accidentally switch namespaces */
 	runtime.LockOSThread()
@@ -78,7 +116,7 @@ func (k *KernelForwarder) createLocalConnection(crossConnect *crossconnect.Cross
 
 	logrus.Infof("local: creating connection for: %s, %s", srcName, dstName)
 
-	if err = k.localConnect.CreateInterfaces(srcName, dstName); err != nil {
+	if err = l.connect.CreateInterfaces(srcName, dstName); err != nil {
 		logrus.Errorf("local: %v", err)
 		return nil, err
 	}
@@ -99,13 +137,13 @@ func (k *KernelForwarder) createLocalConnection(crossConnect *crossconnect.Cross
 
 	logrus.Infof("local: creation completed for devices - source: %s, destination: %s", srcName, dstName)
 
-	srcDevice := monitoring.Device{Name: srcName, XconName: "SRC-" + crossConnect.GetId()}
-	dstDevice := monitoring.Device{Name: dstName, XconName: "DST-" + crossConnect.GetId()}
+	srcDevice := monitoring.Device{Name: srcName, XconName: "SRC-" + crossConnect.GetId(), Mechanism: crossConnect.GetLocalSource().GetMechanism().GetType()}
+	dstDevice := monitoring.Device{Name: dstName, XconName: "DST-" + crossConnect.GetId(), Mechanism: crossConnect.GetLocalDestination().GetMechanism().GetType()}
 	return map[string]monitoring.Device{srcNetNsInode: srcDevice, dstNetNsInode: dstDevice}, nil
 }
 
 // deleteLocalConnection handles deleting a local connection
-func (k *KernelForwarder) deleteLocalConnection(crossConnect *crossconnect.CrossConnect) (map[string]monitoring.Device, error) {
+func (l *localHandler) deleteLocalConnection(crossConnect *crossconnect.CrossConnect) (map[string]monitoring.Device, error) {
 	logrus.Info("local: deleting connection...")
 	/* Lock the OS thread so we don't accidentally switch namespaces */
 	runtime.LockOSThread()
@@ -117,14 +155,14 @@ func (k *KernelForwarder) deleteLocalConnection(crossConnect *crossconnect.Cross
 	srcNetNsInode, srcErr := ClearInterfaceSetup(srcName, crossConnect.GetSource())
 	dstNetNsInode, dstErr := ClearInterfaceSetup(dstName, crossConnect.GetDestination())
 
-	err := k.localConnect.DeleteInterfaces(srcName)
+	err := l.connect.DeleteInterfaces(srcName)
 
 	if srcErr != nil || dstErr != nil || err != nil {
 		return nil, errors.Errorf("local: %v - %v", srcErr, dstErr)
 	}
 
 	logrus.Infof("local: deletion completed for devices - source: %s, destination: %s", srcName, dstName)
-	srcDevice := monitoring.Device{Name: srcName, XconName: "SRC-" + crossConnect.GetId()}
-	dstDevice := monitoring.Device{Name: dstName, XconName: "DST-" + crossConnect.GetId()}
+	srcDevice := monitoring.Device{Name: srcName, XconName: "SRC-" + crossConnect.GetId(), Mechanism: crossConnect.GetLocalSource().GetMechanism().GetType()}
+	dstDevice := monitoring.Device{Name: dstName, XconName: "DST-" + crossConnect.GetId(), Mechanism: crossConnect.GetLocalDestination().GetMechanism().GetType()}
 	return map[string]monitoring.Device{srcNetNsInode: srcDevice, dstNetNsInode: dstDevice}, nil
 }
@@ -0,0 +1,86 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernelforwarder
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/kernel-forwarder/pkg/monitoring"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
+)
+
+type contextKeyType string
+
+const devicesKey contextKeyType = "devicesKey"
+
+// withDevices puts into context devices map filled by the interfaces handlers
+func withDevices(ctx context.Context, devices map[string]monitoring.Device) context.Context {
+	return context.WithValue(ctx, devicesKey, devices)
+}
+
+// addDevices adds devices created or deleted by the interfaces handler to the context devices map, if any
+func addDevices(ctx context.Context, devices map[string]monitoring.Device) {
+	if ctxDevices, ok := ctx.Value(devicesKey).(map[string]monitoring.Device); ok {
+		for nsInode, device := range devices {
+			ctxDevices[nsInode] = device
+		}
+	}
+}
+
+// useMetrics returns handler registering devices created by the next handlers for the metrics collection and
+// unregistering deleted ones, metrics are disabled if monitoring is nil
+func useMetrics(metrics *monitoring.Metrics) forwarder.ForwarderServer {
+	return &metricsHandler{
+		metrics: metrics,
+	}
+}
+
+type metricsHandler struct {
+	metrics *monitoring.Metrics
+}
+
+func (m *metricsHandler) Request(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
+	if m.metrics == nil {
+		return chain.NextRequest(ctx, crossConnect)
+	}
+	m.metrics.GetDevices().Lock()
+	defer m.metrics.GetDevices().Unlock()
+
+	devices := map[string]monitoring.Device{}
+	resp, err := chain.NextRequest(withDevices(ctx, devices), crossConnect)
+	if err == nil {
+		m.metrics.GetDevices().UpdateDeviceList(devices, true)
+	}
+	return resp, err
+}
+
+func (m *metricsHandler) Close(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
+	if m.metrics == nil {
+		return chain.NextClose(ctx, crossConnect)
+	}
+	m.metrics.GetDevices().Lock()
+	defer m.metrics.GetDevices().Unlock()
+
+	devices := map[string]monitoring.Device{}
+	resp, err := chain.NextClose(withDevices(ctx, devices), crossConnect)
+	if err == nil {
+		m.metrics.GetDevices().UpdateDeviceList(devices, false)
+	}
+	return resp, err
+}
@@ -16,58 +16,98 @@
 package kernelforwarder
 
 import (
+	"context"
 	"runtime"
 
+	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
 	common2 "github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/common"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
 	. "github.com/networkservicemesh/networkservicemesh/forwarder/kernel-forwarder/pkg/kernelforwarder/remote"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/kernel-forwarder/pkg/monitoring"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/wgbridge"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
 )
 
-// handleRemoteConnection handles remote connect/disconnect requests for either incoming or outgoing connections
-func (k *KernelForwarder) handleRemoteConnection(crossConnect *crossconnect.CrossConnect, connect bool) (map[string]monitoring.Device, error) {
-	if crossConnect.GetSource().IsRemote() && !crossConnect.GetDestination().IsRemote() {
-		/* 1. Incoming remote connection */
-		logrus.Info("remote: connection type - remote source/local destination - incoming")
-		return k.handleConnection(crossConnect.GetId(), crossConnect.GetDestination(), crossConnect.GetSource(), connect, INCOMING)
-	} else if !crossConnect.GetSource().IsRemote() && crossConnect.GetDestination().IsRemote() {
-		/* 2. Outgoing remote connection */
-		logrus.Info("remote: connection type - local source/remote destination - outgoing")
-		return k.handleConnection(crossConnect.GetId(), crossConnect.GetSource(), crossConnect.GetDestination(), connect, OUTGOING)
+// remoteInterfaces returns handler creating and deleting interfaces of the remote connections using the mechanism,
+// for either incoming or outgoing connections
+func remoteInterfaces(mechanism Mechanism) forwarder.ForwarderServer {
+	return &remoteHandler{
+		mechanism: mechanism,
 	}
-	err := errors.Errorf("remote: invalid connection type")
-	logrus.Errorf("%+v", err)
-	return nil, err
 }
 
-// handleConnection process the request to either creating or deleting a connection
-func (k *KernelForwarder) handleConnection(connID string, localConnection, remoteConnection *connection.Connection, connect bool, direction uint8) (map[string]monitoring.Device, error) {
-	var devices map[string]monitoring.Device
-	var err error
-	if connect {
-		/* 2. Create a connection */
-		devices, err = k.createRemoteConnection(connID, localConnection, remoteConnection, direction)
-		if err != nil {
-			logrus.Errorf("remote: failed to create connection - %v", err)
-			devices = nil
+type remoteHandler struct {
+	mechanism Mechanism
+}
+
+func (r *remoteHandler) Request(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
+	localConnection, remoteConnection, direction, ok := r.connections(crossConnect)
+	if !ok {
+		return chain.NextRequest(ctx, crossConnect)
+	}
+	devices, err := r.createRemoteConnection(crossConnect.GetId(), localConnection, remoteConnection, direction)
+	if err != nil {
+		logrus.Errorf("remote: failed to create connection - %v", err)
+		return nil, err
+	}
+	resp, err := chain.NextRequest(withHandled(ctx), crossConnect)
+	if err != nil {
+		if _, deleteErr := r.deleteRemoteConnection(crossConnect.GetId(), localConnection, remoteConnection, direction); deleteErr != nil {
+			logrus.Errorf("remote: failed to delete connection - %v", deleteErr)
 		}
-	} else {
-		/* 3. Delete a connection */
-		devices, err = k.deleteRemoteConnection(connID, localConnection, remoteConnection, direction)
-		if err != nil {
-			logrus.Errorf("remote: failed to delete connection - %v", err)
-			devices = nil
+		return nil, err
+	}
+	logrus.Info("kernel-forwarder: created devices: ", devices)
+	addDevices(ctx, devices)
+	return resp, nil
+}
+
+func (r *remoteHandler) Close(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
+	localConnection, remoteConnection, direction, ok := r.connections(crossConnect)
+	if !ok {
+		return chain.NextClose(ctx, crossConnect)
+	}
+	resp, err := chain.NextClose(withHandled(ctx), crossConnect)
+	devices, deleteErr := r.deleteRemoteConnection(crossConnect.GetId(), localConnection, remoteConnection, direction)
+	if deleteErr != nil {
+		logrus.Errorf("remote: failed to delete connection - %v", deleteErr)
+		return nil, deleteErr
+	}
+	logrus.Info("kernel-forwarder: deleted devices: ", devices)
+	addDevices(ctx, devices)
+	return resp, err
+}
+
+// connections returns local and remote connections and the direction of the cross connect, ok is false if it does not
+// use the handler mechanism
+func (r *remoteHandler) connections(crossConnect *crossconnect.CrossConnect) (localConnection, remoteConnection *connection.Connection, direction uint8, ok bool) {
+	if src := crossConnect.GetSource(); src.IsRemote() && !crossConnect.GetDestination().IsRemote() {
+		/* 1. Incoming remote connection */
+		if src.GetMechanism().GetType() != r.mechanism.Type() {
+			return nil, nil, 0, false
+		}
+		logrus.Info("remote: connection type - remote source/local destination - incoming")
+		return crossConnect.GetDestination(), src, INCOMING, true
+	}
+	if dst := crossConnect.GetDestination(); dst.IsRemote() && !crossConnect.GetSource().IsRemote() {
+		/* 2. Outgoing remote connection */
+		if dst.GetMechanism().GetType() != r.mechanism.Type() {
+			return nil, nil, 0, false
 		}
+		logrus.Info("remote: connection type - local source/remote destination - outgoing")
+		return crossConnect.GetSource(), dst, OUTGOING, true
 	}
-	return devices, err
+	return nil, nil, 0, false
 }
 
 // createRemoteConnection handler for creating a remote connection
-func (k *KernelForwarder) createRemoteConnection(connID string, localConnection, remoteConnection *connection.Connection, direction uint8) (map[string]monitoring.Device, error) {
+func (r *remoteHandler) createRemoteConnection(connID string, localConnection, remoteConnection *connection.Connection, direction uint8) (map[string]monitoring.Device, error) {
 	logrus.Info("remote: creating connection...")
 
 	var xconName string
@@ -78,13 +118,23 @@ func (k *KernelForwarder) createRemoteConnection(connID string, localConnection,
 	}
 	ifaceName := localConnection.GetMechanism().GetParameters()[common2.InterfaceNameKey]
 	var nsInode string
-	var err error
 
 	/* Lock the OS thread so we don't accidentally switch namespaces */
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
 
-	if err = k.remoteConnect.CreateInterface(ifaceName, remoteConnection, direction); err != nil {
+	updated, err := r.mechanism.UpdateInterface(remoteConnection, direction)
+	if err != nil {
+		logrus.Errorf("remote: %v", err)
+		return nil, err
+	}
+	if updated {
+		nsInode = localConnection.GetMechanism().GetParameters()[common2.NetNsInodeKey]
+		logrus.Infof("remote: update completed for device - %s", ifaceName)
+		return map[string]monitoring.Device{nsInode: newRemoteDevice(ifaceName, xconName, remoteConnection)}, nil
+	}
+
+	if err = r.mechanism.CreateInterface(ifaceName, remoteConnection, direction); err != nil {
 		logrus.Errorf("remote: %v", err)
 		return nil, err
 	}
@@ -95,11 +145,11 @@ func (k *KernelForwarder) createRemoteConnection(connID string, localConnection,
 	}
 
 	logrus.Infof("remote: creation completed for device - %s", ifaceName)
-	return map[string]monitoring.Device{nsInode: {Name: ifaceName, XconName: xconName}}, nil
+	return map[string]monitoring.Device{nsInode: newRemoteDevice(ifaceName, xconName, remoteConnection)}, nil
 }
 
 // deleteRemoteConnection handler for deleting a remote connection
-func (k *KernelForwarder) deleteRemoteConnection(connID string, localConnection, remoteConnection *connection.Connection, direction uint8) (map[string]monitoring.Device, error) {
+func (r *remoteHandler) deleteRemoteConnection(connID string, localConnection, remoteConnection *connection.Connection, direction uint8) (map[string]monitoring.Device, error) {
 	logrus.Info("remote: deleting connection...")
 
 	ifaceName := localConnection.GetMechanism().GetParameters()[common2.InterfaceNameKey]
@@ -115,12 +165,22 @@ func (k *KernelForwarder) deleteRemoteConnection(connID string, localConnection,
 	defer runtime.UnlockOSThread()
 
 	nsInode, localErr := ClearInterfaceSetup(ifaceName, localConnection)
-	remoteErr := k.remoteConnect.DeleteInterface(ifaceName, remoteConnection)
+	remoteErr := r.mechanism.DeleteInterface(ifaceName, remoteConnection)
 
 	if localErr != nil || remoteErr != nil {
 		return nil, errors.Errorf("remote: %v - %v", localErr, remoteErr)
 	}
 
 	logrus.Infof("remote: deletion completed for device - %s", ifaceName)
-	return map[string]monitoring.Device{nsInode: {Name: ifaceName, XconName: xconName}}, nil
+	return map[string]monitoring.Device{nsInode: newRemoteDevice(ifaceName, xconName, remoteConnection)}, nil
+}
+
+// newRemoteDevice returns monitored device of the remote connection, WireGuard peer statistics are read from the
+// host namespace WireGuard device the pod interface is bridged to
+func newRemoteDevice(ifaceName, xconName string, remoteConnection *connection.Connection) monitoring.Device {
+	device := monitoring.Device{Name: ifaceName, XconName: xconName, Mechanism: remoteConnection.GetMechanism().GetType()}
+	if device.Mechanism == wireguard.MECHANISM {
+		device.Tunnel, _ = wgbridge.Names(remoteConnection)
+	}
+	return device
 }
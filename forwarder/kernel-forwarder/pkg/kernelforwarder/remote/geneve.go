@@ -0,0 +1,74 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/geneve"
+)
+
+type geneveInterfaces struct {
+	noUpdate
+}
+
+// NewGeneve - creates Geneve remote mechanism interfaces handler
+func NewGeneve() Mechanism {
+	return &geneveInterfaces{}
+}
+
+// Type returns Geneve mechanism type
+func (g *geneveInterfaces) Type() string {
+	return geneve.MECHANISM
+}
+
+// CreateInterface creates a Geneve interface
+func (g *geneveInterfaces) CreateInterface(ifaceName string, remoteConnection *connection.Connection, direction uint8) error {
+	_, remoteIP, err := tunnelPeers(geneve.ToMechanism(remoteConnection.GetMechanism()), direction)
+	if err != nil {
+		return err
+	}
+	vni, err := geneve.ToMechanism(remoteConnection.GetMechanism()).VNI()
+	if err != nil {
+		return err
+	}
+
+	/* Create interface - host namespace */
+	if err := netlink.LinkAdd(newGeneve(ifaceName, remoteIP, vni)); err != nil {
+		return errors.Wrapf(err, "failed to create Geneve interface")
+	}
+	return nil
+}
+
+// DeleteInterface deletes the Geneve interface
+func (g *geneveInterfaces) DeleteInterface(ifaceName string, _ *connection.Connection) error {
+	return deleteTunnelInterface(ifaceName, geneve.MECHANISM)
+}
+
+// newGeneve returns a Geneve interface instance. Kernel Geneve device has no local address, packets are sent
+// from the address of the route to the remote peer.
+func newGeneve(ifaceName string, remoteIP net.IP, vni uint32) *netlink.Geneve {
+	return &netlink.Geneve{
+		LinkAttrs: netlink.LinkAttrs{
+			Name: ifaceName,
+		},
+		ID:     vni,
+		Remote: remoteIP,
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/gre"
+)
+
+type greInterfaces struct {
+	noUpdate
+}
+
+// NewGRE - creates GRE remote mechanism interfaces handler
+func NewGRE() Mechanism {
+	return &greInterfaces{}
+}
+
+// Type returns GRE mechanism type
+func (g *greInterfaces) Type() string {
+	return gre.MECHANISM
+}
+
+// CreateInterface creates a GRE interface
+func (g *greInterfaces) CreateInterface(ifaceName string, remoteConnection *connection.Connection, direction uint8) error {
+	localIP, remoteIP, err := tunnelPeers(gre.ToMechanism(remoteConnection.GetMechanism()), direction)
+	if err != nil {
+		return err
+	}
+	key, err := gre.ToMechanism(remoteConnection.GetMechanism()).Key()
+	if err != nil {
+		return err
+	}
+
+	/* Create interface - host namespace */
+	if err := netlink.LinkAdd(newGRE(ifaceName, localIP, remoteIP, key)); err != nil {
+		return errors.Wrapf(err, "failed to create GRE interface")
+	}
+	return nil
+}
+
+// DeleteInterface deletes the GRE interface
+func (g *greInterfaces) DeleteInterface(ifaceName string, _ *connection.Connection) error {
+	return deleteTunnelInterface(ifaceName, gre.MECHANISM)
+}
+
+// newGRE returns a GRE interface instance, the same key is used for the both directions. Key flags are set by
+// netlink for the non-zero keys.
+func newGRE(ifaceName string, egressIP, remoteIP net.IP, key uint32) *netlink.Gretun {
+	return &netlink.Gretun{
+		LinkAttrs: netlink.LinkAttrs{
+			Name: ifaceName,
+		},
+		IKey:   key,
+		OKey:   key,
+		Local:  egressIP,
+		Remote: remoteIP,
+	}
+}
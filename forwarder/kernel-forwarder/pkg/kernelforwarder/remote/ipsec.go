@@ -0,0 +1,440 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/ipsec"
+)
+
+const (
+	// ipsecHostIfacePrefix - prefix of the XFRM interface kept in the host namespace for VXLAN encapsulation
+	ipsecHostIfacePrefix = "nsmx"
+	// ipsecRekeyGracePeriod - seconds the replaced inbound security association is kept for the packets in flight
+	ipsecRekeyGracePeriod = 30
+	// ipsecVXLANPort - VXLAN port, VXLAN interfaces are bound to the link-local addresses of their XFRM interfaces
+	ipsecVXLANPort = 4789
+	// ipsecKeyInfo - HKDF info prefix of the security association keys
+	ipsecKeyInfo = "nsm ipsec"
+)
+
+// IPsecKeyPair - X25519 key pair of the forwarder, the public key is advertised in the IPsec remote mechanism and
+// keys of the security associations are derived from the shared secret with the peer forwarder, so they are never
+// sent over the control plane
+type IPsecKeyPair struct {
+	privateKey [32]byte
+	publicKey  [32]byte
+}
+
+// NewIPsecKeyPair - generates X25519 key pair of the forwarder
+func NewIPsecKeyPair() (*IPsecKeyPair, error) {
+	k := &IPsecKeyPair{}
+	if _, err := io.ReadFull(rand.Reader, k.privateKey[:]); err != nil {
+		return nil, errors.Wrap(err, "failed to generate IPsec private key")
+	}
+	curve25519.ScalarBaseMult(&k.publicKey, &k.privateKey)
+	return k, nil
+}
+
+// PublicKey - returns base64 encoded public key of the forwarder
+func (k *IPsecKeyPair) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(k.publicKey[:])
+}
+
+// sharedSecret - returns X25519 shared secret with the peer forwarder
+func (k *IPsecKeyPair) sharedSecret(peerPublicKey []byte) ([]byte, error) {
+	var peer, secret [32]byte
+	copy(peer[:], peerPublicKey)
+	curve25519.ScalarMult(&secret, &k.privateKey, &peer)
+	if subtle.ConstantTimeCompare(secret[:], make([]byte, len(secret))) == 1 {
+		return nil, errors.New("invalid IPsec peer public key")
+	}
+	return secret[:], nil
+}
+
+// ipsecSecurityAssociations - ESP tunnel of the connection seen from the local host
+type ipsecSecurityAssociations struct {
+	ifID      int
+	localIP   net.IP
+	remoteIP  net.IP
+	inSPI     int
+	inKey     []byte
+	outSPI    int
+	outKey    []byte
+	encap     string
+	direction uint8
+}
+
+type ipsecInterfaces struct {
+	keys *IPsecKeyPair
+}
+
+// NewIPsec - creates IPsec remote mechanism interfaces handler, keys of the security associations are derived from
+// the keys key pair
+func NewIPsec(keys *IPsecKeyPair) Mechanism {
+	return &ipsecInterfaces{
+		keys: keys,
+	}
+}
+
+// Type returns IPsec mechanism type
+func (i *ipsecInterfaces) Type() string {
+	return ipsec.MECHANISM
+}
+
+// CreateInterface creates XFRM interface of the connection ESP tunnel. With VXLAN encapsulation the XFRM
+// interface is kept in the host namespace and the ifaceName VXLAN interface is created over it.
+func (i *ipsecInterfaces) CreateInterface(ifaceName string, remoteConnection *connection.Connection, direction uint8) error {
+	sas, err := ipsecConnectionSAs(remoteConnection, direction, i.keys)
+	if err != nil {
+		return err
+	}
+
+	xfrmName := ifaceName
+	if sas.encap == ipsec.EncapsulationVXLAN {
+		xfrmName = fmt.Sprintf("%s%08x", ipsecHostIfacePrefix, uint32(sas.ifID))
+	}
+	if err = netlink.LinkAdd(&netlink.Xfrmi{LinkAttrs: netlink.LinkAttrs{Name: xfrmName}, Ifid: uint32(sas.ifID)}); err != nil {
+		return errors.Wrapf(err, "failed to create XFRM interface")
+	}
+	if err = configureIPsec(sas); err != nil {
+		_ = deleteIPsec(sas.ifID)
+		_ = deleteTunnelInterface(xfrmName, ipsec.MECHANISM)
+		return err
+	}
+	if sas.encap == ipsec.EncapsulationVXLAN {
+		if err = createIPsecVXLAN(ifaceName, xfrmName, sas); err != nil {
+			_ = deleteIPsec(sas.ifID)
+			_ = deleteTunnelInterface(xfrmName, ipsec.MECHANISM)
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateInterface replaces security associations of the existing connection, it is done on keys rotation
+func (i *ipsecInterfaces) UpdateInterface(remoteConnection *connection.Connection, direction uint8) (bool, error) {
+	sas, err := ipsecConnectionSAs(remoteConnection, direction, i.keys)
+	if err != nil {
+		return false, err
+	}
+	states, err := ipsecStates(sas.ifID)
+	if err != nil || len(states) == 0 {
+		return false, err
+	}
+
+	if err = configureIPsec(sas); err != nil {
+		return false, err
+	}
+	for i := range states {
+		state := &states[i]
+		switch {
+		case state.Spi == sas.inSPI || state.Spi == sas.outSPI:
+			continue
+		case state.Dst.Equal(sas.localIP):
+			state.Limits.TimeHard = ipsecRekeyGracePeriod
+			err = netlink.XfrmStateUpdate(state)
+		default:
+			err = netlink.XfrmStateDel(state)
+		}
+		if err != nil {
+			logrus.Warnf("remote: failed to replace IPsec security association %x - %v", state.Spi, err)
+		}
+	}
+	return true, nil
+}
+
+// DeleteInterface deletes the IPsec interface
+func (i *ipsecInterfaces) DeleteInterface(ifaceName string, remoteConnection *connection.Connection) error {
+	ifID := ipsecInterfaceID(remoteConnection)
+	err := deleteIPsec(ifID)
+	if ipsec.ToMechanism(remoteConnection.GetMechanism()).Encapsulation() == ipsec.EncapsulationVXLAN {
+		xfrmName := fmt.Sprintf("%s%08x", ipsecHostIfacePrefix, uint32(ifID))
+		if xfrmErr := deleteTunnelInterface(xfrmName, ipsec.MECHANISM); xfrmErr != nil {
+			logrus.Errorf("remote: %v", xfrmErr)
+		}
+	}
+	if ifaceErr := deleteTunnelInterface(ifaceName, ipsec.MECHANISM); ifaceErr != nil {
+		return ifaceErr
+	}
+	return err
+}
+
+// ipsecConnectionSAs - returns security associations of the connection, security association to the destination
+// host is the inbound one for the incoming connection
+func ipsecConnectionSAs(remoteConnection *connection.Connection, direction uint8, keys *IPsecKeyPair) (*ipsecSecurityAssociations, error) {
+	m := ipsec.ToMechanism(remoteConnection.GetMechanism())
+	if m == nil {
+		return nil, errors.Errorf("not an IPsec mechanism - %v", remoteConnection.GetMechanism().GetType())
+	}
+	localIP, remoteIP, err := tunnelPeers(m, direction)
+	if err != nil {
+		return nil, err
+	}
+	srcSPI, err := m.SrcSPI()
+	if err != nil {
+		return nil, err
+	}
+	dstSPI, err := m.DstSPI()
+	if err != nil {
+		return nil, err
+	}
+	srcKey, dstKey, err := ipsecKeys(m, direction, keys, srcSPI, dstSPI)
+	if err != nil {
+		return nil, err
+	}
+
+	sas := &ipsecSecurityAssociations{
+		ifID:      ipsecInterfaceID(remoteConnection),
+		localIP:   localIP,
+		remoteIP:  remoteIP,
+		inSPI:     int(srcSPI),
+		inKey:     srcKey,
+		outSPI:    int(dstSPI),
+		outKey:    dstKey,
+		encap:     m.Encapsulation(),
+		direction: direction,
+	}
+	if direction == INCOMING {
+		sas.inSPI, sas.inKey, sas.outSPI, sas.outKey = sas.outSPI, sas.outKey, sas.inSPI, sas.inKey
+	}
+	return sas, nil
+}
+
+// ipsecKeys - derives keys of the security associations to the source and destination hosts from the shared secret
+// of the forwarders and SPIs, the local public key is checked to detect the connections negotiated with the
+// previous key pair of the restarted forwarder
+func ipsecKeys(m ipsec.Mechanism, direction uint8, keys *IPsecKeyPair, srcSPI, dstSPI uint32) (srcKey, dstKey []byte, err error) {
+	srcPublicKey, err := m.SrcPublicKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	dstPublicKey, err := m.DstPublicKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	localPublicKey, peerPublicKey := srcPublicKey, dstPublicKey
+	if direction == INCOMING {
+		localPublicKey, peerPublicKey = dstPublicKey, srcPublicKey
+	}
+	if !bytes.Equal(localPublicKey, keys.publicKey[:]) {
+		return nil, nil, errors.New("IPsec connection is negotiated for another forwarder public key")
+	}
+	secret, err := keys.sharedSecret(peerPublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	salt := append(append([]byte{}, srcPublicKey...), dstPublicKey...)
+	if srcKey, err = deriveIPsecKey(secret, salt, srcSPI, dstSPI, 's'); err != nil {
+		return nil, nil, err
+	}
+	if dstKey, err = deriveIPsecKey(secret, salt, srcSPI, dstSPI, 'd'); err != nil {
+		return nil, nil, err
+	}
+	return srcKey, dstKey, nil
+}
+
+// deriveIPsecKey - derives key of the security association to the source ('s') or destination ('d') host with
+// HKDF-SHA256, SPIs are generated on every request, so the keys are rotated by the connection updates
+func deriveIPsecKey(secret, salt []byte, srcSPI, dstSPI uint32, host byte) ([]byte, error) {
+	info := make([]byte, len(ipsecKeyInfo)+9)
+	copy(info, ipsecKeyInfo)
+	binary.BigEndian.PutUint32(info[len(ipsecKeyInfo):], srcSPI)
+	binary.BigEndian.PutUint32(info[len(ipsecKeyInfo)+4:], dstSPI)
+	info[len(info)-1] = host
+
+	key := make([]byte, ipsec.KeyLength)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), key); err != nil {
+		return nil, errors.Wrap(err, "failed to derive IPsec key")
+	}
+	return key, nil
+}
+
+// ipsecInterfaceID - returns XFRM interface ID of the connection. Remote connection IDs are unique for the
+// destination NSMD only, so destination IP is hashed as well.
+func ipsecInterfaceID(remoteConnection *connection.Connection) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(remoteConnection.GetId()))
+	_, _ = h.Write([]byte(remoteConnection.GetMechanism().GetParameters()[ipsec.DstIP]))
+	// Zero interface ID matches no XFRM interface
+	return int(h.Sum32()&0x7fffffff) | 1
+}
+
+// configureIPsec - adds security associations and policies of the connection XFRM interface for IPv4 and IPv6
+// traffic, policies are kept on the keys rotation
+func configureIPsec(sas *ipsecSecurityAssociations) error {
+	for _, state := range []*netlink.XfrmState{
+		newIPsecState(sas.localIP, sas.remoteIP, sas.outSPI, sas.outKey, sas.ifID),
+		newIPsecState(sas.remoteIP, sas.localIP, sas.inSPI, sas.inKey, sas.ifID),
+	} {
+		if err := netlink.XfrmStateAdd(state); err != nil {
+			if err != syscall.EEXIST {
+				return errors.Wrapf(err, "failed to add IPsec security association %x", state.Spi)
+			}
+			if err = netlink.XfrmStateUpdate(state); err != nil {
+				return errors.Wrapf(err, "failed to update IPsec security association %x", state.Spi)
+			}
+		}
+	}
+
+	for _, selector := range []*net.IPNet{
+		{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)},
+		{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)},
+	} {
+		for _, policy := range []*netlink.XfrmPolicy{
+			newIPsecPolicy(selector, netlink.XFRM_DIR_OUT, sas.localIP, sas.remoteIP, sas.ifID),
+			newIPsecPolicy(selector, netlink.XFRM_DIR_IN, sas.remoteIP, sas.localIP, sas.ifID),
+		} {
+			if err := netlink.XfrmPolicyUpdate(policy); err != nil {
+				return errors.Wrapf(err, "failed to add IPsec policy")
+			}
+		}
+	}
+	return nil
+}
+
+// createIPsecVXLAN - creates VXLAN interface over the link-local addresses of the XFRM interface, source host
+// address is fe80::1 and destination host address is fe80::2
+func createIPsecVXLAN(ifaceName, xfrmName string, sas *ipsecSecurityAssociations) error {
+	xfrmLink, err := netlink.LinkByName(xfrmName)
+	if err != nil {
+		return errors.Errorf("failed to get link for %q - %v", xfrmName, err)
+	}
+
+	localIP, remoteIP := net.ParseIP("fe80::1"), net.ParseIP("fe80::2")
+	if sas.direction == INCOMING {
+		localIP, remoteIP = remoteIP, localIP
+	}
+	if err = netlink.AddrAdd(xfrmLink, &netlink.Addr{
+		IPNet: &net.IPNet{IP: localIP, Mask: net.CIDRMask(64, 128)},
+		Flags: syscall.IFA_F_NODAD,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to add link-local address to %s", xfrmName)
+	}
+	if err = netlink.LinkSetUp(xfrmLink); err != nil {
+		return errors.Wrapf(err, "failed to set up %s", xfrmName)
+	}
+
+	if err = netlink.LinkAdd(&netlink.Vxlan{
+		LinkAttrs: netlink.LinkAttrs{
+			Name: ifaceName,
+		},
+		VxlanId:      sas.ifID & 0xffffff,
+		VtepDevIndex: xfrmLink.Attrs().Index,
+		SrcAddr:      localIP,
+		Group:        remoteIP,
+		Port:         ipsecVXLANPort,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to create VXLAN interface")
+	}
+	return nil
+}
+
+// deleteIPsec - deletes security associations and policies of the connection XFRM interface
+func deleteIPsec(ifID int) error {
+	policies, err := netlink.XfrmPolicyList(netlink.FAMILY_ALL)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list IPsec policies")
+	}
+	for i := range policies {
+		if policies[i].Ifid == ifID {
+			if err = netlink.XfrmPolicyDel(&policies[i]); err != nil {
+				return errors.Wrapf(err, "failed to delete IPsec policy")
+			}
+		}
+	}
+
+	states, err := ipsecStates(ifID)
+	if err != nil {
+		return err
+	}
+	for i := range states {
+		if err = netlink.XfrmStateDel(&states[i]); err != nil {
+			return errors.Wrapf(err, "failed to delete IPsec security association %x", states[i].Spi)
+		}
+	}
+	return nil
+}
+
+// ipsecStates - returns security associations of the connection XFRM interface
+func ipsecStates(ifID int) ([]netlink.XfrmState, error) {
+	states, err := netlink.XfrmStateList(netlink.FAMILY_ALL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list IPsec security associations")
+	}
+	var result []netlink.XfrmState
+	for i := range states {
+		if states[i].Ifid == ifID {
+			result = append(result, states[i])
+		}
+	}
+	return result, nil
+}
+
+// newIPsecState returns ESP tunnel mode security association instance
+func newIPsecState(src, dst net.IP, spi int, key []byte, ifID int) *netlink.XfrmState {
+	return &netlink.XfrmState{
+		Src:   src,
+		Dst:   dst,
+		Proto: netlink.XFRM_PROTO_ESP,
+		Mode:  netlink.XFRM_MODE_TUNNEL,
+		Spi:   spi,
+		Reqid: ifID,
+		Ifid:  ifID,
+		Aead: &netlink.XfrmStateAlgo{
+			Name:   ipsec.Algorithm,
+			Key:    key,
+			ICVLen: ipsec.ICVLength,
+		},
+	}
+}
+
+// newIPsecPolicy returns policy of the XFRM interface traffic
+func newIPsecPolicy(selector *net.IPNet, dir netlink.Dir, src, dst net.IP, ifID int) *netlink.XfrmPolicy {
+	return &netlink.XfrmPolicy{
+		Src:  selector,
+		Dst:  selector,
+		Dir:  dir,
+		Ifid: ifID,
+		Tmpls: []netlink.XfrmPolicyTmpl{
+			{
+				Src:   src,
+				Dst:   dst,
+				Proto: netlink.XFRM_PROTO_ESP,
+				Mode:  netlink.XFRM_MODE_TUNNEL,
+				Reqid: ifID,
+			},
+		},
+	}
+}
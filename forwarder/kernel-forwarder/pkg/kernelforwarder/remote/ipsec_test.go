@@ -0,0 +1,306 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"net"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/ipsec"
+)
+
+const (
+	testIPsecIface = "nsm-ipsec"
+	testSrcHostIP  = "192.168.250.1"
+	testDstHostIP  = "192.168.250.2"
+)
+
+// testHosts - source and destination host namespaces connected by veth pair
+type testHosts struct {
+	origin netns.NsHandle
+	src    netns.NsHandle
+	dst    netns.NsHandle
+}
+
+func TestIPsecTunnel(t *testing.T) {
+	testIPsec(t, ipsec.EncapsulationNone)
+}
+
+func TestIPsecOverVXLAN(t *testing.T) {
+	testIPsec(t, ipsec.EncapsulationVXLAN)
+}
+
+func testIPsec(t *testing.T, encapsulation string) {
+	g := NewWithT(t)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	hosts := newTestHosts(t)
+	defer hosts.close()
+
+	// Negotiation is done the same way as by the source and destination NSMDs
+	remoteConnection := &connection.Connection{
+		Id: "1",
+		Mechanism: &connection.Mechanism{
+			Type: ipsec.MECHANISM,
+			Parameters: map[string]string{
+				ipsec.SrcIP:         testSrcHostIP,
+				ipsec.Encapsulation: encapsulation,
+			},
+		},
+	}
+	srcKeys, dstKeys := newTestKeyPairs(g)
+	negotiateTestSAs(g, remoteConnection, srcKeys, dstKeys)
+
+	src, dst := NewIPsec(srcKeys), NewIPsec(dstKeys)
+	hosts.in(hosts.src, func() {
+		g.Expect(src.CreateInterface(testIPsecIface, remoteConnection, OUTGOING)).To(Succeed())
+		setupTestIface(g, "10.250.0.1/30")
+	})
+	hosts.in(hosts.dst, func() {
+		g.Expect(dst.CreateInterface(testIPsecIface, remoteConnection, INCOMING)).To(Succeed())
+		setupTestIface(g, "10.250.0.2/30")
+	})
+	hosts.expectTraffic(g)
+
+	// Keys rotation
+	negotiateTestSAs(g, remoteConnection, srcKeys, dstKeys)
+	for _, host := range []struct {
+		ns        netns.NsHandle
+		c         Mechanism
+		direction uint8
+	}{{hosts.src, src, OUTGOING}, {hosts.dst, dst, INCOMING}} {
+		hosts.in(host.ns, func() {
+			updated, err := host.c.UpdateInterface(remoteConnection, host.direction)
+			g.Expect(err).To(BeNil())
+			g.Expect(updated).To(BeTrue())
+
+			// New security associations and the replaced inbound one
+			states, err := ipsecStates(ipsecInterfaceID(remoteConnection))
+			g.Expect(err).To(BeNil())
+			g.Expect(states).To(HaveLen(3))
+		})
+	}
+	hosts.expectTraffic(g)
+
+	hosts.in(hosts.src, func() {
+		g.Expect(src.DeleteInterface(testIPsecIface, remoteConnection)).To(Succeed())
+		states, err := ipsecStates(ipsecInterfaceID(remoteConnection))
+		g.Expect(err).To(BeNil())
+		g.Expect(states).To(BeEmpty())
+
+		updated, err := src.UpdateInterface(remoteConnection, OUTGOING)
+		g.Expect(err).To(BeNil())
+		g.Expect(updated).To(BeFalse())
+	})
+}
+
+func TestIPsecKeys(t *testing.T) {
+	g := NewWithT(t)
+
+	remoteConnection := &connection.Connection{
+		Id: "1",
+		Mechanism: &connection.Mechanism{
+			Type: ipsec.MECHANISM,
+			Parameters: map[string]string{
+				ipsec.SrcIP: testSrcHostIP,
+			},
+		},
+	}
+	srcKeys, dstKeys := newTestKeyPairs(g)
+	negotiateTestSAs(g, remoteConnection, srcKeys, dstKeys)
+
+	// Both forwarders derive the same keys, the outbound security association of one host is the inbound one of
+	// the other host
+	srcSAs, err := ipsecConnectionSAs(remoteConnection, OUTGOING, srcKeys)
+	g.Expect(err).To(BeNil())
+	dstSAs, err := ipsecConnectionSAs(remoteConnection, INCOMING, dstKeys)
+	g.Expect(err).To(BeNil())
+	g.Expect(srcSAs.outSPI).To(Equal(dstSAs.inSPI))
+	g.Expect(srcSAs.outKey).To(Equal(dstSAs.inKey))
+	g.Expect(srcSAs.inSPI).To(Equal(dstSAs.outSPI))
+	g.Expect(srcSAs.inKey).To(Equal(dstSAs.outKey))
+	g.Expect(srcSAs.inKey).NotTo(Equal(srcSAs.outKey))
+	g.Expect(srcSAs.inKey).To(HaveLen(ipsec.KeyLength))
+
+	// New SPIs rotate the keys
+	negotiateTestSAs(g, remoteConnection, srcKeys, dstKeys)
+	rotatedSAs, err := ipsecConnectionSAs(remoteConnection, OUTGOING, srcKeys)
+	g.Expect(err).To(BeNil())
+	g.Expect(rotatedSAs.outKey).NotTo(Equal(srcSAs.outKey))
+
+	// Connection negotiated for the previous key pair of the restarted forwarder
+	restartedKeys, err := NewIPsecKeyPair()
+	g.Expect(err).To(BeNil())
+	_, err = ipsecConnectionSAs(remoteConnection, INCOMING, restartedKeys)
+	g.Expect(err).NotTo(BeNil())
+}
+
+func newTestKeyPairs(g *WithT) (srcKeys, dstKeys *IPsecKeyPair) {
+	srcKeys, err := NewIPsecKeyPair()
+	g.Expect(err).To(BeNil())
+	dstKeys, err = NewIPsecKeyPair()
+	g.Expect(err).To(BeNil())
+	return srcKeys, dstKeys
+}
+
+// negotiateTestSAs - sets public keys and SPIs the same way as the source and destination NSMDs
+func negotiateTestSAs(g *WithT, remoteConnection *connection.Connection, srcKeys, dstKeys *IPsecKeyPair) {
+	parameters := remoteConnection.GetMechanism().GetParameters()
+	parameters[ipsec.SrcPublicKey] = srcKeys.PublicKey()
+	g.Expect(ipsec.SetSrcSPI(parameters)).To(Succeed())
+	parameters[ipsec.DstIP] = testDstHostIP
+	parameters[ipsec.DstPublicKey] = dstKeys.PublicKey()
+	g.Expect(ipsec.SetDstSPI(parameters)).To(Succeed())
+	g.Expect(remoteConnection.GetMechanism().IsValid()).To(Succeed())
+}
+
+func setupTestIface(g *WithT, addr string) {
+	link, err := netlink.LinkByName(testIPsecIface)
+	g.Expect(err).To(BeNil())
+	ipNet, err := netlink.ParseIPNet(addr)
+	g.Expect(err).To(BeNil())
+	g.Expect(netlink.AddrAdd(link, &netlink.Addr{IPNet: ipNet})).To(Succeed())
+	g.Expect(netlink.LinkSetUp(link)).To(Succeed())
+}
+
+// newTestHosts - creates host namespaces, skips the test if there are no privileges or kernel has no ESP and
+// XFRM interfaces support
+func newTestHosts(t *testing.T) *testHosts {
+	if os.Geteuid() != 0 {
+		t.Skip("network namespaces require root privileges")
+	}
+	origin, err := netns.Get()
+	if err != nil {
+		t.Skipf("failed to get network namespace: %v", err)
+	}
+	hosts := &testHosts{origin: origin, src: netns.None(), dst: netns.None()}
+	if hosts.src, err = netns.New(); err != nil {
+		hosts.close()
+		t.Skipf("failed to create network namespace: %v", err)
+	}
+	if hosts.dst, err = netns.New(); err != nil {
+		hosts.close()
+		t.Skipf("failed to create network namespace: %v", err)
+	}
+	if err = netns.Set(origin); err != nil {
+		hosts.close()
+		t.Fatal(err)
+	}
+
+	hosts.in(hosts.src, func() {
+		err = checkIPsecSupport()
+	})
+	if err != nil {
+		hosts.close()
+		t.Skipf("kernel does not support IPsec: %v", err)
+	}
+
+	g := NewWithT(t)
+	g.Expect(netlink.LinkAdd(&netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "nsm-src"}, PeerName: "nsm-dst"})).To(Succeed())
+	for _, host := range []struct {
+		ns   netns.NsHandle
+		name string
+		addr string
+	}{{hosts.src, "nsm-src", testSrcHostIP}, {hosts.dst, "nsm-dst", testDstHostIP}} {
+		link, err := netlink.LinkByName(host.name)
+		g.Expect(err).To(BeNil())
+		g.Expect(netlink.LinkSetNsFd(link, int(host.ns))).To(Succeed())
+		hosts.in(host.ns, func() {
+			setupTestLink(g, host.name, host.addr+"/24")
+			setupTestLink(g, "lo", "")
+		})
+	}
+	return hosts
+}
+
+func checkIPsecSupport() error {
+	if err := netlink.LinkAdd(&netlink.Xfrmi{LinkAttrs: netlink.LinkAttrs{Name: "nsm-check"}, Ifid: 1}); err != nil {
+		return err
+	}
+	return netlink.XfrmStateAdd(newIPsecState(net.ParseIP(testSrcHostIP), net.ParseIP(testDstHostIP), 256, make([]byte, ipsec.KeyLength), 1))
+}
+
+func setupTestLink(g *WithT, name, addr string) {
+	link, err := netlink.LinkByName(name)
+	g.Expect(err).To(BeNil())
+	if addr != "" {
+		ipNet, err := netlink.ParseIPNet(addr)
+		g.Expect(err).To(BeNil())
+		g.Expect(netlink.AddrAdd(link, &netlink.Addr{IPNet: ipNet})).To(Succeed())
+	}
+	g.Expect(netlink.LinkSetUp(link)).To(Succeed())
+}
+
+// in - runs f in the namespace, OS thread should be locked
+func (h *testHosts) in(ns netns.NsHandle, f func()) {
+	if err := netns.Set(ns); err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := netns.Set(h.origin); err != nil {
+			panic(err)
+		}
+	}()
+	f()
+}
+
+// expectTraffic - sends UDP datagram from the source pod address to the destination pod address
+func (h *testHosts) expectTraffic(g *WithT) {
+	var listener, sender *net.UDPConn
+	var err error
+	h.in(h.dst, func() {
+		listener, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("10.250.0.2"), Port: 5000})
+	})
+	g.Expect(err).To(BeNil())
+	defer func() { _ = listener.Close() }()
+	h.in(h.src, func() {
+		sender, err = net.DialUDP("udp", nil, &net.UDPAddr{IP: net.ParseIP("10.250.0.2"), Port: 5000})
+	})
+	g.Expect(err).To(BeNil())
+	defer func() { _ = sender.Close() }()
+
+	buf := make([]byte, 16)
+	g.Eventually(func() string {
+		if _, err := sender.Write([]byte("ping")); err != nil {
+			return err.Error()
+		}
+		_ = listener.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		n, _, err := listener.ReadFromUDP(buf)
+		if err != nil {
+			return err.Error()
+		}
+		return string(buf[:n])
+	}, 5*time.Second).Should(Equal("ping"))
+}
+
+func (h *testHosts) close() {
+	for _, ns := range []netns.NsHandle{h.src, h.dst} {
+		if ns.IsOpen() {
+			_ = ns.Close()
+		}
+	}
+	_ = netns.Set(h.origin)
+	_ = h.origin.Close()
+}
@@ -20,14 +20,12 @@
 package remote
 
 import (
-	"sync"
+	"net"
 
 	"github.com/pkg/errors"
-	wg "golang.zx2c4.com/wireguard/device"
+	"github.com/vishvananda/netlink"
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
-	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
-	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
 )
 
 // INCOMING, OUTGOING - packet direction constants
@@ -36,37 +34,59 @@ const (
 	OUTGOING = iota
 )
 
-// Connect - struct with remote mechanism interfaces creation and deletion methods
-type Connect struct {
-	wireguardDevicesMutex sync.Mutex
-	wireguardDevices      map[string]*wg.Device
+// Mechanism - creates, updates and deletes host interfaces of a remote mechanism
+type Mechanism interface {
+	// Type - returns remote mechanism type
+	Type() string
+	// CreateInterface - creates interface to remote connection
+	CreateInterface(ifaceName string, remoteConnection *connection.Connection, direction uint8) error
+	// UpdateInterface - updates existing interface to remote connection in place, returns false if there is no such
+	// interface or the mechanism does not support in place updates
+	UpdateInterface(remoteConnection *connection.Connection, direction uint8) (bool, error)
+	// DeleteInterface - deletes interface to remote connection
+	DeleteInterface(ifaceName string, remoteConnection *connection.Connection) error
 }
 
-// NewConnect - creates instance of remote Connect
-func NewConnect() *Connect {
-	return &Connect{
-		wireguardDevices: make(map[string]*wg.Device),
-	}
+// noUpdate - mechanism not supporting in place updates of the interfaces
+type noUpdate struct{}
+
+// UpdateInterface - always returns false, interface is created from scratch
+func (noUpdate) UpdateInterface(*connection.Connection, uint8) (bool, error) {
+	return false, nil
+}
+
+// tunnelMechanism - remote mechanism of the IP tunnel between the source and destination hosts
+type tunnelMechanism interface {
+	SrcIP() (string, error)
+	DstIP() (string, error)
 }
 
-// CreateInterface - creates interface to remote connection
-func (c *Connect) CreateInterface(ifaceName string, remoteConnection *connection.Connection, direction uint8) error {
-	switch remoteConnection.GetMechanism().GetType() {
-	case vxlan.MECHANISM:
-		return c.createVXLANInterface(ifaceName, remoteConnection, direction)
-	case wireguard.MECHANISM:
-		return c.createWireguardInterface(ifaceName, remoteConnection, direction)
+// tunnelPeers - returns local and remote tunnel endpoints, destination host is the local one for the incoming
+// connection
+func tunnelPeers(m tunnelMechanism, direction uint8) (localIP, remoteIP net.IP, err error) {
+	srcIP, err := m.SrcIP()
+	if err != nil {
+		return nil, nil, err
 	}
-	return errors.Errorf("unknown remote mechanism - %v", remoteConnection.GetMechanism().GetType())
+	dstIP, err := m.DstIP()
+	if err != nil {
+		return nil, nil, err
+	}
+	if direction == INCOMING {
+		return net.ParseIP(dstIP), net.ParseIP(srcIP), nil
+	}
+	return net.ParseIP(srcIP), net.ParseIP(dstIP), nil
 }
 
-// DeleteInterface - deletes interface to remote connection
-func (c *Connect) DeleteInterface(ifaceName string, remoteConnection *connection.Connection) error {
-	switch remoteConnection.GetMechanism().GetType() {
-	case vxlan.MECHANISM:
-		return c.deleteVXLANInterface(ifaceName)
-	case wireguard.MECHANISM:
-		return c.deleteWireguardInterface(ifaceName)
+// deleteTunnelInterface - deletes tunnel interface of the remote mechanism from the host namespace
+func deleteTunnelInterface(ifaceName, mechanism string) error {
+	ifaceLink, err := netlink.LinkByName(ifaceName)
+	if err != nil {
+		return errors.Errorf("failed to get link for %q - %v", ifaceName, err)
+	}
+
+	if err = netlink.LinkDel(ifaceLink); err != nil {
+		return errors.Errorf("failed to delete %s interface - %v", mechanism, err)
 	}
-	return errors.Errorf("unknown remote mechanism - %v", remoteConnection.GetMechanism().GetType())
+	return nil
 }
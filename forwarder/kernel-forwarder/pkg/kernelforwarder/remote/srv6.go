@@ -0,0 +1,357 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net"
+
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/srv6"
+)
+
+const (
+	// srv6HostIfacePrefix - prefix of the host end of the veth pair carrying the SRv6 connection
+	srv6HostIfacePrefix = "nsm6"
+	// srv6TableBase - policy routing tables of the SRv6 connections are allocated above it
+	srv6TableBase = 1 << 24
+	// seg6ModeL2Encap - seg6 encap mode encapsulating the whole ethernet frame, not defined by netlink
+	seg6ModeL2Encap = 2
+)
+
+// srv6Peers - SRv6 parameters of the connection seen from the local host
+type srv6Peers struct {
+	localSID      net.IP
+	remoteSID     net.IP
+	remoteHostSID net.IP
+	remoteHwAddr  net.HardwareAddr
+	// localMac and remoteMac - addresses of the pod interfaces on the both sides of the connection, if known
+	localMac  net.HardwareAddr
+	remoteMac net.HardwareAddr
+	remoteIPs []net.IP
+}
+
+type srv6Interfaces struct {
+	noUpdate
+}
+
+// NewSRv6 - creates SRv6 remote mechanism interfaces handler
+func NewSRv6() Mechanism {
+	return &srv6Interfaces{}
+}
+
+// Type returns SRv6 mechanism type
+func (s *srv6Interfaces) Type() string {
+	return srv6.MECHANISM
+}
+
+// CreateInterface creates a veth pair, ifaceName end is moved to the pod and the host end is used as an
+// SRv6 endpoint. The connection is an L2 one, the same as in the vppagent forwarder: local SID is decapsulated to
+// the host end with seg6local End.DX2 and frames received from the pod are encapsulated with seg6 l2encap route in
+// the connection policy routing table with the segment list of the remote host SID and the remote SID. It uses the
+// same SID and hardware address parameters as the vppagent forwarder.
+func (s *srv6Interfaces) CreateInterface(ifaceName string, remoteConnection *connection.Connection, direction uint8) error {
+	peers, err := srv6ConnectionPeers(remoteConnection, direction)
+	if err != nil {
+		return err
+	}
+	hostIfaceName, table := srv6HostInterface(remoteConnection)
+
+	if err = netlink.LinkAdd(newSRv6Veth(ifaceName, hostIfaceName, peers)); err != nil {
+		return errors.Wrapf(err, "failed to create SRv6 interface")
+	}
+	if err = configureSRv6(hostIfaceName, table, peers); err != nil {
+		_ = deleteSRv6Host(hostIfaceName, table)
+		return err
+	}
+	return nil
+}
+
+// DeleteInterface deletes the SRv6 interface
+func (s *srv6Interfaces) DeleteInterface(ifaceName string, remoteConnection *connection.Connection) error {
+	hostIfaceName, table := srv6HostInterface(remoteConnection)
+	return deleteSRv6Host(hostIfaceName, table)
+}
+
+// EnableSRv6 - enables SRv6 processing and forwarding on the host, the egress interface local SID is a local
+// address, so its End behavior is done by the kernel itself
+func EnableSRv6(egressIfaceName string) error {
+	for _, path := range []string{
+		"/proc/sys/net/ipv6/conf/all/seg6_enabled",
+		fmt.Sprintf("/proc/sys/net/ipv6/conf/%s/seg6_enabled", egressIfaceName),
+		"/proc/sys/net/ipv6/conf/all/forwarding",
+		"/proc/sys/net/ipv4/ip_forward",
+	} {
+		if err := writeSysctl(path, "1"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// srv6ConnectionPeers - returns SRv6 parameters of the connection, destination host is the local one for the
+// incoming connection
+func srv6ConnectionPeers(remoteConnection *connection.Connection, direction uint8) (*srv6Peers, error) {
+	m := srv6.ToMechanism(remoteConnection.GetMechanism())
+	if m == nil {
+		return nil, errors.Errorf("not an SRv6 mechanism - %v", remoteConnection.GetMechanism().GetType())
+	}
+
+	ethernetContext := remoteConnection.GetContext().GetEthernetContext()
+	localSID, remoteSID, remoteHostSID, remoteHwAddr := m.SrcLocalSID, m.DstLocalSID, m.DstHostLocalSID, m.DstHardwareAddress
+	localMac, remoteMac := ethernetContext.GetSrcMac(), ethernetContext.GetDstMac()
+	remoteIPs := remoteConnection.GetContext().GetIpContext().DstIPAddrs()
+	if direction == INCOMING {
+		localSID, remoteSID, remoteHostSID, remoteHwAddr = m.DstLocalSID, m.SrcLocalSID, m.SrcHostLocalSID, m.SrcHardwareAddress
+		localMac, remoteMac = remoteMac, localMac
+		remoteIPs = remoteConnection.GetContext().GetIpContext().SrcIPAddrs()
+	}
+
+	peers := &srv6Peers{}
+	var err error
+	if peers.localSID, err = parseSID(localSID); err != nil {
+		return nil, err
+	}
+	if peers.remoteSID, err = parseSID(remoteSID); err != nil {
+		return nil, err
+	}
+	if peers.remoteHostSID, err = parseSID(remoteHostSID); err != nil {
+		return nil, err
+	}
+	hwAddr, err := remoteHwAddr()
+	if err != nil {
+		return nil, err
+	}
+	if peers.remoteHwAddr, err = net.ParseMAC(hwAddr); err != nil {
+		return nil, errors.Wrapf(err, "invalid SRv6 hardware address %s", hwAddr)
+	}
+	if peers.localMac, err = parseMac(localMac); err != nil {
+		return nil, err
+	}
+	if peers.remoteMac, err = parseMac(remoteMac); err != nil {
+		return nil, err
+	}
+	for _, addr := range remoteIPs {
+		ip, _, err := net.ParseCIDR(addr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid address %s", addr)
+		}
+		peers.remoteIPs = append(peers.remoteIPs, ip)
+	}
+	return peers, nil
+}
+
+// configureSRv6 - programs decapsulation of the local SID, encapsulation of the packets received from the pod
+// and reachability of the remote host SID
+func configureSRv6(hostIfaceName string, table int, peers *srv6Peers) error {
+	hostLink, err := netlink.LinkByName(hostIfaceName)
+	if err != nil {
+		return errors.Errorf("failed to get link for %q - %v", hostIfaceName, err)
+	}
+	if err = netlink.LinkSetUp(hostLink); err != nil {
+		return errors.Wrapf(err, "failed to set up %s", hostIfaceName)
+	}
+
+	egressRoutes, err := netlink.RouteGet(peers.remoteHostSID)
+	if err != nil || len(egressRoutes) == 0 {
+		return errors.Errorf("remote host SID %v is not reachable - %v", peers.remoteHostSID, err)
+	}
+	egressRoute := egressRoutes[0]
+	if egressRoute.Gw == nil {
+		// The same as the static ARP entry added by the vppagent forwarder
+		if err = netlink.NeighSet(&netlink.Neigh{
+			LinkIndex:    egressRoute.LinkIndex,
+			Family:       netlink.FAMILY_V6,
+			State:        netlink.NUD_PERMANENT,
+			IP:           peers.remoteHostSID,
+			HardwareAddr: peers.remoteHwAddr,
+		}); err != nil {
+			return errors.Wrapf(err, "failed to add neighbor %v", peers.remoteHostSID)
+		}
+	}
+
+	if err = netlink.RouteAdd(newSRv6Decap(hostLink, peers)); err != nil {
+		return errors.Wrapf(err, "failed to add SRv6 local SID %v", peers.localSID)
+	}
+	if err = configureSRv6PodNeighbor(hostIfaceName, hostLink, peers); err != nil {
+		return err
+	}
+
+	rule := netlink.NewRule()
+	rule.IifName = hostIfaceName
+	rule.Table = table
+	for _, route := range newSRv6Encap(egressRoute.LinkIndex, table, peers) {
+		rule.Family = netlink.FAMILY_V4
+		if route.Dst.IP.To4() == nil {
+			rule.Family = netlink.FAMILY_V6
+		}
+		if err = netlink.RuleAdd(rule); err != nil {
+			return errors.Wrapf(err, "failed to add policy routing rule for %s", hostIfaceName)
+		}
+		if err = netlink.RouteAdd(route); err != nil {
+			return errors.Wrapf(err, "failed to add SRv6 encapsulation route to %v", peers.remoteSID)
+		}
+	}
+	return nil
+}
+
+// configureSRv6PodNeighbor - answers neighbor requests of the pod for the remote addresses, frames of the pod are
+// routed by the host end and sent to the remote pod as they are, so the host end takes the remote pod address
+func configureSRv6PodNeighbor(hostIfaceName string, hostLink netlink.Link, peers *srv6Peers) error {
+	if peers.remoteMac != nil {
+		if err := netlink.LinkSetHardwareAddr(hostLink, peers.remoteMac); err != nil {
+			return errors.Wrapf(err, "failed to set hardware address of %s", hostIfaceName)
+		}
+	}
+	if err := writeSysctl(fmt.Sprintf("/proc/sys/net/ipv4/conf/%s/proxy_arp", hostIfaceName), "1"); err != nil {
+		return err
+	}
+	if err := writeSysctl(fmt.Sprintf("/proc/sys/net/ipv6/conf/%s/proxy_ndp", hostIfaceName), "1"); err != nil {
+		return err
+	}
+	for _, ip := range peers.remoteIPs {
+		if ip.To4() != nil {
+			continue
+		}
+		if err := netlink.NeighAdd(&netlink.Neigh{
+			LinkIndex: hostLink.Attrs().Index,
+			Family:    netlink.FAMILY_V6,
+			Flags:     netlink.NTF_PROXY,
+			IP:        ip,
+		}); err != nil {
+			return errors.Wrapf(err, "failed to add proxy neighbor %v", ip)
+		}
+	}
+	return nil
+}
+
+// deleteSRv6Host - deletes policy routing rule, routes of the connection table and the veth pair, local SID and
+// pod routes are deleted with the host end
+func deleteSRv6Host(hostIfaceName string, table int) error {
+	rule := netlink.NewRule()
+	rule.IifName = hostIfaceName
+	rule.Table = table
+	for _, family := range []int{netlink.FAMILY_V4, netlink.FAMILY_V6} {
+		rule.Family = family
+		_ = netlink.RuleDel(rule)
+	}
+
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: table}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list routes of table %d", table)
+	}
+	for i := range routes {
+		if err = netlink.RouteDel(&routes[i]); err != nil {
+			return errors.Wrapf(err, "failed to delete SRv6 encapsulation route")
+		}
+	}
+	return deleteTunnelInterface(hostIfaceName, srv6.MECHANISM)
+}
+
+// srv6HostInterface - returns name of the host end of the veth pair and the policy routing table of the connection.
+// Remote connection IDs are unique for the destination NSMD only, so destination host SID is hashed as well.
+func srv6HostInterface(remoteConnection *connection.Connection) (hostIfaceName string, table int) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(remoteConnection.GetId()))
+	_, _ = h.Write([]byte(remoteConnection.GetMechanism().GetParameters()[srv6.DstHostLocalSID]))
+	return fmt.Sprintf("%s%08x", srv6HostIfacePrefix, h.Sum32()), srv6TableBase | int(h.Sum32()&0xffffff)
+}
+
+// newSRv6Veth returns a veth pair instance, the pod end takes the local pod address if it is known
+func newSRv6Veth(ifaceName, hostIfaceName string, peers *srv6Peers) *netlink.Veth {
+	return &netlink.Veth{
+		LinkAttrs: netlink.LinkAttrs{
+			Name:         ifaceName,
+			HardwareAddr: peers.localMac,
+		},
+		PeerName: hostIfaceName,
+	}
+}
+
+// newSRv6Decap returns local SID route decapsulating the frames to the host end with End.DX2, the same as the
+// vppagent forwarder does
+func newSRv6Decap(hostLink netlink.Link, peers *srv6Peers) *netlink.Route {
+	encap := &netlink.SEG6LocalEncap{
+		Action: nl.SEG6_LOCAL_ACTION_END_DX2,
+		Oif:    hostLink.Attrs().Index,
+	}
+	encap.Flags[nl.SEG6_LOCAL_ACTION] = true
+	encap.Flags[nl.SEG6_LOCAL_OIF] = true
+	return &netlink.Route{
+		LinkIndex: hostLink.Attrs().Index,
+		Dst:       hostRoute(peers.localSID),
+		Encap:     encap,
+	}
+}
+
+// newSRv6Encap returns IPv4 and IPv6 default routes of the connection table encapsulating the frames with the
+// segment list of the remote host SID and the remote SID, the last segment of the list is the first one visited
+func newSRv6Encap(egressIndex, table int, peers *srv6Peers) []*netlink.Route {
+	var routes []*netlink.Route
+	for _, dst := range []*net.IPNet{
+		{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)},
+		{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)},
+	} {
+		routes = append(routes, &netlink.Route{
+			LinkIndex: egressIndex,
+			Dst:       dst,
+			Table:     table,
+			Encap: &netlink.SEG6Encap{
+				Mode:     seg6ModeL2Encap,
+				Segments: []net.IP{peers.remoteSID, peers.remoteHostSID},
+			},
+		})
+	}
+	return routes
+}
+
+func parseSID(sid func() (string, error)) (net.IP, error) {
+	s, err := sid()
+	if err != nil {
+		return nil, err
+	}
+	return net.ParseIP(s), nil
+}
+
+func parseMac(mac string) (net.HardwareAddr, error) {
+	if mac == "" {
+		return nil, nil
+	}
+	hwAddr, err := net.ParseMAC(mac)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid hardware address %s", mac)
+	}
+	return hwAddr, nil
+}
+
+func hostRoute(ip net.IP) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+func writeSysctl(path, value string) error {
+	if err := ioutil.WriteFile(path, []byte(value), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"net"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/srv6"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+)
+
+func newTestSRv6Connection() *connection.Connection {
+	return &connection.Connection{
+		Id: "1",
+		Mechanism: &connection.Mechanism{
+			Type: srv6.MECHANISM,
+			Parameters: map[string]string{
+				srv6.SrcLocalSID:        "a::1",
+				srv6.DstLocalSID:        "b::1",
+				srv6.SrcHostLocalSID:    "a::",
+				srv6.DstHostLocalSID:    "b::",
+				srv6.SrcHardwareAddress: "0a:00:00:00:00:01",
+				srv6.DstHardwareAddress: "0a:00:00:00:00:02",
+			},
+		},
+		Context: &connectioncontext.ConnectionContext{
+			IpContext: &connectioncontext.IPContext{
+				SrcIpAddr:       "10.30.1.1/30",
+				DstIpAddr:       "10.30.1.2/30",
+				ExtraSrcIpAddrs: []string{"fd00::1/126"},
+				ExtraDstIpAddrs: []string{"fd00::2/126"},
+			},
+			EthernetContext: &connectioncontext.EthernetContext{
+				SrcMac: "0e:00:00:00:00:01",
+				DstMac: "0e:00:00:00:00:02",
+			},
+		},
+	}
+}
+
+func TestSRv6ConnectionPeers(t *testing.T) {
+	g := NewWithT(t)
+
+	peers, err := srv6ConnectionPeers(newTestSRv6Connection(), OUTGOING)
+	g.Expect(err).To(BeNil())
+	g.Expect(peers.localSID.String()).To(Equal("a::1"))
+	g.Expect(peers.remoteSID.String()).To(Equal("b::1"))
+	g.Expect(peers.remoteHostSID.String()).To(Equal("b::"))
+	g.Expect(peers.remoteHwAddr.String()).To(Equal("0a:00:00:00:00:02"))
+	g.Expect(peers.localMac.String()).To(Equal("0e:00:00:00:00:01"))
+	g.Expect(peers.remoteMac.String()).To(Equal("0e:00:00:00:00:02"))
+	g.Expect(peers.remoteIPs).To(Equal([]net.IP{net.ParseIP("10.30.1.2"), net.ParseIP("fd00::2")}))
+
+	peers, err = srv6ConnectionPeers(newTestSRv6Connection(), INCOMING)
+	g.Expect(err).To(BeNil())
+	g.Expect(peers.localSID.String()).To(Equal("b::1"))
+	g.Expect(peers.remoteSID.String()).To(Equal("a::1"))
+	g.Expect(peers.remoteHostSID.String()).To(Equal("a::"))
+	g.Expect(peers.remoteHwAddr.String()).To(Equal("0a:00:00:00:00:01"))
+	g.Expect(peers.localMac.String()).To(Equal("0e:00:00:00:00:02"))
+	g.Expect(peers.remoteMac.String()).To(Equal("0e:00:00:00:00:01"))
+	g.Expect(peers.remoteIPs).To(Equal([]net.IP{net.ParseIP("10.30.1.1"), net.ParseIP("fd00::1")}))
+
+	// Ethernet context is optional
+	conn := newTestSRv6Connection()
+	conn.GetContext().EthernetContext = nil
+	peers, err = srv6ConnectionPeers(conn, OUTGOING)
+	g.Expect(err).To(BeNil())
+	g.Expect(peers.localMac).To(BeNil())
+	g.Expect(peers.remoteMac).To(BeNil())
+}
+
+func TestSRv6Decap(t *testing.T) {
+	g := NewWithT(t)
+
+	for _, direction := range []uint8{OUTGOING, INCOMING} {
+		peers, err := srv6ConnectionPeers(newTestSRv6Connection(), direction)
+		g.Expect(err).To(BeNil())
+		hostLink := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Index: 7}}
+
+		// The same End.DX2 as the vppagent forwarder programs, whatever addresses the connection has
+		route := newSRv6Decap(hostLink, peers)
+		g.Expect(route.LinkIndex).To(Equal(7))
+		g.Expect(route.Dst.String()).To(Equal(peers.localSID.String() + "/128"))
+		encap, ok := route.Encap.(*netlink.SEG6LocalEncap)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(encap.Action).To(Equal(nl.SEG6_LOCAL_ACTION_END_DX2))
+		g.Expect(encap.Oif).To(Equal(7))
+		g.Expect(encap.Flags[nl.SEG6_LOCAL_ACTION]).To(BeTrue())
+		g.Expect(encap.Flags[nl.SEG6_LOCAL_OIF]).To(BeTrue())
+		g.Expect(encap.Flags[nl.SEG6_LOCAL_NH4]).To(BeFalse())
+		g.Expect(encap.Flags[nl.SEG6_LOCAL_NH6]).To(BeFalse())
+	}
+}
+
+func TestSRv6Encap(t *testing.T) {
+	g := NewWithT(t)
+
+	peers, err := srv6ConnectionPeers(newTestSRv6Connection(), OUTGOING)
+	g.Expect(err).To(BeNil())
+
+	routes := newSRv6Encap(3, srv6TableBase|1, peers)
+	g.Expect(routes).To(HaveLen(2))
+	g.Expect(routes[0].Dst.String()).To(Equal("0.0.0.0/0"))
+	g.Expect(routes[1].Dst.String()).To(Equal("::/0"))
+	for _, route := range routes {
+		g.Expect(route.LinkIndex).To(Equal(3))
+		g.Expect(route.Table).To(Equal(srv6TableBase | 1))
+		encap, ok := route.Encap.(*netlink.SEG6Encap)
+		g.Expect(ok).To(BeTrue())
+		// Whole frames are encapsulated, as the vppagent forwarder L2 steering does
+		g.Expect(encap.Mode).To(Equal(seg6ModeL2Encap))
+		g.Expect(encap.Segments).To(Equal([]net.IP{peers.remoteSID, peers.remoteHostSID}))
+	}
+}
+
+func TestSRv6Veth(t *testing.T) {
+	g := NewWithT(t)
+
+	peers, err := srv6ConnectionPeers(newTestSRv6Connection(), INCOMING)
+	g.Expect(err).To(BeNil())
+
+	veth := newSRv6Veth("nsm0", "nsm6host", peers)
+	g.Expect(veth.Name).To(Equal("nsm0"))
+	g.Expect(veth.PeerName).To(Equal("nsm6host"))
+	g.Expect(veth.HardwareAddr.String()).To(Equal("0e:00:00:00:00:02"))
+
+	// Host interfaces of the connections with the same ID to the different hosts do not collide
+	conn := newTestSRv6Connection()
+	name, table := srv6HostInterface(conn)
+	conn.GetMechanism().GetParameters()[srv6.DstHostLocalSID] = "c::"
+	otherName, otherTable := srv6HostInterface(conn)
+	g.Expect(name).NotTo(Equal(otherName))
+	g.Expect(table).NotTo(Equal(otherTable))
+}
@@ -29,8 +29,22 @@ import (
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
 )
 
-// CreateVXLANInterface creates a VXLAN interface
-func (c *Connect) createVXLANInterface(ifaceName string, remoteConnection *connection.Connection, direction uint8) error {
+type vxlanInterfaces struct {
+	noUpdate
+}
+
+// NewVXLAN - creates VXLAN remote mechanism interfaces handler
+func NewVXLAN() Mechanism {
+	return &vxlanInterfaces{}
+}
+
+// Type returns VXLAN mechanism type
+func (v *vxlanInterfaces) Type() string {
+	return vxlan.MECHANISM
+}
+
+// CreateInterface creates a VXLAN interface
+func (v *vxlanInterfaces) CreateInterface(ifaceName string, remoteConnection *connection.Connection, direction uint8) error {
 	/* Create interface - host namespace */
 	srcIP := net.ParseIP(remoteConnection.GetMechanism().GetParameters()[vxlan.SrcIP])
 	dstIP := net.ParseIP(remoteConnection.GetMechanism().GetParameters()[vxlan.DstIP])
@@ -52,7 +66,8 @@ func (c *Connect) createVXLANInterface(ifaceName string, remoteConnection *conne
 	return nil
 }
 
-func (c *Connect) deleteVXLANInterface(ifaceName string) error {
+// DeleteInterface deletes the VXLAN interface
+func (v *vxlanInterfaces) DeleteInterface(ifaceName string, _ *connection.Connection) error {
 	/* Get a link object for interface */
 	ifaceLink, err := netlink.LinkByName(ifaceName)
 	if err != nil {
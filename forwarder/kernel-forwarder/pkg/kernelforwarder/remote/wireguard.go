@@ -17,183 +17,36 @@
 package remote
 
 import (
-	"fmt"
-	"net"
-
-	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
-	"golang.zx2c4.com/wireguard/device"
-	"golang.zx2c4.com/wireguard/ipc"
-	"golang.zx2c4.com/wireguard/tun"
-	"golang.zx2c4.com/wireguard/wgctrl"
-	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
-
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/wgbridge"
 )
 
-// CreateVXLANInterface creates a VXLAN interface
-func (c *Connect) createWireguardInterface(ifaceName string, remoteConnection *connection.Connection, direction uint8) error {
-	c.wireguardDevicesMutex.Lock()
-	defer c.wireguardDevicesMutex.Unlock()
-	mechanism := wireguard.ToMechanism(remoteConnection.GetMechanism())
-
-	/* Create interface - host namespace */
-	var localPrivateKeyStr string
-	var remotePublicKeyStr string
-	var localPort int
-	var remotePort int
-	var dstIPStr string
-	var err error
-	if direction == INCOMING {
-		if localPrivateKeyStr, err = mechanism.DstPrivateKey(); err != nil {
-			return err
-		}
-		if remotePublicKeyStr, err = mechanism.SrcPublicKey(); err != nil {
-			return err
-		}
-		if dstIPStr, err = mechanism.SrcIP(); err != nil {
-			return err
-		}
-		if localPort, err = mechanism.DstPort(); err != nil {
-			return err
-		}
-		if remotePort, err = mechanism.SrcPort(); err != nil {
-			return err
-		}
-	} else {
-		if localPrivateKeyStr, err = mechanism.SrcPrivateKey(); err != nil {
-			return err
-		}
-		if remotePublicKeyStr, err = mechanism.DstPublicKey(); err != nil {
-			return err
-		}
-		if dstIPStr, err = mechanism.DstIP(); err != nil {
-			return err
-		}
-		if localPort, err = mechanism.SrcPort(); err != nil {
-			return err
-		}
-		if remotePort, err = mechanism.DstPort(); err != nil {
-			return err
-		}
-	}
-
-	dstIP := net.ParseIP(dstIPStr)
-	localPrivateKey, err := wgtypes.ParseKey(localPrivateKeyStr)
-	if err != nil {
-		return errors.Errorf("failed to parse local private key: %v", err)
-	}
-	remotePublicKey, err := wgtypes.ParseKey(remotePublicKeyStr)
-	if err != nil {
-		return errors.Errorf("failed to parse remote public key: %v", err)
-	}
-
-	wgDevice, err := createWireguardDevice(ifaceName)
-	if err != nil {
-		return errors.Errorf("Wireguard error: %v", err)
-	}
-	c.wireguardDevices[ifaceName] = wgDevice
-	uapi, err := startWireguardAPI(ifaceName, wgDevice)
-	if err != nil {
-		wgDevice.Close()
-		return errors.Errorf("Wireguard error: %v", err)
-	}
-	defer func() {
-		if uapiErr := uapi.Close(); uapiErr != nil {
-			logrus.Errorf("Wireguard error: failed to close API client %v", uapiErr)
-		}
-	}()
-
-	err = configureWireguardDevice(ifaceName, localPrivateKey, remotePublicKey, localPort, remotePort, dstIP)
-	if err != nil {
-		wgDevice.Close()
-		return errors.Errorf("Wireguard error: %v", err)
-	}
-
-	return nil
-}
-
-func (c *Connect) deleteWireguardInterface(ifaceName string) error {
-	c.wireguardDevicesMutex.Lock()
-	defer c.wireguardDevicesMutex.Unlock()
-	if wgDevice, ok := c.wireguardDevices[ifaceName]; ok {
-		wgDevice.Close()
-		delete(c.wireguardDevices, ifaceName)
-	}
-
-	return nil
+type wireguardInterfaces struct {
+	noUpdate
+	bridges *wgbridge.Bridges
 }
 
-func createWireguardDevice(ifaceName string) (*device.Device, error) {
-	tunIface, err := tun.CreateTUN(ifaceName, device.DefaultMTU)
-	if err != nil {
-		return nil, errors.Errorf("failed to create tun: %v", err)
+// NewWireguard - creates WireGuard remote mechanism interfaces handler
+func NewWireguard() Mechanism {
+	return &wireguardInterfaces{
+		bridges: wgbridge.NewBridges(),
 	}
-
-	logger := device.NewLogger(device.LogLevelDebug, fmt.Sprintf("Wireguard Device (%s): ", ifaceName))
-	return device.NewDevice(tunIface, logger), nil
 }
 
-func startWireguardAPI(ifaceName string, wgDevice *device.Device) (net.Listener, error) {
-	fileUAPI, err := ipc.UAPIOpen(ifaceName)
-	if err != nil {
-		return nil, err
-	}
-
-	uapi, err := ipc.UAPIListen(ifaceName, fileUAPI)
-	if err != nil {
-		return nil, err
-	}
-
-	go func() {
-		for {
-			conn, err := uapi.Accept()
-			if err != nil {
-				return
-			}
-			go wgDevice.IpcHandle(conn)
-		}
-	}()
-
-	return uapi, nil
+// Type returns WireGuard mechanism type
+func (w *wireguardInterfaces) Type() string {
+	return wireguard.MECHANISM
 }
 
-func configureWireguardDevice(ifaceName string, localPrivateKey, remotePublicKey wgtypes.Key, localPort, remotePort int, dstIP net.IP) error {
-	client, err := wgctrl.New()
-	if err != nil {
-		return errors.Errorf("failed to create configuration client: %v", err)
-	}
-	defer func() {
-		if clientErr := client.Close(); clientErr != nil {
-			logrus.Errorf("Wireguard error (%v): failed to close configuration client: %v", ifaceName, clientErr)
-		}
-	}()
-
-	_, ipnet, err := net.ParseCIDR("0.0.0.0/0")
-	if err != nil {
-		return errors.Errorf("failed to configure device: %v", err)
-	}
-	err = client.ConfigureDevice(ifaceName, wgtypes.Config{
-		ListenPort: intPtr(localPort),
-		PrivateKey: &localPrivateKey,
-		Peers: []wgtypes.PeerConfig{
-			{
-				PublicKey: remotePublicKey,
-				AllowedIPs: []net.IPNet{
-					*ipnet,
-				},
-				Endpoint: &net.UDPAddr{
-					IP:   dstIP,
-					Port: remotePort,
-				},
-			},
-		},
-	})
-
-	return errors.Wrapf(err, "failed to configure device: %v", err)
+// CreateInterface creates a WireGuard device and ifaceName ip6gretap interface over it, the same payload is sent
+// over WireGuard by VPP forwarder
+func (w *wireguardInterfaces) CreateInterface(ifaceName string, remoteConnection *connection.Connection, direction uint8) error {
+	return w.bridges.Create(ifaceName, remoteConnection, direction == INCOMING)
 }
 
-func intPtr(v int) *int {
-	return &v
+// DeleteInterface deletes the ip6gretap interface and the WireGuard device
+func (w *wireguardInterfaces) DeleteInterface(ifaceName string, remoteConnection *connection.Connection) error {
+	w.bridges.Delete(ifaceName, remoteConnection)
+	return nil
 }
@@ -0,0 +1,60 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernelforwarder
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+)
+
+const handledKey contextKeyType = "handledKey"
+
+// withHandled marks cross connect as handled by the interfaces handler
+func withHandled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, handledKey, true)
+}
+
+// isHandled returns true if cross connect is handled by some of the interfaces handlers
+func isHandled(ctx context.Context) bool {
+	handled, _ := ctx.Value(handledKey).(bool)
+	return handled
+}
+
+// unhandledConnections returns terminal handler rejecting cross connects not handled by any of the interfaces
+// handlers, e.g. remote source/remote destination
+func unhandledConnections() forwarder.ForwarderServer {
+	return &unhandledHandler{}
+}
+
+type unhandledHandler struct{}
+
+func (u *unhandledHandler) Request(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
+	if !isHandled(ctx) {
+		return nil, errors.Errorf("remote: invalid connection type: %v", crossConnect)
+	}
+	return crossConnect, nil
+}
+
+func (u *unhandledHandler) Close(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
+	if !isHandled(ctx) {
+		return nil, errors.Errorf("remote: invalid connection type: %v", crossConnect)
+	}
+	return new(empty.Empty), nil
+}
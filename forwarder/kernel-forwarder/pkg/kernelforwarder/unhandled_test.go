@@ -0,0 +1,98 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernelforwarder
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/kernel-forwarder/pkg/kernelforwarder/local"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/kernel-forwarder/pkg/kernelforwarder/remote"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/common"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
+	"github.com/networkservicemesh/networkservicemesh/sdk/monitor"
+	monitor_crossconnect "github.com/networkservicemesh/networkservicemesh/sdk/monitor/crossconnect"
+)
+
+type testMonitor struct {
+	monitor_crossconnect.MonitorServer
+	entities map[string]monitor.Entity
+}
+
+func (m *testMonitor) Update(_ context.Context, entity monitor.Entity) {
+	m.entities[entity.GetId()] = entity
+}
+
+func (m *testMonitor) Delete(_ context.Context, entity monitor.Entity) {
+	delete(m.entities, entity.GetId())
+}
+
+func newRemoteConnection(id string) *connection.Connection {
+	return &connection.Connection{
+		Id:        id,
+		Mechanism: &connection.Mechanism{Type: vxlan.MECHANISM},
+		Path: &connection.Path{
+			PathSegments: []*connection.PathSegment{{Name: "src"}, {Name: "dst"}},
+		},
+	}
+}
+
+func TestUnhandledConnectionsRejected(t *testing.T) {
+	g := NewWithT(t)
+	server := chain.ChainOf(
+		localInterfaces(local.NewConnect()),
+		remoteInterfaces(remote.NewVXLAN()),
+		unhandledConnections())
+
+	// Remote source/remote destination is handled by none of the interfaces handlers
+	xcon := &crossconnect.CrossConnect{
+		Id:          "1",
+		Source:      newRemoteConnection("1"),
+		Destination: newRemoteConnection("2"),
+	}
+	_, err := server.Request(context.Background(), xcon)
+	g.Expect(err).To(HaveOccurred())
+	_, err = server.Close(context.Background(), xcon)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestCloseDeletesFromMonitor(t *testing.T) {
+	g := NewWithT(t)
+	xconMonitor := &testMonitor{entities: map[string]monitor.Entity{}}
+	server := CreateKernelForwarder().CreateForwarderServer(&common.ForwarderConfig{
+		Monitor: xconMonitor,
+		Mechanisms: &common.Mechanisms{
+			LocalMechanisms: []*connection.Mechanism{{Type: kernel.MECHANISM}},
+		},
+	})
+
+	// Mechanisms check fails on close, since VXLAN is not supported anymore
+	xcon := &crossconnect.CrossConnect{
+		Id:          "1",
+		Payload:     "IP",
+		Source:      newRemoteConnection("1"),
+		Destination: newRemoteConnection("2"),
+	}
+	xconMonitor.Update(context.Background(), xcon)
+	_, err := server.Close(context.Background(), xcon)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(xconMonitor.entities).To(BeEmpty())
+}
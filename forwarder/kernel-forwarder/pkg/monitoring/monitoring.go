@@ -16,7 +16,6 @@
 package monitoring
 
 import (
-	"fmt"
 	"runtime"
 	"sync"
 	"time"
@@ -25,7 +24,9 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/vishvananda/netlink"
 	"github.com/vishvananda/netns"
+	"golang.zx2c4.com/wireguard/wgctrl"
 
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
 	"github.com/networkservicemesh/networkservicemesh/sdk/monitor/metrics"
 	"github.com/networkservicemesh/networkservicemesh/utils/fs"
@@ -47,6 +48,10 @@ type RegisteredDevices struct {
 type Device struct {
 	Name     string
 	XconName string
+	// Mechanism - type of the connection mechanism served by the device
+	Mechanism string
+	// Tunnel - host namespace WireGuard device of the remote connection the device is bridged to
+	Tunnel string
 }
 
 // CreateMetricsMonitor creates new metric monitoring instance
@@ -97,14 +102,14 @@ func collectMetrics(devices *RegisteredDevices) (map[string]*crossconnect.Metric
 	/* Loop through each registered device */
 	for namespace, listOfDevices := range devices.devices {
 		for _, device := range listOfDevices {
-			metrics, err := getDeviceMetrics(device.Name, namespace)
+			statistics, err := getDeviceMetrics(device, namespace)
 			if err != nil {
 				logrus.Warnf("metrics: failed to extract metrics for device %s in namespace %s: %v", device.Name, namespace, err)
 				logrus.Warnf("metrics: removing device %s from device list", device.Name)
 				failedDevices[namespace] = append(failedDevices[namespace], device)
 			} else {
-				logrus.Infof("metrics: device %s@%s, metrics - %v", device.Name, namespace, metrics)
-				stats[generateMetricsName(device)] = &crossconnect.Metrics{Metrics: metrics}
+				logrus.Infof("metrics: device %s@%s, metrics - %+v", device.Name, namespace, statistics)
+				stats[generateMetricsName(device)] = statistics.Metrics()
 			}
 		}
 	}
@@ -122,8 +127,8 @@ func collectMetrics(devices *RegisteredDevices) (map[string]*crossconnect.Metric
 	return stats, nil
 }
 
-// getDeviceMetrics returns metrics for device in specific namespace
-func getDeviceMetrics(device, nsInode string) (map[string]string, error) {
+// getDeviceMetrics returns statistics for device in specific namespace
+func getDeviceMetrics(device Device, nsInode string) (*crossconnect.Statistics, error) {
 	/* Lock the OS thread so we don't accidentally switch namespaces */
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
@@ -173,21 +178,61 @@ func getDeviceMetrics(device, nsInode string) (map[string]string, error) {
 	}()
 
 	/* Get a link for the interface name */
-	link, err := netlink.LinkByName(device)
+	link, err := netlink.LinkByName(device.Name)
 	if err != nil {
-		logrus.Errorf("metrics: failed to lookup %q, %v", device, err)
+		logrus.Errorf("metrics: failed to lookup %q, %v", device.Name, err)
 		return nil, err
 	}
-	/* 6. Save statistics in metrics map */
-	metrics := make(map[string]string)
-	metrics["rx_bytes"] = fmt.Sprint(link.Attrs().Statistics.RxBytes)
-	metrics["tx_bytes"] = fmt.Sprint(link.Attrs().Statistics.TxBytes)
-	metrics["rx_packets"] = fmt.Sprint(link.Attrs().Statistics.RxPackets)
-	metrics["tx_packets"] = fmt.Sprint(link.Attrs().Statistics.TxPackets)
-	metrics["rx_error_packets"] = fmt.Sprint(link.Attrs().Statistics.RxErrors)
-	metrics["tx_error_packets"] = fmt.Sprint(link.Attrs().Statistics.TxErrors)
+	/* Save link statistics */
+	linkStats := link.Attrs().Statistics
+	if linkStats == nil {
+		return nil, errors.Errorf("metrics: no statistics for %q", device.Name)
+	}
+	statistics := &crossconnect.Statistics{
+		RxBytes:   linkStats.RxBytes,
+		TxBytes:   linkStats.TxBytes,
+		RxPackets: linkStats.RxPackets,
+		TxPackets: linkStats.TxPackets,
+		RxDropped: linkStats.RxDropped,
+		TxDropped: linkStats.TxDropped,
+		RxErrors:  linkStats.RxErrors,
+		TxErrors:  linkStats.TxErrors,
+	}
+	/* WireGuard devices also report the peer handshake and transfer counters */
+	if device.Mechanism == wireguard.MECHANISM && device.Tunnel != "" {
+		if statistics.WireguardPeer, err = getWireguardPeerStatistics(device.Tunnel); err != nil {
+			logrus.Warnf("metrics: failed to get WireGuard peer statistics for %q: %v", device.Tunnel, err)
+		}
+	}
 
-	return metrics, nil
+	return statistics, nil
+}
+
+// getWireguardPeerStatistics returns statistics of the only peer of the WireGuard device
+func getWireguardPeerStatistics(device string) (*crossconnect.WireguardPeerStatistics, error) {
+	client, err := wgctrl.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create WireGuard client")
+	}
+	defer func() {
+		if err = client.Close(); err != nil {
+			logrus.Error("metrics: failed closing WireGuard client: ", err)
+		}
+	}()
+
+	wgDevice, err := client.Device(device)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get WireGuard device %q", device)
+	}
+	if len(wgDevice.Peers) == 0 {
+		return nil, errors.Errorf("WireGuard device %q has no peers", device)
+	}
+	peer := wgDevice.Peers[0]
+	return &crossconnect.WireguardPeerStatistics{
+		LastHandshake: peer.LastHandshakeTime,
+		RxBytes:       uint64(peer.ReceiveBytes),
+		TxBytes:       uint64(peer.TransmitBytes),
+	}, nil
 }
 
 // UpdateDeviceList keeps track of the devices being handled by the Kernel forwarding plane
@@ -21,6 +21,7 @@ import (
 	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools/spanhelper"
@@ -62,6 +63,8 @@ const (
 	ForwarderSocketTypeKey               = "FORWARDER_SOCKET_TYPE"
 	ForwarderSocketTypeDefault           = "unix"
 	ForwarderSrcIPKey                    = "NSM_FORWARDER_SRC_IP"
+	ForwarderCapacityKey                 = "FORWARDER_CAPACITY"
+	ForwarderLabelsKey                   = "FORWARDER_LABELS"
 )
 
 // ForwarderConfig keeps the common configuration for a forwarding plane
@@ -72,6 +75,8 @@ type ForwarderConfig struct {
 	RegistrarSocketType     string
 	ForwarderSocket         string
 	ForwarderSocketType     string
+	Capacity                uint32
+	Labels                  map[string]string
 	MechanismsUpdateChannel chan *Mechanisms
 	Mechanisms              *Mechanisms
 	MetricsEnabled          bool
@@ -118,6 +123,22 @@ func getEnvWithDefaultBool(span spanhelper.SpanHelper, env string, defaultValue
 	return result
 }
 
+// parseLabels parses comma separated key=value pairs
+func parseLabels(value string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, errors.Errorf("invalid label %q", pair)
+		}
+		labels[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return labels, nil
+}
+
 func createForwarderConfig(ctx context.Context, forwarderGoals *ForwarderProbeGoals) *ForwarderConfig {
 	span := spanhelper.FromContext(ctx, "createForwartderConfig")
 	defer span.Finish()
@@ -139,6 +160,19 @@ func createForwarderConfig(ctx context.Context, forwarderGoals *ForwarderProbeGo
 	cfg.RegistrarSocket = getEnvWithDefault(span, ForwarderRegistrarSocketKey, ForwarderRegistrarSocketDefault)
 	cfg.RegistrarSocketType = getEnvWithDefault(span, ForwarderRegistrarSocketTypeKey, ForwarderRegistrarSocketTypeDefault)
 
+	if val, ok := os.LookupEnv(ForwarderCapacityKey); ok {
+		capacity, err := strconv.ParseUint(val, 10, 32)
+		if err != nil {
+			span.Logger().Fatalf("Env variable %s must be set to a number of cross connects, was set to %s", ForwarderCapacityKey, val)
+		}
+		cfg.Capacity = uint32(capacity)
+	}
+	labels, err := parseLabels(getEnvWithDefault(span, ForwarderLabelsKey, ""))
+	if err != nil {
+		span.Logger().Fatalf("Env variable %s must be set to comma separated key=value pairs: %v", ForwarderLabelsKey, err)
+	}
+	cfg.Labels = labels
+
 	cfg.MetricsEnabled = getEnvWithDefaultBool(span, ForwarderMetricsEnabledKey, ForwarderMetricsEnabledDefault)
 	if cfg.MetricsEnabled {
 		cfg.MetricsPeriod = ForwarderMetricsRequestPeriodDefault
@@ -164,7 +198,6 @@ func createForwarderConfig(ctx context.Context, forwarderGoals *ForwarderProbeGo
 	} else {
 		forwarderGoals.SetValidIPReady()
 	}
-	var err error
 	cfg.EgressInterface, err = NewEgressInterface(cfg.SrcIP)
 	if err != nil {
 		span.Logger().Fatalf("Unable to find egress Interface: %s", err)
@@ -183,28 +216,43 @@ func CreateForwarder(ctx context.Context, dp NSMForwarder, forwarderGoals *Forwa
 	// Populate common configuration
 	config := createForwarderConfig(span.Context(), forwarderGoals)
 
+	if err := ServeForwarder(span.Context(), dp, config); err != nil {
+		span.Logger().Fatalf("%v", err)
+	}
+	forwarderGoals.SetSocketListenReady()
+
+	span.Logger().Info("Creating Forwarder Registrar Client...")
+	registrar := NewForwarderRegistrarClient(config.RegistrarSocketType, config.RegistrarSocket)
+	registration := registrar.Register(span.Context(), config.Name, config.ForwarderSocket, config.Capacity, config.Labels, nil, nil)
+	span.Logger().Info("Registered Forwarder Registrar Client")
+
+	return registration
+}
+
+// ServeForwarder initializes the forwarder and serves its gRPC API on the forwarder socket. Unlike CreateForwarder it
+// does not read the environment and does not register the forwarder, so it can be used to run several forwarders in
+// one process. The gRPC server is stopped when the context is done.
+func ServeForwarder(ctx context.Context, dp NSMForwarder, config *ForwarderConfig) error {
 	// Initialize GRPC server
-	config.GRPCserver = tools.NewServer(span.Context())
+	config.GRPCserver = tools.NewServer(ctx)
 	config.Monitor = monitor_crossconnect.NewMonitorServer()
 	crossconnect.RegisterMonitorCrossConnectServer(config.GRPCserver, config.Monitor)
 
 	// Initialize the forwarder
-	err := dp.Init(config)
-	if err != nil {
-		span.Logger().Fatalf("Forwarder initialization failed: %s ", err)
+	if err := dp.Init(config); err != nil {
+		return errors.Wrap(err, "forwarder initialization failed")
 	}
 
 	// Verify the configuration is populated
 	if !sanityCheckConfig(config) {
-		span.Logger().Fatalf("Forwarder configuration sanity check failed: %s ", err)
+		return errors.New("forwarder configuration sanity check failed")
 	}
 
 	// Prepare the gRPC server
+	var err error
 	config.Listener, err = net.Listen(config.ForwarderSocketType, config.ForwarderSocket)
 	if err != nil {
-		span.Logger().Fatalf("Error listening on socket %s: %s ", config.ForwarderSocket, err)
-	} else {
-		forwarderGoals.SetSocketListenReady()
+		return errors.Wrapf(err, "error listening on socket %s", config.ForwarderSocket)
 	}
 
 	forwarder.RegisterForwarderServer(config.GRPCserver, dp.CreateForwarderServer(config))
@@ -215,13 +263,12 @@ func CreateForwarder(ctx context.Context, dp NSMForwarder, forwarderGoals *Forwa
 	go func() {
 		_ = config.GRPCserver.Serve(config.Listener)
 	}()
-	span.Logger().Infof("%s server serving", config.Name)
-	span.Logger().Info("Creating Forwarder Registrar Client...")
-	registrar := NewForwarderRegistrarClient(config.RegistrarSocketType, config.RegistrarSocket)
-	registration := registrar.Register(span.Context(), config.Name, config.ForwarderSocket, nil, nil)
-	span.Logger().Info("Registered Forwarder Registrar Client")
-
-	return registration
+	go func() {
+		<-ctx.Done()
+		config.GRPCserver.Stop()
+	}()
+	logrus.Infof("%s server serving", config.Name)
+	return nil
 }
 
 func sanityCheckConfig(forwarderConfig *ForwarderConfig) bool {
@@ -42,6 +42,8 @@ type ForwarderRegistration struct {
 	registrar       *ForwarderRegistrarClient
 	forwarderName   string
 	forwarderSocket string
+	capacity        uint32
+	labels          map[string]string
 	cancelFunc      context.CancelFunc
 	onConnect       OnConnectFunc
 	onDisconnect    OnDisConnectFunc
@@ -95,6 +97,8 @@ func (dr *ForwarderRegistration) tryRegistration(ctx context.Context) error {
 	req := &forwarderregistrar.ForwarderRegistrationRequest{
 		ForwarderName:   dr.forwarderName,
 		ForwarderSocket: dr.forwarderSocket,
+		Capacity:        dr.capacity,
+		Labels:          dr.labels,
 	}
 	_, err = dr.client.RequestForwarderRegistration(ctx, req)
 	logrus.Infof("%s: send request to Forwarder Registrar: %+v", dr.forwarderName, req)
@@ -179,13 +183,16 @@ func NewForwarderRegistrarClient(network, registrarSocket string) *ForwarderRegi
 	}
 }
 
-// Register creates and register new ForwarderRegistration client
-func (n *ForwarderRegistrarClient) Register(ctx context.Context, forwarderName, forwarderSocket string, onConnect OnConnectFunc, onDisconnect OnDisConnectFunc) *ForwarderRegistration {
+// Register creates and register new ForwarderRegistration client. Capacity is the maximum number of cross connects
+// the forwarder could serve (0 means unlimited), labels are used by NSM to select the forwarder by affinity.
+func (n *ForwarderRegistrarClient) Register(ctx context.Context, forwarderName, forwarderSocket string, capacity uint32, labels map[string]string, onConnect OnConnectFunc, onDisconnect OnDisConnectFunc) *ForwarderRegistration {
 	ctx, cancelFunc := context.WithCancel(ctx)
 	rv := &ForwarderRegistration{
 		registrar:       n,
 		forwarderName:   forwarderName,
 		forwarderSocket: forwarderSocket,
+		capacity:        capacity,
+		labels:          labels,
 		onConnect:       onConnect,
 		onDisconnect:    onDisconnect,
 		cancelFunc:      cancelFunc,
@@ -0,0 +1,71 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qos
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/common"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/utils/fs"
+)
+
+// ApplyCrossConnect applies QoS of the local kernel connections of the crossconnect to their interfaces or removes it.
+// Each side shapes the traffic it sends, so the remote side is handled by the forwarder on the remote host.
+func ApplyCrossConnect(crossConnect *crossconnect.CrossConnect, connect bool) error {
+	for _, conn := range []*connection.Connection{crossConnect.GetLocalSource(), crossConnect.GetLocalDestination()} {
+		if conn == nil {
+			continue
+		}
+		connQoS, err := conn.GetContext().GetQoS()
+		if err != nil {
+			return err
+		}
+		if connQoS.IsEmpty() {
+			continue
+		}
+		if conn.GetMechanism().GetType() != kernel.MECHANISM {
+			logrus.Warnf("qos: %s mechanism is not supported, connection %s is not shaped", conn.GetMechanism().GetType(), conn.GetId())
+			continue
+		}
+		if err := applyConnection(conn, connQoS, connect); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyConnection(conn *connection.Connection, connQoS *connectioncontext.QoS, connect bool) error {
+	ifaceName := conn.GetMechanism().GetParameters()[common.InterfaceNameKey]
+	nsHandle, err := fs.GetNsHandleFromInode(conn.GetMechanism().GetParameters()[common.NetNsInodeKey])
+	if err != nil {
+		return errors.Wrapf(err, "qos: failed to get namespace handle of %s", ifaceName)
+	}
+	defer func() {
+		if err := nsHandle.Close(); err != nil {
+			logrus.Error("qos: error when closing namespace handle: ", err)
+		}
+	}()
+
+	if !connect {
+		return Remove(nsHandle, ifaceName)
+	}
+	logrus.Infof("qos: applying to %s: rate %d bit/s, burst %d bytes", ifaceName, connQoS.Rate, connQoS.Burst)
+	return Apply(nsHandle, ifaceName, connQoS)
+}
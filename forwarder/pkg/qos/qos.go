@@ -0,0 +1,266 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package qos - applies QoS of the connections to the kernel interfaces: traffic sent to the interface is shaped with
+// tbf qdisc and marked with DSCP by nftables
+package qos
+
+import (
+	"bytes"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/expr"
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+)
+
+const (
+	// NftablesTable - name of the inet table with the DSCP marking rules
+	NftablesTable = "nsm-qos"
+
+	postroutingChain = "postrouting"
+
+	// minBurst - default burst is large enough for the segmented GSO packets even on the slow connections
+	minBurst = 16 * 1024
+	// latency - maximum time packet can wait in the tbf queue
+	latency = 50
+)
+
+// Apply replaces QoS of the interface in the network namespace, empty QoS removes it
+func Apply(ns netns.NsHandle, ifaceName string, qos *connectioncontext.QoS) error {
+	if err := Remove(ns, ifaceName); err != nil {
+		return err
+	}
+	if qos.IsEmpty() {
+		return nil
+	}
+
+	if qos.Rate != 0 {
+		if err := addTbf(ns, ifaceName, qos); err != nil {
+			return err
+		}
+	}
+	if qos.DSCP != nil {
+		if err := addDSCPMarking(ns, ifaceName, *qos.DSCP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove removes QoS of the interface from the network namespace, missing interface is not an error
+func Remove(ns netns.NsHandle, ifaceName string) error {
+	if err := deleteTbf(ns, ifaceName); err != nil {
+		return err
+	}
+	return deleteDSCPMarking(ns, ifaceName)
+}
+
+func addTbf(ns netns.NsHandle, ifaceName string, qos *connectioncontext.QoS) error {
+	handle, err := netlink.NewHandleAt(ns)
+	if err != nil {
+		return errors.Wrap(err, "failed to get netlink handle")
+	}
+	defer handle.Delete()
+
+	link, err := handle.LinkByName(ifaceName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find link %s", ifaceName)
+	}
+
+	rate := qos.Rate / 8
+	burst := qos.Burst
+	if burst == 0 {
+		// Enough tokens for 10ms
+		burst = rate / 100
+	}
+	if burst < minBurst {
+		burst = minBurst
+	}
+	tbf := &netlink.Tbf{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(1, 0),
+			Parent:    netlink.HANDLE_ROOT,
+		},
+		Rate:   rate,
+		Limit:  uint32(rate*latency/1000 + burst),
+		Buffer: uint32(netlink.Xmittime(rate, uint32(burst))),
+	}
+	if err := handle.QdiscReplace(tbf); err != nil {
+		return errors.Wrapf(err, "failed to add tbf qdisc to %s", ifaceName)
+	}
+	return nil
+}
+
+func deleteTbf(ns netns.NsHandle, ifaceName string) error {
+	handle, err := netlink.NewHandleAt(ns)
+	if err != nil {
+		return errors.Wrap(err, "failed to get netlink handle")
+	}
+	defer handle.Delete()
+
+	link, err := handle.LinkByName(ifaceName)
+	if _, ok := err.(netlink.LinkNotFoundError); ok {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to find link %s", ifaceName)
+	}
+
+	qdiscs, err := handle.QdiscList(link)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list qdiscs of %s", ifaceName)
+	}
+	for _, qdisc := range qdiscs {
+		if _, ok := qdisc.(*netlink.Tbf); ok && qdisc.Attrs().Parent == netlink.HANDLE_ROOT {
+			if err := handle.QdiscDel(qdisc); err != nil {
+				return errors.Wrapf(err, "failed to delete tbf qdisc of %s", ifaceName)
+			}
+		}
+	}
+	return nil
+}
+
+// addDSCPMarking adds rules rewriting DSCP of IPv4 and IPv6 packets sent to the interface, ECN bits are kept
+func addDSCPMarking(ns netns.NsHandle, ifaceName string, dscp uint8) error {
+	conn := &nftables.Conn{NetNS: int(ns)}
+	table := conn.AddTable(&nftables.Table{
+		Family: nftables.TableFamilyINet,
+		Name:   NftablesTable,
+	})
+	chain := conn.AddChain(&nftables.Chain{
+		Name:     postroutingChain,
+		Table:    table,
+		Type:     nftables.ChainTypeFilter,
+		Hooknum:  nftables.ChainHookPostrouting,
+		Priority: nftables.ChainPriorityMangle,
+	})
+
+	tos := uint16(dscp) << 2
+	trafficClass := uint16(dscp) << 6
+	for _, marking := range []struct {
+		nfproto  byte
+		offset   uint32
+		mask     []byte
+		xor      []byte
+		csumType expr.PayloadCsumType
+	}{
+		// DSCP is in the upper bits of the IPv4 TOS byte, the whole 16-bit word is rewritten for the incremental
+		// header checksum update to be correct
+		{unix.NFPROTO_IPV4, 0, []byte{0xff, 0x03}, []byte{0x00, byte(tos)}, expr.CsumTypeInet},
+		// DSCP is in the upper bits of the IPv6 traffic class spanning the first two bytes of the header
+		{unix.NFPROTO_IPV6, 0, []byte{0xf0, 0x3f}, []byte{byte(trafficClass >> 8), byte(trafficClass)}, expr.CsumTypeNone},
+	} {
+		length := uint32(len(marking.mask))
+		payloadWrite := &expr.Payload{
+			OperationType:  expr.PayloadWrite,
+			SourceRegister: 1,
+			Base:           expr.PayloadBaseNetworkHeader,
+			Offset:         marking.offset,
+			Len:            length,
+			CsumType:       marking.csumType,
+		}
+		if marking.csumType == expr.CsumTypeInet {
+			// IPv4 header checksum offset
+			payloadWrite.CsumOffset = 10
+		}
+		conn.AddRule(&nftables.Rule{
+			Table: table,
+			Chain: chain,
+			Exprs: []expr.Any{
+				&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: ifname(ifaceName)},
+				&expr.Meta{Key: expr.MetaKeyNFPROTO, Register: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{marking.nfproto}},
+				&expr.Payload{
+					DestRegister: 1,
+					Base:         expr.PayloadBaseNetworkHeader,
+					Offset:       marking.offset,
+					Len:          length,
+				},
+				&expr.Bitwise{
+					SourceRegister: 1,
+					DestRegister:   1,
+					Len:            length,
+					Mask:           marking.mask,
+					Xor:            marking.xor,
+				},
+				payloadWrite,
+			},
+		})
+	}
+
+	if err := conn.Flush(); err != nil {
+		return errors.Wrapf(err, "failed to add DSCP marking for %s", ifaceName)
+	}
+	return nil
+}
+
+func deleteDSCPMarking(ns netns.NsHandle, ifaceName string) error {
+	conn := &nftables.Conn{NetNS: int(ns)}
+	chains, err := conn.ListChains()
+	if err != nil {
+		return errors.Wrap(err, "failed to list nftables chains")
+	}
+	table := &nftables.Table{
+		Family: nftables.TableFamilyINet,
+		Name:   NftablesTable,
+	}
+	for _, chain := range chains {
+		// Chains of all the families are listed and their table may come without the family
+		if chain.Table == nil || chain.Table.Name != NftablesTable || chain.Name != postroutingChain {
+			continue
+		}
+		if chain.Table.Family != nftables.TableFamily(unix.NFPROTO_UNSPEC) && chain.Table.Family != table.Family {
+			continue
+		}
+		chain.Table = table
+		rules, err := conn.GetRule(table, chain)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list nftables rules of %s chain", chain.Name)
+		}
+		for _, rule := range rules {
+			if matchesInterface(rule, ifaceName) {
+				if err := conn.DelRule(rule); err != nil {
+					return errors.Wrapf(err, "failed to delete nftables rule of %s chain", chain.Name)
+				}
+			}
+		}
+	}
+
+	if err := conn.Flush(); err != nil {
+		return errors.Wrapf(err, "failed to remove DSCP marking for %s", ifaceName)
+	}
+	return nil
+}
+
+// matchesInterface checks if the marking rule is for the interface, the interface name is the first comparison
+func matchesInterface(rule *nftables.Rule, ifaceName string) bool {
+	for _, e := range rule.Exprs {
+		if cmp, ok := e.(*expr.Cmp); ok {
+			return bytes.Equal(cmp.Data, ifname(ifaceName))
+		}
+	}
+	return false
+}
+
+func ifname(name string) []byte {
+	b := make([]byte, unix.IFNAMSIZ)
+	copy(b, name+"\x00")
+	return b
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qos
+
+import (
+	"net"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+)
+
+const (
+	testClientIface = "nsm-client"
+	testServerIface = "nsm-server"
+	testClientIP    = "10.70.0.1/30"
+	testServerIP    = "10.70.0.2/30"
+	testClientIPv6  = "fd70::1/126"
+	testServerIPv6  = "fd70::2/126"
+)
+
+func TestParseQoS(t *testing.T) {
+	g := NewWithT(t)
+
+	qos, err := connectioncontext.ParseQoS(map[string]string{"app": "firewall"})
+	g.Expect(err).To(BeNil())
+	g.Expect(qos).To(BeNil())
+
+	qos, err = connectioncontext.ParseQoS(map[string]string{
+		connectioncontext.QoSRateKey:  "10Mbit",
+		connectioncontext.QoSBurstKey: "32kb",
+		connectioncontext.QoSDSCPKey:  "ef",
+	})
+	g.Expect(err).To(BeNil())
+	g.Expect(qos.Rate).To(Equal(uint64(10 * 1000 * 1000)))
+	g.Expect(qos.Burst).To(Equal(uint64(32 * 1024)))
+	g.Expect(*qos.DSCP).To(Equal(uint8(46)))
+
+	values := map[string]string{}
+	qos.ToMap(values)
+	parsed, err := connectioncontext.ParseQoS(values)
+	g.Expect(err).To(BeNil())
+	g.Expect(parsed).To(Equal(qos))
+
+	for _, values := range []map[string]string{
+		{connectioncontext.QoSRateKey: "fast"},
+		{connectioncontext.QoSBurstKey: "-1"},
+		{connectioncontext.QoSDSCPKey: "64"},
+		{connectioncontext.QoSDSCPKey: "AF44"},
+	} {
+		_, err = connectioncontext.ParseQoS(values)
+		g.Expect(err).NotTo(BeNil())
+	}
+}
+
+func TestApplyQoS(t *testing.T) {
+	g := NewWithT(t)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origin, client, server := newTestNamespaces(t)
+	defer func() {
+		_ = netns.Set(origin)
+		_ = origin.Close()
+		_ = client.Close()
+		_ = server.Close()
+	}()
+
+	dscp, err := connectioncontext.ParseDSCP("AF41")
+	g.Expect(err).To(BeNil())
+	qos := &connectioncontext.QoS{Rate: 8 * 1000 * 1000, DSCP: dscp}
+	if err = Apply(client, testClientIface, qos); err != nil {
+		t.Skipf("QoS is not supported: %v", err)
+	}
+	// Apply replaces the previous QoS
+	g.Expect(Apply(client, testClientIface, qos)).To(Succeed())
+
+	tbf := testTbf(g, client)
+	g.Expect(tbf).NotTo(BeNil())
+	g.Expect(tbf.Rate).To(Equal(uint64(1000 * 1000)))
+	g.Expect(receivedTOS(g, client, server, testServerIP)).To(Equal(*dscp << 2))
+	g.Expect(receivedTOS(g, client, server, testServerIPv6)).To(Equal(*dscp << 2))
+
+	g.Expect(Remove(client, testClientIface)).To(Succeed())
+	g.Expect(testTbf(g, client)).To(BeNil())
+	g.Expect(receivedTOS(g, client, server, testServerIP)).To(Equal(uint8(0)))
+	g.Expect(receivedTOS(g, client, server, testServerIPv6)).To(Equal(uint8(0)))
+
+	// Interface is already deleted on connection close
+	g.Expect(Remove(client, "nsm-missing")).To(Succeed())
+}
+
+// newTestNamespaces creates client and server namespaces connected with veth pair
+func newTestNamespaces(t *testing.T) (origin, client, server netns.NsHandle) {
+	if os.Geteuid() != 0 {
+		t.Skip("network namespaces require root privileges")
+	}
+	g := NewWithT(t)
+
+	origin, err := netns.Get()
+	g.Expect(err).To(BeNil())
+	if client, err = netns.New(); err != nil {
+		t.Skipf("failed to create network namespace: %v", err)
+	}
+	server, err = netns.New()
+	g.Expect(err).To(BeNil())
+
+	g.Expect(netns.Set(client)).To(Succeed())
+	g.Expect(netlink.LinkAdd(&netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: testClientIface}, PeerName: testServerIface})).To(Succeed())
+	serverLink, err := netlink.LinkByName(testServerIface)
+	g.Expect(err).To(BeNil())
+	g.Expect(netlink.LinkSetNsFd(serverLink, int(server))).To(Succeed())
+	setupTestLink(g, testClientIface, testClientIP, testClientIPv6)
+
+	g.Expect(netns.Set(server)).To(Succeed())
+	setupTestLink(g, testServerIface, testServerIP, testServerIPv6)
+	g.Expect(netns.Set(origin)).To(Succeed())
+
+	return origin, client, server
+}
+
+func setupTestLink(g *WithT, name string, ips ...string) {
+	link, err := netlink.LinkByName(name)
+	g.Expect(err).To(BeNil())
+	for _, ip := range ips {
+		addr, err := netlink.ParseAddr(ip)
+		g.Expect(err).To(BeNil())
+		// Duplicate address detection would delay IPv6 traffic
+		addr.Flags = unix.IFA_F_NODAD
+		g.Expect(netlink.AddrAdd(link, addr)).To(Succeed())
+	}
+	g.Expect(netlink.LinkSetUp(link)).To(Succeed())
+}
+
+func testTbf(g *WithT, ns netns.NsHandle) *netlink.Tbf {
+	handle, err := netlink.NewHandleAt(ns)
+	g.Expect(err).To(BeNil())
+	defer handle.Delete()
+
+	link, err := handle.LinkByName(testClientIface)
+	g.Expect(err).To(BeNil())
+	qdiscs, err := handle.QdiscList(link)
+	g.Expect(err).To(BeNil())
+	for _, qdisc := range qdiscs {
+		if tbf, ok := qdisc.(*netlink.Tbf); ok {
+			return tbf
+		}
+	}
+	return nil
+}
+
+// receivedTOS sends UDP datagram from the client to the server address and returns TOS or IPv6 traffic class it is
+// received with, OS thread should be locked
+func receivedTOS(g *WithT, client, server netns.NsHandle, serverAddr string) uint8 {
+	serverIP, _, err := net.ParseCIDR(serverAddr)
+	g.Expect(err).To(BeNil())
+	level, option, cmsgType := unix.IPPROTO_IP, unix.IP_RECVTOS, unix.IP_TOS
+	if serverIP.To4() == nil {
+		level, option, cmsgType = unix.IPPROTO_IPV6, unix.IPV6_RECVTCLASS, unix.IPV6_TCLASS
+	}
+
+	origin, err := netns.Get()
+	g.Expect(err).To(BeNil())
+	defer func() {
+		g.Expect(netns.Set(origin)).To(Succeed())
+		_ = origin.Close()
+	}()
+
+	g.Expect(netns.Set(server)).To(Succeed())
+	listener, err := net.ListenUDP("udp", &net.UDPAddr{IP: serverIP, Port: 5000})
+	g.Expect(err).To(BeNil())
+	defer func() { _ = listener.Close() }()
+	rawConn, err := listener.SyscallConn()
+	g.Expect(err).To(BeNil())
+	g.Expect(rawConn.Control(func(fd uintptr) {
+		g.Expect(unix.SetsockoptInt(int(fd), level, option, 1)).To(Succeed())
+	})).To(Succeed())
+
+	g.Expect(netns.Set(client)).To(Succeed())
+	sender, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: serverIP, Port: 5000})
+	g.Expect(err).To(BeNil())
+	defer func() { _ = sender.Close() }()
+
+	// Datagrams can be lost while the link is not ready yet
+	buf, oob := make([]byte, 16), make([]byte, 64)
+	var oobn int
+	g.Eventually(func() error {
+		if _, err = sender.Write([]byte("ping")); err != nil {
+			return err
+		}
+		_ = listener.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		_, oobn, _, _, err = listener.ReadMsgUDP(buf, oob)
+		return err
+	}, 5*time.Second).Should(Succeed())
+	messages, err := unix.ParseSocketControlMessage(oob[:oobn])
+	g.Expect(err).To(BeNil())
+	tos := -1
+	for _, message := range messages {
+		if int(message.Header.Level) == level && int(message.Header.Type) == cmsgType {
+			// IPv6 traffic class is received as little endian int, ECN bits are ignored
+			tos = int(message.Data[0] &^ 0x03)
+		}
+	}
+	g.Expect(tos).NotTo(Equal(-1), "TOS is not received")
+	return uint8(tos)
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wgbridge - bridges WireGuard tunnels of the remote connections into the forwarders. WireGuard carries IP
+// packets only, so Ethernet frames of the connection are tunneled over it with ip6gretap device. Kernel forwarder
+// moves ip6gretap device to the pod, VPP is attached to it by AF_PACKET interface, so both forwarders send the same
+// payload over WireGuard and interoperate.
+package wgbridge
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/common"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/wgtunnel"
+)
+
+const (
+	wireguardPrefix = "nsmwg"
+	bridgePrefix    = "nsmgt"
+)
+
+// Link-local tunnel endpoints of the ip6gretap device, they are unique within a single WireGuard device
+var (
+	sourceTunnelIP      = net.ParseIP("fe80::1")
+	destinationTunnelIP = net.ParseIP("fe80::2")
+)
+
+// Names - returns names of the WireGuard device and of the ip6gretap device of the remote connection. Names fit
+// Linux 15 chars limit. Remote connection IDs are unique for the destination NSMD only, so the tunnel endpoints
+// are hashed as well.
+func Names(remoteConnection *connection.Connection) (wireguardName, bridgeName string) {
+	parameters := remoteConnection.GetMechanism().GetParameters()
+	h := fnv.New32a()
+	for _, s := range []string{remoteConnection.GetId(), parameters[common.SrcIP], parameters[common.DstIP]} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	suffix := fmt.Sprintf("%08x", h.Sum32())
+	return wireguardPrefix + suffix, bridgePrefix + suffix
+}
+
+// Bridges - WireGuard tunnels of the remote connections bridged into the forwarders
+type Bridges struct {
+	devices *wgtunnel.Devices
+}
+
+// NewBridges - creates WireGuard bridges
+func NewBridges() *Bridges {
+	return &Bridges{
+		devices: wgtunnel.NewDevices(),
+	}
+}
+
+// Create - creates WireGuard device of the remote connection and bridgeName ip6gretap device over it. Incoming is
+// true on the destination host of the connection.
+func (b *Bridges) Create(bridgeName string, remoteConnection *connection.Connection, incoming bool) error {
+	wireguardName, _ := Names(remoteConnection)
+	// Connection could be updated, tunnel is recreated with the new parameters
+	b.Delete(bridgeName, remoteConnection)
+
+	if err := b.devices.Create(wireguardName, remoteConnection.GetMechanism(), incoming); err != nil {
+		return err
+	}
+	if err := b.createBridge(wireguardName, bridgeName, incoming); err != nil {
+		b.devices.Delete(wireguardName)
+		return err
+	}
+	return nil
+}
+
+func (b *Bridges) createBridge(wireguardName, bridgeName string, incoming bool) error {
+	wireguardLink, err := netlink.LinkByName(wireguardName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get link for %q", wireguardName)
+	}
+
+	localIP, _ := tunnelIPs(incoming)
+	addr := &netlink.Addr{
+		IPNet: &net.IPNet{IP: localIP, Mask: net.CIDRMask(64, 128)},
+		Flags: syscall.IFA_F_NODAD,
+	}
+	if err = netlink.AddrAdd(wireguardLink, addr); err != nil {
+		return errors.Wrapf(err, "failed to set address of %q", wireguardName)
+	}
+	if err = netlink.LinkSetUp(wireguardLink); err != nil {
+		return errors.Wrapf(err, "failed to set %q up", wireguardName)
+	}
+
+	bridge := newBridge(bridgeName, wireguardLink.Attrs().Index, incoming)
+	if err = netlink.LinkAdd(bridge); err != nil {
+		return errors.Wrapf(err, "failed to create ip6gretap interface %q", bridgeName)
+	}
+	if err = netlink.LinkSetUp(bridge); err != nil {
+		_ = netlink.LinkDel(bridge)
+		return errors.Wrapf(err, "failed to set %q up", bridgeName)
+	}
+	return nil
+}
+
+// tunnelIPs - returns local and remote ip6gretap endpoints, destination host is the local one for the incoming
+// connection
+func tunnelIPs(incoming bool) (localIP, remoteIP net.IP) {
+	if incoming {
+		return destinationTunnelIP, sourceTunnelIP
+	}
+	return sourceTunnelIP, destinationTunnelIP
+}
+
+// newBridge - returns ip6gretap device over the WireGuard device with wireguardIndex
+func newBridge(bridgeName string, wireguardIndex int, incoming bool) *netlink.Gretap {
+	localIP, remoteIP := tunnelIPs(incoming)
+	return &netlink.Gretap{
+		LinkAttrs: netlink.LinkAttrs{
+			Name: bridgeName,
+		},
+		Local:  localIP,
+		Remote: remoteIP,
+		Link:   uint32(wireguardIndex),
+	}
+}
+
+// Delete - deletes bridgeName ip6gretap and WireGuard devices of the remote connection
+func (b *Bridges) Delete(bridgeName string, remoteConnection *connection.Connection) {
+	wireguardName, _ := Names(remoteConnection)
+	if link, err := netlink.LinkByName(bridgeName); err == nil {
+		_ = netlink.LinkDel(link)
+	}
+	b.devices.Delete(wireguardName)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wgbridge
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
+)
+
+func newTestConnection(g *WithT, id, srcIP, dstIP string) *connection.Connection {
+	srcPrivateKey, err := wgtypes.GeneratePrivateKey()
+	g.Expect(err).To(BeNil())
+	dstPrivateKey, err := wgtypes.GeneratePrivateKey()
+	g.Expect(err).To(BeNil())
+	return &connection.Connection{
+		Id:             id,
+		NetworkService: "test-network-service",
+		Mechanism: &connection.Mechanism{
+			Type: wireguard.MECHANISM,
+			Parameters: map[string]string{
+				wireguard.SrcIP:         srcIP,
+				wireguard.DstIP:         dstIP,
+				wireguard.SrcPort:       strconv.Itoa(wireguard.BasePort),
+				wireguard.DstPort:       strconv.Itoa(wireguard.BasePort),
+				wireguard.SrcPrivateKey: srcPrivateKey.String(),
+				wireguard.SrcPublicKey:  srcPrivateKey.PublicKey().String(),
+				wireguard.DstPrivateKey: dstPrivateKey.String(),
+				wireguard.DstPublicKey:  dstPrivateKey.PublicKey().String(),
+			},
+		},
+	}
+}
+
+func TestNames(t *testing.T) {
+	g := NewWithT(t)
+	conn := newTestConnection(g, "1", "10.0.0.1", "10.0.0.2")
+
+	wireguardName, bridgeName := Names(conn)
+	g.Expect(len(wireguardName)).To(BeNumerically("<=", 15))
+	g.Expect(len(bridgeName)).To(BeNumerically("<=", 15))
+	g.Expect(wireguardName).NotTo(Equal(bridgeName))
+
+	// Names do not depend on the keys, both sides of the connection get the same names
+	sameWireguardName, sameBridgeName := Names(newTestConnection(g, "1", "10.0.0.1", "10.0.0.2"))
+	g.Expect(sameWireguardName).To(Equal(wireguardName))
+	g.Expect(sameBridgeName).To(Equal(bridgeName))
+
+	// Connection IDs are unique for the destination NSMD only
+	for _, other := range []*connection.Connection{
+		newTestConnection(g, "1", "10.0.0.3", "10.0.0.2"),
+		newTestConnection(g, "1", "10.0.0.1", "10.0.0.3"),
+		newTestConnection(g, "2", "10.0.0.1", "10.0.0.2"),
+	} {
+		otherWireguardName, otherBridgeName := Names(other)
+		g.Expect(otherWireguardName).NotTo(Equal(wireguardName))
+		g.Expect(otherBridgeName).NotTo(Equal(bridgeName))
+	}
+}
+
+func TestNewBridge(t *testing.T) {
+	g := NewWithT(t)
+
+	outgoing := newBridge("nsmgt-out", 7, false)
+	g.Expect(outgoing.Attrs().Name).To(Equal("nsmgt-out"))
+	g.Expect(outgoing.Link).To(Equal(uint32(7)))
+	g.Expect(outgoing.Local).To(Equal(sourceTunnelIP))
+	g.Expect(outgoing.Remote).To(Equal(destinationTunnelIP))
+
+	incoming := newBridge("nsmgt-in", 7, true)
+	g.Expect(incoming.Local).To(Equal(outgoing.Remote))
+	g.Expect(incoming.Remote).To(Equal(outgoing.Local))
+}
+
+func TestBridgesCreateDelete(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("creating WireGuard devices requires root")
+	}
+	g := NewWithT(t)
+	conn := newTestConnection(g, "1", "10.0.0.1", "10.0.0.2")
+	wireguardName, bridgeName := Names(conn)
+
+	bridges := NewBridges()
+	err := bridges.Create(bridgeName, conn, false)
+	if errors.Cause(err) == syscall.EOPNOTSUPP {
+		t.Skip("ip6gretap is not supported by the kernel")
+	}
+	g.Expect(err).To(BeNil())
+	defer bridges.Delete(bridgeName, conn)
+
+	wireguardLink, err := netlink.LinkByName(wireguardName)
+	g.Expect(err).To(BeNil())
+	addrs, err := netlink.AddrList(wireguardLink, netlink.FAMILY_V6)
+	g.Expect(err).To(BeNil())
+	g.Expect(addrs).To(ContainElement(WithTransform(func(addr netlink.Addr) net.IP { return addr.IP }, Equal(sourceTunnelIP))))
+
+	bridgeLink, err := netlink.LinkByName(bridgeName)
+	g.Expect(err).To(BeNil())
+	g.Expect(bridgeLink.Type()).To(Equal("ip6gretap"))
+
+	bridges.Delete(bridgeName, conn)
+	_, err = netlink.LinkByName(bridgeName)
+	g.Expect(err).NotTo(BeNil())
+	_, err = netlink.LinkByName(wireguardName)
+	g.Expect(err).NotTo(BeNil())
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wgtunnel - WireGuard devices of the remote connections shared by the forwarders
+package wgtunnel
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/ipc"
+	"golang.zx2c4.com/wireguard/tun"
+	"golang.zx2c4.com/wireguard/wgctrl"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
+)
+
+// Devices - userspace WireGuard devices of the remote connections
+type Devices struct {
+	mutex   sync.Mutex
+	devices map[string]*wireguardDevice
+}
+
+// wireguardDevice - userspace WireGuard device with its UAPI listener, the listener is kept open while the device
+// exists so the device could be inspected by wgctrl clients, e.g. for the peer statistics
+type wireguardDevice struct {
+	device *device.Device
+	uapi   net.Listener
+}
+
+// NewDevices - creates empty set of WireGuard devices
+func NewDevices() *Devices {
+	return &Devices{
+		devices: make(map[string]*wireguardDevice),
+	}
+}
+
+// peerConfig - WireGuard configuration of the local end of the remote connection
+type peerConfig struct {
+	localPrivateKey wgtypes.Key
+	remotePublicKey wgtypes.Key
+	localPort       int
+	remotePort      int
+	remoteIP        net.IP
+}
+
+// Create - creates WireGuard device configured with the keys, ports and IPs of the mechanism. Incoming is true on
+// the destination host of the connection.
+func (d *Devices) Create(ifaceName string, m *connection.Mechanism, incoming bool) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	config, err := newPeerConfig(m, incoming)
+	if err != nil {
+		return err
+	}
+
+	wgDevice, err := createWireguardDevice(ifaceName)
+	if err != nil {
+		return errors.Errorf("Wireguard error: %v", err)
+	}
+	d.devices[ifaceName] = &wireguardDevice{device: wgDevice}
+	uapi, err := startWireguardAPI(ifaceName, wgDevice)
+	if err != nil {
+		d.closeDevice(ifaceName)
+		return errors.Errorf("Wireguard error: %v", err)
+	}
+	d.devices[ifaceName].uapi = uapi
+
+	err = configureWireguardDevice(ifaceName, config)
+	if err != nil {
+		d.closeDevice(ifaceName)
+		return errors.Errorf("Wireguard error: %v", err)
+	}
+
+	return nil
+}
+
+// newPeerConfig - returns WireGuard configuration of the local end of the connection, destination host is the
+// local one for the incoming connection
+func newPeerConfig(m *connection.Mechanism, incoming bool) (*peerConfig, error) {
+	mechanism := wireguard.ToMechanism(m)
+	if mechanism == nil {
+		return nil, errors.Errorf("not a %s mechanism: %v", wireguard.MECHANISM, m)
+	}
+
+	localPrivateKey, remotePublicKey := mechanism.SrcPrivateKey, mechanism.DstPublicKey
+	localPort, remotePort, remoteIP := mechanism.SrcPort, mechanism.DstPort, mechanism.DstIP
+	if incoming {
+		localPrivateKey, remotePublicKey = mechanism.DstPrivateKey, mechanism.SrcPublicKey
+		localPort, remotePort, remoteIP = mechanism.DstPort, mechanism.SrcPort, mechanism.SrcIP
+	}
+
+	config := &peerConfig{}
+	var err error
+	if config.localPrivateKey, err = parseKey(localPrivateKey); err != nil {
+		return nil, errors.Errorf("failed to parse local private key: %v", err)
+	}
+	if config.remotePublicKey, err = parseKey(remotePublicKey); err != nil {
+		return nil, errors.Errorf("failed to parse remote public key: %v", err)
+	}
+	if config.localPort, err = localPort(); err != nil {
+		return nil, err
+	}
+	if config.remotePort, err = remotePort(); err != nil {
+		return nil, err
+	}
+	ip, err := remoteIP()
+	if err != nil {
+		return nil, err
+	}
+	config.remoteIP = net.ParseIP(ip)
+	return config, nil
+}
+
+func parseKey(key func() (string, error)) (wgtypes.Key, error) {
+	s, err := key()
+	if err != nil {
+		return wgtypes.Key{}, err
+	}
+	return wgtypes.ParseKey(s)
+}
+
+// Delete - closes WireGuard device
+func (d *Devices) Delete(ifaceName string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.closeDevice(ifaceName)
+}
+
+func (d *Devices) closeDevice(ifaceName string) {
+	if wgDevice, ok := d.devices[ifaceName]; ok {
+		if wgDevice.uapi != nil {
+			if err := wgDevice.uapi.Close(); err != nil {
+				logrus.Errorf("Wireguard error (%v): failed to close API listener: %v", ifaceName, err)
+			}
+		}
+		wgDevice.device.Close()
+		delete(d.devices, ifaceName)
+	}
+}
+
+func createWireguardDevice(ifaceName string) (*device.Device, error) {
+	tunIface, err := tun.CreateTUN(ifaceName, device.DefaultMTU)
+	if err != nil {
+		return nil, errors.Errorf("failed to create tun: %v", err)
+	}
+
+	logger := device.NewLogger(device.LogLevelDebug, fmt.Sprintf("Wireguard Device (%s): ", ifaceName))
+	return device.NewDevice(tunIface, logger), nil
+}
+
+func startWireguardAPI(ifaceName string, wgDevice *device.Device) (net.Listener, error) {
+	fileUAPI, err := ipc.UAPIOpen(ifaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	uapi, err := ipc.UAPIListen(ifaceName, fileUAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			conn, err := uapi.Accept()
+			if err != nil {
+				return
+			}
+			go wgDevice.IpcHandle(conn)
+		}
+	}()
+
+	return uapi, nil
+}
+
+func configureWireguardDevice(ifaceName string, config *peerConfig) error {
+	client, err := wgctrl.New()
+	if err != nil {
+		return errors.Errorf("failed to create configuration client: %v", err)
+	}
+	defer func() {
+		if clientErr := client.Close(); clientErr != nil {
+			logrus.Errorf("Wireguard error (%v): failed to close configuration client: %v", ifaceName, clientErr)
+		}
+	}()
+
+	allowedIPs := make([]net.IPNet, 0, 2)
+	for _, cidr := range []string{"0.0.0.0/0", "::/0"} {
+		_, ipnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return errors.Errorf("failed to configure device: %v", err)
+		}
+		allowedIPs = append(allowedIPs, *ipnet)
+	}
+	err = client.ConfigureDevice(ifaceName, wgtypes.Config{
+		ListenPort: intPtr(config.localPort),
+		PrivateKey: &config.localPrivateKey,
+		Peers: []wgtypes.PeerConfig{
+			{
+				PublicKey:  config.remotePublicKey,
+				AllowedIPs: allowedIPs,
+				Endpoint: &net.UDPAddr{
+					IP:   config.remoteIP,
+					Port: config.remotePort,
+				},
+			},
+		},
+	})
+
+	return errors.Wrapf(err, "failed to configure device: %v", err)
+}
+
+func intPtr(v int) *int {
+	return &v
+}
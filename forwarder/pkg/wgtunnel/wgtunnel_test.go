@@ -0,0 +1,128 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wgtunnel
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"golang.zx2c4.com/wireguard/wgctrl"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
+)
+
+type testKeys struct {
+	srcPrivateKey wgtypes.Key
+	dstPrivateKey wgtypes.Key
+}
+
+func newTestKeys(g *WithT) *testKeys {
+	srcPrivateKey, err := wgtypes.GeneratePrivateKey()
+	g.Expect(err).To(BeNil())
+	dstPrivateKey, err := wgtypes.GeneratePrivateKey()
+	g.Expect(err).To(BeNil())
+	return &testKeys{srcPrivateKey: srcPrivateKey, dstPrivateKey: dstPrivateKey}
+}
+
+func newTestMechanism(keys *testKeys) *connection.Mechanism {
+	return &connection.Mechanism{
+		Type: wireguard.MECHANISM,
+		Parameters: map[string]string{
+			wireguard.SrcIP:         "10.0.0.1",
+			wireguard.DstIP:         "10.0.0.2",
+			wireguard.SrcPort:       strconv.Itoa(wireguard.BasePort),
+			wireguard.DstPort:       strconv.Itoa(wireguard.BasePort + 1),
+			wireguard.SrcPrivateKey: keys.srcPrivateKey.String(),
+			wireguard.SrcPublicKey:  keys.srcPrivateKey.PublicKey().String(),
+			wireguard.DstPrivateKey: keys.dstPrivateKey.String(),
+			wireguard.DstPublicKey:  keys.dstPrivateKey.PublicKey().String(),
+		},
+	}
+}
+
+func TestPeerConfigOutgoing(t *testing.T) {
+	g := NewWithT(t)
+	keys := newTestKeys(g)
+
+	config, err := newPeerConfig(newTestMechanism(keys), false)
+	g.Expect(err).To(BeNil())
+	g.Expect(config.localPrivateKey).To(Equal(keys.srcPrivateKey))
+	g.Expect(config.remotePublicKey).To(Equal(keys.dstPrivateKey.PublicKey()))
+	g.Expect(config.localPort).To(Equal(wireguard.BasePort))
+	g.Expect(config.remotePort).To(Equal(wireguard.BasePort + 1))
+	g.Expect(config.remoteIP.String()).To(Equal("10.0.0.2"))
+}
+
+func TestPeerConfigIncoming(t *testing.T) {
+	g := NewWithT(t)
+	keys := newTestKeys(g)
+
+	config, err := newPeerConfig(newTestMechanism(keys), true)
+	g.Expect(err).To(BeNil())
+	g.Expect(config.localPrivateKey).To(Equal(keys.dstPrivateKey))
+	g.Expect(config.remotePublicKey).To(Equal(keys.srcPrivateKey.PublicKey()))
+	g.Expect(config.localPort).To(Equal(wireguard.BasePort + 1))
+	g.Expect(config.remotePort).To(Equal(wireguard.BasePort))
+	g.Expect(config.remoteIP.String()).To(Equal("10.0.0.1"))
+}
+
+func TestPeerConfigInvalidMechanism(t *testing.T) {
+	g := NewWithT(t)
+	keys := newTestKeys(g)
+
+	_, err := newPeerConfig(&connection.Mechanism{Type: "VXLAN"}, false)
+	g.Expect(err).NotTo(BeNil())
+
+	m := newTestMechanism(keys)
+	m.GetParameters()[wireguard.DstPublicKey] = "invalid"
+	_, err = newPeerConfig(m, false)
+	g.Expect(err).NotTo(BeNil())
+
+	m = newTestMechanism(keys)
+	delete(m.GetParameters(), wireguard.SrcPort)
+	_, err = newPeerConfig(m, true)
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestDevicesKeepAPIOpen(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("creating WireGuard devices requires root")
+	}
+	g := NewWithT(t)
+	keys := newTestKeys(g)
+	ifaceName := "nsmwgtest"
+
+	devices := NewDevices()
+	g.Expect(devices.Create(ifaceName, newTestMechanism(keys), false)).To(Succeed())
+	defer devices.Delete(ifaceName)
+
+	client, err := wgctrl.New()
+	g.Expect(err).To(BeNil())
+	defer func() { _ = client.Close() }()
+
+	// Peer statistics are read over UAPI after the device is configured
+	wgDevice, err := client.Device(ifaceName)
+	g.Expect(err).To(BeNil())
+	g.Expect(wgDevice.Peers).To(HaveLen(1))
+	g.Expect(wgDevice.Peers[0].PublicKey).To(Equal(keys.dstPrivateKey.PublicKey()))
+
+	devices.Delete(ifaceName)
+	_, err = client.Device(ifaceName)
+	g.Expect(err).NotTo(BeNil())
+}
@@ -1,5 +1,6 @@
-//Package vppagent provides sdk API for creating chaining vppagent forwarders
-package vppagent
+// Package chain provides forwarder agnostic sdk API for creating chaining forwarders, the data plane is rendered by
+// the backend specific handlers: vpp-agent configuration for the vppagent forwarder, netlink for the kernel forwarder
+package chain
 
 import (
 	"context"
@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/common"
+)
+
+// CheckMechanisms returns Forwarder Server rejecting cross connects with mechanisms not advertised by the forwarder.
+// Mechanisms are read from the config on each call, so the updated ones are taken into account.
+func CheckMechanisms(config *common.ForwarderConfig) forwarder.ForwarderServer {
+	return &checkMechanisms{
+		config: config,
+	}
+}
+
+type checkMechanisms struct {
+	config *common.ForwarderConfig
+}
+
+func (c *checkMechanisms) Request(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
+	if err := common.SanityCheckConnectionType(c.config.Mechanisms, crossConnect); err != nil {
+		Logger(ctx).Errorf("Request: %v is not supported, reason: %v", crossConnect, err)
+		return nil, err
+	}
+	return NextRequest(ctx, crossConnect)
+}
+
+func (c *checkMechanisms) Close(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
+	if err := common.SanityCheckConnectionType(c.config.Mechanisms, crossConnect); err != nil {
+		Logger(ctx).Errorf("Close: %v is not supported, reason: %v", crossConnect, err)
+		return new(empty.Empty), err
+	}
+	return NextClose(ctx, crossConnect)
+}
@@ -0,0 +1,46 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+)
+
+// ClearFunc clears the data plane of the previous cross connect state before it is updated
+type ClearFunc func(ctx context.Context, previous *crossconnect.CrossConnect) error
+
+type clearMechanisms struct {
+	clear ClearFunc
+}
+
+// ClearMechanisms calls clear with the previous cross connect state if crossconnect monitor has entity with request cross connect id.
+// Clearing errors are logged, the request proceeds to the next handler anyway.
+func ClearMechanisms(clear ClearFunc) forwarder.ForwarderServer {
+	return &clearMechanisms{
+		clear: clear,
+	}
+}
+
+func (c *clearMechanisms) Request(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
+	monitor := MonitorServer(ctx)
+	if monitor == nil {
+		Logger(ctx).Info("Crossconnect monitor server not passed")
+		return NextRequest(ctx, crossConnect)
+	}
+	entity, ok := monitor.Entities()[crossConnect.GetId()].(*crossconnect.CrossConnect)
+	if !ok {
+		Logger(ctx).Infof("monitor has not entry with id %v", crossConnect.GetId())
+		return NextRequest(ctx, crossConnect)
+	}
+	if err := c.clear(ctx, entity); err != nil {
+		Logger(ctx).Warnf("Connection Mechanism was not cleared properly before updating: %s", err.Error())
+	}
+	return NextRequest(ctx, crossConnect)
+}
+
+func (c *clearMechanisms) Close(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
+	return NextClose(ctx, crossConnect)
+}
@@ -0,0 +1,104 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/common"
+	"github.com/networkservicemesh/networkservicemesh/sdk/monitor"
+	monitor_crossconnect "github.com/networkservicemesh/networkservicemesh/sdk/monitor/crossconnect"
+)
+
+func newTestCrossConnect(id, localMechanism string) *crossconnect.CrossConnect {
+	return &crossconnect.CrossConnect{
+		Id: id,
+		Source: &connection.Connection{
+			Mechanism: &connection.Mechanism{Type: localMechanism},
+		},
+		Destination: &connection.Connection{
+			Mechanism: &connection.Mechanism{Type: vxlan.MECHANISM},
+			Path: &connection.Path{
+				PathSegments: []*connection.PathSegment{{Name: "src"}, {Name: "dst"}},
+			},
+		},
+	}
+}
+
+// testMonitor keeps cross connects synchronously, the other monitor server methods are not used by the chain
+type testMonitor struct {
+	monitor_crossconnect.MonitorServer
+	entities map[string]monitor.Entity
+}
+
+func (m *testMonitor) Update(_ context.Context, entity monitor.Entity) {
+	m.entities[entity.GetId()] = entity
+}
+
+func (m *testMonitor) Delete(_ context.Context, entity monitor.Entity) {
+	delete(m.entities, entity.GetId())
+}
+
+func (m *testMonitor) Entities() map[string]monitor.Entity {
+	return m.entities
+}
+
+func TestClearMechanisms(t *testing.T) {
+	assert := gomega.NewWithT(t)
+	xconMonitor := &testMonitor{entities: map[string]monitor.Entity{}}
+	var cleared []*crossconnect.CrossConnect
+	last := &testChainForwarderServer{}
+	chain := ChainOf(
+		UseCrossConnectMonitor(xconMonitor),
+		ClearMechanisms(func(ctx context.Context, previous *crossconnect.CrossConnect) error {
+			cleared = append(cleared, previous)
+			return nil
+		}),
+		last)
+
+	first := newTestCrossConnect("1", kernel.MECHANISM)
+	_, err := chain.Request(context.Background(), first)
+	assert.Expect(err).Should(gomega.BeNil())
+	assert.Expect(cleared).Should(gomega.BeEmpty())
+	assert.Expect(xconMonitor.Entities()).Should(gomega.HaveKey("1"))
+
+	_, err = chain.Request(context.Background(), newTestCrossConnect("1", kernel.MECHANISM))
+	assert.Expect(err).Should(gomega.BeNil())
+	assert.Expect(cleared).Should(gomega.Equal([]*crossconnect.CrossConnect{first}))
+	assert.Expect(last.requestCount).Should(gomega.Equal(2))
+
+	_, err = chain.Close(context.Background(), first)
+	assert.Expect(err).Should(gomega.BeNil())
+	assert.Expect(xconMonitor.Entities()).ShouldNot(gomega.HaveKey("1"))
+}
+
+func TestCheckMechanisms(t *testing.T) {
+	assert := gomega.NewWithT(t)
+	config := &common.ForwarderConfig{
+		Mechanisms: &common.Mechanisms{
+			LocalMechanisms: []*connection.Mechanism{{Type: kernel.MECHANISM}},
+		},
+	}
+	last := &testChainForwarderServer{}
+	chain := ChainOf(CheckMechanisms(config), last)
+
+	_, err := chain.Request(context.Background(), newTestCrossConnect("1", kernel.MECHANISM))
+	assert.Expect(err).Should(gomega.BeNil())
+	assert.Expect(last.requestCount).Should(gomega.Equal(1))
+
+	_, err = chain.Request(context.Background(), newTestCrossConnect("2", "MEMIF"))
+	assert.Expect(err).ShouldNot(gomega.BeNil())
+	assert.Expect(last.requestCount).Should(gomega.Equal(1))
+
+	config.Mechanisms = &common.Mechanisms{
+		RemoteMechanisms: []*connection.Mechanism{{Type: vxlan.MECHANISM}},
+	}
+	_, err = chain.Request(context.Background(), newTestCrossConnect("2", "MEMIF"))
+	assert.Expect(err).Should(gomega.BeNil())
+	assert.Expect(last.requestCount).Should(gomega.Equal(2))
+}
@@ -0,0 +1,74 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	monitor_crossconnect "github.com/networkservicemesh/networkservicemesh/sdk/monitor/crossconnect"
+)
+
+type contextKeyType string
+
+const (
+	nextKey    contextKeyType = "nextKey"
+	loggerKey  contextKeyType = "loggerKey"
+	monitorKey contextKeyType = "monitorKey"
+)
+
+func withNext(ctx context.Context, handler forwarder.ForwarderServer) context.Context {
+	return context.WithValue(ctx, nextKey, handler)
+}
+
+// Next returns next forwarder server of current chain state. Returns nil if context has not chain.
+func Next(ctx context.Context) forwarder.ForwarderServer {
+	if v, ok := ctx.Value(nextKey).(forwarder.ForwarderServer); ok {
+		return v
+	}
+	return nil
+}
+
+// NextRequest calls Request of the next forwarder server, returns cross connect if it is the last one
+func NextRequest(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
+	if next := Next(ctx); next != nil {
+		return next.Request(ctx, crossConnect)
+	}
+	return crossConnect, nil
+}
+
+// NextClose calls Close of the next forwarder server, returns empty if it is the last one
+func NextClose(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
+	if next := Next(ctx); next != nil {
+		return next.Close(ctx, crossConnect)
+	}
+	return new(empty.Empty), nil
+}
+
+// Logger returns logger from context
+func Logger(ctx context.Context) logrus.FieldLogger {
+	if logger, ok := ctx.Value(loggerKey).(logrus.FieldLogger); ok {
+		return logger
+	}
+	return logrus.New()
+}
+
+// WithLogger puts logger into context
+func WithLogger(ctx context.Context, logger logrus.FieldLogger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// WithMonitor puts into context cross connect monitor server
+func WithMonitor(ctx context.Context, monitor monitor_crossconnect.MonitorServer) context.Context {
+	return context.WithValue(ctx, monitorKey, monitor)
+}
+
+// MonitorServer gets from context cross connect monitor server
+func MonitorServer(ctx context.Context) monitor_crossconnect.MonitorServer {
+	if monitor, ok := ctx.Value(monitorKey).(monitor_crossconnect.MonitorServer); ok {
+		return monitor
+	}
+	return nil
+}
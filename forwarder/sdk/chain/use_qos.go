@@ -0,0 +1,56 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chain
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/qos"
+)
+
+// UseQoS shapes and marks the traffic of the kernel connections according to the connection QoS once the interfaces
+// are created, the connection is closed if QoS cannot be applied
+func UseQoS() forwarder.ForwarderServer {
+	return &useQoS{}
+}
+
+type useQoS struct {
+}
+
+func (c *useQoS) Request(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
+	resp, err := NextRequest(ctx, crossConnect)
+	if err != nil {
+		return nil, err
+	}
+	if err = qos.ApplyCrossConnect(crossConnect, true); err != nil {
+		Logger(ctx).Errorf("failed to apply QoS: %v", err)
+		if _, closeErr := NextClose(ctx, crossConnect); closeErr != nil {
+			Logger(ctx).Errorf("failed to close connection: %v", closeErr)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *useQoS) Close(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
+	if err := qos.ApplyCrossConnect(crossConnect, false); err != nil {
+		Logger(ctx).Errorf("failed to remove QoS: %v", err)
+	}
+	return NextClose(ctx, crossConnect)
+}
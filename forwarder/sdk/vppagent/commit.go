@@ -12,6 +12,7 @@ import (
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
 )
 
 type commit struct {
@@ -33,7 +34,7 @@ func (c *commit) Request(ctx context.Context, crossConnect *crossconnect.CrossCo
 		return nil, err
 	}
 	updateSpan.Finish()
-	next := Next(ctx)
+	next := chain.Next(ctx)
 	if next == nil {
 		return crossConnect, nil
 	}
@@ -49,7 +50,7 @@ func (c *commit) Close(ctx context.Context, crossConnect *crossconnect.CrossConn
 	if err != nil {
 		return nil, err
 	}
-	next := Next(ctx)
+	next := chain.Next(ctx)
 	if next == nil {
 		return new(empty.Empty), nil
 	}
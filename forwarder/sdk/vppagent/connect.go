@@ -7,6 +7,7 @@ import (
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
 )
 
 //Connect creates handler with connection to vpp-agent confgirator server
@@ -26,10 +27,10 @@ func (c *connect) Request(ctx context.Context, crossConnect *crossconnect.CrossC
 	defer func() {
 		err := closeFn()
 		if err != nil {
-			Logger(ctx).Errorf("An error during closing configuration client: %v", err)
+			chain.Logger(ctx).Errorf("An error during closing configuration client: %v", err)
 		}
 	}()
-	if next := Next(ctx); next != nil {
+	if next := chain.Next(ctx); next != nil {
 		return next.Request(nextCtx, crossConnect)
 	}
 	return crossConnect, nil
@@ -43,13 +44,13 @@ func (c *connect) Close(ctx context.Context, crossConnect *crossconnect.CrossCon
 	defer func() {
 		err := closeFn()
 		if err != nil {
-			Logger(ctx).Errorf("An error during closing configuration client: %v", err)
+			chain.Logger(ctx).Errorf("An error during closing configuration client: %v", err)
 		}
 	}()
-	if next := Next(ctx); next != nil {
+	if next := chain.Next(ctx); next != nil {
 		_, err = next.Close(nextCtx, crossConnect)
 		if err != nil {
-			Logger(ctx).Errorf("An error during closing connection: %v", err)
+			chain.Logger(ctx).Errorf("An error during closing connection: %v", err)
 		}
 		return new(empty.Empty), nil
 	}
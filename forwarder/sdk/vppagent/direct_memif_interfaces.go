@@ -9,6 +9,7 @@ import (
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/vppagent/pkg/memif"
 )
 
@@ -27,7 +28,7 @@ func (c *directMemifInterfaces) Request(ctx context.Context, crossConnect *cross
 	if isDirectMemif(crossConnect) {
 		return c.directMemifConnector.Connect(crossConnect)
 	}
-	next := Next(ctx)
+	next := chain.Next(ctx)
 	if next == nil {
 		return crossConnect, nil
 	}
@@ -39,7 +40,7 @@ func (c *directMemifInterfaces) Close(ctx context.Context, crossConnect *crossco
 		c.directMemifConnector.Disconnect(crossConnect)
 		return new(empty.Empty), nil
 	}
-	next := Next(ctx)
+	next := chain.Next(ctx)
 	if next == nil {
 		return new(empty.Empty), nil
 	}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/vppagent/pkg/converter"
 )
 
@@ -28,7 +29,7 @@ func (c *kernelInterfaces) Request(ctx context.Context, crossConnect *crossconne
 		return nil, err
 	}
 	nextCtx := WithDataChange(ctx, dataChange)
-	next := Next(ctx)
+	next := chain.Next(ctx)
 	if next == nil {
 		return crossConnect, nil
 	}
@@ -44,7 +45,7 @@ func (c *kernelInterfaces) Close(ctx context.Context, crossConnect *crossconnect
 		return nil, err
 	}
 	nextCtx := WithDataChange(ctx, dataChange)
-	next := Next(ctx)
+	next := chain.Next(ctx)
 	if next == nil {
 		return new(empty.Empty), nil
 	}
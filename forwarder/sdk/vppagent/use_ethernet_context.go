@@ -31,6 +31,7 @@ import (
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
 )
 
 //UseEthernetContext fills ethernet context for dst interface if it is empty
@@ -42,7 +43,7 @@ type ethernetContextUpdater struct {
 }
 
 func (c *ethernetContextUpdater) Request(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
-	next := Next(ctx)
+	next := chain.Next(ctx)
 	if next == nil {
 		return crossConnect, nil
 	}
@@ -54,7 +55,7 @@ func (c *ethernetContextUpdater) Request(ctx context.Context, crossConnect *cros
 }
 
 func (c *ethernetContextUpdater) Close(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
-	next := Next(ctx)
+	next := chain.Next(ctx)
 	if next == nil {
 		return new(empty.Empty), nil
 	}
@@ -70,7 +71,7 @@ func setEthernetContext(ctx context.Context, c *crossconnect.CrossConnect) {
 	}
 	mac := getVppDestinationInterfaceMacByID(ctx, c.Id)
 	if mac == "" {
-		Logger(ctx).Warn("DST mac is empty")
+		chain.Logger(ctx).Warn("DST mac is empty")
 		return
 	}
 	if c.GetLocalDestination() != nil {
@@ -80,14 +81,16 @@ func setEthernetContext(ctx context.Context, c *crossconnect.CrossConnect) {
 	}
 	if c.GetLocalSource() != nil {
 		dataChange := DataChange(ctx)
-		dataChange.LinuxConfig.ArpEntries = append(dataChange.LinuxConfig.ArpEntries, &linux.ARPEntry{
-			IpAddress: strings.Split(c.GetLocalSource().GetContext().IpContext.DstIpAddr, "/")[0],
-			Interface: converter.GetSrcInterfaceName(c.Id),
-			HwAddress: mac,
-		})
+		for _, dstIPAddr := range c.GetLocalSource().GetContext().GetIpContext().DstIPAddrs() {
+			dataChange.LinuxConfig.ArpEntries = append(dataChange.LinuxConfig.ArpEntries, &linux.ARPEntry{
+				IpAddress: strings.Split(dstIPAddr, "/")[0],
+				Interface: converter.GetSrcInterfaceName(c.Id),
+				HwAddress: mac,
+			})
+		}
 		_, err := ConfiguratorClient(ctx).Update(ctx, &configurator.UpdateRequest{Update: dataChange})
 		if err != nil {
-			Logger(ctx).Errorf("An error during update arp entries: %v", err.Error())
+			chain.Logger(ctx).Errorf("An error during update arp entries: %v", err.Error())
 		}
 	}
 }
@@ -109,14 +112,14 @@ func getVppDestinationInterfaceMacByID(ctx context.Context, id string) string {
 func dumpRequest(ctx context.Context) *configurator.Config {
 	client := ConfiguratorClient(ctx)
 	if client == nil {
-		Logger(ctx).Warn("Configuration client is empty, can not request dump")
+		chain.Logger(ctx).Warn("Configuration client is empty, can not request dump")
 		return nil
 	}
 	dumpResp, err := client.Dump(context.Background(), &configurator.DumpRequest{})
 	if err != nil {
-		Logger(ctx).Errorf("An error during client.Dump: %v", err)
+		chain.Logger(ctx).Errorf("An error during client.Dump: %v", err)
 		return nil
 	}
-	Logger(ctx).Infof("Dump response: %v", dumpResp.String())
+	chain.Logger(ctx).Infof("Dump response: %v", dumpResp.String())
 	return dumpResp.Dump
 }
@@ -0,0 +1,68 @@
+package vppagent
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/wgbridge"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
+)
+
+// WireguardInterfaces creates forwarder server handler creating WireGuard tunnels of the remote connections, VPP is attached to them by the following handlers
+func WireguardInterfaces() forwarder.ForwarderServer {
+	return &wireguardInterfaces{
+		bridges: wgbridge.NewBridges(),
+	}
+}
+
+type wireguardInterfaces struct {
+	bridges *wgbridge.Bridges
+}
+
+func (c *wireguardInterfaces) Request(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
+	remoteConnection, incoming := wireguardConnection(crossConnect)
+	if remoteConnection != nil {
+		_, bridgeName := wgbridge.Names(remoteConnection)
+		if err := c.bridges.Create(bridgeName, remoteConnection, incoming); err != nil {
+			chain.Logger(ctx).Errorf("Failed to create WireGuard tunnel: %v", err)
+			return nil, err
+		}
+	}
+	rv, err := chain.NextRequest(ctx, crossConnect)
+	if err != nil && remoteConnection != nil {
+		_, bridgeName := wgbridge.Names(remoteConnection)
+		c.bridges.Delete(bridgeName, remoteConnection)
+	}
+	return rv, err
+}
+
+func (c *wireguardInterfaces) Close(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
+	var rv *empty.Empty
+	var err error
+	if next := chain.Next(ctx); next != nil {
+		rv, err = next.Close(ctx, crossConnect)
+	} else {
+		rv = new(empty.Empty)
+	}
+	if remoteConnection, _ := wireguardConnection(crossConnect); remoteConnection != nil {
+		_, bridgeName := wgbridge.Names(remoteConnection)
+		c.bridges.Delete(bridgeName, remoteConnection)
+	}
+	return rv, err
+}
+
+// wireguardConnection returns remote connection of the cross connect if it uses WireGuard mechanism, incoming is true if it is the source one
+func wireguardConnection(crossConnect *crossconnect.CrossConnect) (remoteConnection *connection.Connection, incoming bool) {
+	if src := crossConnect.GetRemoteSource(); src.GetMechanism().GetType() == wireguard.MECHANISM {
+		return src, true
+	}
+	if dst := crossConnect.GetRemoteDestination(); dst.GetMechanism().GetType() == wireguard.MECHANISM {
+		return dst, false
+	}
+	return nil, false
+}
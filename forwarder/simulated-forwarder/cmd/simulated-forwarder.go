@@ -0,0 +1,76 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/common"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/simulated-forwarder/pkg/simulatedforwarder"
+	"github.com/networkservicemesh/networkservicemesh/pkg/probes"
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools/jaeger"
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools/spanhelper"
+	"github.com/networkservicemesh/networkservicemesh/utils"
+)
+
+// Faults injected by the simulated forwarder
+var (
+	// SimulatedDelay - delay of each Request and Close
+	SimulatedDelay = utils.EnvVar("SIMULATED_DELAY")
+	// SimulatedErrorRate - probability of the Request failure, from 0 to 1
+	SimulatedErrorRate = utils.EnvVar("SIMULATED_ERROR_RATE")
+	// SimulatedDownAfter - period after the Request the cross connect destination is reported DOWN
+	SimulatedDownAfter = utils.EnvVar("SIMULATED_DOWN_AFTER")
+)
+
+func main() {
+	logrus.Info("Starting the simulated forwarding plane!")
+
+	closer := jaeger.InitJaeger("simulated-forwarder")
+	defer func() { _ = closer.Close() }()
+
+	span := spanhelper.FromContext(context.Background(), "Start.SimulatedForwarder.Forwarder")
+	defer span.Finish()
+	c := tools.NewOSSignalChannel()
+	forwarderGoals := &common.ForwarderProbeGoals{}
+	forwarderProbes := probes.New("Simulated forwarding plane liveness/readiness healthcheck", forwarderGoals)
+	forwarderProbes.BeginHealthCheck()
+
+	faults := simulatedforwarder.Faults{
+		Delay:     SimulatedDelay.GetOrDefaultDuration(0),
+		DownAfter: SimulatedDownAfter.GetOrDefaultDuration(0),
+	}
+	if value := SimulatedErrorRate.StringValue(); value != "" {
+		errorRate, err := strconv.ParseFloat(value, 64)
+		if err != nil || errorRate < 0 || errorRate > 1 {
+			logrus.Fatalf("Env variable %s must be set to a number from 0 to 1, was set to %s", SimulatedErrorRate.Name(), value)
+		}
+		faults.ErrorRate = errorRate
+	}
+	logrus.Infof("Injected faults: %+v", faults)
+
+	plane := simulatedforwarder.CreateSimulatedForwarder(faults)
+
+	registration := common.CreateForwarder(span.Context(), plane, forwarderGoals)
+
+	<-c
+	logrus.Info("Closing Forwarder Registration")
+	registration.Close()
+	plane.Stop()
+}
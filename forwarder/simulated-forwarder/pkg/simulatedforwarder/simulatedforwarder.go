@@ -0,0 +1,365 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simulatedforwarder - forwarder recording cross connects without programming any data plane. It sends the
+// cross connect events and metrics as the real forwarders do and injects configurable failures, so the control plane
+// can be tested without root privileges, VPP or even separate processes.
+package simulatedforwarder
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/memif"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/common"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
+)
+
+const (
+	// Name - default name of the simulated forwarder
+	Name = "simulated-forwarder"
+	// metricsPackets - mean number of packets each leg of the cross connect transmits and receives per metrics period
+	metricsPackets = 100
+	// metricsPacketSize - size of the simulated packets
+	metricsPacketSize = 1000
+)
+
+// Faults - failures injected by the simulated forwarder
+type Faults struct {
+	// Delay - delay of each Request and Close
+	Delay time.Duration
+	// ErrorRate - probability of the Request failure, from 0 to 1
+	ErrorRate float64
+	// DownAfter - cross connects are reported with DOWN destination once this period passes after the Request,
+	// disabled if zero
+	DownAfter time.Duration
+}
+
+// SimulatedForwarder instance
+type SimulatedForwarder struct {
+	common        *common.ForwarderConfig
+	mtx           sync.Mutex
+	faults        Faults
+	rand          *rand.Rand
+	crossConnects map[string]*crossconnect.CrossConnect
+	statistics    map[string]*crossconnect.Statistics
+	downTimers    map[string]*time.Timer
+	closeCh       chan struct{}
+	closeOnce     sync.Once
+}
+
+// CreateSimulatedForwarder creates an instance of the SimulatedForwarder injecting the faults
+func CreateSimulatedForwarder(faults Faults) *SimulatedForwarder {
+	return &SimulatedForwarder{
+		faults:        faults,
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		crossConnects: map[string]*crossconnect.CrossConnect{},
+		statistics:    map[string]*crossconnect.Statistics{},
+		downTimers:    map[string]*time.Timer{},
+		closeCh:       make(chan struct{}),
+	}
+}
+
+// Init initializes the simulated forwarding plane, mechanisms set in the config are advertised as is
+func (s *SimulatedForwarder) Init(config *common.ForwarderConfig) error {
+	s.common = config
+	if s.common.Name == "" || s.common.Name == common.ForwarderNameDefault {
+		s.common.Name = Name
+	}
+	if s.common.MechanismsUpdateChannel == nil {
+		s.common.MechanismsUpdateChannel = make(chan *common.Mechanisms, 1)
+	}
+	if s.common.Mechanisms == nil {
+		s.common.Mechanisms = s.defaultMechanisms()
+	}
+	if s.common.MetricsEnabled {
+		if s.common.MetricsPeriod <= 0 {
+			s.common.MetricsPeriod = common.ForwarderMetricsRequestPeriodDefault
+		}
+		go s.serveMetrics()
+	}
+	return nil
+}
+
+// Serve serves the simulated forwarder on the config forwarder socket and registers it with NSMD on the config
+// registrar socket, config does not need the egress interface. It is used to run simulated forwarders in the same
+// process with NSMDs, the forwarder is stopped and unregistered when the context is done.
+func (s *SimulatedForwarder) Serve(ctx context.Context, config *common.ForwarderConfig) error {
+	if err := common.ServeForwarder(ctx, s, config); err != nil {
+		return err
+	}
+	go func() {
+		registrar := common.NewForwarderRegistrarClient(config.RegistrarSocketType, config.RegistrarSocket)
+		registration := registrar.Register(ctx, config.Name, config.ForwarderSocket, config.Capacity, config.Labels, nil, nil)
+		<-ctx.Done()
+		registration.Close()
+		s.Stop()
+	}()
+	return nil
+}
+
+// CreateForwarderServer creates an instance of ForwarderServer
+func (s *SimulatedForwarder) CreateForwarderServer(config *common.ForwarderConfig) forwarder.ForwarderServer {
+	return chain.ChainOf(
+		chain.UseCrossConnectMonitor(config.Monitor),
+		chain.RequestValidator(),
+		chain.CheckMechanisms(config),
+		s)
+}
+
+// Request records the cross connect, it is the last handler of the chain
+func (s *SimulatedForwarder) Request(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*crossconnect.CrossConnect, error) {
+	faults := s.Faults()
+	if err := delay(ctx, faults.Delay); err != nil {
+		return nil, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if faults.ErrorRate > 0 && s.rand.Float64() < faults.ErrorRate {
+		err := errors.Errorf("simulated-forwarder: injected failure of cross connect %s", crossConnect.GetId())
+		chain.Logger(ctx).Error(err)
+		return nil, err
+	}
+
+	id := crossConnect.GetId()
+	s.crossConnects[id] = proto.Clone(crossConnect).(*crossconnect.CrossConnect)
+	if _, ok := s.statistics[id]; !ok {
+		s.statistics[id] = &crossconnect.Statistics{}
+	}
+	if timer, ok := s.downTimers[id]; ok {
+		timer.Stop()
+		delete(s.downTimers, id)
+	}
+	if faults.DownAfter > 0 {
+		s.downTimers[id] = time.AfterFunc(faults.DownAfter, func() {
+			if err := s.Down(id); err != nil {
+				logrus.Warnf("simulated-forwarder: %v", err)
+			}
+		})
+	}
+	chain.Logger(ctx).Infof("simulated-forwarder: recorded cross connect %s", id)
+	return chain.NextRequest(ctx, crossConnect)
+}
+
+// Close forgets the cross connect
+func (s *SimulatedForwarder) Close(ctx context.Context, crossConnect *crossconnect.CrossConnect) (*empty.Empty, error) {
+	if err := delay(ctx, s.Faults().Delay); err != nil {
+		return nil, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	id := crossConnect.GetId()
+	delete(s.crossConnects, id)
+	delete(s.statistics, id)
+	if timer, ok := s.downTimers[id]; ok {
+		timer.Stop()
+		delete(s.downTimers, id)
+	}
+	chain.Logger(ctx).Infof("simulated-forwarder: forgot cross connect %s", id)
+	return chain.NextClose(ctx, crossConnect)
+}
+
+// Down reports the destination of the recorded cross connect DOWN, as the forwarders do when the endpoint side of the
+// connection fails. NSMD heals such connections.
+func (s *SimulatedForwarder) Down(crossConnectID string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	xcon, ok := s.crossConnects[crossConnectID]
+	if !ok {
+		return errors.Errorf("cross connect %s is not found", crossConnectID)
+	}
+	xcon.GetDestination().State = connection.State_DOWN
+	delete(s.downTimers, crossConnectID)
+
+	logrus.Infof("simulated-forwarder: reporting destination of cross connect %s DOWN", crossConnectID)
+	s.common.Monitor.Update(context.Background(), proto.Clone(xcon).(*crossconnect.CrossConnect))
+	return nil
+}
+
+// CrossConnects returns copies of the recorded cross connects
+func (s *SimulatedForwarder) CrossConnects() map[string]*crossconnect.CrossConnect {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	rv := make(map[string]*crossconnect.CrossConnect, len(s.crossConnects))
+	for id, xcon := range s.crossConnects {
+		rv[id] = proto.Clone(xcon).(*crossconnect.CrossConnect)
+	}
+	return rv
+}
+
+// Faults returns currently injected faults
+func (s *SimulatedForwarder) Faults() Faults {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.faults
+}
+
+// SetFaults changes injected faults, they are applied to the following requests
+func (s *SimulatedForwarder) SetFaults(faults Faults) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.faults = faults
+}
+
+// UpdateMechanisms advertises new mechanisms to NSMD
+func (s *SimulatedForwarder) UpdateMechanisms(mechanisms *common.Mechanisms) {
+	s.common.MechanismsUpdateChannel <- mechanisms
+}
+
+// Stop stops metrics and DOWN events
+func (s *SimulatedForwarder) Stop() {
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+	})
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for id, timer := range s.downTimers {
+		timer.Stop()
+		delete(s.downTimers, id)
+	}
+}
+
+// MonitorMechanisms handler
+func (s *SimulatedForwarder) MonitorMechanisms(empty *empty.Empty, updateSrv forwarder.MechanismsMonitor_MonitorMechanismsServer) error {
+	initialUpdate := &forwarder.MechanismUpdate{
+		RemoteMechanisms: s.common.Mechanisms.RemoteMechanisms,
+		LocalMechanisms:  s.common.Mechanisms.LocalMechanisms,
+	}
+
+	logrus.Infof("simulated-forwarder: sending MonitorMechanisms update: %v", initialUpdate)
+	if err := updateSrv.Send(initialUpdate); err != nil {
+		logrus.Errorf("simulated-forwarder: detected server error %s, gRPC code: %+v on gRPC channel", err.Error(), status.Convert(err).Code())
+		return nil
+	}
+	for {
+		select {
+		case update := <-s.common.MechanismsUpdateChannel:
+			s.common.Mechanisms = update
+			logrus.Infof("simulated-forwarder: sending MonitorMechanisms update: %v", update)
+
+			updateMsg := &forwarder.MechanismUpdate{
+				RemoteMechanisms: update.RemoteMechanisms,
+				LocalMechanisms:  update.LocalMechanisms,
+			}
+			if err := updateSrv.Send(updateMsg); err != nil {
+				logrus.Errorf("simulated-forwarder: detected server error %s, gRPC code: %+v on gRPC channel", err.Error(), status.Convert(err).Code())
+				return nil
+			}
+		case <-updateSrv.Context().Done():
+			return nil
+		case <-s.closeCh:
+			return nil
+		}
+	}
+}
+
+// defaultMechanisms returns mechanisms supported by both real forwarders
+func (s *SimulatedForwarder) defaultMechanisms() *common.Mechanisms {
+	srcIP := "127.0.0.1"
+	if s.common.SrcIP != nil {
+		srcIP = s.common.SrcIP.String()
+	}
+	return &common.Mechanisms{
+		LocalMechanisms: []*connection.Mechanism{
+			{
+				Type: kernel.MECHANISM,
+			},
+			{
+				Type: memif.MECHANISM,
+			},
+		},
+		RemoteMechanisms: []*connection.Mechanism{
+			{
+				Type: vxlan.MECHANISM,
+				Parameters: map[string]string{
+					vxlan.SrcIP: srcIP,
+				},
+			},
+		},
+	}
+}
+
+// serveMetrics periodically sends growing traffic counters of the recorded cross connects legs named as the kernel
+// forwarder does
+func (s *SimulatedForwarder) serveMetrics() {
+	ticker := time.NewTicker(s.common.MetricsPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if stats := s.collectMetrics(); len(stats) != 0 {
+				s.common.Monitor.HandleMetrics(stats)
+			}
+		case <-s.closeCh:
+			return
+		}
+	}
+}
+
+func (s *SimulatedForwarder) collectMetrics() map[string]*crossconnect.Metrics {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	stats := make(map[string]*crossconnect.Metrics)
+	for id, statistics := range s.statistics {
+		packets := uint64(metricsPackets/2 + s.rand.Intn(metricsPackets))
+		statistics.RxPackets += packets
+		statistics.TxPackets += packets
+		statistics.RxBytes += packets * metricsPacketSize
+		statistics.TxBytes += packets * metricsPacketSize
+
+		xcon := s.crossConnects[id]
+		if xcon.GetLocalSource() != nil {
+			stats["SRC-"+id] = statistics.Metrics()
+		}
+		if xcon.GetLocalDestination() != nil {
+			stats["DST-"+id] = statistics.Metrics()
+		}
+	}
+	return stats
+}
+
+// delay waits for the duration or for the context to be done
+func delay(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulatedforwarder
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/onsi/gomega"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/memif"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/crossconnect"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/common"
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
+)
+
+const testTimeout = 5 * time.Second
+
+type testForwarder struct {
+	forwarder.ForwarderClient
+	forwarder.MechanismsMonitorClient
+	crossconnect.MonitorCrossConnectClient
+}
+
+func startSimulatedForwarder(ctx context.Context, t *testing.T, sim *SimulatedForwarder) *testForwarder {
+	_ = os.Setenv(tools.InsecureEnv, "true")
+	assert := gomega.NewWithT(t)
+	baseDir, err := ioutil.TempDir("", "simulated-forwarder")
+	assert.Expect(err).Should(gomega.BeNil())
+	go func() {
+		<-ctx.Done()
+		_ = os.RemoveAll(baseDir)
+	}()
+
+	config := &common.ForwarderConfig{
+		NSMBaseDir:          baseDir,
+		RegistrarSocket:     path.Join(baseDir, "registrar.sock"),
+		RegistrarSocketType: "unix",
+		ForwarderSocket:     path.Join(baseDir, "forwarder.sock"),
+		ForwarderSocketType: "unix",
+		MetricsEnabled:      true,
+		MetricsPeriod:       10 * time.Millisecond,
+	}
+	assert.Expect(common.ServeForwarder(ctx, sim, config)).Should(gomega.BeNil())
+
+	conn, err := tools.DialUnixInsecure(config.ForwarderSocket)
+	assert.Expect(err).Should(gomega.BeNil())
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+		sim.Stop()
+	}()
+
+	return &testForwarder{
+		ForwarderClient:           forwarder.NewForwarderClient(conn),
+		MechanismsMonitorClient:   forwarder.NewMechanismsMonitorClient(conn),
+		MonitorCrossConnectClient: crossconnect.NewMonitorCrossConnectClient(conn),
+	}
+}
+
+func newTestCrossConnect(id string) *crossconnect.CrossConnect {
+	return &crossconnect.CrossConnect{
+		Id:      id,
+		Payload: "IP",
+		Source: &connection.Connection{
+			Id:             "src-" + id,
+			NetworkService: "golden-network",
+			Mechanism:      &connection.Mechanism{Type: kernel.MECHANISM},
+		},
+		Destination: &connection.Connection{
+			Id:             "dst-" + id,
+			NetworkService: "golden-network",
+			Mechanism:      &connection.Mechanism{Type: kernel.MECHANISM},
+		},
+	}
+}
+
+func receiveCrossConnectEvent(t *testing.T, stream crossconnect.MonitorCrossConnect_MonitorCrossConnectsClient,
+	match func(event *crossconnect.CrossConnectEvent) bool) *crossconnect.CrossConnectEvent {
+	assert := gomega.NewWithT(t)
+	for {
+		event, err := stream.Recv()
+		assert.Expect(err).Should(gomega.BeNil())
+		if match(event) {
+			return event
+		}
+	}
+}
+
+func TestSimulatedForwarderMechanisms(t *testing.T) {
+	assert := gomega.NewWithT(t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	sim := CreateSimulatedForwarder(Faults{})
+	client := startSimulatedForwarder(ctx, t, sim)
+
+	stream, err := client.MonitorMechanisms(ctx, &empty.Empty{})
+	assert.Expect(err).Should(gomega.BeNil())
+	update, err := stream.Recv()
+	assert.Expect(err).Should(gomega.BeNil())
+	assert.Expect(update.GetLocalMechanisms()).Should(gomega.HaveLen(2))
+	assert.Expect(update.GetLocalMechanisms()[0].GetType()).Should(gomega.Equal(kernel.MECHANISM))
+	assert.Expect(update.GetLocalMechanisms()[1].GetType()).Should(gomega.Equal(memif.MECHANISM))
+	assert.Expect(update.GetRemoteMechanisms()).Should(gomega.HaveLen(1))
+	assert.Expect(update.GetRemoteMechanisms()[0].GetType()).Should(gomega.Equal(vxlan.MECHANISM))
+	assert.Expect(update.GetRemoteMechanisms()[0].GetParameters()[vxlan.SrcIP]).Should(gomega.Equal("127.0.0.1"))
+
+	sim.UpdateMechanisms(&common.Mechanisms{
+		LocalMechanisms: []*connection.Mechanism{{Type: memif.MECHANISM}},
+	})
+	update, err = stream.Recv()
+	assert.Expect(err).Should(gomega.BeNil())
+	assert.Expect(update.GetLocalMechanisms()).Should(gomega.HaveLen(1))
+	assert.Expect(update.GetRemoteMechanisms()).Should(gomega.BeEmpty())
+}
+
+func TestSimulatedForwarderRequestClose(t *testing.T) {
+	assert := gomega.NewWithT(t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	sim := CreateSimulatedForwarder(Faults{})
+	client := startSimulatedForwarder(ctx, t, sim)
+	stream, err := client.MonitorCrossConnects(ctx, &empty.Empty{})
+	assert.Expect(err).Should(gomega.BeNil())
+
+	xcon := newTestCrossConnect("1")
+	_, err = client.Request(ctx, xcon)
+	assert.Expect(err).Should(gomega.BeNil())
+	assert.Expect(sim.CrossConnects()).Should(gomega.HaveKey("1"))
+
+	receiveCrossConnectEvent(t, stream, func(event *crossconnect.CrossConnectEvent) bool {
+		return event.GetType() == crossconnect.CrossConnectEventType_UPDATE && event.GetCrossConnects()["1"] != nil
+	})
+	metrics := receiveCrossConnectEvent(t, stream, func(event *crossconnect.CrossConnectEvent) bool {
+		return len(event.GetMetrics()) != 0
+	})
+	assert.Expect(metrics.GetMetrics()).Should(gomega.HaveKey("SRC-1"))
+	assert.Expect(metrics.GetMetrics()).Should(gomega.HaveKey("DST-1"))
+	assert.Expect(metrics.GetMetrics()["SRC-1"].GetMetrics()[crossconnect.RxPacketsKey]).ShouldNot(gomega.Equal("0"))
+
+	_, err = client.Close(ctx, xcon)
+	assert.Expect(err).Should(gomega.BeNil())
+	assert.Expect(sim.CrossConnects()).Should(gomega.BeEmpty())
+	receiveCrossConnectEvent(t, stream, func(event *crossconnect.CrossConnectEvent) bool {
+		return event.GetType() == crossconnect.CrossConnectEventType_DELETE && event.GetCrossConnects()["1"] != nil
+	})
+}
+
+func TestSimulatedForwarderFaults(t *testing.T) {
+	assert := gomega.NewWithT(t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	sim := CreateSimulatedForwarder(Faults{ErrorRate: 1})
+	client := startSimulatedForwarder(ctx, t, sim)
+	stream, err := client.MonitorCrossConnects(ctx, &empty.Empty{})
+	assert.Expect(err).Should(gomega.BeNil())
+
+	_, err = client.Request(ctx, newTestCrossConnect("1"))
+	assert.Expect(err).ShouldNot(gomega.BeNil())
+	assert.Expect(sim.CrossConnects()).Should(gomega.BeEmpty())
+
+	sim.SetFaults(Faults{Delay: 50 * time.Millisecond, DownAfter: 50 * time.Millisecond})
+	start := time.Now()
+	_, err = client.Request(ctx, newTestCrossConnect("2"))
+	assert.Expect(err).Should(gomega.BeNil())
+	assert.Expect(time.Since(start)).Should(gomega.BeNumerically(">=", 50*time.Millisecond))
+
+	receiveCrossConnectEvent(t, stream, func(event *crossconnect.CrossConnectEvent) bool {
+		xcon := event.GetCrossConnects()["2"]
+		return event.GetType() == crossconnect.CrossConnectEventType_UPDATE &&
+			xcon.GetDestination().GetState() == connection.State_DOWN
+	})
+	assert.Expect(sim.CrossConnects()["2"].GetDestination().GetState()).Should(gomega.Equal(connection.State_DOWN))
+	assert.Expect(sim.Down("3")).ShouldNot(gomega.BeNil())
+}
@@ -62,13 +62,13 @@ func (c *KernelConnectionConverter) ToDataRequest(rv *configurator.Config, conne
 	var ipAddresses []string
 	var mac string
 	if c.conversionParameters.Side == DESTINATION {
-		ipAddresses = []string{c.Connection.GetContext().GetIpContext().GetDstIpAddr()}
+		ipAddresses = c.Connection.GetContext().GetIpContext().DstIPAddrs()
 		if !c.GetContext().IsEthernetContextEmtpy() {
 			mac = c.GetContext().EthernetContext.DstMac
 		}
 	}
 	if c.conversionParameters.Side == SOURCE {
-		ipAddresses = []string{c.Connection.GetContext().GetIpContext().GetSrcIpAddr()}
+		ipAddresses = c.Connection.GetContext().GetIpContext().SrcIPAddrs()
 		if !c.GetContext().IsEthernetContextEmtpy() {
 			mac = c.GetContext().EthernetContext.SrcMac
 		}
@@ -161,14 +161,14 @@ func (c *KernelConnectionConverter) ToDataRequest(rv *configurator.Config, conne
 
 	// Process static routes
 	var routes []*connectioncontext.Route
-	var ipAddress string
+	var gwAddresses []string
 	switch c.conversionParameters.Side {
 	case SOURCE:
 		routes = c.Connection.GetContext().GetIpContext().GetDstRoutes()
-		ipAddress = extractCleanIPAddress(c.Connection.GetContext().GetIpContext().GetDstIpAddr())
+		gwAddresses = c.Connection.GetContext().GetIpContext().DstIPAddrs()
 	case DESTINATION:
 		routes = c.Connection.GetContext().GetIpContext().GetSrcRoutes()
-		ipAddress = extractCleanIPAddress(c.Connection.GetContext().GetIpContext().GetSrcIpAddr())
+		gwAddresses = c.Connection.GetContext().GetIpContext().SrcIPAddrs()
 	}
 
 	duplicatedPrefixes := make(map[string]bool)
@@ -179,7 +179,7 @@ func (c *KernelConnectionConverter) ToDataRequest(rv *configurator.Config, conne
 				DstNetwork:        route.Prefix,
 				OutgoingInterface: c.conversionParameters.Name,
 				Scope:             linux_l3.Route_GLOBAL,
-				GwAddr:            ipAddress,
+				GwAddr:            gatewayIPAddress(gwAddresses, route.Prefix),
 			})
 		}
 	}
@@ -195,11 +195,13 @@ func (c *KernelConnectionConverter) ToDataRequest(rv *configurator.Config, conne
 		}
 		if c.GetContext().EthernetContext != nil && c.GetContext().EthernetContext.DstMac != "" {
 			logrus.Infof("set arp for: %v", c.GetContext().String())
-			rv.LinuxConfig.ArpEntries = append(rv.LinuxConfig.ArpEntries, &linux.ARPEntry{
-				IpAddress: strings.Split(c.GetContext().IpContext.DstIpAddr, "/")[0],
-				Interface: c.conversionParameters.Name,
-				HwAddress: c.GetContext().EthernetContext.DstMac,
-			})
+			for _, dstIPAddr := range c.GetContext().GetIpContext().DstIPAddrs() {
+				rv.LinuxConfig.ArpEntries = append(rv.LinuxConfig.ArpEntries, &linux.ARPEntry{
+					IpAddress: strings.Split(dstIPAddr, "/")[0],
+					Interface: c.conversionParameters.Name,
+					HwAddress: c.GetContext().EthernetContext.DstMac,
+				})
+			}
 		}
 	}
 	return rv, nil
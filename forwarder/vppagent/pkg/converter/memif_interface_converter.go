@@ -68,10 +68,10 @@ func (c *MemifInterfaceConverter) ToDataRequest(rv *configurator.Config, connect
 
 	var ipAddresses []string
 	if c.conversionParameters.Terminate && c.conversionParameters.Side == DESTINATION {
-		ipAddresses = []string{c.Connection.GetContext().GetIpContext().GetDstIpAddr()}
+		ipAddresses = c.Connection.GetContext().GetIpContext().DstIPAddrs()
 	}
 	if c.conversionParameters.Terminate && c.conversionParameters.Side == SOURCE {
-		ipAddresses = []string{c.Connection.GetContext().GetIpContext().GetSrcIpAddr()}
+		ipAddresses = c.Connection.GetContext().GetIpContext().SrcIPAddrs()
 	}
 
 	if c.conversionParameters.Name == "" {
@@ -105,7 +105,7 @@ func (c *MemifInterfaceConverter) ToDataRequest(rv *configurator.Config, connect
 		route := &vpp.Route{
 			Type:              vpp_l3.Route_INTER_VRF,
 			DstNetwork:        route.Prefix,
-			NextHopAddr:       extractCleanIPAddress(c.Connection.GetContext().GetIpContext().GetDstIpAddr()),
+			NextHopAddr:       gatewayIPAddress(c.Connection.GetContext().GetIpContext().DstIPAddrs(), route.Prefix),
 			OutgoingInterface: c.conversionParameters.Name,
 		}
 		rv.VppConfig.Routes = append(rv.VppConfig.Routes, route)
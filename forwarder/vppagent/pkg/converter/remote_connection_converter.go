@@ -30,6 +30,8 @@ import (
 	vpp_srv6 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/srv6"
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/wgbridge"
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
 )
@@ -53,7 +55,7 @@ func NewRemoteConnectionConverter(c *connection.Connection, name, tapName string
 }
 
 func (c *RemoteConnectionConverter) checkMechanism() bool {
-	mechanisms := []string{vxlan.MECHANISM, srv6.MECHANISM}
+	mechanisms := []string{vxlan.MECHANISM, srv6.MECHANISM, wireguard.MECHANISM}
 	for _, m := range mechanisms {
 		if m == c.GetMechanism().GetType() {
 			return true
@@ -111,6 +113,21 @@ func (c *RemoteConnectionConverter) ToDataRequest(rv *configurator.Config, conne
 				},
 			},
 		})
+	case wireguard.MECHANISM:
+		// WireGuard tunnel is created in the host namespace, VPP is attached to the Ethernet bridge over it
+		_, bridgeName := wgbridge.Names(c.Connection)
+		logrus.Infof("WireGuard tunnel of %s is bridged by %s", c.GetId(), bridgeName)
+
+		rv.VppConfig.Interfaces = append(rv.VppConfig.Interfaces, &vpp.Interface{
+			Name:    c.name,
+			Type:    vpp_interfaces.Interface_AF_PACKET,
+			Enabled: true,
+			Link: &vpp_interfaces.Interface_Afpacket{
+				Afpacket: &vpp_interfaces.AfpacketLink{
+					HostIfName: bridgeName,
+				},
+			},
+		})
 	case srv6.MECHANISM:
 		m := srv6.ToMechanism(c.GetMechanism())
 
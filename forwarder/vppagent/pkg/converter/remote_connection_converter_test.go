@@ -0,0 +1,64 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter_test
+
+import (
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	vpp_interfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/wgbridge"
+	. "github.com/networkservicemesh/networkservicemesh/forwarder/vppagent/pkg/converter"
+)
+
+func createTestWireguardConnection() *connection.Connection {
+	return &connection.Connection{
+		Id:             connectionId,
+		NetworkService: networkService,
+		Mechanism: &connection.Mechanism{
+			Type: wireguard.MECHANISM,
+			Parameters: map[string]string{
+				wireguard.SrcIP:   "10.0.0.1",
+				wireguard.DstIP:   "10.0.0.2",
+				wireguard.SrcPort: strconv.Itoa(wireguard.BasePort),
+				wireguard.DstPort: strconv.Itoa(wireguard.BasePort),
+			},
+		},
+		Context: createTestContext(),
+	}
+}
+
+func TestWireguardRemoteConnectionConverter(t *testing.T) {
+	g := NewWithT(t)
+	conn := createTestWireguardConnection()
+	_, bridgeName := wgbridge.Names(conn)
+
+	for _, side := range []ConnectionContextSide{SOURCE, DESTINATION} {
+		converter := NewRemoteConnectionConverter(conn, interfaceName, "", side)
+		dataRequest, err := converter.ToDataRequest(nil, true)
+		g.Expect(err).To(BeNil())
+
+		// VPP is attached to the same ip6gretap bridge over WireGuard the kernel forwarder moves to the pod
+		g.Expect(dataRequest.VppConfig.Interfaces).To(HaveLen(1))
+		intf := dataRequest.VppConfig.Interfaces[0]
+		g.Expect(intf.Name).To(Equal(interfaceName))
+		g.Expect(intf.Type).To(Equal(vpp_interfaces.Interface_AF_PACKET))
+		g.Expect(intf.Link.(*vpp_interfaces.Interface_Afpacket).Afpacket.HostIfName).To(Equal(bridgeName))
+	}
+}
@@ -72,6 +72,18 @@ func extractCleanIPAddress(addr string) string {
 	return addr
 }
 
+// gatewayIPAddress returns the address of the same IP family as the route prefix without the prefix length
+func gatewayIPAddress(addrs []string, prefix string) string {
+	_, prefixNet, err := net.ParseCIDR(prefix)
+	for _, addr := range addrs {
+		ip := net.ParseIP(extractCleanIPAddress(addr))
+		if err != nil || ip == nil || (ip.To4() == nil) == (prefixNet.IP.To4() == nil) {
+			return extractCleanIPAddress(addr)
+		}
+	}
+	return ""
+}
+
 func netNsFileName(m *connection.Mechanism) (string, error) {
 	if m == nil {
 		return "", errors.New("mechanism cannot be nil")
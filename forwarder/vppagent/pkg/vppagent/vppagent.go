@@ -35,8 +35,10 @@ import (
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/memif"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/srv6"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/vxlan"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/wireguard"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/api/forwarder"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/pkg/common"
+	"github.com/networkservicemesh/networkservicemesh/forwarder/sdk/chain"
 	sdk "github.com/networkservicemesh/networkservicemesh/forwarder/sdk/vppagent"
 	"github.com/networkservicemesh/networkservicemesh/forwarder/vppagent/pkg/vppagent/kvschedclient"
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
@@ -63,13 +65,16 @@ func CreateVPPAgent() *VPPAgent {
 
 //CreateForwarderServer creates ForwarderServer handler
 func (v *VPPAgent) CreateForwarderServer(config *common.ForwarderConfig) forwarder.ForwarderServer {
-	return sdk.ChainOf(
-		sdk.RequestValidator(),
-		sdk.UseCrossConnectMonitor(config.Monitor),
+	return chain.ChainOf(
+		chain.UseCrossConnectMonitor(config.Monitor),
+		chain.RequestValidator(),
+		chain.CheckMechanisms(config),
 		sdk.DirectMemifInterfaces(config.NSMBaseDir),
 		sdk.Connect(v.endpoint()),
+		sdk.WireguardInterfaces(),
 		sdk.KernelInterfaces(config.NSMBaseDir),
 		sdk.UseEthernetContext(),
+		chain.UseQoS(),
 		sdk.ClearMechanisms(config.NSMBaseDir),
 		sdk.Commit(v.downstreamResync))
 }
@@ -412,6 +417,12 @@ func (v *VPPAgent) configureVPPAgent() error {
 					vxlan.SrcIP: v.common.EgressInterface.SrcIPNet().IP.String(),
 				},
 			},
+			{
+				Type: wireguard.MECHANISM,
+				Parameters: map[string]string{
+					wireguard.SrcIP: v.common.EgressInterface.SrcIPNet().IP.String(),
+				},
+			},
 		},
 	}
 	if v.common.EgressInterface.SrcLocalSID() != nil {
@@ -26,6 +26,7 @@ import (
 
 var closing = false
 var managers = map[string]string{}
+var collector *metricspkg.CrossConnectCollector
 
 func monitorCrossConnects(address string, continuousMonitor bool, cache map[string]string) {
 	var err error
@@ -49,6 +50,9 @@ func monitorCrossConnects(address string, continuousMonitor bool, cache map[stri
 	if _, ok := cache["prometheus"]; !ok {
 		cache["prometheus"] = "off"
 	}
+	if cache["prometheus"] == "on" {
+		defer collector.Forget(address)
+	}
 
 	t := proto.TextMarshaler{}
 	for {
@@ -66,8 +70,11 @@ func monitorCrossConnects(address string, continuousMonitor bool, cache map[stri
 		}
 		println(data)
 
-		if event.Type == crossconnect.CrossConnectEventType_UPDATE && cache["prometheus"] == "on" {
-			trackMetrics(event)
+		if cache["prometheus"] == "on" {
+			if event.Type == crossconnect.CrossConnectEventType_UPDATE {
+				trackMetrics(event)
+			}
+			collector.HandleEvent(address, event)
 		}
 		if !continuousMonitor {
 			logrus.Infof("Monitoring of server: %s. is complete...", address)
@@ -194,6 +201,7 @@ func lookForNSMServers() {
 		logrus.Warningf("failed to serve prometheus: env PROMETHEUS=%t, err: %v", prom, err)
 	} else if prom {
 		cache["prometheus"] = "on"
+		collector = metricspkg.RegisterCrossConnectCollector()
 	}
 
 	nsmNamespace := namespace.GetNamespace()
@@ -0,0 +1,99 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/ipam"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/metrics"
+	"github.com/networkservicemesh/networkservicemesh/k8s/pkg/ipamstore"
+	"github.com/networkservicemesh/networkservicemesh/k8s/pkg/networkservice/namespace"
+	k8s_utils "github.com/networkservicemesh/networkservicemesh/k8s/pkg/utils"
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools/jaeger"
+	"github.com/networkservicemesh/networkservicemesh/sdk/ipamserver"
+	"github.com/networkservicemesh/networkservicemesh/sdk/prefix_pool"
+	"github.com/networkservicemesh/networkservicemesh/utils"
+)
+
+const (
+	ipamServiceAddressEnv     = "IPAM_SERVICE_ADDRESS"
+	ipamPoolsEnv              = "IPAM_POOLS"
+	ipamServiceAddressDefault = "0.0.0.0:5007"
+	configMapPrefix           = "nsm-ipam-"
+	configMapOwner            = "ipam-service"
+)
+
+func main() {
+	logrus.Info("Starting IPAM service...")
+	utils.PrintAllEnv(logrus.StandardLogger())
+	// Capture signals to cleanup before exiting
+	c := tools.NewOSSignalChannel()
+
+	closer := jaeger.InitJaeger("ipam-service")
+	defer func() { _ = closer.Close() }()
+
+	address := os.Getenv(ipamServiceAddressEnv)
+	if strings.TrimSpace(address) == "" {
+		address = ipamServiceAddressDefault
+	}
+
+	logrus.Info("Building Kubernetes clientset...")
+	_, config, err := k8s_utils.NewClientSet()
+	if err != nil {
+		logrus.Fatalln("Failed to build Kubernetes clientset: ", err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		logrus.Fatalln("Failed to build Kubernetes clientset: ", err)
+	}
+
+	/* Connections of each Network Service are kept in its own ConfigMap, so the restarted service keeps the addresses */
+	configMaps := clientset.CoreV1().ConfigMaps(namespace.GetNamespace())
+	pools, err := ipamserver.NewPools(os.Getenv(ipamPoolsEnv), func(networkService string) prefix_pool.Store {
+		return ipamstore.NewConfigMapStore(configMaps, configMapPrefix+networkService, configMapOwner)
+	})
+	if err != nil {
+		logrus.Fatalln("Failed to create IPAM pools: ", err)
+	}
+
+	prometheus, err := tools.ReadEnvBool(metrics.PrometheusEnv, metrics.PrometheusDefault)
+	if err == nil && prometheus {
+		go metrics.RunPrometheusMetricsServer()
+	}
+
+	server := tools.NewServer(context.Background())
+	ipam.RegisterIPAMServer(server, ipamserver.NewServer(pools))
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+
+	logrus.Infof("IPAM service is listening on %s", address)
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			logrus.Fatalln(err)
+		}
+	}()
+	<-c
+}
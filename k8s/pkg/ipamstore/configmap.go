@@ -0,0 +1,144 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipamstore provides the IPAM store keeping the connections in Kubernetes
+package ipamstore
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/networkservicemesh/networkservicemesh/sdk/prefix_pool"
+)
+
+const (
+	// PoolsKeySuffix is the suffix of the ConfigMap data key of the pools stored by the owner
+	PoolsKeySuffix = ".pools.json"
+)
+
+type configMapStore struct {
+	sync.Mutex
+	configMaps v1.ConfigMapInterface
+	name       string
+	owner      string
+}
+
+// NewConfigMapStore creates an IPAM store keeping the pools of the owner in the ConfigMap with the name, the ConfigMap
+// is created on the first save. ConfigMap can be shared by several owners, e.g. endpoints with the same prefixes,
+// each of them keeps its pools under its own data key, so they do not overwrite each other's connections. Owner
+// should be stable across restarts and a valid ConfigMap key.
+func NewConfigMapStore(configMaps v1.ConfigMapInterface, name, owner string) prefix_pool.Store {
+	return &configMapStore{
+		configMaps: configMaps,
+		name:       name,
+		owner:      owner,
+	}
+}
+
+// key is the ConfigMap data key of the owner pools
+func (s *configMapStore) key() string {
+	return s.owner + PoolsKeySuffix
+}
+
+func (s *configMapStore) Load(pool string) (map[string]*prefix_pool.ConnectionRecord, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	cm, err := s.configMaps.Get(context.TODO(), s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get ConfigMap %s", s.name)
+	}
+	pools, err := parsePools(cm, s.key())
+	if err != nil {
+		return nil, err
+	}
+	return pools[pool], nil
+}
+
+func (s *configMapStore) Save(pool string, records map[string]*prefix_pool.ConnectionRecord) error {
+	s.Lock()
+	defer s.Unlock()
+
+	/* ConfigMap can be shared by several owners, retry if it is created or updated by the other one, only the
+	   owner key is replaced, so the other keys are kept as they are read */
+	return retry.OnError(retry.DefaultRetry, isConflict, func() error {
+		cm, err := s.configMaps.Get(context.TODO(), s.name, metav1.GetOptions{})
+		notFound := apierrors.IsNotFound(err)
+		if notFound {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: s.name,
+				},
+			}
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to get ConfigMap %s", s.name)
+		}
+
+		pools, err := parsePools(cm, s.key())
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			delete(pools, pool)
+		} else {
+			pools[pool] = records
+		}
+		data, err := json.Marshal(pools)
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		if len(pools) == 0 {
+			delete(cm.Data, s.key())
+		} else {
+			cm.Data[s.key()] = string(data)
+		}
+
+		if notFound {
+			_, err = s.configMaps.Create(context.TODO(), cm, metav1.CreateOptions{})
+		} else {
+			_, err = s.configMaps.Update(context.TODO(), cm, metav1.UpdateOptions{})
+		}
+		return err
+	})
+}
+
+func isConflict(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+}
+
+func parsePools(cm *corev1.ConfigMap, key string) (map[string]map[string]*prefix_pool.ConnectionRecord, error) {
+	pools := map[string]map[string]*prefix_pool.ConnectionRecord{}
+	data := cm.Data[key]
+	if data == "" {
+		return pools, nil
+	}
+	if err := json.Unmarshal([]byte(data), &pools); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s of ConfigMap %s", key, cm.Name)
+	}
+	return pools, nil
+}
@@ -1,6 +1,7 @@
 package registryserver
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -17,6 +18,10 @@ import (
 const PodNameEnv = utils.EnvVar("POD_NAME")
 const PodUidEnv = utils.EnvVar("POD_UID")
 
+// ConnectionCountAnnotation - NSE annotation with the number of connections advertised by NSM of the endpoint,
+// it is not a label, so it is not matched by the destination selectors
+const ConnectionCountAnnotation = "networkservicemesh.io/connection-count"
+
 func mapNsmToCustomResource(nsm *registry.NetworkServiceManager) *v1.NetworkServiceManager {
 	nsmCr := &v1.NetworkServiceManager{
 		ObjectMeta: metav1.ObjectMeta{
@@ -65,5 +70,25 @@ func mapNseFromCustomResource(cr *v1.NetworkServiceEndpoint) *registry.NetworkSe
 		Payload:                   cr.Spec.Payload,
 		Labels:                    cr.ObjectMeta.Labels,
 		State:                     string(cr.Status.State),
+		ConnectionCount:           connectionCount(cr),
+	}
+}
+
+func connectionCount(cr *v1.NetworkServiceEndpoint) uint32 {
+	count, err := strconv.ParseUint(cr.Annotations[ConnectionCountAnnotation], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(count)
+}
+
+func setConnectionCount(cr *v1.NetworkServiceEndpoint, count uint32) {
+	if count == 0 {
+		delete(cr.Annotations, ConnectionCountAnnotation)
+		return
+	}
+	if cr.Annotations == nil {
+		cr.Annotations = map[string]string{}
 	}
+	cr.Annotations[ConnectionCountAnnotation] = strconv.FormatUint(uint64(count), 10)
 }
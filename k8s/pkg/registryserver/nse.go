@@ -2,6 +2,7 @@ package registryserver
 
 import (
 	"os"
+	"reflect"
 	"strings"
 	"time"
 
@@ -70,30 +71,25 @@ func (rs *nseRegistryService) RegisterNSE(ctx context.Context, request *registry
 			return nil, err
 		}
 
-		var objectMeta metav1.ObjectMeta
-		if request.GetNetworkServiceEndpoint().GetName() == "" {
-			objectMeta = metav1.ObjectMeta{
-				GenerateName: request.GetNetworkService().GetName(),
-				Labels:       labels,
+		var nseResponse *v1.NetworkServiceEndpoint
+		forward := true
+		existingNse := rs.cache.GetNetworkServiceEndpoint(request.GetNetworkServiceEndpoint().GetName())
+		if existingNse != nil && existingNse.Spec.NsmName == rs.nsmName {
+			// NSM updates registration of its own endpoint, e.g. to advertise the number of connections
+			updNse := existingNse.DeepCopy()
+			updNse.Labels = labels
+			setConnectionCount(updNse, request.GetNetworkServiceEndpoint().GetConnectionCount())
+			// Changes of the number of connections only are not forwarded to the proxy registry
+			countChanged := !reflect.DeepEqual(existingNse.Annotations, updNse.Annotations)
+			forward = !countChanged || !reflect.DeepEqual(existingNse.Labels, updNse.Labels)
+			if reflect.DeepEqual(existingNse.ObjectMeta, updNse.ObjectMeta) {
+				nseResponse = existingNse
+			} else {
+				nseResponse, err = rs.cache.UpdateNetworkServiceEndpoint(updNse)
 			}
 		} else {
-			objectMeta = metav1.ObjectMeta{
-				Name:   request.GetNetworkServiceEndpoint().GetName(),
-				Labels: labels,
-			}
+			nseResponse, err = rs.addNetworkServiceEndpoint(request, labels)
 		}
-
-		nseResponse, err := rs.cache.AddNetworkServiceEndpoint(&v1.NetworkServiceEndpoint{
-			ObjectMeta: objectMeta,
-			Spec: v1.NetworkServiceEndpointSpec{
-				NetworkServiceName: request.GetNetworkService().GetName(),
-				Payload:            request.GetNetworkService().GetPayload(),
-				NsmName:            rs.nsmName,
-			},
-			Status: v1.NetworkServiceEndpointStatus{
-				State: v1.RUNNING,
-			},
-		})
 		if err != nil {
 			return nil, err
 		}
@@ -105,16 +101,47 @@ func (rs *nseRegistryService) RegisterNSE(ctx context.Context, request *registry
 		}
 		request.NetworkServiceManager = mapNsmFromCustomResource(nsm)
 
-		go func() {
-			if forwardErr := rs.forwardRegisterNSE(context.Background(), request); forwardErr != nil {
-				logger.Errorf("Cannot forward NSE Registration: %v", forwardErr)
-			}
-		}()
+		if forward {
+			go func() {
+				if forwardErr := rs.forwardRegisterNSE(context.Background(), request); forwardErr != nil {
+					logger.Errorf("Cannot forward NSE Registration: %v", forwardErr)
+				}
+			}()
+		}
 	}
 	logger.Infof("Returned from RegisterNSE: time: %v request: %v", time.Since(st), request)
 	return request, nil
 }
 
+func (rs *nseRegistryService) addNetworkServiceEndpoint(request *registry.NSERegistration, labels map[string]string) (*v1.NetworkServiceEndpoint, error) {
+	var objectMeta metav1.ObjectMeta
+	if request.GetNetworkServiceEndpoint().GetName() == "" {
+		objectMeta = metav1.ObjectMeta{
+			GenerateName: request.GetNetworkService().GetName(),
+			Labels:       labels,
+		}
+	} else {
+		objectMeta = metav1.ObjectMeta{
+			Name:   request.GetNetworkServiceEndpoint().GetName(),
+			Labels: labels,
+		}
+	}
+
+	nse := &v1.NetworkServiceEndpoint{
+		ObjectMeta: objectMeta,
+		Spec: v1.NetworkServiceEndpointSpec{
+			NetworkServiceName: request.GetNetworkService().GetName(),
+			Payload:            request.GetNetworkService().GetPayload(),
+			NsmName:            rs.nsmName,
+		},
+		Status: v1.NetworkServiceEndpointStatus{
+			State: v1.RUNNING,
+		},
+	}
+	setConnectionCount(nse, request.GetNetworkServiceEndpoint().GetConnectionCount())
+	return rs.cache.AddNetworkServiceEndpoint(nse)
+}
+
 func (rs *nseRegistryService) BulkRegisterNSE(srv registry.NetworkServiceRegistry_BulkRegisterNSEServer) error {
 	span := spanhelper.FromContext(srv.Context(), "ProxyNsmgr.BulkRegisterNSE")
 	defer span.Finish()
@@ -25,6 +25,8 @@ type RegistryCache interface {
 	GetNetworkServiceManager(name string) (*v1.NetworkServiceManager, error)
 
 	AddNetworkServiceEndpoint(nse *v1.NetworkServiceEndpoint) (*v1.NetworkServiceEndpoint, error)
+	UpdateNetworkServiceEndpoint(nse *v1.NetworkServiceEndpoint) (*v1.NetworkServiceEndpoint, error)
+	GetNetworkServiceEndpoint(name string) *v1.NetworkServiceEndpoint
 	DeleteNetworkServiceEndpoint(endpointName string) error
 	GetEndpointsByNs(networkServiceName string) []*v1.NetworkServiceEndpoint
 	GetEndpointsByNsm(nsmName string) []*v1.NetworkServiceEndpoint
@@ -124,6 +126,9 @@ func (rc *registryCacheImpl) AddNetworkService(ns *v1.NetworkService) (*v1.Netwo
 
 func (rc *registryCacheImpl) GetNetworkService(name string) (*v1.NetworkService, error) {
 	if ns := rc.networkServiceCache.Get(name); ns == nil {
+		if err := rc.networkServiceCache.ValidationError(name); err != nil {
+			return nil, errors.Wrapf(err, "NetworkService %v is rejected", name)
+		}
 		return nil, errors.Errorf("no NetworkService with name: %v", name)
 	} else {
 		return ns, nil
@@ -140,6 +145,20 @@ func (rc *registryCacheImpl) AddNetworkServiceEndpoint(nse *v1.NetworkServiceEnd
 	return nil, err
 }
 
+func (rc *registryCacheImpl) UpdateNetworkServiceEndpoint(nse *v1.NetworkServiceEndpoint) (*v1.NetworkServiceEndpoint, error) {
+	nseResponse, err := rc.clientset.NetworkserviceV1alpha1().NetworkServiceEndpoints(rc.nsmNamespace).Update(context.TODO(), nse, metav1.UpdateOptions{})
+	if err == nil {
+		rc.networkServiceEndpointCache.Update(nseResponse)
+		return nseResponse, nil
+	}
+
+	return nil, err
+}
+
+func (rc *registryCacheImpl) GetNetworkServiceEndpoint(name string) *v1.NetworkServiceEndpoint {
+	return rc.networkServiceEndpointCache.Get(name)
+}
+
 func (rc *registryCacheImpl) DeleteNetworkServiceEndpoint(endpointName string) error {
 	rc.networkServiceEndpointCache.Delete(endpointName)
 	return rc.clientset.NetworkserviceV1alpha1().NetworkServiceEndpoints(rc.nsmNamespace).Delete(context.TODO(), endpointName, metav1.DeleteOptions{})
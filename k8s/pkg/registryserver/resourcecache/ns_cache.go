@@ -7,6 +7,7 @@ import (
 	"github.com/sirupsen/logrus"
 	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/selector"
 	v1 "github.com/networkservicemesh/networkservicemesh/k8s/pkg/apis/networkservice/v1alpha1"
 	"github.com/networkservicemesh/networkservicemesh/k8s/pkg/networkservice/clientset/versioned"
 	. "github.com/networkservicemesh/networkservicemesh/k8s/pkg/networkservice/informers/externalversions"
@@ -16,6 +17,7 @@ import (
 type NetworkServiceCache struct {
 	cache           abstractResourceCache
 	networkServices map[string]*v1.NetworkService
+	invalid         map[string]error
 	getCh           chan *v1.NetworkService
 }
 
@@ -23,10 +25,12 @@ type NetworkServiceCache struct {
 func NewNetworkServiceCache(policy CacheFilterPolicy) *NetworkServiceCache {
 	rv := &NetworkServiceCache{
 		networkServices: make(map[string]*v1.NetworkService),
+		invalid:         make(map[string]error),
 	}
 	config := cacheConfig{
 		keyFunc:             getNsKey,
 		resourceAddedFunc:   rv.resourceAdded,
+		resourceUpdatedFunc: rv.resourceAdded,
 		resourceDeletedFunc: rv.resourceDeleted,
 		resourceGetFunc:     rv.resourceGet,
 		resourceType:        NsResource,
@@ -43,6 +47,15 @@ func (c *NetworkServiceCache) Get(key string) *v1.NetworkService {
 	return nil
 }
 
+// ValidationError returns the reason NetworkService with the given name was rejected by the cache, if any
+func (c *NetworkServiceCache) ValidationError(key string) error {
+	var err error
+	c.cache.syncExec(func() {
+		err = c.invalid[key]
+	})
+	return err
+}
+
 func (c *NetworkServiceCache) Add(ns *v1.NetworkService) {
 	c.cache.add(ns)
 }
@@ -66,6 +79,7 @@ func (c *NetworkServiceCache) StartWithResync(f SharedInformerFactory, cs *versi
 
 func (c *NetworkServiceCache) replace(resources []v1.NetworkService) {
 	c.networkServices = map[string]*v1.NetworkService{}
+	c.invalid = map[string]error{}
 	logrus.Infof("Replacing Network services with: %v", resources)
 	for i := 0; i < len(resources); i++ {
 		c.resourceAdded(&resources[i])
@@ -74,10 +88,18 @@ func (c *NetworkServiceCache) replace(resources []v1.NetworkService) {
 
 func (c *NetworkServiceCache) resourceAdded(obj interface{}) {
 	ns := obj.(*v1.NetworkService)
+	if err := validateNetworkService(ns); err != nil {
+		logrus.Errorf("Rejecting NetworkService %s: %v", ns.Name, err)
+		c.invalid[ns.Name] = err
+		delete(c.networkServices, ns.Name)
+		return
+	}
+	delete(c.invalid, ns.Name)
 	c.networkServices[ns.Name] = ns
 }
 
 func (c *NetworkServiceCache) resourceDeleted(key string) {
+	delete(c.invalid, key)
 	delete(c.networkServices, key)
 }
 
@@ -85,6 +107,20 @@ func (c *NetworkServiceCache) resourceGet(key string) interface{} {
 	return c.networkServices[key]
 }
 
+func validateNetworkService(ns *v1.NetworkService) error {
+	for _, match := range ns.Spec.Matches {
+		if err := selector.ValidateSelector(match.SourceSelector); err != nil {
+			return errors.Wrap(err, "invalid source selector")
+		}
+		for _, route := range match.Routes {
+			if err := selector.ValidateSelector(route.DestinationSelector); err != nil {
+				return errors.Wrap(err, "invalid destination selector")
+			}
+		}
+	}
+	return nil
+}
+
 func getNsKey(obj interface{}) string {
 	return obj.(*v1.NetworkService).Name
 }
@@ -28,6 +28,7 @@ func NewNetworkServiceEndpointCache(policy CacheFilterPolicy) *NetworkServiceEnd
 	config := cacheConfig{
 		keyFunc:             getNseKey,
 		resourceAddedFunc:   rv.resourceAdded,
+		resourceUpdatedFunc: rv.resourceAdded,
 		resourceDeletedFunc: rv.resourceDeleted,
 		resourceGetFunc:     rv.resourceGet,
 		resourceType:        NseResource,
@@ -69,6 +70,12 @@ func (c *NetworkServiceEndpointCache) Add(nse *v1.NetworkServiceEndpoint) {
 	c.cache.add(nse)
 }
 
+// Update replaces NSE in cache
+func (c *NetworkServiceEndpointCache) Update(nse *v1.NetworkServiceEndpoint) {
+	logrus.Infof("Updating NSE in cache: %v", *nse)
+	c.cache.update(nse)
+}
+
 func (c *NetworkServiceEndpointCache) Delete(key string) {
 	c.cache.delete(key)
 }
@@ -122,11 +122,10 @@ func (c *abstractResourceCache) addEventHandlers(informer cache.SharedInformer)
 				return
 			}
 			logrus.Infof("Update from k8s-registry: %v", reflect.TypeOf(old))
-			if _, ok := old.(*v1.NetworkServiceManager); !ok {
-				return
+			if oldNsm, ok := old.(*v1.NetworkServiceManager); ok {
+				logrus.Infof("Old NSM: %v", oldNsm)
+				logrus.Infof("New NSM: %v", new.(*v1.NetworkServiceManager))
 			}
-			logrus.Infof("Old NSM: %v", old.(*v1.NetworkServiceManager))
-			logrus.Infof("New NSM: %v", new.(*v1.NetworkServiceManager))
 			c.update(new)
 		}
 	}
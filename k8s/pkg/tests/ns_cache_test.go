@@ -38,3 +38,35 @@ func TestNsCacheConcurrentModification(t *testing.T) {
 
 	<-time.After(time.Second)
 }
+
+func TestNsCacheRejectsInvalidSelector(t *testing.T) {
+	g := NewWithT(t)
+
+	c := resourcecache.NewNetworkServiceCache(resourcecache.NoFilterPolicy())
+	fakeRegistry := fakeRegistry{}
+
+	stopFunc, err := c.Start(&fakeRegistry)
+	g.Expect(stopFunc).ToNot(BeNil())
+	g.Expect(err).To(BeNil())
+	defer stopFunc()
+
+	invalid := &v1.NetworkService{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns1"},
+		Spec: v1.NetworkServiceSpec{
+			Matches: []*v1.Match{
+				{
+					Routes: []*v1.Destination{
+						{DestinationSelector: map[string]string{"app": "{{index . \"app\""}},
+					},
+				},
+			},
+		},
+	}
+	c.Add(invalid)
+	g.Expect(c.Get("ns1")).Should(BeNil())
+	g.Expect(c.ValidationError("ns1")).ShouldNot(BeNil())
+
+	c.Add(&v1.NetworkService{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}})
+	g.Expect(c.Get("ns1")).ShouldNot(BeNil())
+	g.Expect(c.ValidationError("ns1")).Should(BeNil())
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backoff - retries with exponentially growing randomized delays, so clients failed at the same
+// time do not retry in lockstep
+package backoff
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	randomMtx sync.Mutex
+	// Seeded per process, jitter should differ between the nodes
+	random = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// Policy describes delays between retry attempts. Delay starts with Initial and is multiplied by Multiplier
+// after every attempt up to Max, every delay is randomized by up to Jitter fraction of it. Retries stop once
+// MaxAttempts attempts are done or Deadline is passed since the first attempt, zero values mean no limit.
+type Policy struct {
+	Initial     time.Duration
+	Max         time.Duration
+	Multiplier  float64
+	Jitter      float64
+	MaxAttempts int
+	Deadline    time.Duration
+}
+
+// Backoff tracks retry attempts of a single operation
+type Backoff struct {
+	policy   Policy
+	attempts int
+	start    time.Time
+}
+
+// Start starts tracking retry attempts of the operation
+func (p Policy) Start() *Backoff {
+	return &Backoff{
+		policy:   p,
+		attempts: 1,
+		start:    time.Now(),
+	}
+}
+
+// Delay returns delay before the given retry attempt, starting from 1, with no jitter applied
+func (p Policy) Delay(retry int) time.Duration {
+	delay := float64(p.Initial)
+	for i := 1; i < retry && (p.Max <= 0 || delay < float64(p.Max)); i++ {
+		if p.Multiplier > 1 {
+			delay *= p.Multiplier
+		}
+	}
+	if p.Max > 0 && delay > float64(p.Max) {
+		return p.Max
+	}
+	return time.Duration(delay)
+}
+
+// Attempts returns number of attempts done
+func (b *Backoff) Attempts() int {
+	return b.attempts
+}
+
+// Next returns delay before the next attempt and counts it as done, false is returned if no attempts left
+func (b *Backoff) Next() (time.Duration, bool) {
+	if b.policy.MaxAttempts > 0 && b.attempts >= b.policy.MaxAttempts {
+		return 0, false
+	}
+	delay := jitter(b.policy.Delay(b.attempts), b.policy.Jitter)
+	if b.policy.Deadline > 0 {
+		left := time.Until(b.start.Add(b.policy.Deadline))
+		if left <= 0 {
+			return 0, false
+		}
+		if delay > left {
+			delay = left
+		}
+	}
+	b.attempts++
+	return delay, true
+}
+
+// Wait waits for the next attempt, false is returned if no attempts left or context is done
+func (b *Backoff) Wait(ctx context.Context) bool {
+	delay, ok := b.Next()
+	if !ok {
+		return false
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Retry calls f until it succeeds, the last error is returned once no attempts left or context is done
+func Retry(ctx context.Context, policy Policy, f func(ctx context.Context) error) error {
+	b := policy.Start()
+	for {
+		err := f(ctx)
+		if err == nil {
+			return nil
+		}
+		if !b.Wait(ctx) {
+			return err
+		}
+	}
+}
+
+func jitter(delay time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || delay <= 0 {
+		return delay
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	randomMtx.Lock()
+	r := random.Float64()
+	randomMtx.Unlock()
+	return time.Duration(float64(delay) * (1 - fraction*r))
+}
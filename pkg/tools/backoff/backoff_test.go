@@ -0,0 +1,108 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backoff
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+func TestDelayIsCapped(t *testing.T) {
+	g := NewWithT(t)
+
+	policy := Policy{
+		Initial:    time.Second,
+		Max:        10 * time.Second,
+		Multiplier: 2,
+	}
+	var delays []time.Duration
+	for retry := 1; retry <= 6; retry++ {
+		delays = append(delays, policy.Delay(retry))
+	}
+	g.Expect(delays).To(Equal([]time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second,
+	}))
+}
+
+func TestJitter(t *testing.T) {
+	g := NewWithT(t)
+
+	b := Policy{
+		Initial:    time.Second,
+		Multiplier: 1,
+		Jitter:     0.5,
+	}.Start()
+	distinct := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		delay, ok := b.Next()
+		g.Expect(ok).To(BeTrue())
+		g.Expect(delay).To(BeNumerically(">=", 500*time.Millisecond))
+		g.Expect(delay).To(BeNumerically("<=", time.Second))
+		distinct[delay] = true
+	}
+	g.Expect(len(distinct)).To(BeNumerically(">", 1))
+}
+
+func TestMaxAttempts(t *testing.T) {
+	g := NewWithT(t)
+
+	calls := 0
+	err := Retry(context.Background(), Policy{Initial: time.Millisecond, MaxAttempts: 3}, func(context.Context) error {
+		calls++
+		return errors.New("failed")
+	})
+	g.Expect(err).NotTo(BeNil())
+	g.Expect(calls).To(Equal(3))
+
+	calls = 0
+	err = Retry(context.Background(), Policy{Initial: time.Millisecond, MaxAttempts: 3}, func(context.Context) error {
+		calls++
+		if calls < 2 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	g.Expect(err).To(BeNil())
+	g.Expect(calls).To(Equal(2))
+}
+
+func TestDeadline(t *testing.T) {
+	g := NewWithT(t)
+
+	b := Policy{
+		Initial:  time.Hour,
+		Deadline: 50 * time.Millisecond,
+	}.Start()
+
+	// Delay is cut to the deadline
+	st := time.Now()
+	g.Expect(b.Wait(context.Background())).To(BeTrue())
+	g.Expect(time.Since(st)).To(BeNumerically("<", time.Second))
+	g.Expect(b.Attempts()).To(Equal(2))
+
+	g.Expect(b.Wait(context.Background())).To(BeFalse())
+}
+
+func TestContextCancel(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g.Expect(Policy{Initial: time.Hour}.Start().Wait(ctx)).To(BeFalse())
+}
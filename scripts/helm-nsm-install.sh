@@ -169,6 +169,7 @@ $HELM install $VERSION_SPECIFIC_OPTS \
   --set spire.enabled="$SPIRE_ENABLED",spire.org="$CONTAINER_REPO",spire.tag="$CONTAINER_TAG" \
   --set admission-webhook.org="$CONTAINER_REPO",admission-webhook.tag="$CONTAINER_TAG" \
   --set prefix-service.org="$CONTAINER_REPO",prefix-service.tag="$CONTAINER_TAG" \
+  --set ipam-service.org="$CONTAINER_REPO",ipam-service.tag="$CONTAINER_TAG" \
   --namespace "$NSM_NAMESPACE" \
   ./deployments/helm/"$CHART" &
 PID=$!
@@ -16,7 +16,11 @@
 package common
 
 import (
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
 
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
 )
@@ -31,6 +35,13 @@ const (
 	mechanismTypeEnv          = "MECHANISM_TYPE"
 	ipAddressEnv              = "IP_ADDRESS"
 	routesEnv                 = "ROUTES"
+	ipamStoreFileEnv          = "IPAM_STORE_FILE"
+	ipamServiceAddressEnv     = "IPAM_SERVICE_ADDRESS"
+	ipamHighWatermarkEnv      = "IPAM_HIGH_WATERMARK"
+	ipamRestoreTimeoutEnv     = "IPAM_RESTORE_TIMEOUT"
+	registerRetryDelayEnv     = "REGISTER_RETRY_DELAY"
+	registerRetryMaxDelayEnv  = "REGISTER_RETRY_MAX_DELAY"
+	registerTimeoutEnv        = "REGISTER_TIMEOUT"
 	podNameEnv                = "POD_NAME"
 )
 
@@ -47,6 +58,13 @@ type NSConfiguration struct {
 	MechanismType          string
 	IPAddress              string
 	Routes                 []string
+	IPAMStoreFile          string
+	IPAMServiceAddress     string
+	IPAMHighWatermark      float64
+	IPAMRestoreTimeout     time.Duration
+	RegisterRetryDelay     time.Duration
+	RegisterRetryMaxDelay  time.Duration
+	RegisterTimeout        time.Duration
 	PodName                string
 	Namespace              string
 }
@@ -102,6 +120,40 @@ func (configuration *NSConfiguration) FromEnv() *NSConfiguration {
 		configuration.IPAddress = getEnv(ipAddressEnv, "IP Address", false)
 	}
 
+	if configuration.IPAMStoreFile == "" {
+		configuration.IPAMStoreFile = getEnv(ipamStoreFileEnv, "IPAM store file", false)
+	}
+
+	if configuration.IPAMServiceAddress == "" {
+		configuration.IPAMServiceAddress = getEnv(ipamServiceAddressEnv, "IPAM service address", false)
+	}
+
+	if configuration.IPAMHighWatermark == 0 {
+		if raw := getEnv(ipamHighWatermarkEnv, "IPAM high watermark", false); raw != "" {
+			watermark, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				logrus.Fatalf("Invalid %v: %v", ipamHighWatermarkEnv, err)
+			}
+			configuration.IPAMHighWatermark = watermark
+		}
+	}
+
+	if configuration.IPAMRestoreTimeout == 0 {
+		configuration.IPAMRestoreTimeout = getDurationEnv(ipamRestoreTimeoutEnv, "IPAM restore timeout")
+	}
+
+	if configuration.RegisterRetryDelay == 0 {
+		configuration.RegisterRetryDelay = getDurationEnv(registerRetryDelayEnv, "Registration retry delay")
+	}
+
+	if configuration.RegisterRetryMaxDelay == 0 {
+		configuration.RegisterRetryMaxDelay = getDurationEnv(registerRetryMaxDelayEnv, "Registration retry max delay")
+	}
+
+	if configuration.RegisterTimeout == 0 {
+		configuration.RegisterTimeout = getDurationEnv(registerTimeoutEnv, "Registration timeout")
+	}
+
 	if configuration.PodName == "" {
 		configuration.PodName = getEnv(podNameEnv, "Pod name", false)
 	}
@@ -119,6 +171,19 @@ func (configuration *NSConfiguration) FromEnv() *NSConfiguration {
 	return configuration
 }
 
+// getDurationEnv returns duration of the env variable or 0 if it is not set, invalid value is fatal
+func getDurationEnv(env, description string) time.Duration {
+	raw := getEnv(env, description, false)
+	if raw == "" {
+		return 0
+	}
+	duration, err := time.ParseDuration(raw)
+	if err != nil {
+		logrus.Fatalf("Invalid %v: %v", env, err)
+	}
+	return duration
+}
+
 func (configuration *NSConfiguration) FromNSUrl(url *tools.NSUrl) *NSConfiguration {
 	var result NSConfiguration
 	if configuration != nil {
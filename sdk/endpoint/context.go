@@ -18,6 +18,7 @@ const (
 	monitorServerKey    contextKeyType = "MonitorServer"
 	nextKey             contextKeyType = "Next"
 	logKey              contextKeyType = "Log"
+	endpointNameKey     contextKeyType = "EndpointName"
 )
 
 // WithClientConnection -
@@ -111,3 +112,26 @@ func MonitorServer(ctx context.Context) connectionMonitor.MonitorServer {
 	}
 	return value.(connectionMonitor.MonitorServer)
 }
+
+// WithEndpointName -
+//   Wraps 'parent' in a new Context that has the name the endpoint is registered with
+//   using Context.Value(...) and returns the result.
+//   Note: any previously existing endpoint name will be overwritten.
+//
+func WithEndpointName(parent context.Context, name string) context.Context {
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithValue(parent, endpointNameKey, name)
+}
+
+// EndpointName -
+//    Returns the name the endpoint is registered with from:
+//      ctx context.Context
+//    If any is present, otherwise ""
+func EndpointName(ctx context.Context) string {
+	if rv, ok := ctx.Value(endpointNameKey).(string); ok {
+		return rv
+	}
+	return ""
+}
@@ -69,13 +69,16 @@ func (a addDnsConfigDstIp) Request(ctx context.Context, request *networkservice.
 			return nil, err
 		}
 	}
-	dstIp := conn.GetContext().GetIpContext().GetDstIpAddr()
-	if dstIp != "" {
+	// Dual stack connections have DNS server address per IP family
+	var dstIps []string
+	for _, dstIp := range conn.GetContext().GetIpContext().DstIPAddrs() {
+		dstIps = append(dstIps, strings.Split(dstIp, "/")[0])
+	}
+	if len(dstIps) != 0 {
 		ensureDnsContextPresent(request)
-		dstIp = strings.Split(dstIp, "/")[0]
 		dnsConfigs := conn.GetContext().GetDnsContext().GetConfigs()
 		dnsConfigs = append(dnsConfigs, &connectioncontext.DNSConfig{
-			DnsServerIps:  []string{dstIp},
+			DnsServerIps:  dstIps,
 			SearchDomains: a.searchDomains,
 		})
 		conn.GetContext().GetDnsContext().Configs = dnsConfigs
@@ -20,10 +20,13 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools/backoff"
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools/jaeger"
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools/spanhelper"
 
@@ -39,6 +42,34 @@ import (
 	"github.com/networkservicemesh/networkservicemesh/sdk/common"
 )
 
+// Default retries of NSE registration, registry could be unavailable while NSMgr is restarting
+const (
+	defaultRegisterRetryDelay    = 500 * time.Millisecond
+	defaultRegisterRetryMaxDelay = 15 * time.Second
+	defaultRegisterTimeout       = 2 * time.Minute
+)
+
+// registerBackoff returns retries of NSE registration, unset configuration values are defaulted
+func registerBackoff(configuration *common.NSConfiguration) backoff.Policy {
+	policy := backoff.Policy{
+		Initial:    configuration.RegisterRetryDelay,
+		Max:        configuration.RegisterRetryMaxDelay,
+		Multiplier: 2,
+		Jitter:     0.3,
+		Deadline:   configuration.RegisterTimeout,
+	}
+	if policy.Initial <= 0 {
+		policy.Initial = defaultRegisterRetryDelay
+	}
+	if policy.Max <= 0 {
+		policy.Max = defaultRegisterRetryMaxDelay
+	}
+	if policy.Deadline <= 0 {
+		policy.Deadline = defaultRegisterTimeout
+	}
+	return policy
+}
+
 // NsmEndpoint  provides the grpc mechanics for an NsmEndpoint
 type NsmEndpoint interface {
 	Start() error
@@ -61,7 +92,9 @@ type nsmEndpoint struct {
 	grpcServer     *grpc.Server
 	registryClient registry.NetworkServiceRegistryClient
 	registrations  []registration
-	tracerCloser   io.Closer
+	// registrationsMutex guards registered names, requests could come before the registration is finished
+	registrationsMutex sync.RWMutex
+	tracerCloser       io.Closer
 }
 
 type registration struct {
@@ -144,12 +177,21 @@ func (nsme *nsmEndpoint) register(r *registration) {
 	}
 	span.LogObject("nse-request", registration)
 
-	registeredNSE, err := nsme.registryClient.RegisterNSE(span.Context(), registration)
+	var registeredNSE *registry.NSERegistration
+	err := backoff.Retry(span.Context(), registerBackoff(nsme.Configuration), func(ctx context.Context) error {
+		var err error
+		if registeredNSE, err = nsme.registryClient.RegisterNSE(ctx, registration); err != nil {
+			span.Logger().Warnf("Failed to register endpoint, retrying: %v", err)
+		}
+		return err
+	})
 	if err != nil {
 		span.Logger().Fatalln("unable to register endpoint", err)
 	}
+	nsme.registrationsMutex.Lock()
 	r.registeredName = registeredNSE.GetNetworkServiceEndpoint().GetName()
-	span.LogObject("endpoint-name", r.registeredName)
+	nsme.registrationsMutex.Unlock()
+	span.LogObject("endpoint-name", registeredNSE.GetNetworkServiceEndpoint().GetName())
 	span.Logger().Infof("NSE registered: %v", registeredNSE)
 	span.Logger().Infof("NSE: channel has been successfully advertised, waiting for connection from NSM...")
 }
@@ -169,6 +211,19 @@ func (nsme *nsmEndpoint) unregister(r *registration) error {
 	return err
 }
 
+// registeredName returns the name the endpoint is registered with for the network service
+func (nsme *nsmEndpoint) registeredName(networkService string) string {
+	nsme.registrationsMutex.RLock()
+	defer nsme.registrationsMutex.RUnlock()
+
+	for i := range nsme.registrations {
+		if nsme.registrations[i].Name == networkService {
+			return nsme.registrations[i].registeredName
+		}
+	}
+	return ""
+}
+
 func (nsme *nsmEndpoint) Delete() error {
 	var result error
 	for i := range nsme.registrations {
@@ -191,6 +246,7 @@ func (nsme *nsmEndpoint) Request(ctx context.Context, request *networkservice.Ne
 	logger := span.Logger()
 	logger.Infof("Request for Network Service received %v", request)
 
+	ctx = WithEndpointName(ctx, nsme.registeredName(request.GetConnection().GetNetworkService()))
 	incomingConnection, err := nsme.service.Request(ctx, request)
 	if err != nil {
 		logger.Errorf("The composite returned an error: %v", err)
@@ -206,6 +262,7 @@ func (nsme *nsmEndpoint) Close(ctx context.Context, incomingConnection *connecti
 	span := spanhelper.FromContext(ctx, "Endpoint.Close")
 	defer span.Finish()
 	span.LogObject("connection", incomingConnection)
+	ctx = WithEndpointName(ctx, nsme.registeredName(incomingConnection.GetNetworkService()))
 	_, _ = nsme.service.Close(ctx, incomingConnection)
 	_, _ = nsme.NsClient.Close(ctx, incomingConnection)
 
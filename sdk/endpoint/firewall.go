@@ -0,0 +1,159 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/common"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/kernel"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+	"github.com/networkservicemesh/networkservicemesh/sdk/firewall"
+)
+
+// FirewallEndpoint -
+//   Programs nftables rules of the firewall configuration for the kernel interface of the incoming connection in the
+//   endpoint network namespace, the rules are removed on Close
+type FirewallEndpoint struct {
+	mtx    sync.RWMutex
+	config *firewall.Config
+	// applied - matches applied to the interfaces of the established connections
+	applied map[string][]*firewall.Match
+}
+
+// Request handler
+//   Consumes from ctx context.Context:
+//     ClientConnection
+//     Next
+func (f *FirewallEndpoint) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*connection.Connection, error) {
+	ifaceName, err := interfaceName(request.GetConnection())
+	if err != nil {
+		return nil, err
+	}
+
+	f.mtx.RLock()
+	matches, err := f.config.Bind(bindContexts(ctx, request.GetConnection())...)
+	previous, update := f.applied[ifaceName]
+	f.mtx.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if err = firewall.ApplyNftables(0, ifaceName, matches); err != nil {
+		Log(ctx).Errorf("Failed to apply firewall rules for %s: %v", ifaceName, err)
+		return nil, err
+	}
+
+	if Next(ctx) == nil {
+		f.setApplied(ifaceName, matches)
+		return request.GetConnection(), nil
+	}
+	conn, err := Next(ctx).Request(ctx, request)
+	if err != nil {
+		// Failed update keeps the connection, so it keeps the previous rules
+		if update {
+			if restoreErr := firewall.ApplyNftables(0, ifaceName, previous); restoreErr != nil {
+				Log(ctx).Errorf("Failed to restore firewall rules for %s: %v", ifaceName, restoreErr)
+			}
+			return nil, err
+		}
+		if removeErr := firewall.RemoveNftables(0, ifaceName); removeErr != nil {
+			Log(ctx).Errorf("Failed to remove firewall rules for %s: %v", ifaceName, removeErr)
+		}
+		return nil, err
+	}
+	f.setApplied(ifaceName, matches)
+	return conn, nil
+}
+
+// Close handler
+//   Consumes from ctx context.Context:
+//     Next
+func (f *FirewallEndpoint) Close(ctx context.Context, conn *connection.Connection) (*empty.Empty, error) {
+	if ifaceName, err := interfaceName(conn); err == nil {
+		f.mtx.Lock()
+		delete(f.applied, ifaceName)
+		f.mtx.Unlock()
+		if err = firewall.RemoveNftables(0, ifaceName); err != nil {
+			Log(ctx).Errorf("Failed to remove firewall rules for %s: %v", ifaceName, err)
+		}
+	}
+	if Next(ctx) != nil {
+		return Next(ctx).Close(ctx, conn)
+	}
+	return &empty.Empty{}, nil
+}
+
+// Name returns the composite name
+func (f *FirewallEndpoint) Name() string {
+	return "firewall"
+}
+
+// Update validates and replaces the firewall configuration, it is applied to the following requests
+func (f *FirewallEndpoint) Update(config *firewall.Config) error {
+	if err := config.Validate(); err != nil {
+		return errors.Wrap(err, "invalid firewall configuration")
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.config = config
+	return nil
+}
+
+// NewFirewallEndpoint creates a FirewallEndpoint, configuration is validated. It uses the same rules as the VPP
+// Agent ACL composite.
+func NewFirewallEndpoint(config *firewall.Config) (*FirewallEndpoint, error) {
+	f := &FirewallEndpoint{
+		applied: map[string][]*firewall.Match{},
+	}
+	if err := f.Update(config); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FirewallEndpoint) setApplied(ifaceName string, matches []*firewall.Match) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.applied[ifaceName] = matches
+}
+
+func interfaceName(conn *connection.Connection) (string, error) {
+	if conn.GetMechanism().GetType() != kernel.MECHANISM {
+		return "", errors.Errorf("firewall requires %s mechanism, got: %s", kernel.MECHANISM, conn.GetMechanism().GetType())
+	}
+	ifaceName := conn.GetMechanism().GetParameters()[common.InterfaceNameKey]
+	if ifaceName == "" {
+		return "", errors.New("found empty incoming connection interface name")
+	}
+	return ifaceName, nil
+}
+
+// bindContexts returns the connection addresses of each IP family, pass-through endpoints get them from the outgoing
+// connection
+func bindContexts(ctx context.Context, conn *connection.Connection) []*firewall.Context {
+	ipContext := conn.GetContext().GetIpContext()
+	if ipContext.GetSrcIpAddr() == "" && ipContext.GetDstIpAddr() == "" {
+		ipContext = ClientConnection(ctx).GetContext().GetIpContext()
+	}
+	return firewall.NewContexts(ipContext)
+}
@@ -17,7 +17,9 @@ package endpoint
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/golang/protobuf/ptypes/empty"
@@ -32,65 +34,128 @@ import (
 // IpamEndpoint - provides Ipam functionality
 type IpamEndpoint struct {
 	PrefixPool prefix_pool.PrefixPool
+	// ExtraPrefixPool - pool of the other IP family, a pair of addresses from each pool is assigned to the dual-stack
+	// connection, nil for single-stack endpoint
+	ExtraPrefixPool prefix_pool.PrefixPool
+	// HighWatermark - percentage of the used pool addresses, the connections allocated above it get a warning in
+	// IpamWarningKey of their ConnectionContext.ExtraContext, so it is seen in the connection monitor stream
+	HighWatermark float64
+
+	warnings ipamWarnings
 }
 
+const (
+	// IpamWarningKey is the ExtraContext key of the IPAM pool exhaustion warning
+	IpamWarningKey = "ipam.warning"
+	// DefaultIpamHighWatermark is the default IpamEndpoint.HighWatermark
+	DefaultIpamHighWatermark = 80
+	// DefaultIpamRestoreTimeout is the default time the connections restored from the IPAM store are kept for
+	DefaultIpamRestoreTimeout = 10 * time.Minute
+)
+
 // Request implements the request handler
 // Consumes from ctx context.Context:
 //	   Next
 func (ice *IpamEndpoint) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*connection.Connection, error) {
-	/* Exclude the prefixes from the pool of available prefixes */
-	excludedPrefixes, err := ice.PrefixPool.ExcludePrefixes(request.Connection.GetContext().GetIpContext().GetExcludedPrefixes())
+	warning, err := ice.allocate(ctx, request.GetConnection().GetId(), request.GetConnection().GetContext().GetIpContext())
 	if err != nil {
 		return nil, err
 	}
+	setIpamWarning(request.GetConnection(), warning)
+	ice.warnings.update(ctx, request.GetConnection().GetId(), warning)
 
-	/* Determine whether the pool is IPv4 or IPv6 */
-	currentIPFamily := connectioncontext.IpFamily_IPV4
-	if common.IsIPv6(ice.PrefixPool.GetPrefixes()[0]) {
-		currentIPFamily = connectioncontext.IpFamily_IPV6
+	conn := request.GetConnection()
+	if Next(ctx) != nil {
+		if conn, err = Next(ctx).Request(ctx, request); err != nil {
+			return nil, err
+		}
 	}
+	ice.warnings.add(conn)
+	return conn, nil
+}
 
-	srcIP, dstIP, prefixes, err := ice.PrefixPool.Extract(request.Connection.Id, currentIPFamily, request.Connection.GetContext().GetIpContext().GetExtraPrefixRequest()...)
-	if err != nil {
-		return nil, err
+// Close implements the close handler
+// Consumes from ctx context.Context:
+//	   Next
+func (ice *IpamEndpoint) Close(ctx context.Context, connection *connection.Connection) (*empty.Empty, error) {
+	ice.Release(ctx, connection.GetId())
+	ice.warnings.remove(connection.GetId())
+	ice.warnings.update(ctx, connection.GetId(), ice.HighWatermarkWarning())
+	if Next(ctx) != nil {
+		return Next(ctx).Close(ctx, connection)
 	}
+	return &empty.Empty{}, nil
+}
+
+// Allocate assigns the connection addresses and the extra prefixes requested in ipContext
+func (ice *IpamEndpoint) Allocate(ctx context.Context, connectionID string, ipContext *connectioncontext.IPContext) error {
+	_, err := ice.allocate(ctx, connectionID, ipContext)
+	return err
+}
 
-	/* Release the actual prefixes that were excluded during IPAM */
-	// TODO - this will vary per Request... so releasing Prefixes globally that are excluded locally
-	// is incorrect behavior.  It should simply *not* draw on those prefixes for this connection.
-	err = ice.PrefixPool.ReleaseExcludedPrefixes(excludedPrefixes)
+/* Allocate and return a warning if the pools are used above the high watermark */
+func (ice *IpamEndpoint) allocate(ctx context.Context, connectionID string, ipContext *connectioncontext.IPContext) (string, error) {
+	extraPrefixRequests := ipContext.GetExtraPrefixRequest()
+
+	/* Excluded prefixes are not drawn on for this connection only, the pool shared by the connections is not changed */
+	poolFamily := prefixPoolFamily(ice.PrefixPool)
+	srcIP, dstIP, prefixes, err := ice.PrefixPool.Extract(connectionID, poolFamily,
+		ipContext.GetExcludedPrefixes(), prefixRequestsOf(poolFamily, extraPrefixRequests, ice.ExtraPrefixPool == nil)...)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+
+	var extraSrcIPs, extraDstIPs []string
+	if ice.ExtraPrefixPool != nil {
+		extraFamily := prefixPoolFamily(ice.ExtraPrefixPool)
+		extraSrcIP, extraDstIP, extraPrefixes, err := ice.ExtraPrefixPool.Extract(connectionID, extraFamily,
+			ipContext.GetExcludedPrefixes(), prefixRequestsOf(extraFamily, extraPrefixRequests, false)...)
+		if err != nil {
+			if releaseErr := ice.PrefixPool.Release(connectionID); releaseErr != nil {
+				Log(ctx).Error("Release error: ", releaseErr)
+			}
+			return "", err
+		}
+		extraSrcIPs, extraDstIPs = []string{extraSrcIP.String()}, []string{extraDstIP.String()}
+		prefixes = append(prefixes, extraPrefixes...)
 	}
 
 	// Update source/dst IP's
-	request.GetConnection().GetContext().GetIpContext().SrcIpAddr = srcIP.String()
-	request.GetConnection().GetContext().GetIpContext().DstIpAddr = dstIP.String()
+	ipContext.SrcIpAddr = srcIP.String()
+	ipContext.DstIpAddr = dstIP.String()
+	ipContext.ExtraSrcIpAddrs = extraSrcIPs
+	ipContext.ExtraDstIpAddrs = extraDstIPs
 
-	request.GetConnection().GetContext().GetIpContext().ExtraPrefixes = prefixes
-	if Next(ctx) != nil {
-		return Next(ctx).Request(ctx, request)
+	ipContext.ExtraPrefixes = prefixes
+
+	warning := ice.HighWatermarkWarning()
+	if warning != "" {
+		Log(ctx).Warn(warning)
 	}
-	return request.GetConnection(), nil
+	return warning, nil
 }
 
-// Close implements the close handler
-// Consumes from ctx context.Context:
-//	   Next
-func (ice *IpamEndpoint) Close(ctx context.Context, connection *connection.Connection) (*empty.Empty, error) {
-	prefix, requests, err := ice.PrefixPool.GetConnectionInformation(connection.GetId())
-	Log(ctx).Infof("Release connection prefixes network: %s extra requests: %v", prefix, requests)
-	if err != nil {
-		Log(ctx).Errorf("Error: %v", err)
+// HighWatermarkWarning checks usage of the pools, the first one used above the high watermark is reported
+func (ice *IpamEndpoint) HighWatermarkWarning() string {
+	for _, pool := range []prefix_pool.PrefixPool{ice.PrefixPool, ice.ExtraPrefixPool} {
+		if pool == nil {
+			continue
+		}
+		usage := pool.Usage()
+		if utilization := 100 * usage.Utilization(); utilization >= ice.HighWatermark {
+			return fmt.Sprintf("IPAM pool %s is %.0f%% used: %.0f addresses and %d prefixes are free",
+				strings.Join(usage.Prefixes, ","), utilization, usage.FreeAddresses, usage.FreePrefixes)
+		}
 	}
-	err = ice.PrefixPool.Release(connection.GetId())
-	if err != nil {
-		Log(ctx).Error("Release error: ", err)
-	}
-	if Next(ctx) != nil {
-		return Next(ctx).Close(ctx, connection)
+	return ""
+}
+
+// Release releases the connection addresses and prefixes
+func (ice *IpamEndpoint) Release(ctx context.Context, connectionID string) {
+	release(ctx, ice.PrefixPool, connectionID)
+	if ice.ExtraPrefixPool != nil {
+		release(ctx, ice.ExtraPrefixPool, connectionID)
 	}
-	return &empty.Empty{}, nil
 }
 
 // Name returns the composite name
@@ -98,14 +163,32 @@ func (ice *IpamEndpoint) Name() string {
 	return "ipam"
 }
 
-// NewIpamEndpoint creates a IpamEndpoint
+// NewIpamEndpoint creates a IpamEndpoint, configuration.IPAddress can have comma separated IPv4 and IPv6 prefixes for
+// dual-stack connections, prefixes of the first IP family are assigned to SrcIpAddr and DstIpAddr.
+// Connections are kept in configuration.IPAMStoreFile if it is set, so they get the same addresses after restart,
+// restored connections not requested again within configuration.IPAMRestoreTimeout are released
 func NewIpamEndpoint(configuration *common.NSConfiguration) *IpamEndpoint {
+	var store prefix_pool.Store
+	if configuration != nil && configuration.IPAMStoreFile != "" {
+		store = prefix_pool.NewFileStore(configuration.IPAMStoreFile)
+	}
+	return NewIpamEndpointWithStore(configuration, store)
+}
+
+// NewIpamEndpointWithStore creates a IpamEndpoint keeping its connections in the store, store can be nil
+func NewIpamEndpointWithStore(configuration *common.NSConfiguration, store prefix_pool.Store) *IpamEndpoint {
 	// ensure the env variables are processed
 	if configuration == nil {
 		configuration = &common.NSConfiguration{}
 	}
 
-	pool, err := prefix_pool.NewPrefixPool(configuration.IPAddress)
+	restoreTimeout := configuration.IPAMRestoreTimeout
+	if restoreTimeout == 0 {
+		restoreTimeout = DefaultIpamRestoreTimeout
+	}
+
+	prefixes, extraPrefixes := splitPrefixesByFamily(configuration.IPAddress)
+	pool, err := newPrefixPool(store, restoreTimeout, prefixes...)
 	if err != nil {
 		panic(err.Error())
 	}
@@ -113,8 +196,80 @@ func NewIpamEndpoint(configuration *common.NSConfiguration) *IpamEndpoint {
 	rand.Seed(time.Now().UTC().UnixNano())
 
 	self := &IpamEndpoint{
-		PrefixPool: pool,
+		PrefixPool:    pool,
+		HighWatermark: configuration.IPAMHighWatermark,
+	}
+	if self.HighWatermark == 0 {
+		self.HighWatermark = DefaultIpamHighWatermark
+	}
+	getIpamCollector().add(configuration.EndpointNetworkService, prefixes, pool)
+
+	if len(extraPrefixes) != 0 {
+		self.ExtraPrefixPool, err = newPrefixPool(store, restoreTimeout, extraPrefixes...)
+		if err != nil {
+			panic(err.Error())
+		}
+		getIpamCollector().add(configuration.EndpointNetworkService, extraPrefixes, self.ExtraPrefixPool)
 	}
 
 	return self
 }
+
+func newPrefixPool(store prefix_pool.Store, restoreTimeout time.Duration, prefixes ...string) (prefix_pool.PrefixPool, error) {
+	if store == nil {
+		return prefix_pool.NewPrefixPool(prefixes...)
+	}
+	return prefix_pool.NewPersistentPrefixPool(store, restoreTimeout, prefixes...)
+}
+
+func release(ctx context.Context, pool prefix_pool.PrefixPool, connectionID string) {
+	prefix, requests, err := pool.GetConnectionInformation(connectionID)
+	Log(ctx).Infof("Release connection prefixes network: %s extra requests: %v", prefix, requests)
+	if err != nil {
+		Log(ctx).Errorf("Error: %v", err)
+	}
+	err = pool.Release(connectionID)
+	if err != nil {
+		Log(ctx).Error("Release error: ", err)
+	}
+}
+
+/* Determine whether the pool is IPv4 or IPv6 */
+func prefixPoolFamily(pool prefix_pool.PrefixPool) connectioncontext.IpFamily_Family {
+	if prefixes := pool.GetPrefixes(); len(prefixes) > 0 && common.IsIPv6(prefixes[0]) {
+		return connectioncontext.IpFamily_IPV6
+	}
+	return connectioncontext.IpFamily_IPV4
+}
+
+// prefixRequestsOf returns extra prefix requests of the family, singleStack pool serves the requests of all families
+func prefixRequestsOf(family connectioncontext.IpFamily_Family, requests []*connectioncontext.ExtraPrefixRequest,
+	singleStack bool) []*connectioncontext.ExtraPrefixRequest {
+	if singleStack {
+		return requests
+	}
+	var rv []*connectioncontext.ExtraPrefixRequest
+	for _, request := range requests {
+		if request.GetAddrFamily().GetFamily() == family {
+			rv = append(rv, request)
+		}
+	}
+	return rv
+}
+
+// splitPrefixesByFamily splits comma separated prefixes into the ones of the first IP family and the other one
+func splitPrefixesByFamily(ipAddress string) (prefixes, extraPrefixes []string) {
+	firstIsIPv6 := false
+	for i, prefix := range strings.Split(ipAddress, ",") {
+		prefix = strings.TrimSpace(prefix)
+		if i == 0 {
+			firstIsIPv6 = common.IsIPv6(prefix)
+		}
+		if common.IsIPv6(prefix) == firstIsIPv6 {
+			prefixes = append(prefixes, prefix)
+		} else {
+			extraPrefixes = append(extraPrefixes, prefix)
+		}
+	}
+	return prefixes, extraPrefixes
+}
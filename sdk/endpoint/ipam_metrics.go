@@ -0,0 +1,113 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/networkservicemesh/sdk/prefix_pool"
+)
+
+// Names of the IPAM metrics
+const (
+	IpamUsedAddressesMetric = "nsm_ipam_used_addresses"
+	IpamFreeAddressesMetric = "nsm_ipam_free_addresses"
+	IpamUsedPrefixesMetric  = "nsm_ipam_used_prefixes"
+	IpamFreePrefixesMetric  = "nsm_ipam_free_prefixes"
+)
+
+// Labels of the IPAM metrics
+const (
+	// IpamNetworkServiceKey is a label for the Network Service of the endpoint
+	IpamNetworkServiceKey = "network_service"
+	// IpamPoolKey is a label for the prefixes of the pool
+	IpamPoolKey = "pool"
+)
+
+type ipamPoolKey struct {
+	networkService string
+	pool           string
+}
+
+// ipamCollector is a Prometheus collector exposing usage of the IPAM prefix pools
+type ipamCollector struct {
+	mtx   sync.Mutex
+	pools map[ipamPoolKey]prefix_pool.PrefixPool
+
+	usedAddresses *prometheus.Desc
+	freeAddresses *prometheus.Desc
+	usedPrefixes  *prometheus.Desc
+	freePrefixes  *prometheus.Desc
+}
+
+var (
+	ipamCollectorOnce     sync.Once
+	ipamCollectorInstance *ipamCollector
+)
+
+// getIpamCollector returns IPAM collector registered in the default Prometheus registry, the same one the
+// controlplane metrics are registered in
+func getIpamCollector() *ipamCollector {
+	ipamCollectorOnce.Do(func() {
+		newDesc := func(name, help string) *prometheus.Desc {
+			return prometheus.NewDesc(name, help, []string{IpamNetworkServiceKey, IpamPoolKey}, nil)
+		}
+		ipamCollectorInstance = &ipamCollector{
+			pools:         map[ipamPoolKey]prefix_pool.PrefixPool{},
+			usedAddresses: newDesc(IpamUsedAddressesMetric, "Addresses of the IPAM pool allocated to the connections"),
+			freeAddresses: newDesc(IpamFreeAddressesMetric, "Addresses of the IPAM pool available for the new connections"),
+			usedPrefixes:  newDesc(IpamUsedPrefixesMetric, "Prefixes of the IPAM pool allocated to the connections"),
+			freePrefixes:  newDesc(IpamFreePrefixesMetric, "Prefixes of the IPAM pool available for the new connections"),
+		}
+		if err := prometheus.Register(ipamCollectorInstance); err != nil {
+			logrus.Infof("failed to register IPAM collector, err: %v", err)
+		}
+	})
+	return ipamCollectorInstance
+}
+
+// add starts exposing usage of the pool, pool with the same labels is replaced
+func (c *ipamCollector) add(networkService string, prefixes []string, pool prefix_pool.PrefixPool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.pools[ipamPoolKey{networkService: networkService, pool: strings.Join(prefixes, ",")}] = pool
+}
+
+// Describe implements prometheus.Collector
+func (c *ipamCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.usedAddresses
+	ch <- c.freeAddresses
+	ch <- c.usedPrefixes
+	ch <- c.freePrefixes
+}
+
+// Collect implements prometheus.Collector
+func (c *ipamCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for key, pool := range c.pools {
+		usage := pool.Usage()
+		ch <- prometheus.MustNewConstMetric(c.usedAddresses, prometheus.GaugeValue, usage.UsedAddresses, key.networkService, key.pool)
+		ch <- prometheus.MustNewConstMetric(c.freeAddresses, prometheus.GaugeValue, usage.FreeAddresses, key.networkService, key.pool)
+		ch <- prometheus.MustNewConstMetric(c.usedPrefixes, prometheus.GaugeValue, float64(usage.UsedPrefixes), key.networkService, key.pool)
+		ch <- prometheus.MustNewConstMetric(c.freePrefixes, prometheus.GaugeValue, float64(usage.FreePrefixes), key.networkService, key.pool)
+	}
+}
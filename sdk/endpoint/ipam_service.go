@@ -0,0 +1,141 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/ipam"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
+	"github.com/networkservicemesh/networkservicemesh/sdk/common"
+)
+
+// IpamServiceEndpoint -
+//   Provides Ipam functionality with the addresses allocated by the IPAM service, so all the endpoints of the Network
+//   Service share its address space.
+type IpamServiceEndpoint struct {
+	client   ipam.IPAMClient
+	warnings ipamWarnings
+}
+
+// Request implements the request handler
+// Consumes from ctx context.Context:
+//	   Next
+//	   EndpointName
+func (ise *IpamServiceEndpoint) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*connection.Connection, error) {
+	conn := request.GetConnection()
+	if conn.GetContext() == nil {
+		conn.Context = &connectioncontext.ConnectionContext{}
+	}
+	var header metadata.MD
+	ipContext, err := ise.client.Allocate(ctx, ipamRequest(ctx, conn), grpc.Header(&header))
+	if err != nil {
+		Log(ctx).Errorf("IPAM service failed to allocate addresses: %v", err)
+		return nil, err
+	}
+	conn.GetContext().IpContext = ipContext
+	warning := ipamWarning(header)
+	if warning != "" {
+		Log(ctx).Warn(warning)
+	}
+	setIpamWarning(conn, warning)
+	ise.warnings.update(ctx, conn.GetId(), warning)
+
+	if Next(ctx) != nil {
+		if conn, err = Next(ctx).Request(ctx, request); err != nil {
+			return nil, err
+		}
+	}
+	ise.warnings.add(conn)
+	return conn, nil
+}
+
+// Close implements the close handler
+// Consumes from ctx context.Context:
+//	   Next
+//	   EndpointName
+func (ise *IpamServiceEndpoint) Close(ctx context.Context, connection *connection.Connection) (*empty.Empty, error) {
+	ise.warnings.remove(connection.GetId())
+	var header metadata.MD
+	if _, err := ise.client.Release(ctx, ipamRequest(ctx, connection), grpc.Header(&header)); err != nil {
+		Log(ctx).Error("Release error: ", err)
+	} else {
+		ise.warnings.update(ctx, connection.GetId(), ipamWarning(header))
+	}
+	if Next(ctx) != nil {
+		return Next(ctx).Close(ctx, connection)
+	}
+	return &empty.Empty{}, nil
+}
+
+// Name returns the composite name
+func (ise *IpamServiceEndpoint) Name() string {
+	return "ipam-service"
+}
+
+// NewIpamServiceEndpoint creates a IpamServiceEndpoint connected to the IPAM service on
+// configuration.IPAMServiceAddress
+func NewIpamServiceEndpoint(configuration *common.NSConfiguration) *IpamServiceEndpoint {
+	// ensure the env variables are processed
+	if configuration == nil {
+		configuration = &common.NSConfiguration{}
+	}
+
+	conn, err := tools.DialTCP(configuration.IPAMServiceAddress)
+	if err != nil {
+		panic(err.Error())
+	}
+	return NewIpamServiceEndpointWithClient(ipam.NewIPAMClient(conn))
+}
+
+// NewIpamServiceEndpointWithClient creates a IpamServiceEndpoint using the IPAM service client
+func NewIpamServiceEndpointWithClient(client ipam.IPAMClient) *IpamServiceEndpoint {
+	return &IpamServiceEndpoint{
+		client: client,
+	}
+}
+
+/* Connection ids are generated by NSMgr and are unique within it only, so they are prefixed with the name
+   the endpoint is registered with and the name of NSMgr requesting the connection */
+func ipamRequest(ctx context.Context, conn *connection.Connection) *ipam.IPAMRequest {
+	return &ipam.IPAMRequest{
+		NetworkService: conn.GetNetworkService(),
+		ConnectionId:   EndpointName(ctx) + "/" + requestingManagerName(conn) + "/" + conn.GetId(),
+		IpContext:      conn.GetContext().GetIpContext(),
+	}
+}
+
+func requestingManagerName(conn *connection.Connection) string {
+	path := conn.GetPath()
+	if int(path.GetIndex()) >= len(path.GetPathSegments()) {
+		return ""
+	}
+	return path.GetPathSegments()[path.GetIndex()].GetName()
+}
+
+// ipamWarning returns the pool exhaustion warning sent by IPAM service in the response header
+func ipamWarning(header metadata.MD) string {
+	if values := header.Get(IpamWarningHeader); len(values) != 0 {
+		return values[0]
+	}
+	return ""
+}
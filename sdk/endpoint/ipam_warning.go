@@ -0,0 +1,89 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+	"sync"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+)
+
+// IpamWarningHeader is the gRPC header IPAM service returns the pool exhaustion warning in
+const IpamWarningHeader = "nsm-ipam-warning"
+
+// ipamWarnings keeps the IPAM warning of the endpoint connections up to date in the connection monitor stream. New
+// connections get the current warning, the other ones are updated once the warning appears or disappears.
+type ipamWarnings struct {
+	mtx         sync.Mutex
+	warning     string
+	connections map[string]*connection.Connection
+}
+
+// update sets the current warning, connections other than connectionID are sent to the monitor if it changes
+func (w *ipamWarnings) update(ctx context.Context, connectionID, warning string) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	changed := (w.warning == "") != (warning == "")
+	w.warning = warning
+	monitor := MonitorServer(ctx)
+	if !changed || monitor == nil {
+		return
+	}
+	for id, conn := range w.connections {
+		if id == connectionID {
+			continue
+		}
+		setIpamWarning(conn, warning)
+		monitor.Update(ctx, conn.Clone())
+	}
+}
+
+// add starts tracking the connection
+func (w *ipamWarnings) add(conn *connection.Connection) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if w.connections == nil {
+		w.connections = map[string]*connection.Connection{}
+	}
+	w.connections[conn.GetId()] = conn.Clone()
+}
+
+// remove stops tracking the connection
+func (w *ipamWarnings) remove(connectionID string) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	delete(w.connections, connectionID)
+}
+
+// setIpamWarning puts the warning into IpamWarningKey of the connection ExtraContext, empty warning is removed
+func setIpamWarning(conn *connection.Connection, warning string) {
+	if conn.GetContext() == nil {
+		conn.Context = &connectioncontext.ConnectionContext{}
+	}
+	connectionContext := conn.GetContext()
+	if warning == "" {
+		delete(connectionContext.ExtraContext, IpamWarningKey)
+		return
+	}
+	if connectionContext.ExtraContext == nil {
+		connectionContext.ExtraContext = map[string]string{}
+	}
+	connectionContext.ExtraContext[IpamWarningKey] = warning
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+)
+
+// QoSEndpoint -
+//   Sets QoS of the connection applied by the forwarders. Clients can request QoS with the connection labels, the
+//   requested rate and burst are limited by the endpoint ones and the endpoint DSCP marking takes precedence.
+type QoSEndpoint struct {
+	qos *connectioncontext.QoS
+}
+
+// Request handler
+//   Consumes from ctx context.Context:
+//     Next
+func (q *QoSEndpoint) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*connection.Connection, error) {
+	requested, err := connectioncontext.ParseQoS(request.GetConnection().GetLabels())
+	if err != nil {
+		Log(ctx).Errorf("Requested QoS is not valid: %v", err)
+		return nil, err
+	}
+
+	if request.GetConnection().GetContext() == nil {
+		request.GetConnection().Context = &connectioncontext.ConnectionContext{}
+	}
+	request.GetConnection().GetContext().SetQoS(limitQoS(requested, q.qos))
+
+	if Next(ctx) != nil {
+		return Next(ctx).Request(ctx, request)
+	}
+	return request.GetConnection(), nil
+}
+
+// Close handler
+//   Consumes from ctx context.Context:
+//     Next
+func (q *QoSEndpoint) Close(ctx context.Context, conn *connection.Connection) (*empty.Empty, error) {
+	if Next(ctx) != nil {
+		return Next(ctx).Close(ctx, conn)
+	}
+	return &empty.Empty{}, nil
+}
+
+// Name returns the composite name
+func (q *QoSEndpoint) Name() string {
+	return "qos"
+}
+
+// NewQoSEndpoint creates a QoSEndpoint, nil QoS means connections are shaped only on the client request
+func NewQoSEndpoint(qos *connectioncontext.QoS) *QoSEndpoint {
+	return &QoSEndpoint{
+		qos: qos,
+	}
+}
+
+func limitQoS(requested, limit *connectioncontext.QoS) *connectioncontext.QoS {
+	if requested == nil {
+		return limit
+	}
+	if limit == nil {
+		return requested
+	}
+	result := &connectioncontext.QoS{
+		Rate:  minLimit(requested.Rate, limit.Rate),
+		Burst: minLimit(requested.Burst, limit.Burst),
+		DSCP:  limit.DSCP,
+	}
+	if result.DSCP == nil {
+		result.DSCP = requested.DSCP
+	}
+	return result
+}
+
+// minLimit returns the smaller limit, zero means unlimited
+func minLimit(a, b uint64) uint64 {
+	if a == 0 || b != 0 && b < a {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firewall
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
+)
+
+// Keys of the key-value rules, f.e. "action=reflect,tcplowport=80,tcpupport=80"
+const (
+	actionKey     = "action"
+	dstNetKey     = "dstnet"
+	srcNetKey     = "srcnet"
+	icmpTypeKey   = "icmptype"
+	tcpLowPortKey = "tcplowport"
+	tcpUpPortKey  = "tcpupport"
+	udpLowPortKey = "udplowport"
+	udpUpPortKey  = "udpupport"
+)
+
+// FromKeyValueRules converts named key-value rules to the configuration, rules are ordered by names
+func FromKeyValueRules(kvRules map[string]string) (*Config, error) {
+	names := make([]string, 0, len(kvRules))
+	for name := range kvRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	config := &Config{}
+	for _, name := range names {
+		rules, err := fromKeyValueRule(name, tools.ParseKVStringToMap(kvRules[name], ",", "="))
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing rule %s failed", kvRules[name])
+		}
+		config.Rules = append(config.Rules, rules...)
+	}
+	return config, config.Validate()
+}
+
+func fromKeyValueRule(name string, parsed map[string]string) ([]*Rule, error) {
+	action, ok := parsed[actionKey]
+	if !ok {
+		return nil, errors.New("rule should have 'action' set")
+	}
+	base := Rule{
+		Name:        name,
+		Action:      action,
+		Source:      parsed[srcNetKey],
+		Destination: parsed[dstNetKey],
+	}
+
+	var rules []*Rule
+	if icmpType, ok := parsed[icmpTypeKey]; ok {
+		icmpType8, err := strconv.ParseUint(icmpType, 10, 8)
+		if err != nil {
+			return nil, errors.Errorf("failed parsing icmptype [%v] with: %v", icmpType, err)
+		}
+		rule := base
+		rule.Protocols = []string{ProtocolICMP}
+		rule.ICMPType = new(uint8)
+		*rule.ICMPType = uint8(icmpType8)
+		rules = append(rules, &rule)
+	}
+	for _, protocol := range []struct {
+		name, low, up string
+	}{{ProtocolTCP, tcpLowPortKey, tcpUpPortKey}, {ProtocolUDP, udpLowPortKey, udpUpPortKey}} {
+		low, lowOk := parsed[protocol.low]
+		up, upOk := parsed[protocol.up]
+		if !lowOk || !upOk {
+			continue
+		}
+		rule := base
+		rule.Protocols = []string{protocol.name}
+		rule.DestinationPorts = []string{strings.Join([]string{low, up}, "-")}
+		rules = append(rules, &rule)
+	}
+	if len(rules) == 0 {
+		rules = append(rules, &base)
+	}
+	return rules, nil
+}
@@ -0,0 +1,345 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firewall
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// NftablesTable - name of the inet table with the connections rules
+	NftablesTable = "nsm"
+
+	preroutingChain  = "prerouting"
+	postroutingChain = "postrouting"
+	ingressSuffix    = "-ingress"
+	egressSuffix     = "-egress"
+
+	// Conntrack state bits of the established connections and the related to them packets
+	ctStateEstablished = 1 << 1
+	ctStateRelated     = 1 << 2
+
+	// ICMPv6 neighbor discovery message types range
+	ndRouterSolicitation    = 133
+	ndNeighborAdvertisement = 136
+)
+
+// ApplyNftables replaces nftables rules of the connection interface in the network namespace, netNS is a file
+// descriptor of the namespace or 0 for the current one. Ingress matches are applied to the packets received from the
+// interface and egress matches to the packets sent to it, unmatched packets are dropped in the direction having
+// rules, except for ICMPv6 neighbor discovery. Interface does not need to exist yet.
+func ApplyNftables(netNS int, ifaceName string, matches []*Match) error {
+	if err := RemoveNftables(netNS, ifaceName); err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	var ingress, egress []*Match
+	for _, match := range matches {
+		if match.Direction == DirectionEgress {
+			egress = append(egress, match)
+		} else {
+			ingress = append(ingress, match)
+		}
+	}
+
+	conn := &nftables.Conn{NetNS: netNS}
+	table, prerouting, postrouting := addBaseChains(conn)
+	addConnectionChain(conn, table, prerouting, expr.MetaKeyIIFNAME, ifaceName, ifaceName+ingressSuffix, ingress, egress)
+	addConnectionChain(conn, table, postrouting, expr.MetaKeyOIFNAME, ifaceName, ifaceName+egressSuffix, egress, ingress)
+
+	if err := conn.Flush(); err != nil {
+		return errors.Wrapf(err, "failed to apply nftables rules for %s", ifaceName)
+	}
+	return nil
+}
+
+// RemoveNftables removes nftables rules of the connection interface from the network namespace
+func RemoveNftables(netNS int, ifaceName string) error {
+	conn := &nftables.Conn{NetNS: netNS}
+	chains, err := listChains(conn)
+	if err != nil {
+		return err
+	}
+
+	names := map[string]bool{ifaceName + ingressSuffix: true, ifaceName + egressSuffix: true}
+	var removed []*nftables.Chain
+	for _, chain := range chains {
+		if chain.Name != preroutingChain && chain.Name != postroutingChain {
+			if names[chain.Name] {
+				removed = append(removed, chain)
+			}
+			continue
+		}
+		// Jumps to the connection chains should be removed first
+		rules, err := conn.GetRule(chain.Table, chain)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list nftables rules of %s chain", chain.Name)
+		}
+		for _, rule := range rules {
+			if names[jumpTarget(rule)] {
+				if err := conn.DelRule(rule); err != nil {
+					return errors.Wrapf(err, "failed to delete nftables rule of %s chain", chain.Name)
+				}
+			}
+		}
+	}
+	for _, chain := range removed {
+		conn.FlushChain(chain)
+		conn.DelChain(chain)
+	}
+
+	if err := conn.Flush(); err != nil {
+		return errors.Wrapf(err, "failed to remove nftables rules for %s", ifaceName)
+	}
+	return nil
+}
+
+// listChains returns chains of the NSM table. Chains are listed for all the families and their table may come
+// without the family, so it is replaced with the inet one.
+func listChains(conn *nftables.Conn) ([]*nftables.Chain, error) {
+	chains, err := conn.ListChains()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list nftables chains")
+	}
+	table := &nftables.Table{
+		Family: nftables.TableFamilyINet,
+		Name:   NftablesTable,
+	}
+	var result []*nftables.Chain
+	for _, chain := range chains {
+		if chain.Table == nil || chain.Table.Name != table.Name {
+			continue
+		}
+		if chain.Table.Family != nftables.TableFamily(unix.NFPROTO_UNSPEC) && chain.Table.Family != table.Family {
+			continue
+		}
+		chain.Table = table
+		result = append(result, chain)
+	}
+	return result, nil
+}
+
+func addBaseChains(conn *nftables.Conn) (table *nftables.Table, prerouting, postrouting *nftables.Chain) {
+	table = conn.AddTable(&nftables.Table{
+		Family: nftables.TableFamilyINet,
+		Name:   NftablesTable,
+	})
+	prerouting = conn.AddChain(&nftables.Chain{
+		Name:     preroutingChain,
+		Table:    table,
+		Type:     nftables.ChainTypeFilter,
+		Hooknum:  nftables.ChainHookPrerouting,
+		Priority: nftables.ChainPriorityFilter,
+	})
+	postrouting = conn.AddChain(&nftables.Chain{
+		Name:     postroutingChain,
+		Table:    table,
+		Type:     nftables.ChainTypeFilter,
+		Hooknum:  nftables.ChainHookPostrouting,
+		Priority: nftables.ChainPriorityFilter,
+	})
+	return table, prerouting, postrouting
+}
+
+// addConnectionChain adds the chain with the matches and the jump to it from the base chain for the packets of the
+// interface. Replies to the packets reflected in the opposite direction and ICMPv6 neighbor discovery are accepted
+// before the matches.
+func addConnectionChain(conn *nftables.Conn, table *nftables.Table, base *nftables.Chain, ifaceKey expr.MetaKey, ifaceName, name string, matches, opposite []*Match) {
+	reflected := false
+	for _, match := range opposite {
+		reflected = reflected || match.Action == ActionReflect
+	}
+	if len(matches) == 0 && !reflected {
+		return
+	}
+
+	chain := conn.AddChain(&nftables.Chain{
+		Name:  name,
+		Table: table,
+	})
+	conn.AddRule(&nftables.Rule{
+		Table: table,
+		Chain: base,
+		Exprs: []expr.Any{
+			&expr.Meta{Key: ifaceKey, Register: 1},
+			&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: ifname(ifaceName)},
+			&expr.Verdict{Kind: expr.VerdictJump, Chain: name},
+		},
+	})
+
+	if reflected {
+		conn.AddRule(&nftables.Rule{
+			Table: table,
+			Chain: chain,
+			Exprs: []expr.Any{
+				&expr.Ct{Register: 1, Key: expr.CtKeySTATE},
+				&expr.Bitwise{
+					SourceRegister: 1,
+					DestRegister:   1,
+					Len:            4,
+					Mask:           binaryutil.NativeEndian.PutUint32(ctStateEstablished | ctStateRelated),
+					Xor:            make([]byte, 4),
+				},
+				&expr.Cmp{Op: expr.CmpOpNeq, Register: 1, Data: make([]byte, 4)},
+				&expr.Verdict{Kind: expr.VerdictAccept},
+			},
+		})
+	}
+	if len(matches) != 0 {
+		conn.AddRule(&nftables.Rule{
+			Table: table,
+			Chain: chain,
+			Exprs: neighborDiscoveryExprs(),
+		})
+	}
+	for _, match := range matches {
+		conn.AddRule(&nftables.Rule{
+			Table: table,
+			Chain: chain,
+			Exprs: matchExprs(match),
+		})
+	}
+	if len(matches) != 0 {
+		conn.AddRule(&nftables.Rule{
+			Table: table,
+			Chain: chain,
+			Exprs: []expr.Any{&expr.Verdict{Kind: expr.VerdictDrop}},
+		})
+	}
+}
+
+func matchExprs(match *Match) []expr.Any {
+	var exprs []expr.Any
+	if match.Source != nil {
+		exprs = append(exprs, networkExprs(match.Source, true)...)
+	}
+	if match.Destination != nil {
+		exprs = append(exprs, networkExprs(match.Destination, false)...)
+	}
+
+	switch match.Protocol {
+	case ProtocolICMP, ProtocolICMPv6:
+		exprs = append(exprs, l4ProtoExprs(match.Protocol)...)
+		if match.ICMPType != nil {
+			exprs = append(exprs,
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: 0, Len: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{*match.ICMPType}},
+			)
+		}
+	case ProtocolTCP, ProtocolUDP:
+		exprs = append(exprs, l4ProtoExprs(match.Protocol)...)
+		exprs = append(exprs, portExprs(match.SourcePorts, 0)...)
+		exprs = append(exprs, portExprs(match.DestinationPorts, 2)...)
+	}
+
+	verdict := expr.VerdictAccept
+	if match.Action == ActionDeny {
+		verdict = expr.VerdictDrop
+	}
+	return append(exprs, &expr.Verdict{Kind: verdict})
+}
+
+// neighborDiscoveryExprs accepts ICMPv6 router and neighbor solicitations and advertisements, IPv6 addresses of the
+// connection are not reachable without them
+func neighborDiscoveryExprs() []expr.Any {
+	exprs := l4ProtoExprs(ProtocolICMPv6)
+	return append(exprs,
+		&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: 0, Len: 1},
+		&expr.Cmp{Op: expr.CmpOpGte, Register: 1, Data: []byte{ndRouterSolicitation}},
+		&expr.Cmp{Op: expr.CmpOpLte, Register: 1, Data: []byte{ndNeighborAdvertisement}},
+		&expr.Verdict{Kind: expr.VerdictAccept},
+	)
+}
+
+func networkExprs(network *net.IPNet, source bool) []expr.Any {
+	nfproto, offset, ip := byte(unix.NFPROTO_IPV6), uint32(8), network.IP.To16()
+	if ip4 := network.IP.To4(); ip4 != nil {
+		nfproto, offset, ip = unix.NFPROTO_IPV4, 12, ip4
+	}
+	if !source {
+		offset += uint32(len(ip))
+	}
+	mask := []byte(network.Mask)
+	if len(mask) != len(ip) {
+		mask = mask[len(mask)-len(ip):]
+	}
+	return []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyNFPROTO, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{nfproto}},
+		&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: offset, Len: uint32(len(ip))},
+		&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: uint32(len(ip)), Mask: mask, Xor: make([]byte, len(ip))},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: ip.Mask(mask)},
+	}
+}
+
+func l4ProtoExprs(protocol string) []expr.Any {
+	proto := map[string]byte{
+		ProtocolICMP:   unix.IPPROTO_ICMP,
+		ProtocolICMPv6: unix.IPPROTO_ICMPV6,
+		ProtocolTCP:    unix.IPPROTO_TCP,
+		ProtocolUDP:    unix.IPPROTO_UDP,
+	}[protocol]
+	return []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{proto}},
+	}
+}
+
+func portExprs(ports PortRange, offset uint32) []expr.Any {
+	if ports.IsAny() {
+		return nil
+	}
+	exprs := []expr.Any{
+		&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: offset, Len: 2},
+	}
+	if ports.Low == ports.High {
+		return append(exprs, &expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: port(ports.Low)})
+	}
+	return append(exprs,
+		&expr.Cmp{Op: expr.CmpOpGte, Register: 1, Data: port(ports.Low)},
+		&expr.Cmp{Op: expr.CmpOpLte, Register: 1, Data: port(ports.High)},
+	)
+}
+
+func port(value uint16) []byte {
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, value)
+	return data
+}
+
+func ifname(name string) []byte {
+	data := make([]byte, unix.IFNAMSIZ)
+	copy(data, name)
+	return data
+}
+
+func jumpTarget(rule *nftables.Rule) string {
+	for _, e := range rule.Exprs {
+		if verdict, ok := e.(*expr.Verdict); ok && verdict.Kind == expr.VerdictJump {
+			return verdict.Chain
+		}
+	}
+	return ""
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firewall
+
+import (
+	"net"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/nftables"
+	. "github.com/onsi/gomega"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	testClientIface = "nsm-client"
+	testServerIface = "nsm-server"
+	testClientIP    = "10.60.0.1"
+	testServerIP    = "10.60.0.2"
+	testClientIPv6  = "fd60::1"
+	testServerIPv6  = "fd60::2"
+)
+
+func TestNftablesRules(t *testing.T) {
+	g := NewWithT(t)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origin, server := newTestNamespace(t)
+	defer func() {
+		_ = netns.Set(origin)
+		_ = origin.Close()
+		_ = server.Close()
+	}()
+
+	config := &Config{
+		Rules: []*Rule{
+			{
+				Name:             "deny-udp-5001",
+				Action:           ActionDeny,
+				Protocols:        []string{ProtocolUDP},
+				DestinationPorts: []string{"5001"},
+			},
+			{
+				Name:             "allow-udp",
+				Action:           ActionReflect,
+				Protocols:        []string{ProtocolUDP},
+				Destination:      "{{.DstIP}}",
+				DestinationPorts: []string{"5000-5001"},
+			},
+		},
+	}
+	matches, err := config.Bind(&Context{SrcIP: testClientIP, DstIP: testServerIP})
+	g.Expect(err).To(BeNil())
+
+	// Rules are applied on the server side of the connection
+	g.Expect(netns.Set(server)).To(Succeed())
+	if err = ApplyNftables(int(server), testServerIface, matches); err != nil {
+		t.Skipf("nftables are not supported: %v", err)
+	}
+	g.Expect(ApplyNftables(int(server), testServerIface, matches)).To(Succeed())
+	g.Expect(testNftablesChains(int(server))).To(ConsistOf("prerouting", "postrouting", testServerIface+"-ingress", testServerIface+"-egress"))
+	g.Expect(netns.Set(origin)).To(Succeed())
+
+	g.Expect(udpEcho(server, 5000)).To(Succeed())
+	g.Expect(udpEcho(server, 5001)).NotTo(Succeed())
+	g.Expect(udpEcho(server, 5002)).NotTo(Succeed())
+
+	g.Expect(RemoveNftables(int(server), testServerIface)).To(Succeed())
+	g.Expect(testNftablesChains(int(server))).To(ConsistOf("prerouting", "postrouting"))
+	g.Expect(udpEcho(server, 5002)).To(Succeed())
+}
+
+func TestNftablesNeighborDiscovery(t *testing.T) {
+	g := NewWithT(t)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origin, server := newTestNamespace(t)
+	defer func() {
+		_ = netns.Set(origin)
+		_ = origin.Close()
+		_ = server.Close()
+	}()
+	addTestIPv6(g, origin, testClientIface, testClientIPv6)
+	addTestIPv6(g, server, testServerIface, testServerIPv6)
+
+	config := &Config{
+		Rules: []*Rule{
+			{
+				Name:        "allow-udp",
+				Action:      ActionReflect,
+				Protocols:   []string{ProtocolUDP},
+				Destination: "{{.DstIP}}",
+			},
+		},
+	}
+	matches, err := config.Bind(&Context{SrcIP: testClientIPv6, DstIP: testServerIPv6})
+	g.Expect(err).To(BeNil())
+	if err = ApplyNftables(int(server), testServerIface, matches); err != nil {
+		t.Skipf("nftables are not supported: %v", err)
+	}
+
+	// Neighbor has to be resolved before the first datagram is sent
+	g.Expect(udpEchoTo(server, testServerIPv6, 5000)).To(Succeed())
+}
+
+// newTestNamespace creates server namespace connected to the current one with veth pair
+func newTestNamespace(t *testing.T) (origin, server netns.NsHandle) {
+	if os.Geteuid() != 0 {
+		t.Skip("network namespaces require root privileges")
+	}
+	g := NewWithT(t)
+
+	origin, err := netns.Get()
+	g.Expect(err).To(BeNil())
+	// Test traffic is sent from the new namespace to keep the host untouched
+	client, err := netns.New()
+	if err != nil {
+		t.Skipf("failed to create network namespace: %v", err)
+	}
+	server, err = netns.New()
+	g.Expect(err).To(BeNil())
+	g.Expect(netns.Set(client)).To(Succeed())
+
+	g.Expect(netlink.LinkAdd(&netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: testClientIface}, PeerName: testServerIface})).To(Succeed())
+	serverLink, err := netlink.LinkByName(testServerIface)
+	g.Expect(err).To(BeNil())
+	g.Expect(netlink.LinkSetNsFd(serverLink, int(server))).To(Succeed())
+	setupTestLink(g, testClientIface, testClientIP)
+
+	g.Expect(netns.Set(server)).To(Succeed())
+	setupTestLink(g, testServerIface, testServerIP)
+	g.Expect(netns.Set(client)).To(Succeed())
+
+	return client, server
+}
+
+func setupTestLink(g *WithT, name, ip string) {
+	link, err := netlink.LinkByName(name)
+	g.Expect(err).To(BeNil())
+	addr, err := netlink.ParseAddr(ip + "/30")
+	g.Expect(err).To(BeNil())
+	g.Expect(netlink.AddrAdd(link, addr)).To(Succeed())
+	g.Expect(netlink.LinkSetUp(link)).To(Succeed())
+}
+
+// addTestIPv6 adds IPv6 address to the link in the namespace without duplicate address detection
+func addTestIPv6(g *WithT, ns netns.NsHandle, name, ip string) {
+	origin, err := netns.Get()
+	g.Expect(err).To(BeNil())
+	defer func() { _ = origin.Close() }()
+
+	g.Expect(netns.Set(ns)).To(Succeed())
+	link, err := netlink.LinkByName(name)
+	g.Expect(err).To(BeNil())
+	addr, err := netlink.ParseAddr(ip + "/64")
+	g.Expect(err).To(BeNil())
+	addr.Flags = unix.IFA_F_NODAD
+	g.Expect(netlink.AddrAdd(link, addr)).To(Succeed())
+	g.Expect(netns.Set(origin)).To(Succeed())
+}
+
+// udpEcho sends datagram to the server port and waits for the reply, OS thread should be locked
+func udpEcho(server netns.NsHandle, port int) error {
+	return udpEchoTo(server, testServerIP, port)
+}
+
+// udpEchoTo sends datagram to the server address and port and waits for the reply, OS thread should be locked
+func udpEchoTo(server netns.NsHandle, serverIP string, port int) error {
+	origin, err := netns.Get()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = origin.Close() }()
+
+	if err = netns.Set(server); err != nil {
+		return err
+	}
+	listener, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(serverIP), Port: port})
+	if setErr := netns.Set(origin); setErr != nil {
+		return setErr
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = listener.Close() }()
+	go func() {
+		buf := make([]byte, 16)
+		n, addr, readErr := listener.ReadFromUDP(buf)
+		if readErr == nil {
+			_, _ = listener.WriteToUDP(buf[:n], addr)
+		}
+	}()
+
+	sender, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: net.ParseIP(serverIP), Port: port})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sender.Close() }()
+	if _, err = sender.Write([]byte("ping")); err != nil {
+		return err
+	}
+	_ = sender.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	_, err = sender.Read(make([]byte, 16))
+	return err
+}
+
+func testNftablesChains(netNS int) []string {
+	chains, err := listChains(&nftables.Conn{NetNS: netNS})
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, chain := range chains {
+		names = append(names, chain.Name)
+	}
+	return names
+}
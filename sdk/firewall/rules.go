@@ -0,0 +1,430 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package firewall - ordered firewall rules language shared by the VPP ACL and nftables composites
+package firewall
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+)
+
+// Rule actions
+const (
+	// ActionDeny drops the matching packets
+	ActionDeny = "deny"
+	// ActionPermit passes the matching packets
+	ActionPermit = "permit"
+	// ActionReflect passes the matching packets and the replies to them
+	ActionReflect = "reflect"
+)
+
+// Rule directions relative to the connection interface
+const (
+	// DirectionIngress - packets received from the connection interface
+	DirectionIngress = "ingress"
+	// DirectionEgress - packets sent to the connection interface
+	DirectionEgress = "egress"
+)
+
+// Rule protocols
+const (
+	ProtocolICMP   = "icmp"
+	ProtocolICMPv6 = "icmpv6"
+	ProtocolTCP    = "tcp"
+	ProtocolUDP    = "udp"
+)
+
+// Config - firewall configuration
+type Config struct {
+	// PortRanges - named port ranges rules can refer to, f.e. "web": "80-443", names are case insensitive
+	PortRanges map[string]string `json:"portRanges,omitempty"`
+	// Rules - rules in the order they are matched
+	Rules []*Rule `json:"rules"`
+}
+
+// Rule - firewall rule, empty fields match any packet
+type Rule struct {
+	Name string `json:"name"`
+	// Action - one of deny, permit or reflect
+	Action string `json:"action"`
+	// Direction - ingress (default) or egress
+	Direction string `json:"direction,omitempty"`
+	// Protocols - set of icmp, icmpv6, tcp or udp
+	Protocols []string `json:"protocols,omitempty"`
+	// Source and Destination - CIDRs or addresses, can use the connection placeholders: {{.SrcIP}}, {{.DstIP}},
+	// {{.SrcNet}} and {{.DstNet}}
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	// SourcePorts and DestinationPorts - ports, port ranges "low-high" or names of the port ranges, tcp and udp only
+	SourcePorts      []string `json:"sourcePorts,omitempty"`
+	DestinationPorts []string `json:"destinationPorts,omitempty"`
+	// ICMPType - ICMP message type, icmp and icmpv6 only
+	ICMPType *uint8 `json:"icmpType,omitempty"`
+}
+
+// PortRange - inclusive range of ports
+type PortRange struct {
+	Low  uint16
+	High uint16
+}
+
+// AnyPort - range matching all ports
+var AnyPort = PortRange{Low: 0, High: 65535}
+
+// Match - single rule match bound to the connection, nil or empty fields match any packet
+type Match struct {
+	Rule             string
+	Action           string
+	Direction        string
+	Protocol         string
+	Source           *net.IPNet
+	Destination      *net.IPNet
+	SourcePorts      PortRange
+	DestinationPorts PortRange
+	ICMPType         *uint8
+}
+
+// Context - connection addresses the rules placeholders are bound to
+type Context struct {
+	// SrcIP and DstIP - source and destination addresses of the connection
+	SrcIP string
+	DstIP string
+	// SrcNet and DstNet - source and destination addresses of the connection with the prefix length
+	SrcNet string
+	DstNet string
+}
+
+// validationContext is used to check the placeholders before any connection exists
+var validationContext = &Context{
+	SrcIP:  "10.0.0.1",
+	DstIP:  "10.0.0.2",
+	SrcNet: "10.0.0.1/30",
+	DstNet: "10.0.0.2/30",
+}
+
+// errNoAddress - connection has no address of the context IP family for the placeholder
+var errNoAddress = errors.New("connection has no address for the placeholder")
+
+// NewContext creates Context from the primary addresses of the connection IP context
+func NewContext(ipContext *connectioncontext.IPContext) *Context {
+	return newContext(ipContext.GetSrcIpAddr(), ipContext.GetDstIpAddr())
+}
+
+// NewContexts creates Context per IP family of the connection IP context, primary addresses family goes first. Dual
+// stack connections have both IPv4 and IPv6 contexts.
+func NewContexts(ipContext *connectioncontext.IPContext) []*Context {
+	var families []bool
+	seen := map[bool]bool{}
+	srcNets, dstNets := map[bool]string{}, map[bool]string{}
+	addNets := func(nets map[bool]string, addrs []string) {
+		for _, addr := range addrs {
+			isIPv4 := net.ParseIP(strings.Split(addr, "/")[0]).To4() != nil
+			if !seen[isIPv4] {
+				seen[isIPv4] = true
+				families = append(families, isIPv4)
+			}
+			if _, ok := nets[isIPv4]; !ok {
+				nets[isIPv4] = addr
+			}
+		}
+	}
+	addNets(srcNets, ipContext.SrcIPAddrs())
+	addNets(dstNets, ipContext.DstIPAddrs())
+
+	if len(families) == 0 {
+		return []*Context{NewContext(ipContext)}
+	}
+	var contexts []*Context
+	for _, isIPv4 := range families {
+		contexts = append(contexts, newContext(srcNets[isIPv4], dstNets[isIPv4]))
+	}
+	return contexts
+}
+
+func newContext(srcNet, dstNet string) *Context {
+	return &Context{
+		SrcIP:  strings.Split(srcNet, "/")[0],
+		DstIP:  strings.Split(dstNet, "/")[0],
+		SrcNet: srcNet,
+		DstNet: dstNet,
+	}
+}
+
+// Validate checks the configuration, placeholders are checked with the sample connection addresses
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	_, err := c.Bind(validationContext)
+	return err
+}
+
+// Bind binds rules to the connection contexts and returns the matches in the order of rules, rules with multiple
+// protocols or port ranges are expanded to multiple matches. Rules with placeholders are bound to every context, so
+// they match each IP family of the connection, the first context must have addresses for all of them.
+func (c *Config) Bind(contexts ...*Context) ([]*Match, error) {
+	if c == nil {
+		return nil, nil
+	}
+	var matches []*Match
+	for i, rule := range c.Rules {
+		for j, ctx := range contexts {
+			if j > 0 && !rule.hasPlaceholders() {
+				break
+			}
+			ruleMatches, err := c.bindRule(rule, ctx)
+			if j > 0 && errors.Cause(err) == errNoAddress {
+				// Connection has no addresses of this IP family for the rule
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "rule %d %q", i, rule.GetName())
+			}
+			matches = append(matches, ruleMatches...)
+		}
+	}
+	return matches, nil
+}
+
+// Reverse returns copies of the matches with the opposite directions. Rules are configured for the endpoint side
+// interface, the client side interface sends the packets the endpoint one receives and vice versa.
+func Reverse(matches []*Match) []*Match {
+	reversed := make([]*Match, 0, len(matches))
+	for _, match := range matches {
+		m := *match
+		if m.Direction == DirectionEgress {
+			m.Direction = DirectionIngress
+		} else {
+			m.Direction = DirectionEgress
+		}
+		reversed = append(reversed, &m)
+	}
+	return reversed
+}
+
+// GetName returns rule name
+func (r *Rule) GetName() string {
+	if r == nil {
+		return ""
+	}
+	return r.Name
+}
+
+func (r *Rule) hasPlaceholders() bool {
+	return r != nil && (strings.Contains(r.Source, "{{") || strings.Contains(r.Destination, "{{"))
+}
+
+func (c *Config) bindRule(rule *Rule, ctx *Context) ([]*Match, error) {
+	if rule == nil {
+		return nil, errors.New("rule cannot be empty")
+	}
+
+	action := strings.ToLower(rule.Action)
+	switch action {
+	case ActionDeny, ActionPermit, ActionReflect:
+	default:
+		return nil, errors.Errorf("invalid action: %q", rule.Action)
+	}
+
+	direction := strings.ToLower(rule.Direction)
+	switch direction {
+	case "":
+		direction = DirectionIngress
+	case DirectionIngress, DirectionEgress:
+	default:
+		return nil, errors.Errorf("invalid direction: %q", rule.Direction)
+	}
+
+	protocols, err := parseProtocols(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := bindNetwork(rule.Source, ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid source")
+	}
+	destination, err := bindNetwork(rule.Destination, ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid destination")
+	}
+
+	sourcePorts, err := c.parsePortRanges(rule.SourcePorts)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid source ports")
+	}
+	destinationPorts, err := c.parsePortRanges(rule.DestinationPorts)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid destination ports")
+	}
+
+	var matches []*Match
+	for _, protocol := range protocols {
+		for _, sourcePortRange := range sourcePorts {
+			for _, destinationPortRange := range destinationPorts {
+				matches = append(matches, &Match{
+					Rule:             rule.Name,
+					Action:           action,
+					Direction:        direction,
+					Protocol:         protocol,
+					Source:           source,
+					Destination:      destination,
+					SourcePorts:      sourcePortRange,
+					DestinationPorts: destinationPortRange,
+					ICMPType:         rule.ICMPType,
+				})
+			}
+		}
+	}
+	return matches, nil
+}
+
+func parseProtocols(rule *Rule) ([]string, error) {
+	if len(rule.Protocols) == 0 {
+		if len(rule.SourcePorts) != 0 || len(rule.DestinationPorts) != 0 {
+			return nil, errors.New("ports require tcp or udp protocols")
+		}
+		if rule.ICMPType != nil {
+			return nil, errors.New("icmpType requires icmp or icmpv6 protocols")
+		}
+		// Any protocol
+		return []string{""}, nil
+	}
+
+	var protocols []string
+	seen := map[string]bool{}
+	for _, protocol := range rule.Protocols {
+		protocol = strings.ToLower(protocol)
+		switch protocol {
+		case ProtocolICMP, ProtocolICMPv6:
+			if len(rule.SourcePorts) != 0 || len(rule.DestinationPorts) != 0 {
+				return nil, errors.Errorf("ports are not supported for %s protocol", protocol)
+			}
+		case ProtocolTCP, ProtocolUDP:
+			if rule.ICMPType != nil {
+				return nil, errors.Errorf("icmpType is not supported for %s protocol", protocol)
+			}
+		default:
+			return nil, errors.Errorf("invalid protocol: %q", protocol)
+		}
+		if !seen[protocol] {
+			seen[protocol] = true
+			protocols = append(protocols, protocol)
+		}
+	}
+	return protocols, nil
+}
+
+func bindNetwork(value string, ctx *Context) (*net.IPNet, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if strings.Contains(value, "{{") {
+		tmpl, err := template.New("network").Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		buf := &bytes.Buffer{}
+		if err := tmpl.Execute(buf, ctx); err != nil {
+			return nil, err
+		}
+		if value = buf.String(); value == "" || strings.HasPrefix(value, "/") {
+			return nil, errNoAddress
+		}
+	}
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, errors.Errorf("%q is neither address nor CIDR", value)
+		}
+		if ip.To4() != nil {
+			return &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, ipNet, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, err
+	}
+	return ipNet, nil
+}
+
+func (c *Config) parsePortRanges(ports []string) ([]PortRange, error) {
+	if len(ports) == 0 {
+		return []PortRange{AnyPort}, nil
+	}
+	var ranges []PortRange
+	for _, port := range ports {
+		if named, ok := c.portRange(port); ok {
+			port = named
+		}
+		portRange, err := ParsePortRange(port)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, portRange)
+	}
+	return ranges, nil
+}
+
+// portRange looks up the named port range, names are case insensitive
+func (c *Config) portRange(name string) (string, bool) {
+	for rangeName, portRange := range c.PortRanges {
+		if strings.EqualFold(rangeName, name) {
+			return portRange, true
+		}
+	}
+	return "", false
+}
+
+// ParsePortRange parses port "80" or port range "8000-8080"
+func ParsePortRange(value string) (PortRange, error) {
+	bounds := strings.SplitN(strings.TrimSpace(value), "-", 2)
+	low, err := strconv.ParseUint(strings.TrimSpace(bounds[0]), 10, 16)
+	if err != nil {
+		return PortRange{}, errors.Errorf("%q is neither port, port range nor port range name", value)
+	}
+	high := low
+	if len(bounds) == 2 {
+		if high, err = strconv.ParseUint(strings.TrimSpace(bounds[1]), 10, 16); err != nil {
+			return PortRange{}, errors.Errorf("%q is not a valid port range", value)
+		}
+	}
+	if low > high {
+		return PortRange{}, errors.Errorf("%q port range is empty", value)
+	}
+	return PortRange{Low: uint16(low), High: uint16(high)}, nil
+}
+
+// String returns port range as "low-high"
+func (p PortRange) String() string {
+	if p.Low == p.High {
+		return fmt.Sprint(p.Low)
+	}
+	return fmt.Sprintf("%d-%d", p.Low, p.High)
+}
+
+// IsAny checks if the range matches all ports
+func (p PortRange) IsAny() bool {
+	return p == AnyPort
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firewall
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+)
+
+func icmpType(t uint8) *uint8 {
+	return &t
+}
+
+func TestBindRules(t *testing.T) {
+	g := NewWithT(t)
+
+	config := &Config{
+		PortRanges: map[string]string{"web": "80-443"},
+		Rules: []*Rule{
+			{
+				Name:      "allow-ping",
+				Action:    "REFLECT",
+				Protocols: []string{ProtocolICMP},
+				ICMPType:  icmpType(8),
+			},
+			{
+				Name:             "allow-web",
+				Action:           ActionPermit,
+				Protocols:        []string{ProtocolTCP, ProtocolUDP},
+				Destination:      "{{.DstIP}}",
+				DestinationPorts: []string{"Web", "8080"},
+			},
+			{
+				Name:      "deny-egress",
+				Action:    ActionDeny,
+				Direction: DirectionEgress,
+				Source:    "{{.SrcNet}}",
+			},
+		},
+	}
+	g.Expect(config.Validate()).To(Succeed())
+
+	ctx := NewContext(&connectioncontext.IPContext{
+		SrcIpAddr: "172.16.1.1/30",
+		DstIpAddr: "172.16.1.2/30",
+	})
+	matches, err := config.Bind(ctx)
+	g.Expect(err).To(BeNil())
+	g.Expect(matches).To(HaveLen(6))
+
+	g.Expect(matches[0]).To(Equal(&Match{
+		Rule:             "allow-ping",
+		Action:           ActionReflect,
+		Direction:        DirectionIngress,
+		Protocol:         ProtocolICMP,
+		SourcePorts:      AnyPort,
+		DestinationPorts: AnyPort,
+		ICMPType:         icmpType(8),
+	}))
+
+	// Protocols and port ranges are expanded in order
+	dstIP := &net.IPNet{IP: net.ParseIP("172.16.1.2").To4(), Mask: net.CIDRMask(32, 32)}
+	for i, expected := range []struct {
+		protocol string
+		ports    PortRange
+	}{
+		{ProtocolTCP, PortRange{Low: 80, High: 443}},
+		{ProtocolTCP, PortRange{Low: 8080, High: 8080}},
+		{ProtocolUDP, PortRange{Low: 80, High: 443}},
+		{ProtocolUDP, PortRange{Low: 8080, High: 8080}},
+	} {
+		match := matches[i+1]
+		g.Expect(match.Protocol).To(Equal(expected.protocol))
+		g.Expect(match.DestinationPorts).To(Equal(expected.ports))
+		g.Expect(match.SourcePorts.IsAny()).To(BeTrue())
+		g.Expect(match.Destination).To(Equal(dstIP))
+	}
+
+	g.Expect(matches[5].Direction).To(Equal(DirectionEgress))
+	g.Expect(matches[5].Protocol).To(BeEmpty())
+	g.Expect(matches[5].Source.String()).To(Equal("172.16.1.0/30"))
+
+	// Connection without addresses cannot be bound to the placeholders
+	_, err = config.Bind(NewContext(nil))
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestBindDualStack(t *testing.T) {
+	g := NewWithT(t)
+	config := &Config{
+		Rules: []*Rule{
+			{
+				Name:        "allow-dns",
+				Action:      ActionPermit,
+				Protocols:   []string{ProtocolUDP},
+				Destination: "{{.DstIP}}",
+			},
+			{
+				Name:   "allow-all-from-client",
+				Action: ActionPermit,
+				Source: "{{.SrcNet}}",
+			},
+			{
+				Name:   "deny-all",
+				Action: ActionDeny,
+			},
+		},
+	}
+
+	contexts := NewContexts(&connectioncontext.IPContext{
+		SrcIpAddr:       "172.16.1.1/30",
+		DstIpAddr:       "172.16.1.2/30",
+		ExtraSrcIpAddrs: []string{"fd00::1/126"},
+		ExtraDstIpAddrs: []string{"fd00::2/126"},
+	})
+	g.Expect(contexts).To(Equal([]*Context{
+		{SrcIP: "172.16.1.1", DstIP: "172.16.1.2", SrcNet: "172.16.1.1/30", DstNet: "172.16.1.2/30"},
+		{SrcIP: "fd00::1", DstIP: "fd00::2", SrcNet: "fd00::1/126", DstNet: "fd00::2/126"},
+	}))
+
+	matches, err := config.Bind(contexts...)
+	g.Expect(err).To(BeNil())
+	g.Expect(matches).To(HaveLen(5))
+	g.Expect(matches[0].Destination.String()).To(Equal("172.16.1.2/32"))
+	g.Expect(matches[1].Destination.String()).To(Equal("fd00::2/128"))
+	g.Expect(matches[2].Source.String()).To(Equal("172.16.1.0/30"))
+	g.Expect(matches[3].Source.String()).To(Equal("fd00::/126"))
+	// Rules without placeholders are bound once
+	g.Expect(matches[4].Rule).To(Equal("deny-all"))
+
+	// IP family without destination address skips the rules using it
+	matches, err = config.Bind(NewContexts(&connectioncontext.IPContext{
+		SrcIpAddr:       "172.16.1.1/30",
+		DstIpAddr:       "172.16.1.2/30",
+		ExtraSrcIpAddrs: []string{"fd00::1/126"},
+	})...)
+	g.Expect(err).To(BeNil())
+	g.Expect(matches).To(HaveLen(4))
+	g.Expect(matches[2].Source.String()).To(Equal("fd00::/126"))
+}
+
+func TestReverse(t *testing.T) {
+	g := NewWithT(t)
+	matches := []*Match{
+		{Rule: "allow-web", Action: ActionReflect, Direction: DirectionIngress, Protocol: ProtocolTCP},
+		{Rule: "deny-egress", Action: ActionDeny, Direction: DirectionEgress},
+	}
+
+	reversed := Reverse(matches)
+	g.Expect(reversed).To(Equal([]*Match{
+		{Rule: "allow-web", Action: ActionReflect, Direction: DirectionEgress, Protocol: ProtocolTCP},
+		{Rule: "deny-egress", Action: ActionDeny, Direction: DirectionIngress},
+	}))
+	// Original matches are not modified
+	g.Expect(matches[0].Direction).To(Equal(DirectionIngress))
+}
+
+func TestValidateRules(t *testing.T) {
+	for name, rule := range map[string]*Rule{
+		"action":          {Action: "accept"},
+		"direction":       {Action: ActionDeny, Direction: "both"},
+		"protocol":        {Action: ActionDeny, Protocols: []string{"sctp"}},
+		"ports":           {Action: ActionDeny, DestinationPorts: []string{"80"}},
+		"icmp ports":      {Action: ActionDeny, Protocols: []string{ProtocolICMP}, SourcePorts: []string{"80"}},
+		"tcp icmp type":   {Action: ActionDeny, Protocols: []string{ProtocolTCP}, ICMPType: icmpType(0)},
+		"port range":      {Action: ActionDeny, Protocols: []string{ProtocolTCP}, DestinationPorts: []string{"90-80"}},
+		"port range name": {Action: ActionDeny, Protocols: []string{ProtocolTCP}, DestinationPorts: []string{"web"}},
+		"network":         {Action: ActionDeny, Source: "10.0.0.0/33"},
+		"placeholder":     {Action: ActionDeny, Source: "{{.SrcAddr}}"},
+	} {
+		rule := rule
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect((&Config{Rules: []*Rule{rule}}).Validate()).NotTo(Succeed())
+		})
+	}
+}
+
+func TestFromKeyValueRules(t *testing.T) {
+	g := NewWithT(t)
+
+	config, err := FromKeyValueRules(map[string]string{
+		"Allow TCP 80": "action=reflect,tcplowport=80,tcpupport=80",
+		"Allow ICMP":   "action=reflect,icmptype=8",
+		"Deny net":     "action=deny,srcnet=10.0.0.0/8",
+	})
+	g.Expect(err).To(BeNil())
+	g.Expect(config.Rules).To(Equal([]*Rule{
+		{Name: "Allow ICMP", Action: "reflect", Protocols: []string{ProtocolICMP}, ICMPType: icmpType(8)},
+		{Name: "Allow TCP 80", Action: "reflect", Protocols: []string{ProtocolTCP}, DestinationPorts: []string{"80-80"}},
+		{Name: "Deny net", Action: "deny", Source: "10.0.0.0/8"},
+	}))
+
+	_, err = FromKeyValueRules(map[string]string{"Invalid": "icmptype=8"})
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestLoadConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "firewall")
+	g.Expect(err).To(BeNil())
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "config.yaml")
+	g.Expect(ioutil.WriteFile(path, []byte(`
+portRanges:
+  Web: 80-443
+rules:
+  - name: allow-web
+    action: permit
+    protocols: [tcp]
+    destination: "{{.DstIP}}"
+    destinationPorts: [web]
+`), 0600)).To(Succeed())
+
+	config, err := LoadConfig(path)
+	g.Expect(err).To(BeNil())
+	g.Expect(config.Rules).To(HaveLen(1))
+	g.Expect(config.Rules[0].DestinationPorts).To(Equal([]string{"web"}))
+
+	g.Expect(ioutil.WriteFile(path, []byte("rules:\n  - name: invalid\n    action: drop\n"), 0600)).To(Succeed())
+	_, err = LoadConfig(path)
+	g.Expect(err).NotTo(BeNil())
+
+	_, err = LoadConfig(filepath.Join(dir, "missing.yaml"))
+	g.Expect(err).NotTo(BeNil())
+}
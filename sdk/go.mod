@@ -5,16 +5,21 @@ go 1.13
 require (
 	github.com/fsnotify/fsnotify v1.4.7
 	github.com/golang/protobuf v1.3.3
+	github.com/google/nftables v0.0.0-20200802175506-c25e4f69b425
 	github.com/hashicorp/go-multierror v1.0.0
 	github.com/networkservicemesh/networkservicemesh/controlplane/api v0.3.0
 	github.com/networkservicemesh/networkservicemesh/pkg v0.3.0
 	github.com/networkservicemesh/networkservicemesh/utils v0.3.0
 	github.com/onsi/gomega v1.7.0
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.1.0
 	github.com/sirupsen/logrus v1.4.2
 	github.com/spf13/viper v1.5.0
 	github.com/teris-io/shortid v0.0.0-20171029131806-771a37caa5cf
+	github.com/vishvananda/netlink v1.1.0
+	github.com/vishvananda/netns v0.0.0-20191106174202-0a2b9b5464df
 	go.ligato.io/vpp-agent/v3 v3.1.0
+	golang.org/x/sys v0.0.0-20200124204421-9fbb57f87de9
 	google.golang.org/grpc v1.27.1
 )
 
@@ -0,0 +1,136 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipamserver provides the IPAM service allocating the connection addresses from the pools shared by all the
+// endpoints of the Network Service
+package ipamserver
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/ipam"
+	"github.com/networkservicemesh/networkservicemesh/sdk/common"
+	"github.com/networkservicemesh/networkservicemesh/sdk/endpoint"
+	"github.com/networkservicemesh/networkservicemesh/sdk/prefix_pool"
+)
+
+// StoreFunc returns the store of the Network Service connections, nil keeps them in memory only
+type StoreFunc func(networkService string) prefix_pool.Store
+
+type ipamServer struct {
+	pools map[string]*endpoint.IpamEndpoint
+}
+
+// NewServer creates the IPAM server allocating the connection addresses from the pools by the Network Service name
+func NewServer(pools map[string]*endpoint.IpamEndpoint) ipam.IPAMServer {
+	return &ipamServer{
+		pools: pools,
+	}
+}
+
+// NewPools creates the pools from the configuration like "secure-intranet-connectivity=10.60.0.0/16,fd00::/64;icmp-responder=10.70.0.0/16",
+// each pool is configured the same way as IP_ADDRESS of the endpoint
+func NewPools(config string, newStore StoreFunc) (map[string]*endpoint.IpamEndpoint, error) {
+	pools := map[string]*endpoint.IpamEndpoint{}
+	for _, pool := range strings.Split(config, ";") {
+		pool = strings.TrimSpace(pool)
+		if pool == "" {
+			continue
+		}
+		kv := strings.SplitN(pool, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" {
+			return nil, errors.Errorf("invalid IPAM pool %q, expected <network service>=<prefixes>", pool)
+		}
+		networkService, prefixes := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if _, ok := pools[networkService]; ok {
+			return nil, errors.Errorf("IPAM pool of %s is configured twice", networkService)
+		}
+		for _, prefix := range strings.Split(prefixes, ",") {
+			if _, _, err := net.ParseCIDR(strings.TrimSpace(prefix)); err != nil {
+				return nil, errors.Wrapf(err, "invalid IPAM pool of %s", networkService)
+			}
+		}
+		var store prefix_pool.Store
+		if newStore != nil {
+			store = newStore(networkService)
+		}
+		pools[networkService] = endpoint.NewIpamEndpointWithStore(&common.NSConfiguration{
+			EndpointNetworkService: networkService,
+			IPAddress:              prefixes,
+		}, store)
+	}
+	if len(pools) == 0 {
+		return nil, errors.New("no IPAM pools are configured")
+	}
+	return pools, nil
+}
+
+func (s *ipamServer) Allocate(ctx context.Context, request *ipam.IPAMRequest) (*connectioncontext.IPContext, error) {
+	pool, err := s.pool(request)
+	if err != nil {
+		return nil, err
+	}
+	ipContext := request.GetIpContext()
+	if ipContext == nil {
+		ipContext = &connectioncontext.IPContext{}
+	}
+	if err := pool.Allocate(ctx, request.GetConnectionId(), ipContext); err != nil {
+		endpoint.Log(ctx).Errorf("IPAM: failed to allocate connection %s of %s: %v", request.GetConnectionId(),
+			request.GetNetworkService(), err)
+		return nil, err
+	}
+	endpoint.Log(ctx).Infof("IPAM: allocated %s/%s for connection %s of %s", ipContext.GetSrcIpAddr(), ipContext.GetDstIpAddr(),
+		request.GetConnectionId(), request.GetNetworkService())
+	sendWarning(ctx, pool)
+	return ipContext, nil
+}
+
+func (s *ipamServer) Release(ctx context.Context, request *ipam.IPAMRequest) (*empty.Empty, error) {
+	pool, err := s.pool(request)
+	if err != nil {
+		return nil, err
+	}
+	pool.Release(ctx, request.GetConnectionId())
+	sendWarning(ctx, pool)
+	return &empty.Empty{}, nil
+}
+
+// sendWarning sends the pool exhaustion warning in the response header, so the endpoints put it into the connection
+// monitor stream, empty warning is sent too, so it is removed once the pool usage is below the high watermark
+func sendWarning(ctx context.Context, pool *endpoint.IpamEndpoint) {
+	if err := grpc.SetHeader(ctx, metadata.Pairs(endpoint.IpamWarningHeader, pool.HighWatermarkWarning())); err != nil {
+		endpoint.Log(ctx).Debugf("IPAM: failed to send warning header: %v", err)
+	}
+}
+
+func (s *ipamServer) pool(request *ipam.IPAMRequest) (*endpoint.IpamEndpoint, error) {
+	if request.GetConnectionId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "connection id is not set")
+	}
+	pool, ok := s.pools[request.GetNetworkService()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no IPAM pool for network service %q", request.GetNetworkService())
+	}
+	return pool, nil
+}
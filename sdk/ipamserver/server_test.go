@@ -0,0 +1,213 @@
+package ipamserver
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/ipam"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+	"github.com/networkservicemesh/networkservicemesh/sdk/endpoint"
+	"github.com/networkservicemesh/networkservicemesh/sdk/monitor"
+	"github.com/networkservicemesh/networkservicemesh/sdk/monitor/connectionmonitor"
+)
+
+func startServer(g *WithT, config string) (ipam.IPAMClient, map[string]*endpoint.IpamEndpoint, func()) {
+	pools, err := NewPools(config, nil)
+	g.Expect(err).To(BeNil())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).To(BeNil())
+	server := grpc.NewServer()
+	ipam.RegisterIPAMServer(server, NewServer(pools))
+	go func() { _ = server.Serve(listener) }()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	g.Expect(err).To(BeNil())
+	return ipam.NewIPAMClient(conn), pools, func() {
+		_ = conn.Close()
+		server.Stop()
+	}
+}
+
+/* Request is built the way NSMgr builds it for the local endpoint: the endpoint name is not set */
+func newRequest(managerName, id, networkService string) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &connection.Connection{
+			Id:             id,
+			NetworkService: networkService,
+			Path: &connection.Path{
+				PathSegments: []*connection.PathSegment{{Name: managerName}},
+			},
+			Context: &connectioncontext.ConnectionContext{
+				IpContext: &connectioncontext.IPContext{
+					SrcIpRequired: true,
+					DstIpRequired: true,
+				},
+			},
+		},
+	}
+}
+
+func TestNewPools(t *testing.T) {
+	g := NewWithT(t)
+
+	pools, err := NewPools("ns1=10.60.0.0/16,fd00::/64; ns2=10.70.0.0/16;", nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(pools).To(HaveLen(2))
+	g.Expect(pools["ns1"].PrefixPool.GetPrefixes()).To(Equal([]string{"10.60.0.0/16"}))
+	g.Expect(pools["ns1"].ExtraPrefixPool.GetPrefixes()).To(Equal([]string{"fd00::/64"}))
+	g.Expect(pools["ns2"].PrefixPool.GetPrefixes()).To(Equal([]string{"10.70.0.0/16"}))
+	g.Expect(pools["ns2"].ExtraPrefixPool).To(BeNil())
+
+	for _, config := range []string{"", "ns1", "=10.60.0.0/16", "ns1=10.60.0.0", "ns1=10.60.0.0/16;ns1=10.70.0.0/16"} {
+		_, err = NewPools(config, nil)
+		g.Expect(err).NotTo(BeNil(), config)
+	}
+}
+
+func TestReplicasShareAddressSpace(t *testing.T) {
+	g := NewWithT(t)
+
+	client, pools, stop := startServer(g, "ns1=10.60.0.0/24,fd00::/120")
+	defer stop()
+
+	replicas := []*endpoint.IpamServiceEndpoint{
+		endpoint.NewIpamServiceEndpointWithClient(client),
+		endpoint.NewIpamServiceEndpointWithClient(client),
+	}
+
+	/* Connection ids are generated by NSMgr, so they are the same for the replicas on different nodes */
+	type result struct {
+		conn *connection.Connection
+		err  error
+	}
+	var requests []*networkservice.NetworkServiceRequest
+	results := make([]result, 2*20)
+	wg := sync.WaitGroup{}
+	for i, replica := range replicas {
+		ctx := endpoint.WithEndpointName(context.Background(), "nse-"+strconv.Itoa(i))
+		for id := 0; id < 20; id++ {
+			request := newRequest("nsm-"+strconv.Itoa(i), strconv.Itoa(id), "ns1")
+			requests = append(requests, request)
+			wg.Add(1)
+			go func(replica *endpoint.IpamServiceEndpoint, request *networkservice.NetworkServiceRequest, r *result) {
+				defer wg.Done()
+				r.conn, r.err = replica.Request(ctx, request)
+			}(replica, request, &results[len(requests)-1])
+		}
+	}
+	wg.Wait()
+
+	addrs := map[string]bool{}
+	for _, r := range results {
+		g.Expect(r.err).To(BeNil())
+		ipContext := r.conn.GetContext().GetIpContext()
+		g.Expect(ipContext.GetExtraSrcIpAddrs()).To(HaveLen(1))
+		for _, addr := range []string{ipContext.GetSrcIpAddr(), ipContext.GetExtraSrcIpAddrs()[0]} {
+			g.Expect(addrs).NotTo(HaveKey(addr))
+			addrs[addr] = true
+		}
+	}
+	g.Expect(addrs).To(HaveLen(80))
+
+	/* Healed connection gets the same addresses */
+	ctx := endpoint.WithEndpointName(context.Background(), "nse-0")
+	conn, err := replicas[0].Request(ctx, newRequest("nsm-0", "0", "ns1"))
+	g.Expect(err).To(BeNil())
+	g.Expect(conn.GetContext().GetIpContext().GetSrcIpAddr()).To(Equal(results[0].conn.GetContext().GetIpContext().GetSrcIpAddr()))
+
+	/* Closing the connection of one replica does not release the addresses of the other one */
+	_, err = replicas[0].Close(ctx, requests[0].GetConnection())
+	g.Expect(err).To(BeNil())
+	conn, err = replicas[1].Request(endpoint.WithEndpointName(context.Background(), "nse-1"), newRequest("nsm-1", "0", "ns1"))
+	g.Expect(err).To(BeNil())
+	g.Expect(conn.GetContext().GetIpContext().GetSrcIpAddr()).To(Equal(results[20].conn.GetContext().GetIpContext().GetSrcIpAddr()))
+
+	for i := 1; i < len(requests); i++ {
+		ctx := endpoint.WithEndpointName(context.Background(), "nse-"+strconv.Itoa(i/20))
+		_, err := replicas[i/20].Close(ctx, requests[i].GetConnection())
+		g.Expect(err).To(BeNil())
+	}
+	g.Expect(pools["ns1"].PrefixPool.GetPrefixes()).To(Equal([]string{"10.60.0.0/24"}))
+	g.Expect(pools["ns1"].ExtraPrefixPool.GetPrefixes()).To(Equal([]string{"fd00::/120"}))
+}
+
+func TestUnknownNetworkService(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _, stop := startServer(g, "ns1=10.60.0.0/24")
+	defer stop()
+
+	_, err := endpoint.NewIpamServiceEndpointWithClient(client).Request(context.Background(), newRequest("nsm-0", "1", "ns2"))
+	g.Expect(status.Code(err)).To(Equal(codes.NotFound))
+}
+
+/* Monitor server recording the connection updates */
+type testMonitor struct {
+	connectionmonitor.MonitorServer
+	mtx     sync.Mutex
+	updates []*connection.Connection
+}
+
+func (m *testMonitor) Update(_ context.Context, entity monitor.Entity) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.updates = append(m.updates, entity.(*connection.Connection))
+}
+
+func (m *testMonitor) takeUpdates() []*connection.Connection {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	updates := m.updates
+	m.updates = nil
+	return updates
+}
+
+func TestHighWatermarkWarning(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _, stop := startServer(g, "ns1=10.60.0.0/28")
+	defer stop()
+
+	xconMonitor := &testMonitor{}
+	ctx := endpoint.WithMonitorServer(endpoint.WithEndpointName(context.Background(), "nse-0"), xconMonitor)
+	nse := endpoint.NewIpamServiceEndpointWithClient(client)
+	for id := 0; id < 3; id++ {
+		conn, err := nse.Request(ctx, newRequest("nsm-0", strconv.Itoa(id), "ns1"))
+		g.Expect(err).To(BeNil())
+		g.Expect(conn.GetContext().GetExtraContext()).NotTo(HaveKey(endpoint.IpamWarningKey))
+	}
+	g.Expect(xconMonitor.takeUpdates()).To(BeEmpty())
+
+	/* Warning is sent by the IPAM service, the established connections get it too */
+	warning := "IPAM pool 10.60.0.0/28 is 100% used: 0 addresses and 0 prefixes are free"
+	request := newRequest("nsm-0", "3", "ns1")
+	conn, err := nse.Request(ctx, request)
+	g.Expect(err).To(BeNil())
+	g.Expect(conn.GetContext().GetExtraContext()).To(HaveKeyWithValue(endpoint.IpamWarningKey, warning))
+	updates := xconMonitor.takeUpdates()
+	g.Expect(updates).To(HaveLen(3))
+	for _, update := range updates {
+		g.Expect(update.GetId()).NotTo(Equal("3"))
+		g.Expect(update.GetContext().GetExtraContext()).To(HaveKeyWithValue(endpoint.IpamWarningKey, warning))
+	}
+
+	/* Warning is removed from the established connections once the pool usage is below the high watermark */
+	_, err = nse.Close(ctx, conn)
+	g.Expect(err).To(BeNil())
+	updates = xconMonitor.takeUpdates()
+	g.Expect(updates).To(HaveLen(3))
+	for _, update := range updates {
+		g.Expect(update.GetContext().GetExtraContext()).NotTo(HaveKey(endpoint.IpamWarningKey))
+	}
+}
@@ -1,10 +1,13 @@
 package prefix_pool
 
 import (
+	"math"
 	"math/big"
 	"net"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -18,13 +21,25 @@ All in one interface for managing prefixes.
 type PrefixPool interface {
 	/*
 		Process ExtraPrefixesRequest and provide a list of prefixes for clients to use.
+		Addresses and prefixes are allocated from the view of the pool without the excluded prefixes, the pool itself
+		is not affected by the exclusions, so concurrent requests with different exclusions do not interfere.
 	*/
-	Extract(connectionId string, family connectioncontext.IpFamily_Family, requests ...*connectioncontext.ExtraPrefixRequest) (srcIP *net.IPNet, dstIP *net.IPNet, requested []string, err error)
+	Extract(connectionId string, family connectioncontext.IpFamily_Family, excludedPrefixes []string, requests ...*connectioncontext.ExtraPrefixRequest) (srcIP *net.IPNet, dstIP *net.IPNet, requested []string, err error)
 	Release(connectionId string) error
 	GetConnectionInformation(connectionId string) (string, []string, error)
 	GetPrefixes() []string
 	Intersect(prefix string) (bool, error)
+	/*
+		Usage returns the numbers of the used and free addresses and prefixes of the pool.
+	*/
+	Usage() Usage
+	/*
+		Deprecated: ExcludePrefixes mutates the pool shared by all the connections, use excludedPrefixes of Extract.
+	*/
 	ExcludePrefixes(excludedPrefixes []string) ([]string, error)
+	/*
+		Deprecated: ReleaseExcludedPrefixes mutates the pool shared by all the connections, use excludedPrefixes of Extract.
+	*/
 	ReleaseExcludedPrefixes(excludedPrefixes []string) error
 }
 
@@ -34,15 +49,42 @@ type prefixPool struct {
 	basePrefixes []string // Just to know where we start from
 	prefixes     []string
 	connections  map[string]*connectionRecord
+	store        Store // Optional persistent storage of the connections
 }
 
 func (impl *prefixPool) GetPrefixes() []string {
-	return impl.prefixes
+	impl.RLock()
+	defer impl.RUnlock()
+	return append([]string{}, impl.prefixes...)
+}
+
+// Usage is the utilization of the prefix pool. Address counts are float64, as IPv6 pools easily exceed uint64.
+type Usage struct {
+	// UsedAddresses is the number of addresses allocated to the connections
+	UsedAddresses float64
+	// FreeAddresses is the number of addresses available for the new connections
+	FreeAddresses float64
+	// UsedPrefixes is the number of prefixes allocated to the connections, including their extra prefixes
+	UsedPrefixes int
+	// FreePrefixes is the number of the available prefixes, the pool could be fragmented into many small ones
+	FreePrefixes int
+	// Prefixes are the prefixes the pool is configured with
+	Prefixes []string
+}
+
+// Utilization returns the used part of the pool addresses from 0 to 1
+func (u Usage) Utilization() float64 {
+	if total := u.UsedAddresses + u.FreeAddresses; total > 0 {
+		return u.UsedAddresses / total
+	}
+	return 0
 }
 
 type connectionRecord struct {
 	ipNet    *net.IPNet
 	prefixes []string
+	// restored is true until the connection restored from the store is requested again
+	restored bool
 }
 
 func NewPrefixPool(prefixes ...string) (PrefixPool, error) {
@@ -54,6 +96,117 @@ func NewPrefixPool(prefixes ...string) (PrefixPool, error) {
 	}, nil
 }
 
+// NewPersistentPrefixPool creates a prefix pool keeping its connections in the store, connections stored for the same
+// prefixes are restored, so they get their previous addresses and prefixes on the next Extract. Restored connections
+// not requested again within restoreTimeout are released, e.g. they are closed or got new IDs while the pool was
+// down. Zero restoreTimeout keeps the restored connections until they are released.
+func NewPersistentPrefixPool(store Store, restoreTimeout time.Duration, prefixes ...string) (PrefixPool, error) {
+	impl := &prefixPool{
+		basePrefixes: prefixes,
+		prefixes:     prefixes,
+		connections:  map[string]*connectionRecord{},
+		store:        store,
+	}
+
+	records, err := store.Load(impl.name())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load prefix pool %s", impl.name())
+	}
+	/* Sort the connections, so the restore is deterministic if the stored connections overlap */
+	ids := make([]string, 0, len(records))
+	for id := range records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := impl.restore(id, records[id]); err != nil {
+			logrus.Warnf("IPAM: skipping stored connection %s of prefix pool %s: %v", id, impl.name(), err)
+		}
+	}
+	logrus.Infof("IPAM: restored %d connections of prefix pool %s", len(impl.connections), impl.name())
+	if len(impl.connections) != 0 && restoreTimeout > 0 {
+		time.AfterFunc(restoreTimeout, impl.releaseRestored)
+	}
+	return impl, nil
+}
+
+// releaseRestored releases the restored connections which are not requested again
+func (impl *prefixPool) releaseRestored() {
+	impl.Lock()
+	defer impl.Unlock()
+
+	released := 0
+	for id, conn := range impl.connections {
+		if !conn.restored {
+			continue
+		}
+		if err := impl.release(id); err != nil {
+			logrus.Errorf("IPAM: failed to release restored connection %s of prefix pool %s: %v", id, impl.name(), err)
+			continue
+		}
+		released++
+	}
+	if released == 0 {
+		return
+	}
+	logrus.Infof("IPAM: released %d restored connections of prefix pool %s, they are not requested again", released, impl.name())
+	if err := impl.save(); err != nil {
+		logrus.Errorf("IPAM: %v", err)
+	}
+}
+
+// name identifies the pool in the store
+func (impl *prefixPool) name() string {
+	return strings.Join(impl.basePrefixes, ",")
+}
+
+// restore allocates the stored connection record, all its prefixes should be available
+func (impl *prefixPool) restore(connectionID string, record *ConnectionRecord) error {
+	_, ipNet, err := net.ParseCIDR(record.GetPrefix())
+	if err != nil {
+		return err
+	}
+	allocated := append([]string{ipNet.String()}, record.GetPrefixes()...)
+	for _, prefix := range allocated {
+		available, err := contains(impl.prefixes, prefix)
+		if err != nil {
+			return err
+		}
+		if !available {
+			return errors.Errorf("prefix %s is not available", prefix)
+		}
+	}
+	remaining, _, err := excludePrefixes(impl.prefixes, allocated)
+	if err != nil {
+		return err
+	}
+	impl.prefixes = remaining
+	impl.connections[connectionID] = &connectionRecord{
+		ipNet:    ipNet,
+		prefixes: record.GetPrefixes(),
+		restored: true,
+	}
+	return nil
+}
+
+// save stores the connections if the pool is persistent
+func (impl *prefixPool) save() error {
+	if impl.store == nil {
+		return nil
+	}
+	records := make(map[string]*ConnectionRecord, len(impl.connections))
+	for id, conn := range impl.connections {
+		records[id] = &ConnectionRecord{
+			Prefix:   conn.ipNet.String(),
+			Prefixes: conn.prefixes,
+		}
+	}
+	if err := impl.store.Save(impl.name(), records); err != nil {
+		return errors.Wrapf(err, "failed to save prefix pool %s", impl.name())
+	}
+	return nil
+}
+
 /* Release excluded prefixes back the pool of available ones */
 func (impl *prefixPool) ReleaseExcludedPrefixes(excludedPrefixes []string) error {
 	impl.Lock()
@@ -73,15 +226,34 @@ func (impl *prefixPool) ReleaseExcludedPrefixes(excludedPrefixes []string) error
 func (impl *prefixPool) ExcludePrefixes(excludedPrefixes []string) ([]string, error) {
 	impl.Lock()
 	defer impl.Unlock()
+
+	remaining, removedPrefixes, err := excludePrefixes(impl.prefixes, excludedPrefixes)
+	if err != nil {
+		return nil, err
+	}
+	/* Raise an error, if there aren't any available prefixes left after excluding */
+	if len(remaining) == 0 {
+		return nil, errEmptyPool()
+	}
+	/* Everything should be fine, update the available prefixes with what's left */
+	impl.prefixes = remaining
+	return removedPrefixes, nil
+}
+
+/* Exclude prefixes from the list of prefixes, returns the remaining prefixes and the actually removed ones */
+func excludePrefixes(prefixes, excludedPrefixes []string) (remaining, removedPrefixes []string, err error) {
 	/* Use a working copy for the available prefixes */
-	copyPrefixes := append([]string{}, impl.prefixes...)
+	copyPrefixes := append([]string{}, prefixes...)
 
-	removedPrefixes := []string{}
+	removedPrefixes = []string{}
 
 	for _, excludedPrefix := range excludedPrefixes {
 		splittedEntries := []string{}
 		prefixesToRemove := []string{}
-		_, subnetExclude, _ := net.ParseCIDR(excludedPrefix)
+		_, subnetExclude, err := net.ParseCIDR(excludedPrefix)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "IPAM: invalid excluded prefix %s", excludedPrefix)
+		}
 
 		/* 1. Check if each excluded entry overlaps with the available prefix */
 		for _, prefix := range copyPrefixes {
@@ -96,7 +268,7 @@ func (impl *prefixPool) ExcludePrefixes(excludedPrefixes []string) ([]string, er
 					/* 1.1.2. If the original entry is bigger, we split it and remove the avoided range */
 					res, err := extractSubnet(subnetPrefix, subnetExclude)
 					if err != nil {
-						return nil, err
+						return nil, nil, err
 					}
 					/* 1.1.3. Collect the resulted split prefixes */
 					splittedEntries = append(splittedEntries, res...)
@@ -108,10 +280,8 @@ func (impl *prefixPool) ExcludePrefixes(excludedPrefixes []string) ([]string, er
 				}
 				/* 1.1.4. Collect prefixes that should be removed from the original pool */
 				prefixesToRemove = append(prefixesToRemove, subnetPrefix.String())
-
-				break
 			}
-			/* 1.2. If not intersecting, proceed verifying the next one */
+			/* 1.2. Proceed verifying the next one, the bigger excluded entry can overlap several prefixes */
 		}
 		/* 2. Keep only the prefixes that should not be removed from the original pool */
 		if len(prefixesToRemove) != 0 {
@@ -132,15 +302,28 @@ func (impl *prefixPool) ExcludePrefixes(excludedPrefixes []string) ([]string, er
 			copyPrefixes = splittedEntries
 		}
 	}
-	/* Raise an error, if there aren't any available prefixes left after excluding */
-	if len(copyPrefixes) == 0 {
-		err := errors.New("IPAM: The available address pool is empty, probably intersected by excludedPrefix")
-		logrus.Errorf("%v", err)
-		return nil, err
+	return copyPrefixes, removedPrefixes, nil
+}
+
+func errEmptyPool() error {
+	err := errors.New("IPAM: The available address pool is empty, probably intersected by excludedPrefix")
+	logrus.Errorf("%v", err)
+	return err
+}
+
+/* Check if the prefix is inside of one of the prefixes */
+func contains(prefixes []string, prefix string) (bool, error) {
+	_, subnet, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return false, err
 	}
-	/* Everything should be fine, update the available prefixes with what's left */
-	impl.prefixes = copyPrefixes
-	return removedPrefixes, nil
+	for _, p := range prefixes {
+		_, sn, _ := net.ParseCIDR(p)
+		if ret, snIsBigger := intersect(sn, subnet); ret && (snIsBigger || sn.String() == subnet.String()) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 /* Split the wider range removing the avoided smaller range from it */
@@ -175,15 +358,39 @@ func extractSubnet(wider, smaller *net.IPNet) ([]string, error) {
 	return append(leftParts, rightParts...), nil
 }
 
-func (impl *prefixPool) Extract(connectionId string, family connectioncontext.IpFamily_Family, requests ...*connectioncontext.ExtraPrefixRequest) (srcIP *net.IPNet, dstIP *net.IPNet, requested []string, err error) {
+func (impl *prefixPool) Extract(connectionId string, family connectioncontext.IpFamily_Family, excludedPrefixes []string, requests ...*connectioncontext.ExtraPrefixRequest) (srcIP *net.IPNet, dstIP *net.IPNet, requested []string, err error) {
 	impl.Lock()
 	defer impl.Unlock()
 
+	/* Connection is already known e.g. it is healed, return its addresses if they still meet the request */
+	if conn, ok := impl.connections[connectionId]; ok {
+		if conn.meets(family, excludedPrefixes, requests) {
+			src, dst, err := connectionAddresses(conn.ipNet)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			conn.restored = false
+			return src, dst, conn.prefixes, nil
+		}
+		if err := impl.release(connectionId); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	/* Filter out the excluded prefixes, they are returned back to the pool once the allocation is done */
+	available, excluded, err := excludePrefixes(impl.prefixes, excludedPrefixes)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(available) == 0 && len(impl.prefixes) != 0 {
+		return nil, nil, nil, errEmptyPool()
+	}
+
 	prefixLen := 30 // At lest 4 addresses
 	if family == connectioncontext.IpFamily_IPV6 {
 		prefixLen = 126
 	}
-	result, remaining, err := ExtractPrefixes(impl.prefixes, &connectioncontext.ExtraPrefixRequest{
+	result, remaining, err := ExtractPrefixes(available, &connectioncontext.ExtraPrefixRequest{
 		RequiredNumber:  1,
 		RequestedNumber: 1,
 		PrefixLen:       uint32(prefixLen),
@@ -193,17 +400,12 @@ func (impl *prefixPool) Extract(connectionId string, family connectioncontext.Ip
 		return nil, nil, nil, err
 	}
 
-	ip, ipNet, err := net.ParseCIDR(result[0])
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	src, err := IncrementIP(ip, ipNet)
+	_, ipNet, err := net.ParseCIDR(result[0])
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	dst, err := IncrementIP(src, ipNet)
+	srcIP, dstIP, err = connectionAddresses(ipNet)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -215,19 +417,110 @@ func (impl *prefixPool) Extract(connectionId string, family connectioncontext.Ip
 		}
 	}
 
+	if len(excluded) != 0 {
+		remaining, err = ReleasePrefixes(remaining, excluded...)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		/* Sort the prefixes, so their order is consistent during unit testing */
+		sort.Slice(remaining, func(i, j int) bool { return remaining[i] < remaining[j] })
+	}
+	previous := impl.prefixes
 	impl.prefixes = remaining
 
 	impl.connections[connectionId] = &connectionRecord{
 		ipNet:    ipNet,
 		prefixes: requested,
 	}
-	return &net.IPNet{IP: src, Mask: ipNet.Mask}, &net.IPNet{IP: dst, Mask: ipNet.Mask}, requested, nil
+	/* The connection does not get the addresses which could be lost on restart */
+	if err := impl.save(); err != nil {
+		impl.prefixes = previous
+		delete(impl.connections, connectionId)
+		return nil, nil, nil, err
+	}
+	return srcIP, dstIP, requested, nil
+}
+
+/* Get source and destination addresses of the connection network */
+func connectionAddresses(ipNet *net.IPNet) (srcIP, dstIP *net.IPNet, err error) {
+	src, err := IncrementIP(ipNet.IP, ipNet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dst, err := IncrementIP(src, ipNet)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &net.IPNet{IP: src, Mask: ipNet.Mask}, &net.IPNet{IP: dst, Mask: ipNet.Mask}, nil
+}
+
+/* Check if the connection has the requested family, required extra prefixes and does not intersect excluded prefixes */
+func (conn *connectionRecord) meets(family connectioncontext.IpFamily_Family, excludedPrefixes []string, requests []*connectioncontext.ExtraPrefixRequest) bool {
+	if (conn.ipNet.IP.To4() == nil) != (family == connectioncontext.IpFamily_IPV6) {
+		return false
+	}
+	allocated := append([]string{conn.ipNet.String()}, conn.prefixes...)
+	for _, excludedPrefix := range excludedPrefixes {
+		_, excludedNet, err := net.ParseCIDR(excludedPrefix)
+		if err != nil {
+			return false
+		}
+		for _, prefix := range allocated {
+			_, prefixNet, _ := net.ParseCIDR(prefix)
+			if ret, _ := intersect(excludedNet, prefixNet); ret {
+				return false
+			}
+		}
+	}
+	/* Count extra prefixes by prefix length, each request should find its required number of them */
+	prefixLens := map[int]int{}
+	for _, prefix := range conn.prefixes {
+		_, prefixNet, _ := net.ParseCIDR(prefix)
+		prefixLen, _ := prefixNet.Mask.Size()
+		prefixLens[prefixLen]++
+	}
+	for _, request := range requests {
+		prefixLen := int(request.GetPrefixLen())
+		if prefixLens[prefixLen] < int(request.GetRequiredNumber()) {
+			return false
+		}
+		prefixLens[prefixLen] -= int(request.GetRequiredNumber())
+	}
+	return true
+}
+
+func (impl *prefixPool) Usage() Usage {
+	impl.RLock()
+	defer impl.RUnlock()
+
+	usage := Usage{
+		FreeAddresses: addressCountFloat(impl.prefixes...),
+		FreePrefixes:  len(impl.prefixes),
+		Prefixes:      append([]string{}, impl.basePrefixes...),
+	}
+	for _, conn := range impl.connections {
+		usage.UsedAddresses += addressCountFloat(conn.ipNet.String()) + addressCountFloat(conn.prefixes...)
+		usage.UsedPrefixes += 1 + len(conn.prefixes)
+	}
+	return usage
 }
 
 func (impl *prefixPool) Release(connectionId string) error {
 	impl.Lock()
 	defer impl.Unlock()
 
+	if err := impl.release(connectionId); err != nil {
+		return err
+	}
+	/* Released addresses are not handed out to the other connections until they are saved */
+	if err := impl.save(); err != nil {
+		logrus.Errorf("IPAM: %v", err)
+	}
+	return nil
+}
+
+func (impl *prefixPool) release(connectionId string) error {
 	conn := impl.connections[connectionId]
 	if conn == nil {
 		return errors.Errorf("Failed to release connection infomration: %s", connectionId)
@@ -264,6 +557,9 @@ func (impl *prefixPool) Intersect(prefix string) (bool, error) {
 		return false, err
 	}
 
+	impl.RLock()
+	defer impl.RUnlock()
+
 	for _, p := range impl.prefixes {
 		_, sn, _ := net.ParseCIDR(p)
 		if ret, _ := intersect(sn, subnet); ret {
@@ -531,6 +827,20 @@ func addressCount(pr string) uint64 {
 	return 1 << (uint64(bits) - uint64(prefixLen))
 }
 
+/* Count addresses without overflow of the IPv6 prefixes wider than /64 */
+func addressCountFloat(prefixes ...string) float64 {
+	var c float64
+	for _, pr := range prefixes {
+		_, network, err := net.ParseCIDR(pr)
+		if err != nil {
+			continue
+		}
+		prefixLen, bits := network.Mask.Size()
+		c += math.Ldexp(1, bits-prefixLen)
+	}
+	return c
+}
+
 func setNetIndexInIP(ip net.IP, num int, prefixLen int) net.IP {
 	ipInt, totalBits := fromIP(ip)
 	bigNum := big.NewInt(int64(num))
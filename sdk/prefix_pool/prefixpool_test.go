@@ -1,7 +1,10 @@
 package prefix_pool
 
 import (
+	"fmt"
+	"math"
 	"net"
+	"sync"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -61,7 +64,7 @@ func testNetExtract(t *testing.T, inPool, srcDesired, dstDesired string, family
 	pool, err := NewPrefixPool(inPool)
 	g.Expect(err).To(BeNil())
 
-	srcIP, dstIP, requested, err := pool.Extract("c1", family)
+	srcIP, dstIP, requested, err := pool.Extract("c1", family, nil)
 	g.Expect(err).To(BeNil())
 	g.Expect(requested).To(BeNil())
 
@@ -429,3 +432,182 @@ func TestExcludePrefixesFullOverlap(t *testing.T) {
 
 	g.Expect(err.Error()).To(Equal("IPAM: The available address pool is empty, probably intersected by excludedPrefix"))
 }
+
+func TestExtractExcludedPrefixes(t *testing.T) {
+	g := NewWithT(t)
+
+	pool, err := NewPrefixPool("10.20.0.0/24")
+	g.Expect(err).To(BeNil())
+
+	srcIP, dstIP, requested, err := pool.Extract("c1", connectioncontext.IpFamily_IPV4, []string{"10.20.0.0/26", "10.32.0.0/16"},
+		&connectioncontext.ExtraPrefixRequest{
+			AddrFamily:      &connectioncontext.IpFamily{Family: connectioncontext.IpFamily_IPV4},
+			RequiredNumber:  1,
+			RequestedNumber: 1,
+			PrefixLen:       28,
+		})
+	g.Expect(err).To(BeNil())
+	g.Expect(srcIP.String()).To(Equal("10.20.0.65/30"))
+	g.Expect(dstIP.String()).To(Equal("10.20.0.66/30"))
+	g.Expect(requested).To(Equal([]string{"10.20.0.80/28"}))
+
+	/* Excluded prefix stays available for the other connections */
+	srcIP, _, _, err = pool.Extract("c2", connectioncontext.IpFamily_IPV4, []string{"10.20.0.64/26", "10.20.0.128/25"})
+	g.Expect(err).To(BeNil())
+	g.Expect(srcIP.String()).To(Equal("10.20.0.1/30"))
+
+	g.Expect(pool.Release("c1")).To(BeNil())
+	g.Expect(pool.Release("c2")).To(BeNil())
+	g.Expect(pool.GetPrefixes()).To(Equal([]string{"10.20.0.0/24"}))
+}
+
+func TestExtractExcludedPrefixesOverlapSeveralPrefixes(t *testing.T) {
+	g := NewWithT(t)
+
+	pool, err := NewPrefixPool("10.20.0.0/24", "10.20.1.0/24", "10.30.0.0/24")
+	g.Expect(err).To(BeNil())
+
+	srcIP, _, _, err := pool.Extract("c1", connectioncontext.IpFamily_IPV4, []string{"10.20.0.0/16"})
+	g.Expect(err).To(BeNil())
+	g.Expect(srcIP.String()).To(Equal("10.30.0.1/30"))
+
+	_, _, _, err = pool.Extract("c2", connectioncontext.IpFamily_IPV4, []string{"10.0.0.0/8"})
+	g.Expect(err).NotTo(BeNil())
+
+	_, _, _, err = pool.Extract("c3", connectioncontext.IpFamily_IPV4, []string{"10.20.0.0"})
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestExtractExcludedPrefixesConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	pool, err := NewPrefixPool("10.20.0.0/20")
+	g.Expect(err).To(BeNil())
+
+	const count = 200
+	exclusions := [][]string{
+		nil,
+		{"10.20.0.0/22"},
+		{"10.20.4.0/22", "10.20.12.0/24"},
+		{"10.20.8.0/21"},
+		{"10.20.0.0/21", "10.20.15.0/24"},
+	}
+
+	type allocation struct {
+		excluded []string
+		ipNet    *net.IPNet
+		prefixes []string
+		err      error
+	}
+	allocations := make([]allocation, count)
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			excluded := exclusions[i%len(exclusions)]
+			srcIP, _, prefixes, err := pool.Extract(fmt.Sprintf("c%d", i), connectioncontext.IpFamily_IPV4, excluded,
+				&connectioncontext.ExtraPrefixRequest{
+					AddrFamily:      &connectioncontext.IpFamily{Family: connectioncontext.IpFamily_IPV4},
+					RequiredNumber:  1,
+					RequestedNumber: 1,
+					PrefixLen:       31,
+				})
+			allocations[i] = allocation{excluded: excluded, prefixes: prefixes, err: err}
+			if err == nil {
+				allocations[i].ipNet = &net.IPNet{IP: srcIP.IP.Mask(srcIP.Mask), Mask: srcIP.Mask}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var allocated []*net.IPNet
+	for i := range allocations {
+		g.Expect(allocations[i].err).To(BeNil())
+		nets := []*net.IPNet{allocations[i].ipNet}
+		for _, prefix := range allocations[i].prefixes {
+			_, prefixNet, err := net.ParseCIDR(prefix)
+			g.Expect(err).To(BeNil())
+			nets = append(nets, prefixNet)
+		}
+		for _, n := range nets {
+			/* Allocation does not intersect the exclusions of its request */
+			for _, excluded := range allocations[i].excluded {
+				_, excludedNet, _ := net.ParseCIDR(excluded)
+				overlap, _ := intersect(n, excludedNet)
+				g.Expect(overlap).To(BeFalse(), "%v intersects excluded %v", n, excludedNet)
+			}
+			/* Allocation does not intersect the other allocations */
+			for _, other := range allocated {
+				overlap, _ := intersect(n, other)
+				g.Expect(overlap).To(BeFalse(), "%v intersects %v", n, other)
+			}
+			allocated = append(allocated, n)
+		}
+	}
+	g.Expect(AddressCount(pool.GetPrefixes()...)).To(Equal(uint64(4096 - count*(4+2))))
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			g.Expect(pool.Release(fmt.Sprintf("c%d", i))).To(BeNil())
+		}(i)
+	}
+	wg.Wait()
+	g.Expect(pool.GetPrefixes()).To(Equal([]string{"10.20.0.0/20"}))
+}
+
+func TestExtractSameConnection(t *testing.T) {
+	g := NewWithT(t)
+
+	pool, err := NewPrefixPool("10.20.0.0/24")
+	g.Expect(err).To(BeNil())
+
+	srcIP1, dstIP1, _, err := pool.Extract("c1", connectioncontext.IpFamily_IPV4, nil)
+	g.Expect(err).To(BeNil())
+
+	srcIP, dstIP, _, err := pool.Extract("c1", connectioncontext.IpFamily_IPV4, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(srcIP.String()).To(Equal(srcIP1.String()))
+	g.Expect(dstIP.String()).To(Equal(dstIP1.String()))
+
+	/* Addresses are allocated again if they are excluded now */
+	srcIP, _, _, err = pool.Extract("c1", connectioncontext.IpFamily_IPV4, []string{srcIP1.String()})
+	g.Expect(err).To(BeNil())
+	g.Expect(srcIP.String()).NotTo(Equal(srcIP1.String()))
+
+	g.Expect(pool.Release("c1")).To(BeNil())
+	g.Expect(pool.GetPrefixes()).To(Equal([]string{"10.20.0.0/24"}))
+}
+
+func TestUsage(t *testing.T) {
+	g := NewWithT(t)
+
+	pool, err := NewPrefixPool("10.20.0.0/24")
+	g.Expect(err).To(BeNil())
+	g.Expect(pool.Usage()).To(Equal(Usage{FreeAddresses: 256, FreePrefixes: 1, Prefixes: []string{"10.20.0.0/24"}}))
+
+	_, _, _, err = pool.Extract("c1", connectioncontext.IpFamily_IPV4, nil, &connectioncontext.ExtraPrefixRequest{
+		AddrFamily:      &connectioncontext.IpFamily{Family: connectioncontext.IpFamily_IPV4},
+		RequiredNumber:  1,
+		RequestedNumber: 1,
+		PrefixLen:       28,
+	})
+	g.Expect(err).To(BeNil())
+	usage := pool.Usage()
+	g.Expect(usage.UsedAddresses).To(Equal(float64(20)))
+	g.Expect(usage.FreeAddresses).To(Equal(float64(236)))
+	g.Expect(usage.UsedPrefixes).To(Equal(2))
+	g.Expect(usage.Utilization()).To(BeNumerically("~", 20.0/256))
+	g.Expect(usage.Prefixes).To(Equal([]string{"10.20.0.0/24"}))
+
+	g.Expect(pool.Release("c1")).To(BeNil())
+	g.Expect(pool.Usage()).To(Equal(Usage{FreeAddresses: 256, FreePrefixes: 1, Prefixes: []string{"10.20.0.0/24"}}))
+
+	/* IPv6 pools do not overflow */
+	pool, err = NewPrefixPool("fd00::/64")
+	g.Expect(err).To(BeNil())
+	g.Expect(pool.Usage().FreeAddresses).To(Equal(math.Ldexp(1, 64)))
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prefix_pool
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Store keeps connection records of the prefix pools, so the connections get the same addresses after restart
+type Store interface {
+	// Load returns connection records of the pool by connection id
+	Load(pool string) (map[string]*ConnectionRecord, error)
+	// Save replaces connection records of the pool
+	Save(pool string, records map[string]*ConnectionRecord) error
+}
+
+// ConnectionRecord is the stored allocation of the connection
+type ConnectionRecord struct {
+	// Prefix is the network of the connection source and destination addresses
+	Prefix string `json:"prefix"`
+	// Prefixes are the extra prefixes of the connection
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+// GetPrefix returns prefix of the connection
+func (r *ConnectionRecord) GetPrefix() string {
+	if r == nil {
+		return ""
+	}
+	return r.Prefix
+}
+
+// GetPrefixes returns extra prefixes of the connection
+func (r *ConnectionRecord) GetPrefixes() []string {
+	if r == nil {
+		return nil
+	}
+	return r.Prefixes
+}
+
+type fileStore struct {
+	sync.Mutex
+	path string
+}
+
+// NewFileStore creates a store keeping the pools in the JSON file
+func NewFileStore(path string) Store {
+	return &fileStore{
+		path: path,
+	}
+}
+
+func (s *fileStore) Load(pool string) (map[string]*ConnectionRecord, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	pools, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return pools[pool], nil
+}
+
+func (s *fileStore) Save(pool string, records map[string]*ConnectionRecord) error {
+	s.Lock()
+	defer s.Unlock()
+
+	pools, err := s.read()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		delete(pools, pool)
+	} else {
+		pools[pool] = records
+	}
+	data, err := json.Marshal(pools)
+	if err != nil {
+		return err
+	}
+	/* Write a temporary file and rename it, so the file is never left half written */
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path))
+	if err != nil {
+		return errors.Wrap(err, "failed to create IPAM store file")
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return errors.Wrapf(err, "failed to write IPAM store file %s", tmp.Name())
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write IPAM store file %s", tmp.Name())
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *fileStore) read() (map[string]map[string]*ConnectionRecord, error) {
+	pools := map[string]map[string]*ConnectionRecord{}
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return pools, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read IPAM store file %s", s.path)
+	}
+	if len(data) == 0 {
+		return pools, nil
+	}
+	if err := json.Unmarshal(data, &pools); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse IPAM store file %s", s.path)
+	}
+	return pools, nil
+}
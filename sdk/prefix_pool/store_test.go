@@ -0,0 +1,155 @@
+package prefix_pool
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+)
+
+func newTestFileStore(g *WithT) (Store, func()) {
+	dir, err := ioutil.TempDir("", "ipam-store")
+	g.Expect(err).To(BeNil())
+	return NewFileStore(filepath.Join(dir, "ipam.json")), func() { _ = os.RemoveAll(dir) }
+}
+
+func TestFileStore(t *testing.T) {
+	g := NewWithT(t)
+
+	store, cleanup := newTestFileStore(g)
+	defer cleanup()
+
+	records, err := store.Load("10.20.0.0/24")
+	g.Expect(err).To(BeNil())
+	g.Expect(records).To(BeEmpty())
+
+	g.Expect(store.Save("10.20.0.0/24", map[string]*ConnectionRecord{
+		"c1": {Prefix: "10.20.0.0/30", Prefixes: []string{"10.20.0.16/28"}},
+	})).To(BeNil())
+	g.Expect(store.Save("fd00::/120", map[string]*ConnectionRecord{
+		"c1": {Prefix: "fd00::/126"},
+	})).To(BeNil())
+
+	records, err = store.Load("10.20.0.0/24")
+	g.Expect(err).To(BeNil())
+	g.Expect(records).To(Equal(map[string]*ConnectionRecord{
+		"c1": {Prefix: "10.20.0.0/30", Prefixes: []string{"10.20.0.16/28"}},
+	}))
+
+	g.Expect(store.Save("10.20.0.0/24", nil)).To(BeNil())
+	records, err = store.Load("10.20.0.0/24")
+	g.Expect(err).To(BeNil())
+	g.Expect(records).To(BeEmpty())
+	records, err = store.Load("fd00::/120")
+	g.Expect(err).To(BeNil())
+	g.Expect(records).To(HaveLen(1))
+}
+
+func TestPersistentPrefixPoolRestart(t *testing.T) {
+	g := NewWithT(t)
+
+	store, cleanup := newTestFileStore(g)
+	defer cleanup()
+
+	request := &connectioncontext.ExtraPrefixRequest{
+		AddrFamily:      &connectioncontext.IpFamily{Family: connectioncontext.IpFamily_IPV4},
+		RequiredNumber:  1,
+		RequestedNumber: 1,
+		PrefixLen:       28,
+	}
+
+	pool, err := NewPersistentPrefixPool(store, 0, "10.20.0.0/24")
+	g.Expect(err).To(BeNil())
+	srcIP1, dstIP1, requested1, err := pool.Extract("c1", connectioncontext.IpFamily_IPV4, nil, request)
+	g.Expect(err).To(BeNil())
+	srcIP2, _, _, err := pool.Extract("c2", connectioncontext.IpFamily_IPV4, nil)
+	g.Expect(err).To(BeNil())
+	prefixes := pool.GetPrefixes()
+
+	/* Pool is created again after the endpoint restart */
+	pool, err = NewPersistentPrefixPool(store, 0, "10.20.0.0/24")
+	g.Expect(err).To(BeNil())
+	g.Expect(pool.GetPrefixes()).To(ConsistOf(prefixes))
+
+	/* New connection does not get the stored addresses */
+	srcIP3, _, _, err := pool.Extract("c3", connectioncontext.IpFamily_IPV4, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(srcIP3.String()).NotTo(Equal(srcIP1.String()))
+	g.Expect(srcIP3.String()).NotTo(Equal(srcIP2.String()))
+
+	/* Healed connection gets its previous addresses */
+	srcIP, dstIP, requested, err := pool.Extract("c1", connectioncontext.IpFamily_IPV4, nil, request)
+	g.Expect(err).To(BeNil())
+	g.Expect(srcIP.String()).To(Equal(srcIP1.String()))
+	g.Expect(dstIP.String()).To(Equal(dstIP1.String()))
+	g.Expect(requested).To(Equal(requested1))
+
+	g.Expect(pool.Release("c1")).To(BeNil())
+	g.Expect(pool.Release("c2")).To(BeNil())
+	g.Expect(pool.Release("c3")).To(BeNil())
+	g.Expect(pool.GetPrefixes()).To(Equal([]string{"10.20.0.0/24"}))
+
+	records, err := store.Load("10.20.0.0/24")
+	g.Expect(err).To(BeNil())
+	g.Expect(records).To(BeEmpty())
+}
+
+func TestPersistentPrefixPoolSkipsUnavailableRecords(t *testing.T) {
+	g := NewWithT(t)
+
+	store, cleanup := newTestFileStore(g)
+	defer cleanup()
+
+	g.Expect(store.Save("10.20.0.0/24", map[string]*ConnectionRecord{
+		"c1": {Prefix: "10.20.0.0/30"},
+		"c2": {Prefix: "10.20.0.0/30"},
+		"c3": {Prefix: "10.30.0.0/30"},
+	})).To(BeNil())
+
+	pool, err := NewPersistentPrefixPool(store, 0, "10.20.0.0/24")
+	g.Expect(err).To(BeNil())
+
+	srcIP, _, _, err := pool.Extract("c1", connectioncontext.IpFamily_IPV4, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(srcIP.String()).To(Equal("10.20.0.1/30"))
+	srcIP, _, _, err = pool.Extract("c2", connectioncontext.IpFamily_IPV4, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(srcIP.String()).NotTo(Equal("10.20.0.1/30"))
+}
+
+func TestPersistentPrefixPoolReleasesNotRequested(t *testing.T) {
+	g := NewWithT(t)
+
+	store, cleanup := newTestFileStore(g)
+	defer cleanup()
+
+	pool, err := NewPersistentPrefixPool(store, 0, "10.20.0.0/24")
+	g.Expect(err).To(BeNil())
+	srcIP1, _, _, err := pool.Extract("c1", connectioncontext.IpFamily_IPV4, nil)
+	g.Expect(err).To(BeNil())
+	_, _, _, err = pool.Extract("c2", connectioncontext.IpFamily_IPV4, nil)
+	g.Expect(err).To(BeNil())
+
+	/* Only c1 is requested again after the restart, c2 got a new ID */
+	pool, err = NewPersistentPrefixPool(store, 100*time.Millisecond, "10.20.0.0/24")
+	g.Expect(err).To(BeNil())
+	srcIP, _, _, err := pool.Extract("c1", connectioncontext.IpFamily_IPV4, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(srcIP.String()).To(Equal(srcIP1.String()))
+
+	g.Eventually(func() map[string]*ConnectionRecord {
+		records, loadErr := store.Load("10.20.0.0/24")
+		g.Expect(loadErr).To(BeNil())
+		return records
+	}, time.Second, 10*time.Millisecond).Should(And(HaveLen(1), HaveKey("c1")))
+	_, _, err = pool.GetConnectionInformation("c2")
+	g.Expect(err).NotTo(BeNil())
+
+	g.Expect(pool.Release("c1")).To(BeNil())
+	g.Expect(pool.GetPrefixes()).To(Equal([]string{"10.20.0.0/24"}))
+}
@@ -0,0 +1,36 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+	"github.com/networkservicemesh/networkservicemesh/sdk/endpoint"
+)
+
+func TestAddDnsConfigDstIpDualStack(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &connection.Connection{
+			Id: "1",
+			Context: &connectioncontext.ConnectionContext{
+				IpContext: &connectioncontext.IPContext{
+					DstIpAddr:       "172.16.1.2/30",
+					ExtraDstIpAddrs: []string{"fd00::2/126"},
+				},
+			},
+		},
+	}
+	conn, err := endpoint.NewAddDnsConfigDstIp("my.domain").Request(context.Background(), request)
+	g.Expect(err).To(gomega.BeNil())
+
+	configs := conn.GetContext().GetDnsContext().GetConfigs()
+	g.Expect(configs).To(gomega.HaveLen(1))
+	g.Expect(configs[0].GetDnsServerIps()).To(gomega.Equal([]string{"172.16.1.2", "fd00::2"}))
+	g.Expect(configs[0].GetSearchDomains()).To(gomega.Equal([]string{"my.domain"}))
+}
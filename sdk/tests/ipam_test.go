@@ -0,0 +1,215 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+	"github.com/networkservicemesh/networkservicemesh/sdk/common"
+	"github.com/networkservicemesh/networkservicemesh/sdk/endpoint"
+)
+
+func newIpamRequest(id string, extraPrefixRequests ...*connectioncontext.ExtraPrefixRequest) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &connection.Connection{
+			Id:             id,
+			NetworkService: "my_network_service",
+			Context: &connectioncontext.ConnectionContext{
+				IpContext: &connectioncontext.IPContext{
+					SrcIpRequired:      true,
+					DstIpRequired:      true,
+					ExtraPrefixRequest: extraPrefixRequests,
+				},
+			},
+		},
+	}
+}
+
+func TestIpamSingleStack(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ipam := endpoint.NewIpamEndpoint(&common.NSConfiguration{IPAddress: "10.20.1.0/24"})
+	g.Expect(ipam.ExtraPrefixPool).To(gomega.BeNil())
+
+	conn, err := ipam.Request(context.Background(), newIpamRequest("1"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(conn.GetContext().GetIpContext().GetSrcIpAddr()).To(gomega.Equal("10.20.1.1/30"))
+	g.Expect(conn.GetContext().GetIpContext().GetDstIpAddr()).To(gomega.Equal("10.20.1.2/30"))
+	g.Expect(conn.GetContext().GetIpContext().GetExtraSrcIpAddrs()).To(gomega.BeEmpty())
+	g.Expect(conn.GetContext().GetIpContext().GetExtraDstIpAddrs()).To(gomega.BeEmpty())
+}
+
+func TestIpamDualStack(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ipam := endpoint.NewIpamEndpoint(&common.NSConfiguration{IPAddress: "10.20.1.0/24, fd00::/120"})
+	g.Expect(ipam.PrefixPool.GetPrefixes()).To(gomega.Equal([]string{"10.20.1.0/24"}))
+	g.Expect(ipam.ExtraPrefixPool.GetPrefixes()).To(gomega.Equal([]string{"fd00::/120"}))
+
+	conn, err := ipam.Request(context.Background(), newIpamRequest("1",
+		&connectioncontext.ExtraPrefixRequest{
+			AddrFamily:      &connectioncontext.IpFamily{Family: connectioncontext.IpFamily_IPV4},
+			PrefixLen:       30,
+			RequiredNumber:  1,
+			RequestedNumber: 1,
+		},
+		&connectioncontext.ExtraPrefixRequest{
+			AddrFamily:      &connectioncontext.IpFamily{Family: connectioncontext.IpFamily_IPV6},
+			PrefixLen:       126,
+			RequiredNumber:  1,
+			RequestedNumber: 1,
+		}))
+	g.Expect(err).To(gomega.BeNil())
+
+	ipContext := conn.GetContext().GetIpContext()
+	g.Expect(ipContext.SrcIPAddrs()).To(gomega.Equal([]string{"10.20.1.1/30", "fd00::1/126"}))
+	g.Expect(ipContext.DstIPAddrs()).To(gomega.Equal([]string{"10.20.1.2/30", "fd00::2/126"}))
+	g.Expect(ipContext.GetExtraPrefixes()).To(gomega.Equal([]string{"10.20.1.4/30", "fd00::4/126"}))
+	g.Expect(conn.GetContext().IsValid()).To(gomega.BeNil())
+
+	_, err = ipam.Close(context.Background(), conn)
+	g.Expect(err).To(gomega.BeNil())
+	_, _, err = ipam.PrefixPool.GetConnectionInformation("1")
+	g.Expect(err).NotTo(gomega.BeNil())
+	_, _, err = ipam.ExtraPrefixPool.GetConnectionInformation("1")
+	g.Expect(err).NotTo(gomega.BeNil())
+}
+
+func TestIpamDualStackExhausted(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ipam := endpoint.NewIpamEndpoint(&common.NSConfiguration{IPAddress: "fd00::/120,10.20.1.0/30"})
+	g.Expect(ipam.PrefixPool.GetPrefixes()).To(gomega.Equal([]string{"fd00::/120"}))
+
+	conn, err := ipam.Request(context.Background(), newIpamRequest("1"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(conn.GetContext().GetIpContext().GetSrcIpAddr()).To(gomega.Equal("fd00::1/126"))
+	g.Expect(conn.GetContext().GetIpContext().GetExtraSrcIpAddrs()).To(gomega.Equal([]string{"10.20.1.1/30"}))
+
+	_, err = ipam.Request(context.Background(), newIpamRequest("2"))
+	g.Expect(err).NotTo(gomega.BeNil())
+	_, _, err = ipam.PrefixPool.GetConnectionInformation("2")
+	g.Expect(err).NotTo(gomega.BeNil())
+}
+
+func TestIpamConcurrentExcludedPrefixes(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ipam := endpoint.NewIpamEndpoint(&common.NSConfiguration{IPAddress: "10.20.0.0/22,fd00::/118"})
+	exclusions := [][]string{
+		{"10.20.0.0/23"},
+		{"10.20.2.0/23", "fd00::/119"},
+		{"10.20.1.0/24", "fd00::200/120"},
+	}
+
+	const count = 60
+	conns := make([]*connection.Connection, count)
+	errs := make([]error, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			request := newIpamRequest(strconv.Itoa(i))
+			request.GetConnection().GetContext().GetIpContext().ExcludedPrefixes = exclusions[i%len(exclusions)]
+			conns[i], errs[i] = ipam.Request(context.Background(), request)
+		}(i)
+	}
+	wg.Wait()
+
+	assigned := map[string]bool{}
+	for i, conn := range conns {
+		g.Expect(errs[i]).To(gomega.BeNil())
+		ipContext := conn.GetContext().GetIpContext()
+		for _, addr := range append(ipContext.SrcIPAddrs(), ipContext.DstIPAddrs()...) {
+			ip, _, err := net.ParseCIDR(addr)
+			g.Expect(err).To(gomega.BeNil())
+			g.Expect(assigned).NotTo(gomega.HaveKey(ip.String()))
+			assigned[ip.String()] = true
+			for _, excluded := range exclusions[i%len(exclusions)] {
+				_, excludedNet, _ := net.ParseCIDR(excluded)
+				g.Expect(excludedNet.Contains(ip)).To(gomega.BeFalse(), "%v is excluded by %v", ip, excludedNet)
+			}
+		}
+	}
+	g.Expect(assigned).To(gomega.HaveLen(count * 4))
+}
+
+func TestIpamHighWatermark(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ipam := endpoint.NewIpamEndpoint(&common.NSConfiguration{IPAddress: "10.30.1.0/28", IPAMHighWatermark: 50})
+
+	conn, err := ipam.Request(context.Background(), newIpamRequest("1"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(conn.GetContext().GetExtraContext()).NotTo(gomega.HaveKey(endpoint.IpamWarningKey))
+
+	conn, err = ipam.Request(context.Background(), newIpamRequest("2"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(conn.GetContext().GetExtraContext()).To(gomega.HaveKeyWithValue(endpoint.IpamWarningKey,
+		"IPAM pool 10.30.1.0/28 is 50% used: 8 addresses and 1 prefixes are free"))
+
+	/* Warning is removed once the pool usage is below the high watermark */
+	_, err = ipam.Close(context.Background(), conn)
+	g.Expect(err).To(gomega.BeNil())
+	conn, err = ipam.Request(context.Background(), newIpamRequest("1"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(conn.GetContext().GetExtraContext()).NotTo(gomega.HaveKey(endpoint.IpamWarningKey))
+}
+
+func TestIpamMetrics(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ipam := endpoint.NewIpamEndpoint(&common.NSConfiguration{
+		EndpointNetworkService: "ipam-metrics",
+		IPAddress:              "10.30.2.0/24,fd00::/120",
+	})
+	_, err := ipam.Request(context.Background(), newIpamRequest("1",
+		&connectioncontext.ExtraPrefixRequest{
+			AddrFamily:      &connectioncontext.IpFamily{Family: connectioncontext.IpFamily_IPV4},
+			PrefixLen:       28,
+			RequiredNumber:  1,
+			RequestedNumber: 1,
+		}))
+	g.Expect(err).To(gomega.BeNil())
+
+	gauges := gatherIpamMetrics(g, "ipam-metrics")
+	g.Expect(gauges).To(gomega.Equal(map[string]float64{
+		endpoint.IpamUsedAddressesMetric + "/10.30.2.0/24": 20,
+		endpoint.IpamFreeAddressesMetric + "/10.30.2.0/24": 236,
+		endpoint.IpamUsedPrefixesMetric + "/10.30.2.0/24":  2,
+		endpoint.IpamFreePrefixesMetric + "/10.30.2.0/24":  5,
+		endpoint.IpamUsedAddressesMetric + "/fd00::/120":   4,
+		endpoint.IpamFreeAddressesMetric + "/fd00::/120":   252,
+		endpoint.IpamUsedPrefixesMetric + "/fd00::/120":    1,
+		endpoint.IpamFreePrefixesMetric + "/fd00::/120":    6,
+	}))
+}
+
+/* Gather IPAM metrics of the Network Service from the default registry by metric name and pool */
+func gatherIpamMetrics(g *gomega.WithT, networkService string) map[string]float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	g.Expect(err).To(gomega.BeNil())
+
+	gauges := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels[endpoint.IpamNetworkServiceKey] == networkService {
+				gauges[family.GetName()+"/"+labels[endpoint.IpamPoolKey]] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	return gauges
+}
@@ -17,9 +17,7 @@ package vppagent
 
 import (
 	"context"
-	"net"
-	"strconv"
-	"strings"
+	"sync"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/pkg/errors"
@@ -29,24 +27,15 @@ import (
 
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
-	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
 	"github.com/networkservicemesh/networkservicemesh/sdk/endpoint"
-)
-
-const (
-	action     = "action"     // DENY, PERMIT, REFLECT
-	dstNet     = "dstnet"     // IPv4 or IPv6 CIDR
-	srcNet     = "srcnet"     // IPv4 or IPv6 CIDR
-	icmpType   = "icmptype"   // 8-bit unsigned integer
-	tcpLowPort = "tcplowport" // 16-bit unsigned integer
-	tcpUpPort  = "tcpupport"  // 16-bit unsigned integer
-	udpLowPort = "udplowport" // 16-bit unsigned integer
-	udpUpPort  = "udpupport"  // 16-bit unsigned integer
+	"github.com/networkservicemesh/networkservicemesh/sdk/firewall"
 )
 
 // ACL is a VPP Agent ACL composite
 type ACL struct {
-	Rules map[string]string
+	mtx    sync.RWMutex
+	config *firewall.Config
+	err    error
 }
 
 // Request implements the request handler
@@ -67,7 +56,7 @@ func (a *ACL) Request(ctx context.Context, request *networkservice.NetworkServic
 		return nil, err
 	}
 
-	err := a.appendDataChange(vppAgentConfig, iface.Name)
+	err := a.appendDataChange(vppAgentConfig, iface.Name, a.bindContexts(ctx, request.GetConnection()))
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +86,7 @@ func (a *ACL) Close(ctx context.Context, connection *connection.Connection) (*em
 		return nil, err
 	}
 
-	err := a.appendDataChange(vppAgentConfig, iface.Name)
+	err := a.appendDataChange(vppAgentConfig, iface.Name, a.bindContexts(ctx, connection))
 	if err != nil {
 		return nil, err
 	}
@@ -112,227 +101,177 @@ func (a *ACL) Name() string {
 	return "acl"
 }
 
-// NewACL creates an ACL
+// NewACL creates an ACL from the named key-value rules, f.e. "action=reflect,tcplowport=80,tcpupport=80".
+// Invalid rules fail the requests.
 func NewACL(rules map[string]string) *ACL {
+	config, err := firewall.FromKeyValueRules(rules)
 	return &ACL{
-		Rules: rules,
+		config: config,
+		err:    err,
 	}
 }
 
-func (a *ACL) appendDataChange(rv *configurator.Config, ingressInterface string) error {
-	if rv == nil {
-		return errors.New("ACL.appendDataChange cannot be called with rv == nil")
-	}
-	if rv.VppConfig == nil {
-		rv.VppConfig = &vpp.ConfigData{}
-	}
-	if len(a.Rules) == 0 {
-		return nil
+// NewFirewallACL creates an ACL from the firewall configuration, configuration is validated
+func NewFirewallACL(config *firewall.Config) (*ACL, error) {
+	a := &ACL{}
+	if err := a.Update(config); err != nil {
+		return nil, err
 	}
+	return a, nil
+}
 
-	rules := []*acl.ACL_Rule{}
-
-	for _, rule := range a.Rules {
-		parsed := tools.ParseKVStringToMap(rule, ",", "=")
-
-		action, err := getAction(parsed)
-		if err != nil {
-			return errors.Errorf("parsing rule %s failed with %v", rule, err)
-		}
-
-		match, err := getMatch(parsed)
-		if err != nil {
-			return errors.Errorf("parsing rule %s failed with %v", rule, err)
-		}
-
-		match.Action = action
-		rules = append(rules, match)
+// Update validates and replaces the firewall configuration, it is applied to the following requests
+func (a *ACL) Update(config *firewall.Config) error {
+	if err := config.Validate(); err != nil {
+		return errors.Wrap(err, "invalid firewall configuration")
 	}
 
-	name := "ingress-acl-" + ingressInterface
-
-	rv.VppConfig.Acls = append(rv.VppConfig.Acls, &acl.ACL{
-		Name:  name,
-		Rules: rules,
-		Interfaces: &acl.ACL_Interfaces{
-			Egress:  []string{},
-			Ingress: []string{ingressInterface},
-		},
-	})
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
 
+	a.config = config
+	a.err = nil
 	return nil
 }
 
-func getAction(parsed map[string]string) (acl.ACL_Rule_Action, error) {
-	actionName, ok := parsed[action]
-	if !ok {
-		return acl.ACL_Rule_Action(0), errors.New("rule should have 'action' set")
+// bindContexts returns the connection addresses of each IP family, pass-through endpoints get them from the outgoing
+// connection
+func (a *ACL) bindContexts(ctx context.Context, conn *connection.Connection) []*firewall.Context {
+	ipContext := conn.GetContext().GetIpContext()
+	if ipContext.GetSrcIpAddr() == "" && ipContext.GetDstIpAddr() == "" {
+		ipContext = endpoint.ClientConnection(ctx).GetContext().GetIpContext()
 	}
-	action, ok := acl.ACL_Rule_Action_value[strings.ToUpper(actionName)]
-	if !ok {
-		return acl.ACL_Rule_Action(0), errors.New("rule should have a valid 'action'")
-	}
-	return acl.ACL_Rule_Action(action), nil
+	return firewall.NewContexts(ipContext)
 }
 
-func getIP(parsed map[string]string) (*acl.ACL_Rule_IpRule_Ip, error) {
-	dstNet, dstNetOk := parsed[dstNet]
-	srcNet, srcNetOk := parsed[srcNet]
-	if dstNetOk {
-		_, _, err := net.ParseCIDR(dstNet)
-		if err != nil {
-			return nil, errors.Errorf("dstnet is not a valid CIDR [%v]. Failed with: %v", dstNet, err)
-		}
-	} else {
-		dstNet = ""
+func (a *ACL) appendDataChange(rv *configurator.Config, ifaceName string, bindContexts []*firewall.Context) error {
+	if rv == nil {
+		return errors.New("ACL.appendDataChange cannot be called with rv == nil")
 	}
-
-	if srcNetOk {
-		_, _, err := net.ParseCIDR(srcNet)
-		if err != nil {
-			return nil, errors.Errorf("srcnet is not a valid CIDR [%v]. Failed with: %v", srcNet, err)
-		}
-	} else {
-		srcNet = ""
+	if rv.VppConfig == nil {
+		rv.VppConfig = &vpp.ConfigData{}
 	}
 
-	if dstNetOk || srcNetOk {
-		return &acl.ACL_Rule_IpRule_Ip{
-			DestinationNetwork: dstNet,
-			SourceNetwork:      srcNet,
-		}, nil
-	}
-	return nil, nil
-}
+	a.mtx.RLock()
+	config, err := a.config, a.err
+	a.mtx.RUnlock()
 
-func getICMP(parsed map[string]string) (*acl.ACL_Rule_IpRule_Icmp, error) {
-	icmpType, ok := parsed[icmpType]
-	if !ok {
-		return nil, nil
+	if err != nil {
+		return err
 	}
-	icmpType8, err := strconv.ParseUint(icmpType, 10, 8)
+	matches, err := config.Bind(bindContexts...)
 	if err != nil {
-		return nil, errors.Errorf("failed parsing icmptype [%v] with: %v", icmpType, err)
+		return errors.Wrapf(err, "failed to bind firewall rules to the %s interface", ifaceName)
 	}
-	return &acl.ACL_Rule_IpRule_Icmp{
-		Icmpv6: false,
-		IcmpCodeRange: &acl.ACL_Rule_IpRule_Icmp_Range{
-			First: uint32(0),
-			Last:  uint32(65535),
-		},
-		IcmpTypeRange: &acl.ACL_Rule_IpRule_Icmp_Range{
-			First: uint32(icmpType8),
-			Last:  uint32(icmpType8),
-		},
-	}, nil
-}
 
-func getPort(name string, parsed map[string]string) (uint16, bool, error) {
-	port, ok := parsed[name]
-	if !ok {
-		return 0, false, nil
+	var ingress, egress []*acl.ACL_Rule
+	for _, match := range matches {
+		rule := getRule(match)
+		if match.Direction == firewall.DirectionEgress {
+			egress = append(egress, rule)
+		} else {
+			ingress = append(ingress, rule)
+		}
 	}
-	port16, err := strconv.ParseUint(port, 10, 16)
-	if err != nil {
-		return 0, true, errors.Errorf("failed parsing %s [%v] with: %v", name, port, err)
+
+	if len(ingress) != 0 {
+		rv.VppConfig.Acls = append(rv.VppConfig.Acls, &acl.ACL{
+			Name:  "ingress-acl-" + ifaceName,
+			Rules: ingress,
+			Interfaces: &acl.ACL_Interfaces{
+				Egress:  []string{},
+				Ingress: []string{ifaceName},
+			},
+		})
+	}
+	if len(egress) != 0 {
+		rv.VppConfig.Acls = append(rv.VppConfig.Acls, &acl.ACL{
+			Name:  "egress-acl-" + ifaceName,
+			Rules: egress,
+			Interfaces: &acl.ACL_Interfaces{
+				Egress:  []string{ifaceName},
+				Ingress: []string{},
+			},
+		})
 	}
 
-	return uint16(port16), true, nil
+	return nil
 }
 
-func getTCP(parsed map[string]string) (*acl.ACL_Rule_IpRule_Tcp, error) {
-	lowerPort, lpFound, lpErr := getPort(tcpLowPort, parsed)
-	if !lpFound {
-		return nil, nil
-	} else if lpErr != nil {
-		return nil, lpErr
+func getRule(match *firewall.Match) *acl.ACL_Rule {
+	return &acl.ACL_Rule{
+		Action:    getAction(match.Action),
+		IpRule:    getIPRule(match),
+		MacipRule: nil,
 	}
+}
 
-	upperPort, upFound, upErr := getPort(tcpUpPort, parsed)
-	if !upFound {
-		return nil, nil
-	} else if upErr != nil {
-		return nil, lpErr
+func getAction(action string) acl.ACL_Rule_Action {
+	switch action {
+	case firewall.ActionPermit:
+		return acl.ACL_Rule_PERMIT
+	case firewall.ActionReflect:
+		return acl.ACL_Rule_REFLECT
+	default:
+		return acl.ACL_Rule_DENY
 	}
-
-	return &acl.ACL_Rule_IpRule_Tcp{
-		DestinationPortRange: &acl.ACL_Rule_IpRule_PortRange{
-			LowerPort: uint32(lowerPort),
-			UpperPort: uint32(upperPort),
-		},
-		SourcePortRange: &acl.ACL_Rule_IpRule_PortRange{
-			LowerPort: uint32(0),
-			UpperPort: uint32(65535),
-		},
-		TcpFlagsMask:  0,
-		TcpFlagsValue: 0,
-	}, nil
 }
 
-func getUDP(parsed map[string]string) (*acl.ACL_Rule_IpRule_Udp, error) {
-	lowerPort, lpFound, lpErr := getPort(udpLowPort, parsed)
-	if !lpFound {
-		return nil, nil
-	} else if lpErr != nil {
-		return nil, lpErr
+func getIPRule(match *firewall.Match) *acl.ACL_Rule_IpRule {
+	ipRule := &acl.ACL_Rule_IpRule{}
+
+	if match.Source != nil || match.Destination != nil {
+		ipRule.Ip = &acl.ACL_Rule_IpRule_Ip{}
+		if match.Source != nil {
+			ipRule.Ip.SourceNetwork = match.Source.String()
+		}
+		if match.Destination != nil {
+			ipRule.Ip.DestinationNetwork = match.Destination.String()
+		}
 	}
 
-	upperPort, upFound, upErr := getPort(udpUpPort, parsed)
-	if !upFound {
-		return nil, nil
-	} else if upErr != nil {
-		return nil, lpErr
+	switch match.Protocol {
+	case firewall.ProtocolICMP, firewall.ProtocolICMPv6:
+		ipRule.Icmp = getICMP(match)
+	case firewall.ProtocolTCP:
+		ipRule.Tcp = &acl.ACL_Rule_IpRule_Tcp{
+			DestinationPortRange: getPortRange(match.DestinationPorts),
+			SourcePortRange:      getPortRange(match.SourcePorts),
+			TcpFlagsMask:         0,
+			TcpFlagsValue:        0,
+		}
+	case firewall.ProtocolUDP:
+		ipRule.Udp = &acl.ACL_Rule_IpRule_Udp{
+			DestinationPortRange: getPortRange(match.DestinationPorts),
+			SourcePortRange:      getPortRange(match.SourcePorts),
+		}
 	}
 
-	return &acl.ACL_Rule_IpRule_Udp{
-		DestinationPortRange: &acl.ACL_Rule_IpRule_PortRange{
-			LowerPort: uint32(lowerPort),
-			UpperPort: uint32(upperPort),
-		},
-		SourcePortRange: &acl.ACL_Rule_IpRule_PortRange{
-			LowerPort: uint32(0),
-			UpperPort: uint32(65535),
-		},
-	}, nil
+	return ipRule
 }
 
-func getIPRule(parsed map[string]string) (*acl.ACL_Rule_IpRule, error) {
-	ip, err := getIP(parsed)
-	if err != nil {
-		return nil, err
-	}
-
-	icmp, err := getICMP(parsed)
-	if err != nil {
-		return nil, err
+func getICMP(match *firewall.Match) *acl.ACL_Rule_IpRule_Icmp {
+	typeRange := &acl.ACL_Rule_IpRule_Icmp_Range{
+		First: uint32(0),
+		Last:  uint32(255),
 	}
-
-	tcp, err := getTCP(parsed)
-	if err != nil {
-		return nil, err
+	if match.ICMPType != nil {
+		typeRange.First = uint32(*match.ICMPType)
+		typeRange.Last = uint32(*match.ICMPType)
 	}
-
-	udp, err := getUDP(parsed)
-	if err != nil {
-		return nil, err
+	return &acl.ACL_Rule_IpRule_Icmp{
+		Icmpv6: match.Protocol == firewall.ProtocolICMPv6,
+		IcmpCodeRange: &acl.ACL_Rule_IpRule_Icmp_Range{
+			First: uint32(0),
+			Last:  uint32(65535),
+		},
+		IcmpTypeRange: typeRange,
 	}
-
-	return &acl.ACL_Rule_IpRule{
-		Ip:   ip,
-		Icmp: icmp,
-		Tcp:  tcp,
-		Udp:  udp,
-	}, nil
 }
 
-func getMatch(parsed map[string]string) (*acl.ACL_Rule, error) {
-	ipRule, err := getIPRule(parsed)
-	if err != nil {
-		return nil, err
+func getPortRange(portRange firewall.PortRange) *acl.ACL_Rule_IpRule_PortRange {
+	return &acl.ACL_Rule_IpRule_PortRange{
+		LowerPort: uint32(portRange.Low),
+		UpperPort: uint32(portRange.High),
 	}
-
-	return &acl.ACL_Rule{
-		IpRule:    ipRule,
-		MacipRule: nil,
-	}, nil
 }
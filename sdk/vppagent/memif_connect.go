@@ -97,7 +97,7 @@ func appendMemifInterface(rv *configurator.Config, connection *connection.Connec
 	name := connection.GetId()
 	var ipAddresses []string
 	if master {
-		ipAddresses = append(ipAddresses, connection.GetContext().GetIpContext().DstIpAddr)
+		ipAddresses = connection.GetContext().GetIpContext().DstIPAddrs()
 	}
 
 	if rv == nil {
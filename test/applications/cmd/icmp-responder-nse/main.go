@@ -31,6 +31,7 @@ import (
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connectioncontext"
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/networkservice"
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/metrics"
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
 	"github.com/networkservicemesh/networkservicemesh/sdk/common"
 	"github.com/networkservicemesh/networkservicemesh/sdk/endpoint"
@@ -106,6 +107,11 @@ func main() {
 
 	composite := endpoint.NewCompositeEndpoint(endpoints...)
 
+	// IPAM pool usage is served with the other NSM metrics
+	if prometheus, err := tools.ReadEnvBool(metrics.PrometheusEnv, metrics.PrometheusDefault); err == nil && prometheus {
+		go metrics.RunPrometheusMetricsServer()
+	}
+
 	nsmEndpoint, err := endpoint.NewNSMEndpoint(context.Background(), configuration, composite)
 	if err != nil {
 		logrus.Fatalf("%v", err)
@@ -1,15 +1,19 @@
 package main
 
 import (
-	"fmt"
-
 	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+
+	"github.com/networkservicemesh/networkservicemesh/sdk/firewall"
+	"github.com/networkservicemesh/networkservicemesh/sdk/vppagent"
 )
 
 const (
+	// aclRules - deprecated named key-value rules, used only if there are no ordered rules
 	aclRules = "aclRules"
+	rules    = "rules"
 )
 
 var viperConfig *viper.Viper
@@ -19,18 +23,41 @@ func initConfig() {
 	viperConfig.SetConfigName("config")
 	viperConfig.AddConfigPath("/etc/vppagent-firewall/")
 
-	if err := viperConfig.ReadInConfig(); err == nil {
-		viperConfig.WatchConfig()
-		viperConfig.OnConfigChange(func(e fsnotify.Event) {
-			fmt.Println("Config file changed:", e.Name)
-		})
-	} else {
+	if err := viperConfig.ReadInConfig(); err != nil {
 		logrus.Errorf("Error reading the config file: %s \n", err)
 	}
 
 	logrus.Infof("Firewall config finished")
 }
 
-func getAclRulesConfig() map[string]string {
-	return viperConfig.GetStringMapString(aclRules)
+// loadFirewallConfig reads and validates the firewall configuration
+func loadFirewallConfig() (*firewall.Config, error) {
+	if !viperConfig.IsSet(rules) {
+		return firewall.FromKeyValueRules(viperConfig.GetStringMapString(aclRules))
+	}
+	config := &firewall.Config{}
+	if err := viperConfig.Unmarshal(config); err != nil {
+		return nil, errors.Wrap(err, "failed to parse firewall config")
+	}
+	return config, config.Validate()
+}
+
+// watchFirewallConfig reloads the ACL rules on config file change, invalid config is ignored
+func watchFirewallConfig(acl *vppagent.ACL) {
+	if viperConfig.ConfigFileUsed() == "" {
+		return
+	}
+	viperConfig.OnConfigChange(func(e fsnotify.Event) {
+		logrus.Infof("Config file changed: %s", e.Name)
+		config, err := loadFirewallConfig()
+		if err == nil {
+			err = acl.Update(config)
+		}
+		if err != nil {
+			logrus.Errorf("Failed to reload firewall config, keeping the previous rules: %v", err)
+			return
+		}
+		logrus.Infof("Firewall rules reloaded: %d rules", len(config.Rules))
+	})
+	viperConfig.WatchConfig()
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/memif"
 	"github.com/networkservicemesh/networkservicemesh/utils"
 
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/metrics"
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
 	"github.com/networkservicemesh/networkservicemesh/sdk/common"
 	"github.com/networkservicemesh/networkservicemesh/sdk/endpoint"
@@ -43,6 +44,15 @@ func main() {
 	logrus.SetLevel(logrus.TraceLevel)
 
 	initConfig()
+	firewallConfig, err := loadFirewallConfig()
+	if err != nil {
+		logrus.Fatalf("Invalid firewall config: %v", err)
+	}
+	acl, err := vppagent.NewFirewallACL(firewallConfig)
+	if err != nil {
+		logrus.Fatalf("Invalid firewall config: %v", err)
+	}
+	watchFirewallConfig(acl)
 
 	configuration := (&common.NSConfiguration{
 		MechanismType: memif.MECHANISM,
@@ -55,10 +65,15 @@ func main() {
 		vppagent.NewClientMemifConnect(configuration),
 		vppagent.NewMemifConnect(configuration),
 		vppagent.NewXConnect(configuration),
-		vppagent.NewACL(getAclRulesConfig()),
+		acl,
 		vppagent.NewCommit("localhost:9112", true),
 	)
 
+	// IPAM pool usage is served with the other NSM metrics
+	if prometheus, err := tools.ReadEnvBool(metrics.PrometheusEnv, metrics.PrometheusDefault); err == nil && prometheus {
+		go metrics.RunPrometheusMetricsServer()
+	}
+
 	nsmEndpoint, err := endpoint.NewNSMEndpoint(context.TODO(), configuration, composite)
 	if err != nil {
 		logrus.Panicf("%v", err)
@@ -21,6 +21,7 @@ import (
 	"github.com/networkservicemesh/networkservicemesh/controlplane/api/connection/mechanisms/memif"
 	"github.com/networkservicemesh/networkservicemesh/utils"
 
+	"github.com/networkservicemesh/networkservicemesh/controlplane/pkg/metrics"
 	"github.com/networkservicemesh/networkservicemesh/pkg/tools"
 	"github.com/networkservicemesh/networkservicemesh/sdk/common"
 	"github.com/networkservicemesh/networkservicemesh/sdk/endpoint"
@@ -49,6 +50,11 @@ func main() {
 		vppagent.NewCommit("localhost:9112", true),
 	)
 
+	// IPAM pool usage is served with the other NSM metrics
+	if prometheus, err := tools.ReadEnvBool(metrics.PrometheusEnv, metrics.PrometheusDefault); err == nil && prometheus {
+		go metrics.RunPrometheusMetricsServer()
+	}
+
 	nsmEndpoint, err := endpoint.NewNSMEndpoint(nil, configuration, composite)
 	if err != nil {
 		logrus.Panicf("%v", err)
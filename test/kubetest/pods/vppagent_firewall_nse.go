@@ -16,9 +16,15 @@ func VppAgentFirewallNSEConfigMapICMPHTTP(name, namespace string) *v1.ConfigMap
 			Kind: "ConfigMap",
 		},
 		Data: map[string]string{
-			"config.yaml": "aclRules:\n" +
-				"  \"Allow ICMP\": \"action=reflect,icmptype=8\"\n" +
-				"  \"Allow TCP 80\": \"action=reflect,tcplowport=80,tcpupport=80\"\n",
+			"config.yaml": "rules:\n" +
+				"  - name: allow-icmp\n" +
+				"    action: reflect\n" +
+				"    protocols: [icmp]\n" +
+				"    icmpType: 8\n" +
+				"  - name: allow-http\n" +
+				"    action: reflect\n" +
+				"    protocols: [tcp]\n" +
+				"    destinationPorts: [\"80\"]\n",
 		},
 	}
 }
@@ -28,8 +34,7 @@ func VppAgentFirewallNSEPodWithConfigMap(name string, node *v1.Node, env map[str
 	p := VppAgentFirewallNSEPod(name, node, env)
 	p.Spec.Containers[0].VolumeMounts = append(p.Spec.Containers[0].VolumeMounts, v1.VolumeMount{
 		Name:      p.ObjectMeta.Name + "-config-volume",
-		MountPath: "/etc/vppagent-firewall/config.yaml",
-		SubPath:   "config.yaml",
+		MountPath: "/etc/vppagent-firewall",
 	})
 	p.Spec.Volumes = append(p.Spec.Volumes, v1.Volume{
 		Name: p.ObjectMeta.Name + "-config-volume",